@@ -0,0 +1,30 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+)
+
+func TestDescribeInterval(t *testing.T) {
+	cases := []struct {
+		interval, adaptive sql.NullInt32
+		want               string
+	}{
+		{sql.NullInt32{}, sql.NullInt32{}, "every agg tick"},
+		{
+			sql.NullInt32{},
+			sql.NullInt32{Int32: 5400, Valid: true},
+			"1h30m0s (adaptive, with agg --adaptive)",
+		},
+		{
+			sql.NullInt32{Int32: 300, Valid: true},
+			sql.NullInt32{Int32: 5400, Valid: true},
+			"5m0s (setinterval)",
+		},
+	}
+	for _, tc := range cases {
+		if got := describeInterval(tc.interval, tc.adaptive); got != tc.want {
+			t.Errorf("describeInterval(%+v, %+v) = %q, want %q", tc.interval, tc.adaptive, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package main
+
+import "testing"
+
+func TestParseFeedAuthors(t *testing.T) {
+	body := []byte(`<rss xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel><title>Authors</title>
+<item><title>Creator</title><author>jane@example.com</author><dc:creator>Jane Doe</dc:creator></item>
+<item><title>Podcast</title><itunes:author>Pod Host</itunes:author><author>host@example.com</author></item>
+<item><title>Email with name</title><author>john@example.com (John Smith)</author></item>
+<item><title>Email only</title><author>anon@example.com</author></item>
+<item><title>Nobody</title></item>
+</channel></rss>`)
+
+	feed, err := parseFeed("application/rss+xml", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := []string{"Jane Doe", "Pod Host", "John Smith", "anon@example.com", ""}
+	if len(feed.Channel.Item) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(feed.Channel.Item))
+	}
+	for i, item := range feed.Channel.Item {
+		if got := item.author(); got != want[i] {
+			t.Errorf("%s: author() = %q, want %q", item.Title, got, want[i])
+		}
+	}
+}
+
+func TestParseFeedJSONFeedAuthors(t *testing.T) {
+	body := []byte(`{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "Example",
+		"items": [
+			{"id": "1", "url": "https://example.org/1", "authors": [{"name": "Ada"}, {"name": "Grace"}]},
+			{"id": "2", "url": "https://example.org/2", "author": {"name": "Old Style"}}
+		]
+	}`)
+
+	feed, err := parseFeed("application/feed+json", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := feed.Channel.Item[0].author(); got != "Ada" {
+		t.Errorf("first author = %q, want Ada", got)
+	}
+	if got := feed.Channel.Item[1].author(); got != "Old Style" {
+		t.Errorf("JSON Feed 1.0 author = %q, want Old Style", got)
+	}
+}
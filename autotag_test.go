@@ -0,0 +1,36 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"gator/internal/database"
+)
+
+func TestMatchingTags(t *testing.T) {
+	kubernetes := database.TagRule{Keyword: "kubernetes", Tag: "devops", Field: "any"}
+	k8s := database.TagRule{Keyword: "k8s", Tag: "devops", Field: "any"}
+	goTitle := database.TagRule{Keyword: "Go", Tag: "golang", Field: "title", CaseSensitive: true}
+	rustDesc := database.TagRule{Keyword: "rust", Tag: "rust", Field: "description"}
+
+	cases := []struct {
+		name        string
+		rules       []database.TagRule
+		title, desc string
+		want        []string
+	}{
+		{"no rules", nil, "Kubernetes 1.31", "", nil},
+		{"case-insensitive by default", []database.TagRule{kubernetes}, "KUBERNETES 1.31 released", "", []string{"devops"}},
+		{"same tag once", []database.TagRule{kubernetes, k8s}, "Kubernetes (k8s) tips", "", []string{"devops"}},
+		{"case-sensitive", []database.TagRule{goTitle}, "Let's go hiking", "", nil},
+		{"case-sensitive match", []database.TagRule{goTitle}, "Go 1.25 is out", "", []string{"golang"}},
+		{"field is respected", []database.TagRule{rustDesc}, "Rust 2024", "Release notes", nil},
+		{"description as text", []database.TagRule{rustDesc}, "Weekly", `<a href="https://rust-lang.org">link</a>`, nil},
+		{"rule order", []database.TagRule{rustDesc, k8s}, "k8s", "<p>rust</p>", []string{"rust", "devops"}},
+	}
+	for _, tc := range cases {
+		if got := matchingTags(tc.rules, tc.title, tc.desc); !reflect.DeepEqual(got, tc.want) {
+			t.Errorf("%s: matchingTags = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+//go:build sqlite
+
+package main
+
+import (
+	"database/sql"
+
+	"gator/internal/config"
+	"gator/internal/database"
+	"gator/internal/database/sqlite"
+)
+
+func init() {
+	backends[config.DriverSQLite] = backend{
+		open:          sqlite.Open,
+		queries:       func(db *sql.DB) database.Store { return sqlite.New(db) },
+		migrate:       sqlite.Migrate,
+		missingTables: sqlite.MissingTables,
+	}
+}
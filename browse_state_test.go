@@ -0,0 +1,60 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestBrowseStateNextAndPrev(t *testing.T) {
+	saved := browseStateFile
+	browseStateFile = filepath.Join(t.TempDir(), "browse_state.json")
+	defer func() { browseStateFile = saved }()
+
+	if _, err := loadBrowseState("alice", "h1"); err == nil {
+		t.Fatal("expected an error before any browse")
+	}
+
+	first := browseState{User: "alice", Limit: 10, Sort: "title", Order: "asc", FeedsHash: "h1"}
+	if err := saveBrowseState(first); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+	last, err := loadBrowseState("alice", "h1")
+	if err != nil || last != first {
+		t.Fatalf("expected %+v back, got %+v (%v)", first, last, err)
+	}
+
+	if _, err := last.step("prev"); err == nil {
+		t.Fatal("expected prev on the first page to fail")
+	}
+	next, _ := last.step("next")
+	if next.Offset != 10 {
+		t.Errorf("next moved to offset %d, want 10", next.Offset)
+	}
+	// A page that started mid-way goes back to the start rather than below it
+	next.Offset = 5
+	if prev, err := next.step("prev"); err != nil || prev.Offset != 0 {
+		t.Errorf("prev moved to offset %d (%v), want 0", prev.Offset, err)
+	}
+
+	if _, err := loadBrowseState("bob", "h1"); err == nil {
+		t.Fatal("expected another user's position to be ignored")
+	}
+	if _, err := loadBrowseState("alice", "h2"); err == nil {
+		t.Fatal("expected a changed feed list to invalidate the position")
+	}
+	if _, err := loadBrowseState("alice", "h1"); err == nil {
+		t.Fatal("expected the invalidated position to be gone")
+	}
+}
+
+func TestFeedIDsHashIgnoresOrder(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	if feedIDsHash([]uuid.UUID{a, b}) != feedIDsHash([]uuid.UUID{b, a}) {
+		t.Error("expected the same feeds in another order to hash the same")
+	}
+	if feedIDsHash([]uuid.UUID{a}) == feedIDsHash([]uuid.UUID{a, b}) {
+		t.Error("expected following another feed to change the hash")
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateRunes(t *testing.T) {
+	if got := truncateRunes("short", 200); got != "short" {
+		t.Errorf("short text changed: %q", got)
+	}
+
+	long := strings.Repeat("é", 250)
+	got := truncateRunes(long, descriptionPreviewLen)
+	if n := len([]rune(got)); n != descriptionPreviewLen {
+		t.Errorf("truncated to %d characters, want %d", n, descriptionPreviewLen)
+	}
+	if !strings.HasSuffix(got, "...") {
+		t.Errorf("expected the cut to be marked, got %q", got)
+	}
+}
+
+func TestTerminalWidthFromColumns(t *testing.T) {
+	t.Setenv("COLUMNS", "120")
+	if got := terminalWidth(); got != 120 {
+		t.Errorf("terminalWidth() = %d, want 120", got)
+	}
+}
@@ -0,0 +1,81 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gator/internal/config"
+)
+
+// fakeEditor points $EDITOR at a script that replaces the file it's given with content,
+// then exits non-zero when fails is set
+func fakeEditor(t *testing.T, content string, fails bool) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "new.json"), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	script := filepath.Join(dir, "editor.sh")
+	body := "#!/bin/sh\ncp " + filepath.Join(dir, "new.json") + " \"$1\"\n"
+	if fails {
+		body += "exit 1\n"
+	}
+	if err := os.WriteFile(script, []byte(body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("EDITOR", script)
+}
+
+func TestConfigEdit(t *testing.T) {
+	const before = `{"db_url":"postgres://localhost/gator"}`
+	cases := []struct {
+		name    string
+		edited  string
+		fails   bool
+		wantErr string
+		want    string
+	}{
+		{"valid", `{"db_url":"postgres://localhost/other"}`, false, "", `{"db_url":"postgres://localhost/other"}`},
+		{"broken json", `{"db_url":`, false, "couldn't parse config", before},
+		{"empty db_url", `{"db_url":""}`, false, "db_url must not be empty", before},
+		{"editor fails", `{"db_url":`, true, "previous config was restored", before},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "config.json")
+			if err := os.WriteFile(path, []byte(before), 0644); err != nil {
+				t.Fatal(err)
+			}
+			cfg, err := config.ReadFrom(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			fakeEditor(t, c.edited, c.fails)
+
+			err = handlerConfigEdit(&state{cfg: &cfg}, command{name: "configedit"})
+			if c.wantErr == "" && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.wantErr != "" && (err == nil || !strings.Contains(err.Error(), c.wantErr)) {
+				t.Fatalf("expected error containing %q, got %v", c.wantErr, err)
+			}
+
+			got, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != c.want {
+				t.Errorf("config after edit = %s, want %s", got, c.want)
+			}
+			backup, err := os.ReadFile(path + ".bak")
+			if err != nil {
+				t.Fatalf("expected a backup: %v", err)
+			}
+			if string(backup) != before {
+				t.Errorf("backup = %s, want %s", backup, before)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+//go:build sqlite
+
+package main
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/database/sqlite"
+
+	"github.com/google/uuid"
+)
+
+func TestCopyTable(t *testing.T) {
+	ctx := context.Background()
+	src, err := sqlite.Open(filepath.Join(t.TempDir(), "src.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	dst, err := sqlite.Open(filepath.Join(t.TempDir(), "dst.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer dst.Close()
+
+	createdAt := time.Date(2025, 3, 1, 9, 30, 0, 0, time.FixedZone("CET", 3600))
+	user, err := sqlite.New(src).CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: createdAt, UpdatedAt: createdAt, Name: "alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err := dst.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	copied, err := copyTable(ctx, src, tx, "users")
+	if err != nil {
+		t.Fatalf("copyTable: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	if copied != 1 {
+		t.Errorf("expected 1 row copied, got %d", copied)
+	}
+
+	got, err := sqlite.New(dst).GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatalf("copied user not found: %v", err)
+	}
+	if got.ID != user.ID || !got.CreatedAt.Equal(createdAt) {
+		t.Errorf("expected %+v, got %+v", user, got)
+	}
+}
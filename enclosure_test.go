@@ -0,0 +1,92 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+
+	"gator/internal/database"
+)
+
+func TestParseFeedEnclosures(t *testing.T) {
+	body := []byte(`<rss version="2.0" xmlns:media="http://search.yahoo.com/mrss/"><channel>
+		<title>Podcast</title>
+		<item><title>Episode 1</title><enclosure url="https://cdn.example.com/1.mp3" length="12345" type="audio/mpeg"/></item>
+		<item><title>Clip</title><media:content url="https://cdn.example.com/clip.mp4" type="video/mp4"/></item>
+		<item><title>Both</title>
+			<enclosure url="https://cdn.example.com/both.mp3" length="" type="audio/mpeg"/>
+			<media:content url="https://cdn.example.com/both.mp4" type="video/mp4"/>
+		</item>
+		<item><title>Text only</title></item>
+	</channel></rss>`)
+
+	feed, err := parseFeed("application/rss+xml", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(feed.Channel.Item) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(feed.Channel.Item))
+	}
+
+	cases := []struct {
+		url, mediaType string
+		length         int64
+		ok             bool
+	}{
+		{"https://cdn.example.com/1.mp3", "audio/mpeg", 12345, true},
+		{"https://cdn.example.com/clip.mp4", "video/mp4", 0, true},
+		{"https://cdn.example.com/both.mp3", "audio/mpeg", 0, true},
+		{"", "", 0, false},
+	}
+	for i, want := range cases {
+		url, mediaType, length, ok := feed.Channel.Item[i].enclosure()
+		if url != want.url || mediaType != want.mediaType || length.Int64 != want.length || length.Valid != (want.length > 0) || ok != want.ok {
+			t.Errorf("item %d: got %q %q %v %v, want %+v", i, url, mediaType, length, ok, want)
+		}
+	}
+}
+
+func TestParseFeedITunes(t *testing.T) {
+	body := []byte(`<rss version="2.0" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+		<title>Show</title>
+		<itunes:author>Jane Host</itunes:author>
+		<itunes:image href="https://example.com/cover.jpg"/>
+		<item><title>Pilot</title><itunes:episode>1</itunes:episode><itunes:season>2</itunes:season><itunes:duration>1:02:03</itunes:duration></item>
+	</channel></rss>`)
+
+	feed, err := parseFeed("application/rss+xml", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if feed.Channel.Title != "Show" || feed.Channel.Author != "Jane Host" || feed.Channel.Image.Href != "https://example.com/cover.jpg" {
+		t.Fatalf("unexpected channel %+v", feed.Channel)
+	}
+	item := feed.Channel.Item[0]
+	if item.Title != "Pilot" || item.Episode != "1" || item.Season != "2" || item.Duration != "1:02:03" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+}
+
+func TestFormatEpisode(t *testing.T) {
+	cases := []struct {
+		episode database.GetEpisodeInfoForPostsRow
+		want    string
+	}{
+		{database.GetEpisodeInfoForPostsRow{}, ""},
+		{database.GetEpisodeInfoForPostsRow{
+			EpisodeNumber: sql.NullInt32{Int32: 12, Valid: true},
+			SeasonNumber:  sql.NullInt32{Int32: 2, Valid: true},
+			DurationSecs:  sql.NullInt32{Int32: 3723, Valid: true},
+		}, "S2 E12 · 1:02:03"},
+		{database.GetEpisodeInfoForPostsRow{
+			EpisodeNumber: sql.NullInt32{Int32: 7, Valid: true},
+		}, "E7"},
+		{database.GetEpisodeInfoForPostsRow{
+			DurationSecs: sql.NullInt32{Int32: 1805, Valid: true},
+		}, "30:05"},
+	}
+	for _, tc := range cases {
+		if got := formatEpisode(tc.episode); got != tc.want {
+			t.Errorf("formatEpisode(%+v) = %q, want %q", tc.episode, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"gator/internal/database"
+)
+
+func TestPickFeed(t *testing.T) {
+	goFeed := database.Feed{Name: "Go", Url: "https://go.dev/blog/feed.atom"}
+	goWeekly := database.Feed{Name: "Golang Weekly", Url: "https://golangweekly.com/rss"}
+
+	if _, err := pickFeed("nothing", nil); err == nil {
+		t.Error("expected no matches to be an error")
+	}
+	if feed, err := pickFeed("weekly", []database.Feed{goWeekly}); err != nil || feed.Name != goWeekly.Name {
+		t.Errorf("expected the only match, got %q (%v)", feed.Name, err)
+	}
+	// Exact name matches are sorted first by SearchFeedsByName
+	if feed, err := pickFeed("go", []database.Feed{goFeed, goWeekly}); err != nil || feed.Name != goFeed.Name {
+		t.Errorf("expected the exact name match, got %q (%v)", feed.Name, err)
+	}
+
+	_, err := pickFeed("g", []database.Feed{goFeed, goWeekly})
+	if err == nil {
+		t.Fatal("expected several matches to be ambiguous")
+	}
+	for _, want := range []string{"Go (https://go.dev/blog/feed.atom)", "Golang Weekly (https://golangweekly.com/rss)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the ambiguous matches to be listed, got %q", err)
+		}
+	}
+}
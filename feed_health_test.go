@@ -0,0 +1,38 @@
+package main
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/tui"
+)
+
+func TestFeedHealth(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	fetched := func(ago time.Duration) sql.NullTime {
+		return sql.NullTime{Time: now.Add(-ago), Valid: true}
+	}
+	secs := func(d time.Duration) sql.NullInt32 {
+		return sql.NullInt32{Int32: int32(d.Seconds()), Valid: true}
+	}
+	tests := []struct {
+		name string
+		row  database.GetFeedsWithHealthRow
+		want tui.Health
+	}{
+		{"never fetched", database.GetFeedsWithHealthRow{}, tui.HealthStale},
+		{"failing", database.GetFeedsWithHealthRow{LastFetchedAt: fetched(time.Minute), ConsecutiveErrors: 2}, tui.HealthFailing},
+		{"recent, agg tick", database.GetFeedsWithHealthRow{LastFetchedAt: fetched(90 * time.Minute)}, tui.HealthOK},
+		{"old, agg tick", database.GetFeedsWithHealthRow{LastFetchedAt: fetched(3 * time.Hour)}, tui.HealthStale},
+		{"within own interval", database.GetFeedsWithHealthRow{LastFetchedAt: fetched(30 * time.Hour), IntervalSecs: secs(24 * time.Hour)}, tui.HealthOK},
+		{"past adaptive interval", database.GetFeedsWithHealthRow{LastFetchedAt: fetched(time.Hour), AdaptiveIntervalSecs: secs(10 * time.Minute)}, tui.HealthStale},
+		{"own interval wins", database.GetFeedsWithHealthRow{LastFetchedAt: fetched(time.Hour), IntervalSecs: secs(time.Hour), AdaptiveIntervalSecs: secs(10 * time.Minute)}, tui.HealthOK},
+	}
+	for _, tt := range tests {
+		if got := feedHealth(tt.row, now); got != tt.want {
+			t.Errorf("%s: feedHealth = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import "testing"
+
+func TestNewFeedPage(t *testing.T) {
+	cases := []struct {
+		limit, offset, page int
+		want                feedPage
+	}{
+		{0, 0, 0, feedPage{}},
+		{10, 30, 0, feedPage{limit: 10, offset: 30}},
+		{10, 0, 3, feedPage{limit: 10, offset: 20}},
+		{0, 0, 2, feedPage{limit: 20, offset: 20}},
+		{0, 0, 1, feedPage{limit: 20}},
+	}
+	for _, tc := range cases {
+		got, err := newFeedPage(tc.limit, tc.offset, tc.page, "")
+		if err != nil {
+			t.Errorf("newFeedPage(%d, %d, %d): unexpected error %v", tc.limit, tc.offset, tc.page, err)
+			continue
+		}
+		if got != tc.want {
+			t.Errorf("newFeedPage(%d, %d, %d) = %+v, want %+v", tc.limit, tc.offset, tc.page, got, tc.want)
+		}
+	}
+
+	if _, err := newFeedPage(10, 5, 2, ""); err == nil {
+		t.Error("expected an error combining --offset and --page")
+	}
+	if _, err := newFeedPage(-1, 0, 0, ""); err == nil {
+		t.Error("expected an error for a negative limit")
+	}
+}
+
+func TestFeedPagePattern(t *testing.T) {
+	cases := map[string]string{
+		"":         "%%",
+		"Hacker":   "%Hacker%",
+		"100%_off": `%100\%\_off%`,
+	}
+	for filter, want := range cases {
+		if got := (feedPage{filter: filter}).pattern(); got != want {
+			t.Errorf("pattern for %q = %q, want %q", filter, got, want)
+		}
+	}
+}
+
+func TestFeedPageFooter(t *testing.T) {
+	if got := feedPageFooter(20, 20, 153); got != "Showing 21-40 of 153" {
+		t.Errorf("got %q", got)
+	}
+	if got := feedPageFooter(160, 0, 153); got != "Showing 0 of 153" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRelativeTime(t *testing.T) {
+	now := time.Date(2025, 10, 20, 12, 0, 0, 0, time.UTC)
+	cases := map[time.Duration]string{
+		10 * time.Second:             "just now",
+		5 * time.Minute:              "5m ago",
+		2*time.Hour + 59*time.Minute: "2h ago",
+		49 * time.Hour:               "2d ago",
+	}
+	for ago, want := range cases {
+		if got := relativeTime(now.Add(-ago), now); got != want {
+			t.Errorf("relativeTime(%v ago) = %q, want %q", ago, got, want)
+		}
+	}
+}
+
+func TestFeedLineColorCodesMatchLength(t *testing.T) {
+	// feeds --verbose relies on this to keep its columns aligned
+	if len(ansiRed) != len(ansiDefault) {
+		t.Errorf("colour codes differ in length: %q and %q", ansiRed, ansiDefault)
+	}
+}
@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+)
+
+func TestFetchFeedConditionalGet(t *testing.T) {
+	const etag = `"v1"`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 02 Jan 2006 15:04:05 GMT")
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss><channel><title>Cached</title></channel></rss>`))
+	}))
+	defer srv.Close()
+
+	feed, result, err := fetchFeed(context.Background(), srv.Client(), srv.URL, feedCache{}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if feed.Channel.Title != "Cached" {
+		t.Fatalf("unexpected title %q", feed.Channel.Title)
+	}
+	if result.Cache.ETag != etag || result.Cache.LastModified == "" {
+		t.Fatalf("expected cache validators to be returned, got %+v", result.Cache)
+	}
+
+	_, _, err = fetchFeed(context.Background(), srv.Client(), srv.URL, result.Cache, "")
+	if !errors.Is(err, ErrNotModified) {
+		t.Fatalf("expected ErrNotModified, got %v", err)
+	}
+}
+
+func TestFetchFeedTracksPermanentRedirects(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/moved", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/borrowed", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed", http.StatusFound)
+	})
+	mux.HandleFunc("/moved-then-borrowed", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/borrowed", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<rss><channel><title>Redirected</title></channel></rss>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	tests := []struct {
+		path           string
+		wantFinal      string
+		wantRedirected bool
+	}{
+		{"/feed", "/feed", false},
+		{"/moved", "/feed", true},
+		{"/borrowed", "/borrowed", false},
+		{"/moved-then-borrowed", "/borrowed", true},
+	}
+	for _, tt := range tests {
+		_, result, err := fetchFeed(context.Background(), srv.Client(), srv.URL+tt.path, feedCache{}, "")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.path, err)
+		}
+		if result.FinalURL != srv.URL+tt.wantFinal || result.WasRedirected != tt.wantRedirected {
+			t.Errorf("%s: got final URL %s, redirected %v; want %s, %v",
+				tt.path, result.FinalURL, result.WasRedirected, srv.URL+tt.wantFinal, tt.wantRedirected)
+		}
+	}
+}
+
+func TestFetchFeedDedupesNormalizedLinks(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<rss><channel><title>Dupes</title>
+<item><title>First</title><link>https://example.com/post</link></item>
+<item><title>Tracked</title><link>http://example.com/post/?utm_source=rss#top</link></item>
+<item><title>Other</title><link>https://example.com/post?id=2&amp;utm_medium=feed</link></item>
+<item><title>No link</title></item>
+<item><title>Also no link</title></item>
+</channel></rss>`))
+	}))
+	defer srv.Close()
+
+	feed, _, err := fetchFeed(context.Background(), srv.Client(), srv.URL, feedCache{}, "")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var titles []string
+	for _, item := range feed.Channel.Item {
+		titles = append(titles, item.Title)
+	}
+	if got := strings.Join(titles, ", "); got != "First, Other, No link, Also no link" {
+		t.Errorf("unexpected items after dedupe: %s", got)
+	}
+}
+
+func TestFetchFeedUserAgent(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("User-Agent")
+		w.Write([]byte(`<rss><channel><title>Agent</title></channel></rss>`))
+	}))
+	defer srv.Close()
+
+	if _, _, err := fetchFeed(context.Background(), srv.Client(), srv.URL, feedCache{}, ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != defaultUserAgent {
+		t.Errorf("expected the default User-Agent %q, got %q", defaultUserAgent, got)
+	}
+
+	const browserLike = "Mozilla/5.0 (compatible; Gator/1.0)"
+	if _, _, err := fetchFeed(context.Background(), srv.Client(), srv.URL, feedCache{}, browserLike); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != browserLike {
+		t.Errorf("expected the feed's User-Agent %q, got %q", browserLike, got)
+	}
+}
+
+func TestScrapeFeedsConcurrentlyTimeoutDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer slow.Close()
+	defer close(release)
+
+	fast := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`<rss><channel><title>Fast</title></channel></rss>`))
+	}))
+	defer fast.Close()
+
+	feeds := []database.Feed{{Url: slow.URL}}
+	for range 4 {
+		feeds = append(feeds, database.Feed{Url: fast.URL})
+	}
+
+	var mu sync.Mutex
+	fetched := 0
+	start := time.Now()
+	errs := scrapeFeedsConcurrently(context.Background(), feeds, 2, 200*time.Millisecond, func(ctx context.Context, client *http.Client, feed database.Feed) error {
+		_, _, err := fetchFeed(ctx, client, feed.Url, feedCache{}, "")
+		if err == nil {
+			mu.Lock()
+			fetched++
+			mu.Unlock()
+		}
+		return err
+	})
+
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Fatalf("scrape took %s, slow feed blocked the pool", elapsed)
+	}
+	if len(errs) != 1 {
+		t.Fatalf("expected only the slow feed to fail, got %v", errs)
+	}
+	if fetched != 4 {
+		t.Fatalf("expected 4 fast feeds to be fetched, got %d", fetched)
+	}
+}
+
+func TestProbeFeed(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/feed", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/feed", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "application/atom+xml")
+		w.Write([]byte(`<?xml version="1.0"?><feed xmlns="http://www.w3.org/2005/Atom"></feed>`))
+	})
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><body>not a feed</body></html>`))
+	})
+	mux.HandleFunc("/loop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/loop", http.StatusFound)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	result := probeFeed(context.Background(), srv.URL+"/old")
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if result.StatusCode != http.StatusOK || result.FinalURL != srv.URL+"/feed" || result.Format != "Atom" {
+		t.Fatalf("unexpected probe result: %+v", result)
+	}
+
+	result = probeFeed(context.Background(), srv.URL+"/page")
+	if !errors.Is(result.Err, errInvalidFeed) {
+		t.Fatalf("expected parse error for HTML page, got %v", result.Err)
+	}
+
+	result = probeFeed(context.Background(), srv.URL+"/loop")
+	if result.Err == nil || !strings.Contains(result.Err.Error(), "redirects") {
+		t.Fatalf("expected redirect limit error, got %v", result.Err)
+	}
+}
+
+func TestFetchFeedWithRetry(t *testing.T) {
+	saved := fetchBackoff
+	fetchBackoff = []time.Duration{time.Millisecond, time.Millisecond, time.Millisecond}
+	defer func() { fetchBackoff = saved }()
+
+	var mu sync.Mutex
+	hits := map[string]int{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		hits[r.URL.Path]++
+		n := hits[r.URL.Path]
+		mu.Unlock()
+
+		switch r.URL.Path {
+		case "/flaky":
+			if n < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+		case "/limited":
+			if n == 1 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+		case "/gone":
+			w.WriteHeader(http.StatusNotFound)
+			return
+		case "/down":
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.Write([]byte(`<rss><channel><title>OK</title></channel></rss>`))
+	}))
+	defer srv.Close()
+
+	for _, path := range []string{"/flaky", "/limited"} {
+		if _, _, err := fetchFeedWithRetry(context.Background(), slog.New(slog.DiscardHandler), srv.Client(), srv.URL+path, feedCache{}, ""); err != nil {
+			t.Fatalf("%s: expected retries to succeed, got %v", path, err)
+		}
+	}
+
+	_, _, err := fetchFeedWithRetry(context.Background(), slog.New(slog.DiscardHandler), srv.Client(), srv.URL+"/gone", feedCache{}, "")
+	if err == nil || isTransientFetchError(err) || hits["/gone"] != 1 {
+		t.Fatalf("expected a single permanent failure for 404, got %v after %d requests", err, hits["/gone"])
+	}
+
+	_, _, err = fetchFeedWithRetry(context.Background(), slog.New(slog.DiscardHandler), srv.Client(), srv.URL+"/down", feedCache{}, "")
+	if !isTransientFetchError(err) || hits["/down"] != 4 {
+		t.Fatalf("expected 4 attempts ending in a transient error, got %v after %d requests", err, hits["/down"])
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Duration{
+		"":                              0,
+		"5":                             5 * time.Second,
+		"-5":                            0,
+		"3600":                          maxRetryAfter,
+		"Mon, 01 Jan 2024 12:00:30 GMT": 30 * time.Second,
+		"Mon, 01 Jan 2024 11:00:00 GMT": 0,
+		"soon":                          0,
+	}
+	for header, want := range cases {
+		if got := parseRetryAfter(header, now); got != want {
+			t.Fatalf("parseRetryAfter(%q) = %s, want %s", header, got, want)
+		}
+	}
+}
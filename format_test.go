@@ -0,0 +1,28 @@
+package main
+
+import (
+	"testing"
+
+	"gator/internal/output"
+)
+
+func TestSupportsFormat(t *testing.T) {
+	cases := []struct {
+		format, cmd string
+		want        bool
+	}{
+		{output.Text, "agg", true},
+		{output.JSON, "feeds", true},
+		{output.JSON, "browse", true},
+		{output.JSON, "agg", false},
+		{output.JSON, "addfeed", false},
+		{output.CSV, "search", true},
+		{output.CSV, "export", true},
+		{output.CSV, "feeds", false},
+	}
+	for _, c := range cases {
+		if got := supportsFormat(c.format, c.cmd); got != c.want {
+			t.Fatalf("supportsFormat(%q, %q) = %v, want %v", c.format, c.cmd, got, c.want)
+		}
+	}
+}
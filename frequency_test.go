@@ -0,0 +1,40 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"gator/internal/database"
+)
+
+func TestRankFeedFrequency(t *testing.T) {
+	now := time.Now()
+	rows := []database.GetFeedFrequencyStatsRow{
+		{Name: "busy", PostCount: 40, NewestPost: now.Add(-2 * time.Hour)},
+		{Name: "Another", PostCount: 12, NewestPost: now.Add(-time.Hour)},
+		{Name: "quiet", PostCount: 0},
+	}
+	names := func(entries []frequencyEntry) (out []string) {
+		for _, e := range entries {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	byName := rankFeedFrequency(rows, "name", 0)
+	if got := names(byName); got[0] != "Another" || got[1] != "busy" || got[2] != "quiet" {
+		t.Errorf("unexpected name order %v", got)
+	}
+	if byName[0].rank != 2 {
+		t.Errorf("expected ranks to follow frequency, got %d for Another", byName[0].rank)
+	}
+
+	newest := rankFeedFrequency(rows, "newest", 0)
+	if got := names(newest); got[0] != "Another" || got[2] != "quiet" {
+		t.Errorf("unexpected newest order %v", got)
+	}
+
+	if got := names(rankFeedFrequency(rows, "frequency", 2)); len(got) != 2 || got[0] != "busy" {
+		t.Errorf("expected the two busiest feeds, got %v", got)
+	}
+}
@@ -0,0 +1,30 @@
+package main
+
+import (
+	"slices"
+	"testing"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestGroupsByFeed(t *testing.T) {
+	goBlog, news := uuid.New(), uuid.New()
+	members := []database.GetFeedGroupMembersForUserRow{
+		{GroupName: "Reading", FeedID: goBlog, FeedName: "Go Blog"},
+		{GroupName: "Tech", FeedID: goBlog, FeedName: "Go Blog"},
+		{GroupName: "Tech", FeedID: news, FeedName: "News"},
+	}
+
+	groups := groupsByFeed(members)
+	if got := groups[goBlog]; !slices.Equal(got, []string{"Reading", "Tech"}) {
+		t.Errorf("Go Blog groups = %q, want Reading and Tech", got)
+	}
+	if got := groups[news]; !slices.Equal(got, []string{"Tech"}) {
+		t.Errorf("News groups = %q, want Tech", got)
+	}
+	if got := groups[uuid.New()]; got != nil {
+		t.Errorf("ungrouped feed has groups %q", got)
+	}
+}
@@ -1,28 +1,247 @@
+// Package api serves gator's HTTP API.
 package api
 
 import (
+	"context"
+	"database/sql"
 	"encoding/json"
-	"github.com/gorilla/mux"
+	"errors"
+	"fmt"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/events"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
 )
 
-// StartAPI initializes and starts the HTTP API server
-func StartAPI() {
+// shutdownTimeout is how long in-flight requests get to finish once Start's context is cancelled
+const shutdownTimeout = 30 * time.Second
+
+// Post limits for GET /posts?limit=N
+const (
+	defaultPostLimit = 20
+	maxPostLimit     = 100
+)
+
+// Options configure the server beyond its address and database
+type Options struct {
+	// TLSCert and TLSKey are certificate and key files; HTTPS is served when both are set
+	TLSCert string
+	TLSKey  string
+	// Refresh fetches a feed immediately and returns how many new posts were saved;
+	// POST /feeds/{feedID}/refresh answers 501 when it's nil
+	Refresh func(ctx context.Context, feedID uuid.UUID) (int64, error)
+	// Bus carries the new-post events streamed by /ws/posts; without one the socket stays quiet
+	Bus *events.Bus
+	// AllowedOrigins get CORS headers, "*" meaning any origin; none means no CORS headers
+	AllowedOrigins []string
+}
+
+// Post is a post as returned by GET /posts
+type Post struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	Read        bool       `json:"read"`
+	Bookmarked  bool       `json:"bookmarked"`
+}
+
+// BookmarkRequest is the body of POST /bookmark
+type BookmarkRequest struct {
+	PostID uuid.UUID `json:"post_id"`
+}
+
+// ErrorResponse is the body of every failed request; Field names the invalid input
+// of a validation error
+type ErrorResponse struct {
+	Error string `json:"error"`
+	Field string `json:"field,omitempty"`
+}
+
+type server struct {
+	db      database.Querier
+	refresh func(ctx context.Context, feedID uuid.UUID) (int64, error)
+	bus     *events.Bus
+	origins []string
+	// closing is closed on shutdown so WebSocket connections, which Shutdown doesn't track, end too
+	closing   chan struct{}
+	closeOnce sync.Once
+	// userForKey finds the owner of an API key hash; tests replace it to avoid a database
+	userForKey func(ctx context.Context, keyHash string) (database.User, error)
+}
+
+// NewRouter returns the API's routes, e.g. for httptest.NewServer
+func NewRouter(db database.Querier, opts Options) http.Handler {
+	return newServer(db, opts).routes()
+}
+
+func newServer(db database.Querier, opts Options) *server {
+	s := &server{db: db, refresh: opts.Refresh, bus: opts.Bus, origins: opts.AllowedOrigins, closing: make(chan struct{})}
+	s.userForKey = s.useAPIKey
+	return s
+}
+
+// closeSockets tells open WebSocket connections to finish
+func (s *server) closeSockets() {
+	s.closeOnce.Do(func() { close(s.closing) })
+}
+
+// routes registers the endpoints: the spec and its docs are public, everything else
+// needs an API key. CORS wraps the whole router so preflight requests are answered
+// before routing and authentication.
+func (s *server) routes() http.Handler {
 	r := mux.NewRouter()
+	r.HandleFunc("/openapi.json", s.specJSONHandler).Methods("GET")
+	r.HandleFunc("/openapi.yaml", s.specYAMLHandler).Methods("GET")
+	r.HandleFunc("/docs", s.docsHandler).Methods("GET")
 
-	r.HandleFunc("/posts", getPostsHandler).Methods("GET")
-	r.HandleFunc("/bookmark", bookmarkPostHandler).Methods("POST")
+	authed := r.NewRoute().Subrouter()
+	authed.Use(s.requireAPIKey)
+	authed.HandleFunc("/posts", s.getPostsHandler).Methods("GET")
+	authed.HandleFunc("/bookmark", s.bookmarkPostHandler).Methods("POST")
+	authed.HandleFunc("/feeds", s.getFeedsHandler).Methods("GET")
+	authed.HandleFunc("/feeds", s.createFeedHandler).Methods("POST")
+	authed.HandleFunc("/feeds/{feedID}", s.unfollowFeedHandler).Methods("DELETE")
+	authed.HandleFunc("/feeds/{feedID}/posts", s.getFeedPostsHandler).Methods("GET")
+	authed.HandleFunc("/feeds/{feedID}/refresh", s.refreshFeedHandler).Methods("POST")
+	authed.HandleFunc("/ws/posts", s.postsSocketHandler).Methods("GET")
 
-	http.ListenAndServe(":8080", r)
+	return cors(s.origins, r)
 }
 
-func getPostsHandler(w http.ResponseWriter, r *http.Request) {
-	// Authentication and fetching posts logic here
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode([]string{"Post 1", "Post 2"})
+// Start serves the HTTP API on addr, e.g. "127.0.0.1:8080", until ctx is cancelled,
+// then waits up to 30 seconds for in-flight requests to finish
+func Start(ctx context.Context, addr string, db database.Querier, opts Options) error {
+	s := newServer(db, opts)
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.routes(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	srv.RegisterOnShutdown(s.closeSockets)
+
+	errCh := make(chan error, 1)
+	go func() {
+		if opts.TLSCert != "" && opts.TLSKey != "" {
+			errCh <- srv.ListenAndServeTLS(opts.TLSCert, opts.TLSKey)
+		} else {
+			errCh <- srv.ListenAndServe()
+		}
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("couldn't finish in-flight requests: %w", err)
+	}
+	return nil
+}
+
+func (s *server) getPostsHandler(w http.ResponseWriter, r *http.Request) {
+	limit, ok := postLimit(w, r)
+	if !ok {
+		return
+	}
+
+	user := userFromContext(r.Context())
+	rows, err := s.db.GetPostsForUser(r.Context(), database.GetPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't get posts")
+		return
+	}
+
+	posts := make([]Post, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, postFromRow(row))
+	}
+	writeJSON(w, http.StatusOK, posts)
+}
+
+// postLimit reads ?limit=N, writing a 400 and returning false when it's out of range
+func postLimit(w http.ResponseWriter, r *http.Request) (int, bool) {
+	raw := r.URL.Query().Get("limit")
+	if raw == "" {
+		return defaultPostLimit, true
+	}
+	n, err := strconv.Atoi(raw)
+	if err != nil || n < 1 || n > maxPostLimit {
+		writeFieldError(w, fmt.Sprintf("limit must be between 1 and %d", maxPostLimit), "limit")
+		return 0, false
+	}
+	return n, true
 }
 
-func bookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
-	// Authentication and bookmarking logic here
+// postFromRow converts a post with the user's read and bookmark state to its API shape
+func postFromRow(row database.GetPostsForUserRow) Post {
+	post := Post{
+		ID:          row.ID,
+		Title:       row.Title,
+		URL:         row.Url,
+		Description: row.Description.String,
+		FeedID:      row.FeedID,
+		Read:        row.IsRead,
+		Bookmarked:  row.IsBookmarked,
+	}
+	if row.PublishedAt.Valid {
+		post.PublishedAt = &row.PublishedAt.Time
+	}
+	return post
+}
+
+func (s *server) bookmarkPostHandler(w http.ResponseWriter, r *http.Request) {
+	var req BookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.PostID == uuid.Nil {
+		writeError(w, http.StatusBadRequest, "body must be {\"post_id\": \"<uuid>\"}")
+		return
+	}
+
+	if _, err := s.db.GetPostByID(r.Context(), req.PostID); errors.Is(err, sql.ErrNoRows) {
+		writeError(w, http.StatusNotFound, "post not found")
+		return
+	} else if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't look up post")
+		return
+	}
+
+	err := s.db.BookmarkPost(r.Context(), database.BookmarkPostParams{
+		UserID: userFromContext(r.Context()).ID,
+		PostID: req.PostID,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't bookmark post")
+		return
+	}
 	w.WriteHeader(http.StatusCreated)
 }
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, message string) {
+	writeJSON(w, status, ErrorResponse{Error: message})
+}
+
+// writeFieldError answers 400 for an invalid input, naming the field at fault
+func writeFieldError(w http.ResponseWriter, message, field string) {
+	writeJSON(w, http.StatusBadRequest, ErrorResponse{Error: message, Field: field})
+}
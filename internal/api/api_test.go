@@ -0,0 +1,290 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/events"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+)
+
+const testKey = "gator_testkey"
+
+// testUser owns testKey
+var testUser = database.User{ID: uuid.New(), Name: "tester"}
+
+// newTestServer serves the routes with testKey as the only valid API key
+func newTestServer(t *testing.T, opts Options) *httptest.Server {
+	s := newServer(nil, opts)
+	s.userForKey = func(ctx context.Context, keyHash string) (database.User, error) {
+		if keyHash != HashKey(testKey) {
+			return database.User{}, errUnauthorized
+		}
+		return testUser, nil
+	}
+	srv := httptest.NewServer(s.routes())
+	t.Cleanup(func() {
+		s.closeSockets()
+		srv.Close()
+	})
+	return srv
+}
+
+// do sends a request with the given API key, if any
+func do(t *testing.T, method, url, key, body string) *http.Response {
+	req, err := http.NewRequest(method, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "" {
+		req.Header.Set("Authorization", "Bearer "+key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp
+}
+
+func TestRouterRejectsBadRequests(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	resp := do(t, "GET", srv.URL+"/bookmark", testKey, "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("GET /bookmark = %d, want 405", resp.StatusCode)
+	}
+
+	resp = do(t, "GET", srv.URL+"/posts?limit=1000", testKey, "")
+	var body ErrorResponse
+	json.NewDecoder(resp.Body).Decode(&body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest || body.Error == "" {
+		t.Errorf("GET /posts?limit=1000 = %d %+v, want 400 with an error", resp.StatusCode, body)
+	}
+
+	resp = do(t, "POST", srv.URL+"/bookmark", testKey, `{"post_id": "nope"}`)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("POST /bookmark with a bad ID = %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestRequireAPIKey(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	for name, key := range map[string]string{"missing key": "", "unknown key": "gator_wrong"} {
+		resp := do(t, "GET", srv.URL+"/posts", key, "")
+		var body ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized || body.Error != "invalid or missing API key" {
+			t.Errorf("%s: GET /posts = %d %+v, want 401 with an error", name, resp.StatusCode, body)
+		}
+	}
+
+	// A valid key reaches the handler, which rejects the limit before touching the database
+	resp := do(t, "GET", srv.URL+"/posts?limit=0", testKey, "")
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("GET /posts with a valid key = %d, want the handler's 400", resp.StatusCode)
+	}
+}
+
+func TestGenerateKey(t *testing.T) {
+	key, prefix, err := GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(key, prefix) || len(prefix) != KeyPrefixLen || !strings.HasPrefix(key, "gator_") {
+		t.Errorf("GenerateKey() = %q, %q; want a gator_ key starting with its prefix", key, prefix)
+	}
+	if other, _, _ := GenerateKey(); other == key {
+		t.Error("GenerateKey returned the same key twice")
+	}
+	if HashKey(key) == HashKey(key+"x") || len(HashKey(key)) != 64 {
+		t.Error("HashKey should return distinct hex SHA-256 digests")
+	}
+}
+
+func TestStartStopsWhenContextIsCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- Start(ctx, "127.0.0.1:0", nil, Options{}) }()
+
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Fatalf("Start returned %v after cancel, want nil", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Start didn't return after its context was cancelled")
+	}
+}
+
+func TestFeedRoutesValidateInput(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	for _, tc := range []struct {
+		method, path, body, field string
+	}{
+		{"POST", "/feeds", `{"name": "", "url": "https://example.com/rss"}`, "name"},
+		{"POST", "/feeds", `{"name": "Example", "url": "example.com/rss"}`, "url"},
+		{"POST", "/feeds", `{"name": "Example", "url": "ftp://example.com/rss"}`, "url"},
+		{"POST", "/feeds", `not json`, ""},
+		{"DELETE", "/feeds/nope", "", "feedID"},
+		{"GET", "/feeds/nope/posts", "", "feedID"},
+		{"GET", "/feeds/" + uuid.NewString() + "/posts?limit=0", "", "limit"},
+		{"POST", "/feeds/nope/refresh", "", "feedID"},
+	} {
+		resp := do(t, tc.method, srv.URL+tc.path, testKey, tc.body)
+		var body ErrorResponse
+		json.NewDecoder(resp.Body).Decode(&body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest || body.Error == "" || body.Field != tc.field {
+			t.Errorf("%s %s %s = %d %+v, want 400 on field %q", tc.method, tc.path, tc.body, resp.StatusCode, body, tc.field)
+		}
+	}
+}
+
+func TestPostsSocketStreamsEvents(t *testing.T) {
+	bus := events.NewBus()
+	srv := newTestServer(t, Options{Bus: bus})
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + "/ws/posts"
+
+	if _, resp, err := websocket.DefaultDialer.Dial(wsURL+"?token=gator_wrong", nil); err == nil || resp == nil || resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("dial with a bad token: err %v, want a 401", err)
+	}
+
+	conn, _, err := websocket.DefaultDialer.Dial(wsURL+"?token="+testKey, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	want := events.PostEvent{PostID: uuid.New(), Title: "Hello", FeedName: "Example", URL: "https://example.com/hello"}
+	// The subscription starts once the handler runs, so keep publishing until a message arrives
+	go func() {
+		for range 50 {
+			bus.Publish(events.PostEvent{Recipients: []uuid.UUID{uuid.New()}})
+			event := want
+			event.Recipients = []uuid.UUID{testUser.ID}
+			bus.Publish(event)
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var got events.PostEvent
+	if err := conn.ReadJSON(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got.PostID != want.PostID || got.Title != want.Title || got.FeedName != want.FeedName || got.URL != want.URL {
+		t.Errorf("received %+v, want %+v", got, want)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	preflight := func(srv *httptest.Server, origin string) *http.Response {
+		req, _ := http.NewRequest("OPTIONS", srv.URL+"/feeds", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	srv := newTestServer(t, Options{AllowedOrigins: []string{"https://dash.example.com"}})
+	resp := preflight(srv, "https://dash.example.com")
+	if resp.StatusCode != http.StatusNoContent ||
+		resp.Header.Get("Access-Control-Allow-Origin") != "https://dash.example.com" ||
+		resp.Header.Get("Access-Control-Allow-Headers") != "Authorization, Content-Type" ||
+		resp.Header.Get("Access-Control-Allow-Methods") != "GET, POST, DELETE" ||
+		resp.Header.Get("Vary") != "Origin" {
+		t.Errorf("preflight from an allowed origin = %d %v", resp.StatusCode, resp.Header)
+	}
+	if resp := preflight(srv, "https://evil.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("preflight from another origin was allowed: %v", resp.Header)
+	}
+
+	// Actual requests carry the header too, even when authentication fails
+	req, _ := http.NewRequest("GET", srv.URL+"/posts", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized || resp.Header.Get("Access-Control-Allow-Origin") != "https://dash.example.com" {
+		t.Errorf("GET /posts from an allowed origin = %d %v", resp.StatusCode, resp.Header)
+	}
+
+	wildcard := newTestServer(t, Options{AllowedOrigins: []string{"*"}})
+	if resp := preflight(wildcard, "https://anything.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "https://anything.example.com" {
+		t.Errorf("wildcard didn't allow the origin: %v", resp.Header)
+	}
+
+	none := newTestServer(t, Options{})
+	if resp := preflight(none, "https://dash.example.com"); resp.Header.Get("Access-Control-Allow-Origin") != "" || resp.Header.Get("Vary") != "" {
+		t.Errorf("CORS headers sent without allowed origins: %v", resp.Header)
+	}
+}
+
+func TestSpecIsValid(t *testing.T) {
+	data, err := SpecYAML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		t.Fatalf("loading the generated spec: %v", err)
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		t.Fatalf("generated spec is invalid: %v", err)
+	}
+
+	// Every route must be documented
+	for _, route := range []struct{ method, path string }{
+		{"GET", "/posts"}, {"POST", "/bookmark"}, {"GET", "/feeds"}, {"POST", "/feeds"},
+		{"DELETE", "/feeds/{feedID}"}, {"GET", "/feeds/{feedID}/posts"},
+		{"POST", "/feeds/{feedID}/refresh"}, {"GET", "/ws/posts"},
+	} {
+		if item := doc.Paths.Value(route.path); item == nil || item.GetOperation(route.method) == nil {
+			t.Errorf("%s %s is missing from the spec", route.method, route.path)
+		}
+	}
+	if id := doc.Components.Schemas["Post"].Value.Properties["id"]; id == nil || id.Value.Format != "uuid" {
+		t.Errorf("Post.id schema = %+v, want a uuid string", id)
+	}
+}
+
+func TestSpecAndDocsArePublic(t *testing.T) {
+	srv := newTestServer(t, Options{})
+
+	for path, contentType := range map[string]string{
+		"/openapi.json": "application/json",
+		"/openapi.yaml": "application/yaml",
+		"/docs":         "text/html; charset=utf-8",
+	} {
+		resp := do(t, "GET", srv.URL+path, "", "")
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != contentType {
+			t.Errorf("GET %s without a key = %d %q, want 200 %q", path, resp.StatusCode, resp.Header.Get("Content-Type"), contentType)
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+)
+
+// Feed is a followed feed as returned by GET /feeds and POST /feeds
+type Feed struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+}
+
+// CreateFeedRequest is the body of POST /feeds
+type CreateFeedRequest struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// RefreshResponse is the body of a successful POST /feeds/{feedID}/refresh
+type RefreshResponse struct {
+	FeedID   uuid.UUID `json:"feed_id"`
+	NewPosts int64     `json:"new_posts"`
+}
+
+func (s *server) getFeedsHandler(w http.ResponseWriter, r *http.Request) {
+	follows, err := s.db.GetFeedFollowsForUser(r.Context(), userFromContext(r.Context()).ID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't get feeds")
+		return
+	}
+
+	feeds := make([]Feed, 0, len(follows))
+	for _, follow := range follows {
+		feeds = append(feeds, Feed{ID: follow.FeedID, Name: follow.FeedName, URL: follow.FeedUrl})
+	}
+	writeJSON(w, http.StatusOK, feeds)
+}
+
+// createFeedHandler adds a feed and follows it. A URL that's already stored is
+// followed instead, answering 200 rather than 201.
+func (s *server) createFeedHandler(w http.ResponseWriter, r *http.Request) {
+	var req CreateFeedRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeFieldError(w, "body must be {\"name\": \"...\", \"url\": \"...\"}", "")
+		return
+	}
+	req.Name, req.URL = strings.TrimSpace(req.Name), strings.TrimSpace(req.URL)
+	if req.Name == "" {
+		writeFieldError(w, "name must not be empty", "name")
+		return
+	}
+	if parsed, err := neturl.Parse(req.URL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		writeFieldError(w, "url must be an absolute http or https URL", "url")
+		return
+	}
+
+	ctx := r.Context()
+	user := userFromContext(ctx)
+	now := time.Now().UTC()
+	status := http.StatusCreated
+
+	var feed Feed
+	existing, err := s.db.GetFeedByURL(ctx, req.URL)
+	switch {
+	case err == nil:
+		if _, followed, err := s.followedFeed(ctx, user.ID, existing.ID); err != nil {
+			writeError(w, http.StatusInternalServerError, "couldn't get feeds")
+			return
+		} else if followed {
+			writeError(w, http.StatusConflict, "already following this feed")
+			return
+		}
+		feed = Feed{ID: existing.ID, Name: existing.Name, URL: existing.Url}
+		status = http.StatusOK
+	case errors.Is(err, sql.ErrNoRows):
+		created, err := s.db.CreateFeed(ctx, database.CreateFeedParams{
+			ID:        uuid.New(),
+			CreatedAt: now,
+			UpdatedAt: now,
+			Name:      req.Name,
+			Url:       req.URL,
+			UserID:    user.ID,
+		})
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, "couldn't create feed")
+			return
+		}
+		feed = Feed{ID: created.ID, Name: created.Name, URL: created.Url}
+	default:
+		writeError(w, http.StatusInternalServerError, "couldn't look up feed")
+		return
+	}
+
+	_, err = s.db.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't follow feed")
+		return
+	}
+	writeJSON(w, status, feed)
+}
+
+func (s *server) unfollowFeedHandler(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := feedIDVar(w, r)
+	if !ok {
+		return
+	}
+
+	deleted, err := s.db.DeleteFeedFollowByUserAndFeed(r.Context(), database.DeleteFeedFollowByUserAndFeedParams{
+		UserID: userFromContext(r.Context()).ID,
+		FeedID: feedID,
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't unfollow feed")
+		return
+	}
+	if deleted == 0 {
+		writeError(w, http.StatusNotFound, "not following this feed")
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (s *server) getFeedPostsHandler(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := feedIDVar(w, r)
+	if !ok {
+		return
+	}
+	limit, ok := postLimit(w, r)
+	if !ok {
+		return
+	}
+
+	ctx := r.Context()
+	user := userFromContext(ctx)
+	if !s.requireFollowed(ctx, w, user.ID, feedID) {
+		return
+	}
+
+	rows, err := s.db.GetPostsForUserFeed(ctx, database.GetPostsForUserFeedParams{
+		UserID: user.ID,
+		FeedID: feedID,
+		Limit:  int32(limit),
+	})
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't get posts")
+		return
+	}
+
+	posts := make([]Post, 0, len(rows))
+	for _, row := range rows {
+		posts = append(posts, postFromRow(database.GetPostsForUserRow(row)))
+	}
+	writeJSON(w, http.StatusOK, posts)
+}
+
+func (s *server) refreshFeedHandler(w http.ResponseWriter, r *http.Request) {
+	feedID, ok := feedIDVar(w, r)
+	if !ok {
+		return
+	}
+	if s.refresh == nil {
+		writeError(w, http.StatusNotImplemented, "refreshing feeds isn't available on this server")
+		return
+	}
+
+	ctx := r.Context()
+	if !s.requireFollowed(ctx, w, userFromContext(ctx).ID, feedID) {
+		return
+	}
+
+	saved, err := s.refresh(ctx, feedID)
+	if err != nil {
+		writeError(w, http.StatusBadGateway, "couldn't fetch feed: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, RefreshResponse{FeedID: feedID, NewPosts: saved})
+}
+
+// feedIDVar parses the {feedID} path variable, writing a 400 and returning false when it isn't a UUID
+func feedIDVar(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	feedID, err := uuid.Parse(mux.Vars(r)["feedID"])
+	if err != nil {
+		writeFieldError(w, "feed ID must be a UUID", "feedID")
+		return uuid.Nil, false
+	}
+	return feedID, true
+}
+
+// followedFeed returns the user's follow of a feed, if they follow it
+func (s *server) followedFeed(ctx context.Context, userID, feedID uuid.UUID) (database.GetFeedFollowsForUserRow, bool, error) {
+	follows, err := s.db.GetFeedFollowsForUser(ctx, userID)
+	if err != nil {
+		return database.GetFeedFollowsForUserRow{}, false, err
+	}
+	for _, follow := range follows {
+		if follow.FeedID == feedID {
+			return follow, true, nil
+		}
+	}
+	return database.GetFeedFollowsForUserRow{}, false, nil
+}
+
+// requireFollowed writes a 404 and returns false unless the user follows the feed
+func (s *server) requireFollowed(ctx context.Context, w http.ResponseWriter, userID, feedID uuid.UUID) bool {
+	_, followed, err := s.followedFeed(ctx, userID, feedID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't get feeds")
+		return false
+	}
+	if !followed {
+		writeError(w, http.StatusNotFound, "not following this feed")
+		return false
+	}
+	return true
+}
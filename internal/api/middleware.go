@@ -0,0 +1,126 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"slices"
+	"strings"
+
+	"gator/internal/database"
+
+	"github.com/gorilla/websocket"
+)
+
+// KeyPrefixLen is how many leading characters of a key are stored in the clear so
+// keys can be listed and revoked without knowing the whole key
+const KeyPrefixLen = 14
+
+// keyMarker starts every generated key so it's recognisable in config files and logs
+const keyMarker = "gator_"
+
+// errUnauthorized is returned by userForKey when no key matches the hash
+var errUnauthorized = errors.New("invalid or missing API key")
+
+// contextKey types the values this package stores in a request context
+type contextKey int
+
+const userKey contextKey = iota
+
+// GenerateKey returns a new random API key, e.g. "gator_3f9c...", and its prefix
+func GenerateKey() (key, prefix string, err error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	key = keyMarker + hex.EncodeToString(secret)
+	return key, key[:KeyPrefixLen], nil
+}
+
+// HashKey returns the hex SHA-256 of a key, the form keys are stored in
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// requireAPIKey rejects requests without a valid "Authorization: Bearer <key>" header
+// and passes the key's owner to the handler through the request context
+func (s *server) requireAPIKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := requestKey(r)
+		if key == "" {
+			writeError(w, http.StatusUnauthorized, errUnauthorized.Error())
+			return
+		}
+
+		user, err := s.userForKey(r.Context(), HashKey(key))
+		if errors.Is(err, errUnauthorized) {
+			writeError(w, http.StatusUnauthorized, errUnauthorized.Error())
+			return
+		} else if err != nil {
+			writeError(w, http.StatusInternalServerError, "couldn't check API key")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userKey, user)))
+	})
+}
+
+// requestKey returns the API key from the Authorization header. Browsers can't set
+// headers on WebSocket handshakes, so those may pass it as ?token=<key> instead.
+func requestKey(r *http.Request) string {
+	if key, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return strings.TrimSpace(key)
+	}
+	if websocket.IsWebSocketUpgrade(r) {
+		return r.URL.Query().Get("token")
+	}
+	return ""
+}
+
+// useAPIKey looks up the owner of a key hash and records that the key was used
+func (s *server) useAPIKey(ctx context.Context, keyHash string) (database.User, error) {
+	user, err := s.db.UseAPIKey(ctx, keyHash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.User{}, errUnauthorized
+	}
+	return user, err
+}
+
+// userFromContext returns the user requireAPIKey authenticated
+func userFromContext(ctx context.Context) database.User {
+	user, _ := ctx.Value(userKey).(database.User)
+	return user
+}
+
+// cors lets browsers on the allowed origins call the API. Requests from other
+// origins are still served but get no Access-Control-Allow-Origin, so the browser
+// hides the response. With no allowed origins the handler is returned unchanged.
+func cors(allowed []string, next http.Handler) http.Handler {
+	if len(allowed) == 0 {
+		return next
+	}
+	anyOrigin := slices.Contains(allowed, "*")
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The response depends on Origin, so caches must not share it across origins
+		w.Header().Add("Vary", "Origin")
+
+		origin := r.Header.Get("Origin")
+		if origin != "" && (anyOrigin || slices.Contains(allowed, origin)) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Allow-Headers", "Authorization, Content-Type")
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE")
+		}
+
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
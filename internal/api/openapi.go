@@ -0,0 +1,240 @@
+package api
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+
+	"gator/internal/events"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3gen"
+	"github.com/google/uuid"
+	"github.com/oasdiff/yaml"
+)
+
+// docsPage is the SwaggerUI page served at /docs; it loads /openapi.json
+//
+//go:embed docs.html
+var docsPage []byte
+
+// specVersion is the version of the API contract described by Spec
+const specVersion = "1.0.0"
+
+// schemaTypes are the Go types whose schemas the spec's components are generated from
+var schemaTypes = map[string]any{
+	"Post":              Post{},
+	"Feed":              Feed{},
+	"CreateFeedRequest": CreateFeedRequest{},
+	"BookmarkRequest":   BookmarkRequest{},
+	"RefreshResponse":   RefreshResponse{},
+	"ErrorResponse":     ErrorResponse{},
+	"PostEvent":         events.PostEvent{},
+}
+
+// Spec returns the OpenAPI 3.0 description of every endpoint, with the request and
+// response schemas generated from the types the handlers encode and decode
+func Spec() (*openapi3.T, error) {
+	doc := &openapi3.T{
+		OpenAPI: "3.0.3",
+		Info: &openapi3.Info{
+			Title:       "gator API",
+			Description: "Read and manage the feeds and posts of the user owning the API key.",
+			Version:     specVersion,
+		},
+		Components: &openapi3.Components{
+			Schemas: openapi3.Schemas{},
+			SecuritySchemes: openapi3.SecuritySchemes{
+				"apiKey": &openapi3.SecuritySchemeRef{Value: openapi3.NewJWTSecurityScheme().
+					WithBearerFormat("gator API key").
+					WithDescription("A key from `gator apikey create`, sent as `Authorization: Bearer <key>`")},
+			},
+		},
+		Security: openapi3.SecurityRequirements{{"apiKey": []string{}}},
+		Paths:    openapi3.NewPaths(),
+	}
+
+	for name, v := range schemaTypes {
+		ref, err := openapi3gen.NewSchemaRefForValue(v, nil, openapi3gen.SchemaCustomizer(uuidSchema))
+		if err != nil {
+			return nil, fmt.Errorf("couldn't generate %s schema: %w", name, err)
+		}
+		doc.Components.Schemas[name] = ref
+	}
+
+	limit := openapi3.NewQueryParameter("limit").
+		WithDescription("How many posts to return, newest first").
+		WithSchema(openapi3.NewIntegerSchema().WithMin(1).WithMax(maxPostLimit).WithDefault(defaultPostLimit))
+	feedID := openapi3.NewPathParameter("feedID").
+		WithDescription("ID of a followed feed").
+		WithSchema(openapi3.NewUUIDSchema())
+
+	doc.AddOperation("/posts", http.MethodGet, operation("getPosts", "Newest posts from the user's feeds",
+		[]*openapi3.Parameter{limit}, nil,
+		response(http.StatusOK, "The posts", arrayOf("Post")),
+		response(http.StatusBadRequest, "Invalid limit", schemaRef("ErrorResponse")),
+	))
+	doc.AddOperation("/bookmark", http.MethodPost, operation("bookmarkPost", "Bookmark a post",
+		nil, requestBody("BookmarkRequest"),
+		response(http.StatusCreated, "Bookmarked", nil),
+		response(http.StatusBadRequest, "Missing or invalid post ID", schemaRef("ErrorResponse")),
+		response(http.StatusNotFound, "No such post", schemaRef("ErrorResponse")),
+	))
+	doc.AddOperation("/feeds", http.MethodGet, operation("getFeeds", "Feeds the user follows",
+		nil, nil,
+		response(http.StatusOK, "The feeds", arrayOf("Feed")),
+	))
+	doc.AddOperation("/feeds", http.MethodPost, operation("createFeed", "Add a feed and follow it",
+		nil, requestBody("CreateFeedRequest"),
+		response(http.StatusCreated, "Feed added and followed", schemaRef("Feed")),
+		response(http.StatusOK, "The URL was already stored; that feed is now followed", schemaRef("Feed")),
+		response(http.StatusBadRequest, "Invalid name or URL", schemaRef("ErrorResponse")),
+		response(http.StatusConflict, "Already following this feed", schemaRef("ErrorResponse")),
+	))
+	doc.AddOperation("/feeds/{feedID}", http.MethodDelete, operation("unfollowFeed", "Unfollow a feed",
+		[]*openapi3.Parameter{feedID}, nil,
+		response(http.StatusNoContent, "Unfollowed", nil),
+		response(http.StatusBadRequest, "Feed ID isn't a UUID", schemaRef("ErrorResponse")),
+		response(http.StatusNotFound, "Not following this feed", schemaRef("ErrorResponse")),
+	))
+	doc.AddOperation("/feeds/{feedID}/posts", http.MethodGet, operation("getFeedPosts", "Newest posts from one followed feed",
+		[]*openapi3.Parameter{feedID, limit}, nil,
+		response(http.StatusOK, "The posts", arrayOf("Post")),
+		response(http.StatusBadRequest, "Invalid feed ID or limit", schemaRef("ErrorResponse")),
+		response(http.StatusNotFound, "Not following this feed", schemaRef("ErrorResponse")),
+	))
+	doc.AddOperation("/feeds/{feedID}/refresh", http.MethodPost, operation("refreshFeed", "Fetch a followed feed now",
+		[]*openapi3.Parameter{feedID}, nil,
+		response(http.StatusOK, "Feed fetched", schemaRef("RefreshResponse")),
+		response(http.StatusBadRequest, "Feed ID isn't a UUID", schemaRef("ErrorResponse")),
+		response(http.StatusNotFound, "Not following this feed", schemaRef("ErrorResponse")),
+		response(http.StatusNotImplemented, "The server can't refresh feeds", schemaRef("ErrorResponse")),
+		response(http.StatusBadGateway, "The feed couldn't be fetched", schemaRef("ErrorResponse")),
+	))
+
+	// OpenAPI can't describe the messages of a WebSocket, so the handshake is documented
+	// and the description points at the PostEvent schema
+	token := openapi3.NewQueryParameter("token").
+		WithDescription("The API key, for browsers that can't set the Authorization header on the handshake").
+		WithSchema(openapi3.NewStringSchema())
+	socket := operation("streamPosts", "Stream new posts over a WebSocket",
+		[]*openapi3.Parameter{token}, nil,
+		response(http.StatusSwitchingProtocols, "Connected; each new post arrives as a JSON PostEvent message", nil),
+	)
+	socket.Description = "Upgrades to a WebSocket that sends a `PostEvent` (see components) for every post saved in the user's feeds."
+	doc.AddOperation("/ws/posts", http.MethodGet, socket)
+
+	// Every authenticated operation can be rejected for a bad key
+	for _, item := range doc.Paths.Map() {
+		for _, op := range item.Operations() {
+			op.AddResponse(http.StatusUnauthorized, openapi3.NewResponse().
+				WithDescription("Missing or invalid API key").
+				WithJSONSchemaRef(schemaRef("ErrorResponse")))
+		}
+	}
+
+	// The spec and docs themselves need no key
+	for _, page := range []struct{ path, id, summary string }{
+		{"/openapi.json", "getSpecJSON", "This document as JSON"},
+		{"/openapi.yaml", "getSpecYAML", "This document as YAML"},
+		{"/docs", "getDocs", "Interactive documentation (SwaggerUI)"},
+	} {
+		op := operation(page.id, page.summary, nil, nil, response(http.StatusOK, "OK", nil))
+		op.Security = &openapi3.SecurityRequirements{}
+		doc.AddOperation(page.path, http.MethodGet, op)
+	}
+
+	return doc, nil
+}
+
+// SpecYAML returns Spec as YAML, the form `gator api --gen-spec` writes
+func SpecYAML() ([]byte, error) {
+	doc, err := Spec()
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+	return yaml.JSONToYAML(data)
+}
+
+// uuidSchema describes uuid.UUID as the string it marshals to rather than its byte array
+func uuidSchema(_ string, t reflect.Type, _ reflect.StructTag, schema *openapi3.Schema) error {
+	if t == reflect.TypeFor[uuid.UUID]() {
+		*schema = *openapi3.NewUUIDSchema()
+	}
+	return nil
+}
+
+func operation(id, summary string, params []*openapi3.Parameter, body *openapi3.RequestBodyRef, responses ...func(*openapi3.Responses)) *openapi3.Operation {
+	op := &openapi3.Operation{
+		OperationID: id,
+		Summary:     summary,
+		RequestBody: body,
+		Responses:   openapi3.NewResponses(),
+	}
+	// NewResponses starts with a default response; every status here is explicit
+	op.Responses.Delete("default")
+	for _, p := range params {
+		op.Parameters = append(op.Parameters, &openapi3.ParameterRef{Value: p})
+	}
+	for _, add := range responses {
+		add(op.Responses)
+	}
+	return op
+}
+
+// response adds a status to an operation, with a JSON body when schema is non-nil
+func response(status int, description string, schema *openapi3.SchemaRef) func(*openapi3.Responses) {
+	return func(responses *openapi3.Responses) {
+		resp := openapi3.NewResponse().WithDescription(description)
+		if schema != nil {
+			resp.WithJSONSchemaRef(schema)
+		}
+		responses.Set(fmt.Sprint(status), &openapi3.ResponseRef{Value: resp})
+	}
+}
+
+func requestBody(name string) *openapi3.RequestBodyRef {
+	return &openapi3.RequestBodyRef{Value: openapi3.NewRequestBody().
+		WithRequired(true).
+		WithJSONSchemaRef(schemaRef(name))}
+}
+
+func schemaRef(name string) *openapi3.SchemaRef {
+	return openapi3.NewSchemaRef("#/components/schemas/"+name, nil)
+}
+
+func arrayOf(name string) *openapi3.SchemaRef {
+	schema := openapi3.NewArraySchema()
+	schema.Items = schemaRef(name)
+	return schema.NewRef()
+}
+
+func (s *server) specJSONHandler(w http.ResponseWriter, r *http.Request) {
+	doc, err := Spec()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't generate spec")
+		return
+	}
+	writeJSON(w, http.StatusOK, doc)
+}
+
+func (s *server) specYAMLHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := SpecYAML()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "couldn't generate spec")
+		return
+	}
+	w.Header().Set("Content-Type", "application/yaml")
+	w.Write(data)
+}
+
+func (s *server) docsHandler(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(docsPage)
+}
@@ -0,0 +1,77 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Keepalive timing for /ws/posts: a ping goes out every pingPeriod and the client
+// must answer within pongWait or the connection is dropped
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+)
+
+var upgrader = websocket.Upgrader{
+	// Clients authenticate with their API key, so any origin may connect
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// postsSocketHandler streams a JSON message for every new post in the user's feeds
+// until the client disconnects or the server shuts down
+func (s *server) postsSocketHandler(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already answered the request
+		return
+	}
+	defer conn.Close()
+
+	user := userFromContext(r.Context())
+	posts := s.bus.Subscribe(user.ID)
+	defer s.bus.Unsubscribe(user.ID, posts)
+
+	// Reading is needed to process pongs and notice when the client goes away
+	gone := make(chan struct{})
+	go func() {
+		defer close(gone)
+		conn.SetReadDeadline(time.Now().Add(pongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(pongWait))
+		})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ping := time.NewTicker(pingPeriod)
+	defer ping.Stop()
+	for {
+		select {
+		case event, ok := <-posts:
+			if !ok {
+				return
+			}
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ping.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(writeWait)); err != nil {
+				return
+			}
+		case <-gone:
+			return
+		case <-s.closing:
+			conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(websocket.CloseGoingAway, "server shutting down"),
+				time.Now().Add(writeWait))
+			return
+		}
+	}
+}
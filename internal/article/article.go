@@ -0,0 +1,74 @@
+// Package article fetches a post's web page and pulls out the article text.
+package article
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// maxPageSize caps how much of a page is read; articles are far smaller
+const maxPageSize = 5 << 20
+
+// ErrNoArticle is returned for pages with neither an <article> nor a <main> element
+var ErrNoArticle = errors.New("no <article> or <main> element on the page")
+
+// Fetch downloads the page at pageURL and returns the HTML inside its article
+func Fetch(ctx context.Context, client *http.Client, pageURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("couldn't create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gator")
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("server answered %s", resp.Status)
+	}
+	return Extract(io.LimitReader(resp.Body, maxPageSize))
+}
+
+// Extract returns the inner HTML of the first <article> element in page, or of the
+// first <main> when there's no article
+func Extract(page io.Reader) (string, error) {
+	doc, err := html.Parse(page)
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse page: %w", err)
+	}
+	node := find(doc, "article")
+	if node == nil {
+		node = find(doc, "main")
+	}
+	if node == nil {
+		return "", ErrNoArticle
+	}
+
+	var b strings.Builder
+	for c := node.FirstChild; c != nil; c = c.NextSibling {
+		if err := html.Render(&b, c); err != nil {
+			return "", err
+		}
+	}
+	return b.String(), nil
+}
+
+// find returns the first element named tag under n, depth first
+func find(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := find(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
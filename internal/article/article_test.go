@@ -0,0 +1,58 @@
+package article
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	cases := map[string]string{
+		`<html><body><nav>Menu</nav><article><h1>Title</h1><p>Body</p></article><footer>x</footer></body></html>`: "<h1>Title</h1><p>Body</p>",
+		`<html><body><header>Site</header><main><p>Main text</p></main></body></html>`:                            "<p>Main text</p>",
+		`<main><p>Outer</p><article><p>Inner</p></article></main>`:                                                "<p>Inner</p>",
+	}
+	for page, want := range cases {
+		got, err := Extract(strings.NewReader(page))
+		if err != nil {
+			t.Errorf("Extract(%q): unexpected error %v", page, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("Extract(%q) = %q, want %q", page, got, want)
+		}
+	}
+
+	if _, err := Extract(strings.NewReader("<p>No article here</p>")); !errors.Is(err, ErrNoArticle) {
+		t.Errorf("expected ErrNoArticle, got %v", err)
+	}
+}
+
+func TestFetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/gone" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("User-Agent") != "gator" {
+			t.Errorf("unexpected User-Agent %q", r.Header.Get("User-Agent"))
+		}
+		w.Write([]byte(`<html><body><article><p>Full text</p></article></body></html>`))
+	}))
+	defer srv.Close()
+
+	got, err := Fetch(context.Background(), srv.Client(), srv.URL+"/post")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "<p>Full text</p>" {
+		t.Errorf("got %q", got)
+	}
+
+	if _, err := Fetch(context.Background(), srv.Client(), srv.URL+"/gone"); err == nil {
+		t.Error("expected an error for a 404")
+	}
+}
@@ -0,0 +1,51 @@
+// Package browser opens URLs in the user's default web browser.
+package browser
+
+import (
+	"context"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"time"
+)
+
+// launchTimeout bounds how long the platform launcher may take to start the browser
+const launchTimeout = 5 * time.Second
+
+// Open launches the default browser on url. Nothing is written to the terminal, so it
+// is safe to call from the TUI; if the launch fails, the returned error names the URL
+// so the caller can show it to be opened by hand.
+func Open(ctx context.Context, url string) error {
+	if err := run(ctx, runtime.GOOS, url); err != nil {
+		return fmt.Errorf("couldn't open a browser, visit %s: %w", url, err)
+	}
+	return nil
+}
+
+func run(ctx context.Context, goos, url string) error {
+	name, args, err := command(goos, url)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, launchTimeout)
+	defer cancel()
+	if err := exec.CommandContext(ctx, name, args...).Run(); err != nil {
+		return fmt.Errorf("couldn't run %s: %w", name, err)
+	}
+	return nil
+}
+
+// command returns the launcher for a platform. The URL is always passed as a single
+// argument and never through a shell, so characters like & in query strings survive.
+func command(goos, url string) (string, []string, error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "xdg-open", []string{url}, nil
+	case "darwin":
+		return "open", []string{url}, nil
+	case "windows":
+		return "rundll32", []string{"url.dll,FileProtocolHandler", url}, nil
+	}
+	return "", nil, fmt.Errorf("opening a browser isn't supported on %s", goos)
+}
@@ -0,0 +1,31 @@
+package browser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	const url = "https://example.com/search?q=go&page=2#top"
+	cases := map[string]struct {
+		name string
+		args []string
+	}{
+		"linux":   {"xdg-open", []string{url}},
+		"darwin":  {"open", []string{url}},
+		"windows": {"rundll32", []string{"url.dll,FileProtocolHandler", url}},
+	}
+	for goos, want := range cases {
+		name, args, err := command(goos, url)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", goos, err)
+		}
+		if name != want.name || !slices.Equal(args, want.args) {
+			t.Fatalf("%s: got %s %q, want %s %q", goos, name, args, want.name, want.args)
+		}
+	}
+
+	if _, _, err := command("plan9", url); err == nil {
+		t.Fatal("expected an error for an unsupported platform")
+	}
+}
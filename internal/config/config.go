@@ -2,28 +2,103 @@ package config
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"net/url"
 	"os"
 	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
 )
 
 const configFileName = ".gatorconfig.json"
 
+// defaultFetchTimeout applies when fetch_timeout_secs is unset
+const defaultFetchTimeout = 30 * time.Second
+
+// fallbackUserAgent identifies feed requests when default_user_agent is unset
+const fallbackUserAgent = "gator"
+
+// Connection pool defaults for the db_* settings left at zero
+const (
+	defaultDBMaxOpenConns    = 25
+	defaultDBMaxIdleConns    = 5
+	defaultDBConnMaxLifetime = 5 * time.Minute
+	defaultDBConnMaxIdleTime = 5 * time.Minute
+)
+
+// Database drivers db_driver can name; postgres is the default
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// DefaultAPIAddr is where the api command listens when api_addr is unset
+const DefaultAPIAddr = "0.0.0.0:8080"
+
 // Config represents the JSON file structure
 type Config struct {
+	DbURL            string   `json:"db_url"`
+	DbDriver         string   `json:"db_driver,omitempty"`
+	CurrentUser      string   `json:"current_user_name"`
+	AdminUser        string   `json:"admin_user,omitempty"`
+	ProxyURL         string   `json:"proxy_url,omitempty"`
+	ProxyType        string   `json:"proxy_type,omitempty"`
+	NoProxy          []string `json:"no_proxy,omitempty"`
+	FetchTimeoutSecs int      `json:"fetch_timeout_secs,omitempty"`
+	APIAddr          string   `json:"api_addr,omitempty"`
+	APITLSCert       string   `json:"api_tls_cert,omitempty"`
+	APITLSKey        string   `json:"api_tls_key,omitempty"`
+	AllowedOrigins   []string `json:"allowed_origins,omitempty"`
+	ReadingWPM       int      `json:"reading_wpm,omitempty"`
+	DefaultUserAgent string   `json:"default_user_agent,omitempty"`
+
+	// ResolvePostURLs makes agg store where new posts' links redirect to, as --resolve-urls does
+	ResolvePostURLs bool `json:"resolve_post_urls,omitempty"`
+	// AutoRefreshFeedMeta renames feeds after their channel title whenever they're fetched
+	AutoRefreshFeedMeta bool `json:"auto_refresh_feed_meta,omitempty"`
+
+	DBMaxOpenConns        int `json:"db_max_open_conns,omitempty"`
+	DBMaxIdleConns        int `json:"db_max_idle_conns,omitempty"`
+	DBConnMaxLifetimeSecs int `json:"db_conn_max_lifetime_secs,omitempty"`
+	DBConnMaxIdleTimeSecs int `json:"db_conn_max_idle_time_secs,omitempty"`
+
+	// Profiles hold alternative databases and users. While one is active, DbURL and
+	// CurrentUser are the profile's; the top-level values in the file stay as the default.
+	Profiles      map[string]ProfileConfig `json:"profiles,omitempty"`
+	ActiveProfile string                   `json:"active_profile,omitempty"`
+
+	path string        // file the config was read from
+	base ProfileConfig // top-level values while a profile is active
+}
+
+// ProfileConfig is a named database and user
+type ProfileConfig struct {
 	DbURL       string `json:"db_url"`
 	CurrentUser string `json:"current_user_name"`
 }
 
-// Read reads the JSON file found at ~/.gatorconfig.json and returns a Config struct
+// DefaultProfile names the top-level settings, for switching back to them
+const DefaultProfile = "default"
+
+// Read reads the config file, ~/.gatorconfig.json unless GATOR_CONFIG_PATH is set,
+// and returns a Config struct
 func Read() (Config, error) {
-	var cfg Config
-	
 	configPath, err := getConfigFilePath()
 	if err != nil {
-		return cfg, err
+		return Config{}, err
 	}
+	return ReadFrom(configPath)
+}
+
+// ReadFrom reads the config file at path. Later writes go back to the same file.
+func ReadFrom(path string) (Config, error) {
+	cfg := Config{path: path}
 	
-	file, err := os.ReadFile(configPath)
+	file, err := os.ReadFile(path)
 	if err != nil {
 		return cfg, err
 	}
@@ -33,17 +108,277 @@ func Read() (Config, error) {
 		return cfg, err
 	}
 	
+	if cfg.ActiveProfile != "" {
+		profile, ok := cfg.Profiles[cfg.ActiveProfile]
+		if !ok {
+			return cfg, fmt.Errorf("active profile %q is not defined", cfg.ActiveProfile)
+		}
+		cfg.base = ProfileConfig{DbURL: cfg.DbURL, CurrentUser: cfg.CurrentUser}
+		cfg.DbURL, cfg.CurrentUser = profile.DbURL, profile.CurrentUser
+	}
+	
 	return cfg, nil
 }
 
+// Path returns the file the config was read from and is written to
+func (c Config) Path() (string, error) {
+	if c.path != "" {
+		return c.path, nil
+	}
+	return getConfigFilePath()
+}
+
+// Redacted returns the config as stored in the file, with the credentials in database
+// and proxy URLs masked
+func (c Config) Redacted() Config {
+	c = c.fileContents()
+	c.DbURL = redactURL(c.DbURL)
+	c.ProxyURL = redactURL(c.ProxyURL)
+	profiles := make(map[string]ProfileConfig, len(c.Profiles))
+	for name, profile := range c.Profiles {
+		profile.DbURL = redactURL(profile.DbURL)
+		profiles[name] = profile
+	}
+	if len(profiles) > 0 {
+		c.Profiles = profiles
+	}
+	return c
+}
+
+// dsnPassword matches the password of a key=value connection string
+var dsnPassword = regexp.MustCompile(`(password=)('[^']*'|\S+)`)
+
+// redactURL masks the credentials in a URL or the password in a key=value connection
+// string. URLs come back as scheme://***@host/path: the query is dropped too, since
+// libpq accepts a password there.
+func redactURL(raw string) string {
+	if raw == "" {
+		return raw
+	}
+	if parsed, err := url.Parse(raw); err == nil && parsed.Scheme != "" && parsed.Host != "" {
+		userinfo := ""
+		if parsed.User != nil {
+			userinfo = "***@"
+		}
+		return parsed.Scheme + "://" + userinfo + parsed.Host + parsed.EscapedPath()
+	}
+	return dsnPassword.ReplaceAllString(raw, "${1}xxxxx")
+}
+
 // SetUser writes the config struct to the JSON file after setting the current_user_name field
 func (c *Config) SetUser(username string) error {
+	if username == "" {
+		return errors.New("username must not be empty; use ClearUser to log out")
+	}
 	c.CurrentUser = username
 	return write(*c)
 }
 
-// getConfigFilePath returns the full path to the config file
+// ClearUser writes the config struct to the JSON file after clearing the current_user_name field
+func (c *Config) ClearUser() error {
+	if c.CurrentUser == "" {
+		return errors.New("no user is logged in")
+	}
+	c.CurrentUser = ""
+	return write(*c)
+}
+
+// SetProxy writes the config struct to the JSON file after setting the proxy fields
+func (c *Config) SetProxy(proxyURL, proxyType string, noProxy []string) error {
+	c.ProxyURL = proxyURL
+	c.ProxyType = proxyType
+	c.NoProxy = noProxy
+	return write(*c)
+}
+
+// ClearProxy writes the config struct to the JSON file after removing the proxy settings
+func (c *Config) ClearProxy() error {
+	return c.SetProxy("", "", nil)
+}
+
+// ProfileNames returns the defined profiles in alphabetical order
+func (c Config) ProfileNames() []string {
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// CreateProfile writes the config struct to the JSON file after adding a profile with
+// no logged-in user; an empty dbURL copies the current one
+func (c *Config) CreateProfile(name, dbURL string) error {
+	if name == "" || name == DefaultProfile {
+		return fmt.Errorf("invalid profile name %q", name)
+	}
+	if _, ok := c.Profiles[name]; ok {
+		return fmt.Errorf("profile %s already exists", name)
+	}
+	if dbURL == "" {
+		dbURL = c.DbURL
+	}
+	if err := Validate(Config{DbURL: dbURL, DbDriver: c.DbDriver}); err != nil {
+		return err
+	}
+	
+	if c.Profiles == nil {
+		c.Profiles = make(map[string]ProfileConfig)
+	}
+	c.Profiles[name] = ProfileConfig{DbURL: dbURL}
+	return write(*c)
+}
+
+// SwitchProfile writes the config struct to the JSON file after making name the active
+// profile; DefaultProfile switches back to the top-level settings
+func (c *Config) SwitchProfile(name string) error {
+	if name != DefaultProfile {
+		if _, ok := c.Profiles[name]; !ok {
+			return fmt.Errorf("profile %s does not exist", name)
+		}
+	}
+	
+	// Keep the current profile's user before leaving it
+	if c.ActiveProfile != "" {
+		c.Profiles[c.ActiveProfile] = ProfileConfig{DbURL: c.DbURL, CurrentUser: c.CurrentUser}
+	} else {
+		c.base = ProfileConfig{DbURL: c.DbURL, CurrentUser: c.CurrentUser}
+	}
+	
+	if name == DefaultProfile {
+		c.ActiveProfile = ""
+		c.DbURL, c.CurrentUser = c.base.DbURL, c.base.CurrentUser
+	} else {
+		profile := c.Profiles[name]
+		c.ActiveProfile = name
+		c.DbURL, c.CurrentUser = profile.DbURL, profile.CurrentUser
+	}
+	return write(*c)
+}
+
+// DeleteProfile writes the config struct to the JSON file after removing a profile,
+// which must not be the active one
+func (c *Config) DeleteProfile(name string) error {
+	if _, ok := c.Profiles[name]; !ok {
+		return fmt.Errorf("profile %s does not exist", name)
+	}
+	if name == c.ActiveProfile {
+		return fmt.Errorf("profile %s is active; switch to another profile first", name)
+	}
+	delete(c.Profiles, name)
+	return write(*c)
+}
+
+// FetchTimeout returns how long a single feed request may take, 30 seconds unless configured
+func (c Config) FetchTimeout() time.Duration {
+	if c.FetchTimeoutSecs <= 0 {
+		return defaultFetchTimeout
+	}
+	return time.Duration(c.FetchTimeoutSecs) * time.Second
+}
+
+// UserAgent returns the User-Agent feed requests send unless the feed sets its own,
+// "gator" unless configured
+func (c Config) UserAgent() string {
+	if c.DefaultUserAgent == "" {
+		return fallbackUserAgent
+	}
+	return c.DefaultUserAgent
+}
+
+// DBPool is how the database connection pool is sized
+type DBPool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// DBPool returns the connection pool settings, using the defaults (25 open, 5 idle,
+// 5 minute lifetime and idle time) for any left at zero
+func (c Config) DBPool() DBPool {
+	pool := DBPool{
+		MaxOpenConns:    defaultDBMaxOpenConns,
+		MaxIdleConns:    defaultDBMaxIdleConns,
+		ConnMaxLifetime: defaultDBConnMaxLifetime,
+		ConnMaxIdleTime: defaultDBConnMaxIdleTime,
+	}
+	if c.DBMaxOpenConns > 0 {
+		pool.MaxOpenConns = c.DBMaxOpenConns
+	}
+	if c.DBMaxIdleConns > 0 {
+		pool.MaxIdleConns = c.DBMaxIdleConns
+	}
+	if c.DBConnMaxLifetimeSecs > 0 {
+		pool.ConnMaxLifetime = time.Duration(c.DBConnMaxLifetimeSecs) * time.Second
+	}
+	if c.DBConnMaxIdleTimeSecs > 0 {
+		pool.ConnMaxIdleTime = time.Duration(c.DBConnMaxIdleTimeSecs) * time.Second
+	}
+	// database/sql would lower idle to the open limit anyway; doing it here keeps what
+	// dbstats reports accurate
+	pool.MaxIdleConns = min(pool.MaxIdleConns, pool.MaxOpenConns)
+	return pool
+}
+
+// Driver returns the database driver to use, DriverPostgres unless db_driver says otherwise
+func (c Config) Driver() string {
+	if c.DbDriver == "" {
+		return DriverPostgres
+	}
+	return c.DbDriver
+}
+
+// SetDatabase writes the config struct to the JSON file after pointing it at a new
+// database, for moving gator's data to another backend
+func (c *Config) SetDatabase(driver, dbURL string) error {
+	c.DbDriver = driver
+	if driver == DriverPostgres {
+		c.DbDriver = ""
+	}
+	c.DbURL = dbURL
+	return write(*c)
+}
+
+// SetDBURL writes the config struct to the JSON file after pointing it at another
+// PostgreSQL database. The current user is logged out, since they may not exist there.
+// Nothing changes when the URL doesn't validate.
+func (c *Config) SetDBURL(dbURL string) error {
+	next := *c
+	next.DbDriver = ""
+	next.DbURL = dbURL
+	next.CurrentUser = ""
+	if err := write(next); err != nil {
+		return err
+	}
+	*c = next
+	return nil
+}
+
+// APIListenAddr returns the address the API server binds to, DefaultAPIAddr unless configured
+func (c Config) APIListenAddr() string {
+	if c.APIAddr == "" {
+		return DefaultAPIAddr
+	}
+	return c.APIAddr
+}
+
+// SetFetchTimeout writes the config struct to the JSON file after setting the fetch timeout;
+// zero restores the default
+func (c *Config) SetFetchTimeout(secs int) error {
+	if secs < 0 {
+		return errors.New("fetch timeout must not be negative")
+	}
+	c.FetchTimeoutSecs = secs
+	return write(*c)
+}
+
+// getConfigFilePath returns the full path to the config file: GATOR_CONFIG_PATH if set,
+// otherwise ~/.gatorconfig.json
 func getConfigFilePath() (string, error) {
+	if configPath := os.Getenv("GATOR_CONFIG_PATH"); configPath != "" {
+		return configPath, nil
+	}
 	homeDir, err := os.UserHomeDir()
 	if err != nil {
 		return "", err
@@ -51,17 +386,113 @@ func getConfigFilePath() (string, error) {
 	return filepath.Join(homeDir, configFileName), nil
 }
 
-// write writes the config struct to the JSON file
+// Validate checks that the config can be used: with the postgres driver DbURL must be a
+// postgres:// URL or a key=value connection string, with sqlite the database file's path
+func Validate(cfg Config) error {
+	dbURL := strings.TrimSpace(cfg.DbURL)
+	if dbURL == "" {
+		return errors.New("invalid config: db_url must not be empty")
+	}
+
+	switch cfg.Driver() {
+	case DriverPostgres:
+	case DriverSQLite:
+		if strings.Contains(dbURL, "://") {
+			return errors.New("invalid config: with db_driver sqlite, db_url must be the path of the database file")
+		}
+		return nil
+	default:
+		return fmt.Errorf("invalid config: db_driver must be %s or %s, not %q", DriverPostgres, DriverSQLite, cfg.DbDriver)
+	}
+
+	if strings.Contains(dbURL, "://") {
+		parsed, err := url.Parse(dbURL)
+		if err != nil {
+			return fmt.Errorf("invalid config: db_url is not a valid URL: %w", err)
+		}
+		if parsed.Scheme != "postgres" && parsed.Scheme != "postgresql" {
+			return fmt.Errorf("invalid config: db_url must use the postgres:// scheme, not %s://", parsed.Scheme)
+		}
+		return nil
+	}
+
+	for _, field := range strings.Fields(dbURL) {
+		key, _, ok := strings.Cut(field, "=")
+		if !ok || key == "" {
+			// Quoted values may contain spaces; only the first word needs a key
+			if strings.HasSuffix(field, "'") {
+				continue
+			}
+			return fmt.Errorf("invalid config: db_url is neither a postgres:// URL nor key=value pairs (near %q)", field)
+		}
+	}
+	return nil
+}
+
+// write validates the config and writes it to the JSON file. The data goes to a
+// temporary file in the same directory first, which is then renamed over the
+// config, so a crash mid-write can't leave a truncated file behind.
 func write(cfg Config) error {
-	configPath, err := getConfigFilePath()
+	if err := Validate(cfg); err != nil {
+		return err
+	}
+
+	configPath, err := cfg.Path()
 	if err != nil {
 		return err
 	}
 	
-	data, err := json.MarshalIndent(cfg, "", "  ")
+	data, err := json.MarshalIndent(cfg.fileContents(), "", "  ")
 	if err != nil {
 		return err
 	}
 	
-	return os.WriteFile(configPath, data, 0644)
-}
\ No newline at end of file
+	if err := os.MkdirAll(filepath.Dir(configPath), 0755); err != nil {
+		return fmt.Errorf("couldn't create config directory: %w", err)
+	}
+	
+	tmpPath := configPath + ".tmp"
+	if err := writeFileSynced(tmpPath, data); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	
+	if err := os.Rename(tmpPath, configPath); err != nil {
+		// Renaming can fail across devices, e.g. when the config is a mount; copying
+		// loses atomicity but still leaves a complete file
+		fmt.Fprintf(os.Stderr, "Warning: couldn't replace %s atomically (%v); copying instead\n", configPath, err)
+		defer os.Remove(tmpPath)
+		return writeFileSynced(configPath, data)
+	}
+	return nil
+}
+
+// fileContents is the config as stored: the active profile's values go back into its
+// entry and the top-level fields keep the default settings
+func (c Config) fileContents() Config {
+	if c.ActiveProfile == "" {
+		return c
+	}
+	stored := c
+	stored.Profiles = maps.Clone(c.Profiles)
+	stored.Profiles[c.ActiveProfile] = ProfileConfig{DbURL: c.DbURL, CurrentUser: c.CurrentUser}
+	stored.DbURL, stored.CurrentUser = c.base.DbURL, c.base.CurrentUser
+	return stored
+}
+
+// writeFileSynced writes data to path and flushes it to disk
+func writeFileSynced(path string, data []byte) error {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(data); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Sync(); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
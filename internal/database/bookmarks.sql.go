@@ -7,6 +7,8 @@ package database
 
 import (
 	"context"
+	"database/sql"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -26,3 +28,98 @@ func (q *Queries) BookmarkPost(ctx context.Context, arg BookmarkPostParams) erro
 	_, err := q.db.ExecContext(ctx, bookmarkPost, arg.UserID, arg.PostID)
 	return err
 }
+
+const deleteBookmark = `-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND post_id = $2
+`
+
+type DeleteBookmarkParams struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+}
+
+func (q *Queries) DeleteBookmark(ctx context.Context, arg DeleteBookmarkParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBookmark, arg.UserID, arg.PostID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteBookmarksForUser = `-- name: DeleteBookmarksForUser :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteBookmarksForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteBookmarksForUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getBookmarksForUser = `-- name: GetBookmarksForUser :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+       f.name AS feed_name, b.created_at AS bookmarked_at
+FROM bookmarks b
+JOIN posts p ON b.post_id = p.id
+JOIN feeds f ON p.feed_id = f.id
+WHERE b.user_id = $1
+ORDER BY b.created_at DESC
+LIMIT $2 OFFSET $3
+`
+
+type GetBookmarksForUserParams struct {
+	UserID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type GetBookmarksForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	FeedName     string
+	BookmarkedAt sql.NullTime
+}
+
+func (q *Queries) GetBookmarksForUser(ctx context.Context, arg GetBookmarksForUserParams) ([]GetBookmarksForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getBookmarksForUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetBookmarksForUserRow
+	for rows.Next() {
+		var i GetBookmarksForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.FeedName,
+			&i.BookmarkedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
@@ -7,11 +7,59 @@ package database
 
 import (
 	"context"
+	"database/sql"
 	"time"
 
 	"github.com/google/uuid"
 )
 
+const claimFeedForFetch = `-- name: ClaimFeedForFetch :execrows
+UPDATE feeds
+SET last_attempted_at = NOW(), updated_at = NOW()
+WHERE id = $1
+  AND (last_attempted_at IS NULL
+    OR last_attempted_at <= last_fetched_at
+    OR last_attempted_at < $2::timestamp)
+`
+
+type ClaimFeedForFetchParams struct {
+	ID            uuid.UUID
+	ClaimedBefore time.Time
+}
+
+// Stamps last_attempted_at before a fetch. A stamp newer than the last fetch is a
+// claim by another agg run, which holds until claimed_before passes it.
+func (q *Queries) ClaimFeedForFetch(ctx context.Context, arg ClaimFeedForFetchParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, claimFeedForFetch, arg.ID, arg.ClaimedBefore)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const countFeeds = `-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds
+`
+
+func (q *Queries) CountFeeds(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeeds)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countFeedsMatching = `-- name: CountFeedsMatching :one
+SELECT COUNT(*) FROM feeds
+WHERE lower(name) LIKE lower($1::text)
+`
+
+func (q *Queries) CountFeedsMatching(ctx context.Context, filter string) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countFeedsMatching, filter)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
 const createFeed = `-- name: CreateFeed :one
 INSERT INTO feeds (id, created_at, updated_at, name, url, user_id)
 VALUES (
@@ -122,6 +170,19 @@ func (q *Queries) CreateFeedFollow(ctx context.Context, arg CreateFeedFollowPara
 	return i, err
 }
 
+const deleteFeed = `-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) DeleteFeed(ctx context.Context, id uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeed, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 const deleteFeedFollowByUserAndFeed = `-- name: DeleteFeedFollowByUserAndFeed :execrows
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2
@@ -140,6 +201,177 @@ func (q *Queries) DeleteFeedFollowByUserAndFeed(ctx context.Context, arg DeleteF
 	return result.RowsAffected()
 }
 
+const deleteFeedFollowsForFeed = `-- name: DeleteFeedFollowsForFeed :execrows
+DELETE FROM feed_follows
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeleteFeedFollowsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowsForFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFeedFollowsForUser = `-- name: DeleteFeedFollowsForUser :execrows
+DELETE FROM feed_follows
+WHERE user_id = $1
+`
+
+func (q *Queries) DeleteFeedFollowsForUser(ctx context.Context, userID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowsForUser, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteFeedFollowsMatching = `-- name: DeleteFeedFollowsMatching :execrows
+DELETE FROM feed_follows
+WHERE user_id = $1
+  AND ($2::text IS NULL
+    OR feed_id IN (SELECT feed_id FROM feed_tags WHERE tag = $2::text))
+  AND ($3::uuid IS NULL
+    OR feed_id IN (SELECT feed_id FROM feed_group_members WHERE group_id = $3::uuid))
+`
+
+type DeleteFeedFollowsMatchingParams struct {
+	UserID  uuid.UUID
+	Tag     sql.NullString
+	GroupID uuid.NullUUID
+}
+
+// Unfollows what GetFollowedFeedsMatching lists, in one statement
+func (q *Queries) DeleteFeedFollowsMatching(ctx context.Context, arg DeleteFeedFollowsMatchingParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deleteFeedFollowsMatching, arg.UserID, arg.Tag, arg.GroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const followAllFeeds = `-- name: FollowAllFeeds :execrows
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+SELECT gen_random_uuid(), $1::timestamp, $1::timestamp, $2::uuid, feeds.id
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = $2::uuid AND feed_follows.feed_id = feeds.id
+  )
+  AND ($3::text IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_tags WHERE tag = $3::text))
+`
+
+type FollowAllFeedsParams struct {
+	Now    time.Time
+	UserID uuid.UUID
+	Tag    sql.NullString
+}
+
+// Follows what GetUnfollowedFeeds lists, in one statement
+func (q *Queries) FollowAllFeeds(ctx context.Context, arg FollowAllFeedsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, followAllFeeds, arg.Now, arg.UserID, arg.Tag)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getDeadFeedsForUser = `-- name: GetDeadFeedsForUser :many
+SELECT feeds.id, feeds.name, feeds.url, feeds.last_fetched_at, feeds.consecutive_errors, feeds.last_error
+FROM feeds
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+  AND (
+    feeds.consecutive_errors >= $2::int
+    OR (
+        COALESCE(feeds.last_fetched_at, feeds.created_at) < $3::timestamp
+        AND NOT EXISTS (
+            SELECT 1 FROM posts
+            WHERE posts.feed_id = feeds.id AND posts.created_at >= $3::timestamp
+        )
+    )
+  )
+ORDER BY feeds.name
+`
+
+type GetDeadFeedsForUserParams struct {
+	UserID      uuid.UUID
+	Threshold   int32
+	StaleBefore time.Time
+}
+
+type GetDeadFeedsForUserRow struct {
+	ID                uuid.UUID
+	Name              string
+	Url               string
+	LastFetchedAt     sql.NullTime
+	ConsecutiveErrors int32
+	LastError         sql.NullString
+}
+
+// Followed feeds that keep failing, or that haven't been fetched since stale_before and
+// have had no new posts since then either
+func (q *Queries) GetDeadFeedsForUser(ctx context.Context, arg GetDeadFeedsForUserParams) ([]GetDeadFeedsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDeadFeedsForUser, arg.UserID, arg.Threshold, arg.StaleBefore)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetDeadFeedsForUserRow
+	for rows.Next() {
+		var i GetDeadFeedsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+			&i.LastFetchedAt,
+			&i.ConsecutiveErrors,
+			&i.LastError,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedByID = `-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, last_etag, last_modified, interval_secs, consecutive_errors, last_error, last_attempted_at, fetch_timeout_secs, user_agent
+FROM feeds
+WHERE id = $1
+`
+
+func (q *Queries) GetFeedByID(ctx context.Context, id uuid.UUID) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getFeedByID, id)
+	var i Feed
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Name,
+		&i.Url,
+		&i.UserID,
+		&i.LastFetchedAt,
+		&i.LastEtag,
+		&i.LastModified,
+		&i.IntervalSecs,
+		&i.ConsecutiveErrors,
+		&i.LastError,
+		&i.LastAttemptedAt,
+		&i.FetchTimeoutSecs,
+		&i.UserAgent,
+	)
+	return i, err
+}
+
 const getFeedByURL = `-- name: GetFeedByURL :one
 SELECT id, created_at, updated_at, name, url, user_id
 FROM feeds
@@ -169,6 +401,45 @@ func (q *Queries) GetFeedByURL(ctx context.Context, url string) (GetFeedByURLRow
 	return i, err
 }
 
+const getFeedFollowersForFeed = `-- name: GetFeedFollowersForFeed :many
+SELECT users.id, users.name
+FROM feed_follows
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = $1
+ORDER BY feed_follows.created_at
+`
+
+type GetFeedFollowersForFeedRow struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) GetFeedFollowersForFeed(ctx context.Context, feedID uuid.UUID) ([]GetFeedFollowersForFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedFollowersForFeed, feedID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedFollowersForFeedRow
+	for rows.Next() {
+		var i GetFeedFollowersForFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getFeedFollowsForUser = `-- name: GetFeedFollowsForUser :many
 SELECT 
     feed_follows.id,
@@ -236,8 +507,17 @@ SELECT
     users.name AS user_name
 FROM feeds
 JOIN users ON feeds.user_id = users.id
+WHERE lower(feeds.name) LIKE lower($1::text)
+ORDER BY feeds.name
+LIMIT NULLIF($2::int, 0) OFFSET $3::int
 `
 
+type GetFeedsParams struct {
+	Filter string
+	Limit  int32
+	Offset int32
+}
+
 type GetFeedsRow struct {
 	FeedID   uuid.UUID
 	FeedName string
@@ -245,8 +525,10 @@ type GetFeedsRow struct {
 	UserName string
 }
 
-func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
-	rows, err := q.db.QueryContext(ctx, getFeeds)
+// Feeds whose names match the LIKE pattern filter, ignoring case; a limit of 0 returns
+// every match
+func (q *Queries) GetFeeds(ctx context.Context, arg GetFeedsParams) ([]GetFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeeds, arg.Filter, arg.Limit, arg.Offset)
 	if err != nil {
 		return nil, err
 	}
@@ -273,15 +555,366 @@ func (q *Queries) GetFeeds(ctx context.Context) ([]GetFeedsRow, error) {
 	return items, nil
 }
 
+const getFeedsCreatedByUser = `-- name: GetFeedsCreatedByUser :many
+SELECT id, name, url
+FROM feeds
+WHERE user_id = $1
+ORDER BY name
+`
+
+type GetFeedsCreatedByUserRow struct {
+	ID   uuid.UUID
+	Name string
+	Url  string
+}
+
+func (q *Queries) GetFeedsCreatedByUser(ctx context.Context, userID uuid.UUID) ([]GetFeedsCreatedByUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsCreatedByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsCreatedByUserRow
+	for rows.Next() {
+		var i GetFeedsCreatedByUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsDueForFetch = `-- name: GetFeedsDueForFetch :many
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.last_etag, feeds.last_modified, feeds.interval_secs, feeds.consecutive_errors, feeds.last_error, feeds.last_attempted_at, feeds.fetch_timeout_secs, feeds.user_agent
+FROM feeds
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feeds.last_fetched_at IS NULL
+   OR feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0)) <= NOW()
+ORDER BY feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0)) NULLS FIRST
+`
+
+// A feed's own interval wins; with adaptive set, the learned one applies next
+func (q *Queries) GetFeedsDueForFetch(ctx context.Context, adaptive bool) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsDueForFetch, adaptive)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.LastEtag,
+			&i.LastModified,
+			&i.IntervalSecs,
+			&i.ConsecutiveErrors,
+			&i.LastError,
+			&i.LastAttemptedAt,
+			&i.FetchTimeoutSecs,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsVerbose = `-- name: GetFeedsVerbose :many
+SELECT
+    feeds.id AS feed_id,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    feeds.last_fetched_at,
+    feeds.consecutive_errors,
+    feeds.last_error,
+    feeds.interval_secs,
+    feed_adaptive_intervals.interval_secs AS adaptive_interval_secs,
+    COUNT(posts.id) AS post_count
+FROM feeds
+JOIN users ON feeds.user_id = users.id
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+LEFT JOIN posts ON posts.feed_id = feeds.id
+WHERE lower(feeds.name) LIKE lower($1::text)
+GROUP BY feeds.id, users.name, feed_adaptive_intervals.interval_secs
+ORDER BY feeds.name
+LIMIT NULLIF($2::int, 0) OFFSET $3::int
+`
+
+type GetFeedsVerboseParams struct {
+	Filter string
+	Limit  int32
+	Offset int32
+}
+
+type GetFeedsVerboseRow struct {
+	FeedID               uuid.UUID
+	FeedName             string
+	FeedUrl              string
+	UserName             string
+	LastFetchedAt        sql.NullTime
+	ConsecutiveErrors    int32
+	LastError            sql.NullString
+	IntervalSecs         sql.NullInt32
+	AdaptiveIntervalSecs sql.NullInt32
+	PostCount            int64
+}
+
+// GetFeeds with each feed's fetch health, how many posts are stored and its polling
+// intervals
+func (q *Queries) GetFeedsVerbose(ctx context.Context, arg GetFeedsVerboseParams) ([]GetFeedsVerboseRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsVerbose, arg.Filter, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsVerboseRow
+	for rows.Next() {
+		var i GetFeedsVerboseRow
+		if err := rows.Scan(
+			&i.FeedID,
+			&i.FeedName,
+			&i.FeedUrl,
+			&i.UserName,
+			&i.LastFetchedAt,
+			&i.ConsecutiveErrors,
+			&i.LastError,
+			&i.IntervalSecs,
+			&i.AdaptiveIntervalSecs,
+			&i.PostCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsWithErrors = `-- name: GetFeedsWithErrors :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, last_etag, last_modified, interval_secs, consecutive_errors, last_error, last_attempted_at, fetch_timeout_secs, user_agent
+FROM feeds
+WHERE consecutive_errors > 0 AND consecutive_errors >= $1::int
+ORDER BY consecutive_errors DESC, name
+`
+
+func (q *Queries) GetFeedsWithErrors(ctx context.Context, threshold int32) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithErrors, threshold)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.LastEtag,
+			&i.LastModified,
+			&i.IntervalSecs,
+			&i.ConsecutiveErrors,
+			&i.LastError,
+			&i.LastAttemptedAt,
+			&i.FetchTimeoutSecs,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFeedsWithHealth = `-- name: GetFeedsWithHealth :many
+SELECT feeds.id, feeds.last_fetched_at, feeds.consecutive_errors, feeds.interval_secs,
+    feed_adaptive_intervals.interval_secs AS adaptive_interval_secs
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+`
+
+type GetFeedsWithHealthRow struct {
+	ID                   uuid.UUID
+	LastFetchedAt        sql.NullTime
+	ConsecutiveErrors    int32
+	IntervalSecs         sql.NullInt32
+	AdaptiveIntervalSecs sql.NullInt32
+}
+
+// How recently and how well each feed the user follows was fetched, for the TUI's feed pane
+func (q *Queries) GetFeedsWithHealth(ctx context.Context, userID uuid.UUID) ([]GetFeedsWithHealthRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFeedsWithHealth, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFeedsWithHealthRow
+	for rows.Next() {
+		var i GetFeedsWithHealthRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.LastFetchedAt,
+			&i.ConsecutiveErrors,
+			&i.IntervalSecs,
+			&i.AdaptiveIntervalSecs,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFollowedFeedsMatching = `-- name: GetFollowedFeedsMatching :many
+SELECT feeds.id, feeds.name, feeds.url
+FROM feed_follows
+JOIN feeds ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+  AND ($2::text IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_tags WHERE tag = $2::text))
+  AND ($3::uuid IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_group_members WHERE group_id = $3::uuid))
+ORDER BY feeds.name
+`
+
+type GetFollowedFeedsMatchingParams struct {
+	UserID  uuid.UUID
+	Tag     sql.NullString
+	GroupID uuid.NullUUID
+}
+
+type GetFollowedFeedsMatchingRow struct {
+	ID   uuid.UUID
+	Name string
+	Url  string
+}
+
+// The feeds a user follows, narrowed to a tag and/or one of their groups when given
+func (q *Queries) GetFollowedFeedsMatching(ctx context.Context, arg GetFollowedFeedsMatchingParams) ([]GetFollowedFeedsMatchingRow, error) {
+	rows, err := q.db.QueryContext(ctx, getFollowedFeedsMatching, arg.UserID, arg.Tag, arg.GroupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFollowedFeedsMatchingRow
+	for rows.Next() {
+		var i GetFollowedFeedsMatchingRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getHTTPFeeds = `-- name: GetHTTPFeeds :many
+SELECT id, name, url
+FROM feeds
+WHERE url ILIKE 'http://%'
+ORDER BY name
+`
+
+type GetHTTPFeedsRow struct {
+	ID   uuid.UUID
+	Name string
+	Url  string
+}
+
+func (q *Queries) GetHTTPFeeds(ctx context.Context) ([]GetHTTPFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getHTTPFeeds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetHTTPFeedsRow
+	for rows.Next() {
+		var i GetHTTPFeedsRow
+		if err := rows.Scan(&i.ID, &i.Name, &i.Url); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const getNextFeedToFetch = `-- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.last_etag, feeds.last_modified, feeds.interval_secs, feeds.consecutive_errors, feeds.last_error, feeds.last_attempted_at, feeds.fetch_timeout_secs, feeds.user_agent
 FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feeds.last_fetched_at IS NULL
+   OR feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0)) <= NOW()
+ORDER BY feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0)) NULLS FIRST
 LIMIT 1
 `
 
-func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
-	row := q.db.QueryRowContext(ctx, getNextFeedToFetch)
+// A feed's own interval wins; with adaptive set, the learned one applies next
+func (q *Queries) GetNextFeedToFetch(ctx context.Context, adaptive bool) (Feed, error) {
+	row := q.db.QueryRowContext(ctx, getNextFeedToFetch, adaptive)
 	var i Feed
 	err := row.Scan(
 		&i.ID,
@@ -291,17 +924,313 @@ func (q *Queries) GetNextFeedToFetch(ctx context.Context) (Feed, error) {
 		&i.Url,
 		&i.UserID,
 		&i.LastFetchedAt,
+		&i.LastEtag,
+		&i.LastModified,
+		&i.IntervalSecs,
+		&i.ConsecutiveErrors,
+		&i.LastError,
+		&i.LastAttemptedAt,
+		&i.FetchTimeoutSecs,
+		&i.UserAgent,
 	)
 	return i, err
 }
 
-const markFeedFetched = `-- name: MarkFeedFetched :exec
+const getUnfollowedFeeds = `-- name: GetUnfollowedFeeds :many
+SELECT feeds.id, feeds.name, feeds.url
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = $1 AND feed_follows.feed_id = feeds.id
+  )
+  AND ($2::text IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_tags WHERE tag = $2::text))
+ORDER BY feeds.name
+`
+
+type GetUnfollowedFeedsParams struct {
+	UserID uuid.UUID
+	Tag    sql.NullString
+}
+
+type GetUnfollowedFeedsRow struct {
+	ID   uuid.UUID
+	Name string
+	Url  string
+}
+
+// Every feed the user doesn't follow yet, or only those with a tag when given
+func (q *Queries) GetUnfollowedFeeds(ctx context.Context, arg GetUnfollowedFeedsParams) ([]GetUnfollowedFeedsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getUnfollowedFeeds, arg.UserID, arg.Tag)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUnfollowedFeedsRow
+	for rows.Next() {
+		var i GetUnfollowedFeedsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const markFeedFetchedError = `-- name: MarkFeedFetchedError :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), last_attempted_at = NOW(), updated_at = NOW(),
+    consecutive_errors = consecutive_errors + 1, last_error = $2::text
+WHERE id = $1
+`
+
+type MarkFeedFetchedErrorParams struct {
+	ID        uuid.UUID
+	LastError string
+}
+
+func (q *Queries) MarkFeedFetchedError(ctx context.Context, arg MarkFeedFetchedErrorParams) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetchedError, arg.ID, arg.LastError)
+	return err
+}
+
+const markFeedFetchedOK = `-- name: MarkFeedFetchedOK :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), last_attempted_at = NOW(), updated_at = NOW(),
+    consecutive_errors = 0, last_error = NULL
+WHERE id = $1
+`
+
+func (q *Queries) MarkFeedFetchedOK(ctx context.Context, id uuid.UUID) error {
+	_, err := q.db.ExecContext(ctx, markFeedFetchedOK, id)
+	return err
+}
+
+const moveFeedFollows = `-- name: MoveFeedFollows :execrows
+UPDATE feed_follows
+SET feed_id = $1, updated_at = NOW()
+WHERE feed_id = $2
+  AND user_id NOT IN (
+    SELECT user_id FROM feed_follows WHERE feed_id = $1
+  )
+`
+
+type MoveFeedFollowsParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MoveFeedFollows(ctx context.Context, arg MoveFeedFollowsParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, moveFeedFollows, arg.ToFeedID, arg.FromFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const reassignFeed = `-- name: ReassignFeed :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type ReassignFeedParams struct {
+	ID     uuid.UUID
+	UserID uuid.UUID
+}
+
+func (q *Queries) ReassignFeed(ctx context.Context, arg ReassignFeedParams) error {
+	_, err := q.db.ExecContext(ctx, reassignFeed, arg.ID, arg.UserID)
+	return err
+}
+
+const recordFeedFetchError = `-- name: RecordFeedFetchError :exec
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
+SET last_attempted_at = NOW(), updated_at = NOW(),
+    consecutive_errors = consecutive_errors + 1, last_error = $2::text
 WHERE id = $1
 `
 
-func (q *Queries) MarkFeedFetched(ctx context.Context, id uuid.UUID) error {
-	_, err := q.db.ExecContext(ctx, markFeedFetched, id)
+type RecordFeedFetchErrorParams struct {
+	ID        uuid.UUID
+	LastError string
+}
+
+func (q *Queries) RecordFeedFetchError(ctx context.Context, arg RecordFeedFetchErrorParams) error {
+	_, err := q.db.ExecContext(ctx, recordFeedFetchError, arg.ID, arg.LastError)
+	return err
+}
+
+const resetFeedErrors = `-- name: ResetFeedErrors :execrows
+UPDATE feeds
+SET consecutive_errors = 0, last_error = NULL, updated_at = NOW()
+WHERE url = $1
+`
+
+func (q *Queries) ResetFeedErrors(ctx context.Context, url string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resetFeedErrors, url)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchFeedsByName = `-- name: SearchFeedsByName :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, last_etag, last_modified, interval_secs, consecutive_errors, last_error, last_attempted_at, fetch_timeout_secs, user_agent
+FROM feeds
+WHERE name ILIKE '%' || $1::text || '%'
+   OR url ILIKE '%' || $1::text || '%'
+ORDER BY CASE
+    WHEN lower(name) = lower($1::text) THEN 0
+    WHEN name ILIKE $1::text || '%' THEN 1
+    ELSE 2
+END, name
+`
+
+// Feeds whose name or URL contains pattern, ignoring case. Exact name matches come
+// first, then names starting with pattern, then the rest.
+func (q *Queries) SearchFeedsByName(ctx context.Context, pattern string) ([]Feed, error) {
+	rows, err := q.db.QueryContext(ctx, searchFeedsByName, pattern)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Feed
+	for rows.Next() {
+		var i Feed
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Name,
+			&i.Url,
+			&i.UserID,
+			&i.LastFetchedAt,
+			&i.LastEtag,
+			&i.LastModified,
+			&i.IntervalSecs,
+			&i.ConsecutiveErrors,
+			&i.LastError,
+			&i.LastAttemptedAt,
+			&i.FetchTimeoutSecs,
+			&i.UserAgent,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const setFeedCacheHeaders = `-- name: SetFeedCacheHeaders :exec
+UPDATE feeds
+SET last_etag = $2, last_modified = $3
+WHERE id = $1
+`
+
+type SetFeedCacheHeadersParams struct {
+	ID           uuid.UUID
+	LastEtag     sql.NullString
+	LastModified sql.NullString
+}
+
+func (q *Queries) SetFeedCacheHeaders(ctx context.Context, arg SetFeedCacheHeadersParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedCacheHeaders, arg.ID, arg.LastEtag, arg.LastModified)
+	return err
+}
+
+const setFeedFetchTimeout = `-- name: SetFeedFetchTimeout :exec
+UPDATE feeds
+SET fetch_timeout_secs = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedFetchTimeoutParams struct {
+	ID               uuid.UUID
+	FetchTimeoutSecs sql.NullInt32
+}
+
+func (q *Queries) SetFeedFetchTimeout(ctx context.Context, arg SetFeedFetchTimeoutParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedFetchTimeout, arg.ID, arg.FetchTimeoutSecs)
+	return err
+}
+
+const setFeedInterval = `-- name: SetFeedInterval :exec
+UPDATE feeds
+SET interval_secs = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedIntervalParams struct {
+	ID           uuid.UUID
+	IntervalSecs sql.NullInt32
+}
+
+func (q *Queries) SetFeedInterval(ctx context.Context, arg SetFeedIntervalParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedInterval, arg.ID, arg.IntervalSecs)
+	return err
+}
+
+const setFeedUserAgent = `-- name: SetFeedUserAgent :exec
+UPDATE feeds
+SET user_agent = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type SetFeedUserAgentParams struct {
+	ID        uuid.UUID
+	UserAgent sql.NullString
+}
+
+func (q *Queries) SetFeedUserAgent(ctx context.Context, arg SetFeedUserAgentParams) error {
+	_, err := q.db.ExecContext(ctx, setFeedUserAgent, arg.ID, arg.UserAgent)
+	return err
+}
+
+const updateFeedName = `-- name: UpdateFeedName :exec
+UPDATE feeds
+SET name = $2, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedNameParams struct {
+	ID   uuid.UUID
+	Name string
+}
+
+func (q *Queries) UpdateFeedName(ctx context.Context, arg UpdateFeedNameParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedName, arg.ID, arg.Name)
+	return err
+}
+
+const updateFeedURL = `-- name: UpdateFeedURL :exec
+UPDATE feeds
+SET url = $2, last_etag = NULL, last_modified = NULL, updated_at = NOW()
+WHERE id = $1
+`
+
+type UpdateFeedURLParams struct {
+	ID  uuid.UUID
+	Url string
+}
+
+func (q *Queries) UpdateFeedURL(ctx context.Context, arg UpdateFeedURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateFeedURL, arg.ID, arg.Url)
 	return err
 }
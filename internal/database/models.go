@@ -11,6 +11,24 @@ import (
 	"github.com/google/uuid"
 )
 
+type AggRun struct {
+	ID           uuid.UUID
+	StartedAt    time.Time
+	FinishedAt   time.Time
+	FeedsFetched int32
+	FeedsFailed  int32
+	PostsSaved   int32
+}
+
+type ApiKey struct {
+	ID         uuid.UUID
+	UserID     uuid.UUID
+	KeyHash    string
+	KeyPrefix  string
+	CreatedAt  time.Time
+	LastUsedAt sql.NullTime
+}
+
 type Bookmark struct {
 	ID        uuid.UUID
 	CreatedAt sql.NullTime
@@ -20,13 +38,45 @@ type Bookmark struct {
 }
 
 type Feed struct {
-	ID            uuid.UUID
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
-	Name          string
-	Url           string
-	UserID        uuid.UUID
-	LastFetchedAt sql.NullTime
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Name              string
+	Url               string
+	UserID            uuid.UUID
+	LastFetchedAt     sql.NullTime
+	LastEtag          sql.NullString
+	LastModified      sql.NullString
+	IntervalSecs      sql.NullInt32
+	ConsecutiveErrors int32
+	LastError         sql.NullString
+	LastAttemptedAt   sql.NullTime
+	FetchTimeoutSecs  sql.NullInt32
+	UserAgent         sql.NullString
+}
+
+type FeedAdaptiveInterval struct {
+	FeedID       uuid.UUID
+	IntervalSecs int32
+	UpdatedAt    time.Time
+}
+
+type FeedGroup struct {
+	ID        uuid.UUID
+	UserID    uuid.UUID
+	Name      string
+	CreatedAt time.Time
+}
+
+type FeedGroupMember struct {
+	GroupID uuid.UUID
+	FeedID  uuid.UUID
+}
+
+type FeedTag struct {
+	FeedID    uuid.UUID
+	Tag       string
+	CreatedAt time.Time
 }
 
 type FeedFollow struct {
@@ -37,20 +87,106 @@ type FeedFollow struct {
 	FeedID    uuid.UUID
 }
 
+type KeywordFilter struct {
+	ID         uuid.UUID
+	CreatedAt  time.Time
+	UserID     uuid.UUID
+	Keyword    string
+	FilterType string
+	Field      string
+}
+
+type Notification struct {
+	FeedID  uuid.UUID
+	UserID  uuid.UUID
+	Enabled bool
+}
+
+type PinnedPost struct {
+	UserID   uuid.UUID
+	PostID   uuid.UUID
+	PinnedAt time.Time
+	Note     sql.NullString
+}
+
+type PodcastFeed struct {
+	FeedID    uuid.UUID
+	Author    string
+	ImageUrl  string
+	Category  string
+	Explicit  bool
+	UpdatedAt time.Time
+}
+
 type Post struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Title         string
+	Url           string
+	Description   sql.NullString
+	PublishedAt   sql.NullTime
+	FeedID        uuid.UUID
+	NormalizedUrl string
+	Author        sql.NullString
+	Language      sql.NullString
+	RedirectFrom  sql.NullString
 }
 
-type User struct {
-	ID        uuid.UUID
+type PostEnclosure struct {
+	PostID        uuid.UUID
+	Url           string
+	MediaType     string
+	Length        sql.NullInt64
+	EpisodeNumber sql.NullInt32
+	SeasonNumber  sql.NullInt32
+	DurationSecs  sql.NullInt32
+}
+
+type PostTag struct {
+	PostID    uuid.UUID
+	Tag       string
 	CreatedAt time.Time
-	UpdatedAt time.Time
-	Name      string
+}
+
+type ReadPost struct {
+	UserID uuid.UUID
+	PostID uuid.UUID
+	ReadAt time.Time
+}
+
+type ReadQueue struct {
+	UserID   uuid.UUID
+	PostID   uuid.UUID
+	Position int32
+	AddedAt  time.Time
+	Status   string
+}
+
+type TagRule struct {
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UserID        uuid.UUID
+	Keyword       string
+	Tag           string
+	Field         string
+	CaseSensitive bool
+}
+
+type User struct {
+	ID                uuid.UUID
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+	Name              string
+	PreferredLanguage sql.NullString
+}
+
+type Webhook struct {
+	ID            uuid.UUID
+	UserID        uuid.UUID
+	Url           string
+	Secret        string
+	FeedFilterID  uuid.NullUUID
+	KeywordFilter sql.NullString
+	CreatedAt     time.Time
 }
@@ -11,27 +11,44 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
-const createPost = `-- name: CreatePost :exec
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (url) DO NOTHING
+const countPostsForFeed = `-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) CountPostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countPostsForFeed, feedID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const createPost = `-- name: CreatePost :execrows
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, normalized_url, author, language, redirect_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (normalized_url) DO NOTHING
 `
 
 type CreatePostParams struct {
-	ID          uuid.UUID
-	CreatedAt   time.Time
-	UpdatedAt   time.Time
-	Title       string
-	Url         string
-	Description sql.NullString
-	PublishedAt sql.NullTime
-	FeedID      uuid.UUID
+	ID            uuid.UUID
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Title         string
+	Url           string
+	Description   sql.NullString
+	PublishedAt   sql.NullTime
+	FeedID        uuid.UUID
+	NormalizedUrl string
+	Author        sql.NullString
+	Language      sql.NullString
+	RedirectFrom  sql.NullString
 }
 
-func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
-	_, err := q.db.ExecContext(ctx, createPost,
+func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, createPost,
 		arg.ID,
 		arg.CreatedAt,
 		arg.UpdatedAt,
@@ -40,26 +57,276 @@ func (q *Queries) CreatePost(ctx context.Context, arg CreatePostParams) error {
 		arg.Description,
 		arg.PublishedAt,
 		arg.FeedID,
+		arg.NormalizedUrl,
+		arg.Author,
+		arg.Language,
+		arg.RedirectFrom,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const deleteOldPostsForUser = `-- name: DeleteOldPostsForUser :many
+DELETE FROM posts p
+USING feed_follows ff
+WHERE p.feed_id = ff.feed_id
+  AND ff.user_id = $1
+  AND COALESCE(p.published_at, p.created_at) < $2::timestamp
+RETURNING p.id, p.title, p.url, p.published_at
+`
+
+type DeleteOldPostsForUserParams struct {
+	UserID uuid.UUID
+	Cutoff time.Time
+}
+
+type DeleteOldPostsForUserRow struct {
+	ID          uuid.UUID
+	Title       string
+	Url         string
+	PublishedAt sql.NullTime
+}
+
+func (q *Queries) DeleteOldPostsForUser(ctx context.Context, arg DeleteOldPostsForUserParams) ([]DeleteOldPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, deleteOldPostsForUser, arg.UserID, arg.Cutoff)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []DeleteOldPostsForUserRow
+	for rows.Next() {
+		var i DeleteOldPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Title,
+			&i.Url,
+			&i.PublishedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deletePostsForFeed = `-- name: DeletePostsForFeed :execrows
+DELETE FROM posts
+WHERE feed_id = $1
+`
+
+func (q *Queries) DeletePostsForFeed(ctx context.Context, feedID uuid.UUID) (int64, error) {
+	result, err := q.db.ExecContext(ctx, deletePostsForFeed, feedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const getPostByID = `-- name: GetPostByID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, normalized_url, author, language, redirect_from
+FROM posts
+WHERE id = $1
+`
+
+func (q *Queries) GetPostByID(ctx context.Context, id uuid.UUID) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByID, id)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.NormalizedUrl,
+		&i.Author,
+		&i.Language,
+		&i.RedirectFrom,
 	)
-	return err
+	return i, err
+}
+
+const getPostByURL = `-- name: GetPostByURL :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, normalized_url, author, language, redirect_from
+FROM posts
+WHERE url = $1 OR normalized_url = $2
+LIMIT 1
+`
+
+type GetPostByURLParams struct {
+	Url           string
+	NormalizedUrl string
+}
+
+// Matches the URL as published or any variant that normalizes to the same post
+func (q *Queries) GetPostByURL(ctx context.Context, arg GetPostByURLParams) (Post, error) {
+	row := q.db.QueryRowContext(ctx, getPostByURL, arg.Url, arg.NormalizedUrl)
+	var i Post
+	err := row.Scan(
+		&i.ID,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Title,
+		&i.Url,
+		&i.Description,
+		&i.PublishedAt,
+		&i.FeedID,
+		&i.NormalizedUrl,
+		&i.Author,
+		&i.Language,
+		&i.RedirectFrom,
+	)
+	return i, err
+}
+
+const getPostRedirects = `-- name: GetPostRedirects :many
+SELECT redirect_from, url
+FROM posts
+WHERE redirect_from = ANY($1::text[])
+`
+
+type GetPostRedirectsRow struct {
+	RedirectFrom sql.NullString
+	Url          string
+}
+
+// The posts stored under a resolved URL whose original link is one of the given ones
+func (q *Queries) GetPostRedirects(ctx context.Context, urls []string) ([]GetPostRedirectsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostRedirects, pq.Array(urls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostRedirectsRow
+	for rows.Next() {
+		var i GetPostRedirectsRow
+		if err := rows.Scan(
+			&i.RedirectFrom,
+			&i.Url,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const getPostsForUser = `-- name: GetPostsForUser :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+    EXISTS (
+        SELECT 1 FROM read_posts rp
+        WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.user_id = ff.user_id AND b.post_id = p.id
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM pinned_posts pp
+        WHERE pp.user_id = ff.user_id AND pp.post_id = p.id
+    ) AS is_pinned
 FROM posts p
 JOIN feed_follows ff ON p.feed_id = ff.feed_id
 WHERE ff.user_id = $1
 ORDER BY COALESCE(p.published_at, p.created_at) DESC
-LIMIT $2
+LIMIT $2 OFFSET $3
 `
 
 type GetPostsForUserParams struct {
 	UserID uuid.UUID
 	Limit  int32
+	Offset int32
 }
 
-func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit)
+type GetPostsForUserRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	IsRead       bool
+	IsBookmarked bool
+	IsPinned     bool
+}
+
+func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams) ([]GetPostsForUserRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUser, arg.UserID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserRow
+	for rows.Next() {
+		var i GetPostsForUserRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.IsRead,
+			&i.IsBookmarked,
+			&i.IsPinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByAuthor = `-- name: GetPostsForUserByAuthor :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1 AND p.author ILIKE '%' || $2::text || '%'
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetPostsForUserByAuthorParams struct {
+	UserID uuid.UUID
+	Author string
+	Limit  int32
+	Offset int32
+}
+
+// Matches any part of the author's name, ignoring case
+func (q *Queries) GetPostsForUserByAuthor(ctx context.Context, arg GetPostsForUserByAuthorParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByAuthor,
+		arg.UserID,
+		arg.Author,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -76,6 +343,10 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.NormalizedUrl,
+			&i.Author,
+			&i.Language,
+			&i.RedirectFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -90,23 +361,93 @@ func (q *Queries) GetPostsForUser(ctx context.Context, arg GetPostsForUserParams
 	return items, nil
 }
 
-const getPostsForUserPaginated = `-- name: GetPostsForUserPaginated :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+const getPostsForUserByGroup = `-- name: GetPostsForUserByGroup :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+JOIN feed_group_members gm ON p.feed_id = gm.feed_id
+WHERE ff.user_id = $1 AND gm.group_id = $2
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT $3 OFFSET $4
+`
+
+type GetPostsForUserByGroupParams struct {
+	UserID  uuid.UUID
+	GroupID uuid.UUID
+	Limit   int32
+	Offset  int32
+}
+
+func (q *Queries) GetPostsForUserByGroup(ctx context.Context, arg GetPostsForUserByGroupParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByGroup,
+		arg.UserID,
+		arg.GroupID,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.NormalizedUrl,
+			&i.Author,
+			&i.Language,
+			&i.RedirectFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserByTag = `-- name: GetPostsForUserByTag :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
 FROM posts p
 JOIN feed_follows ff ON p.feed_id = ff.feed_id
 WHERE ff.user_id = $1
+  AND (EXISTS (
+    SELECT 1 FROM feed_tags ft WHERE ft.feed_id = p.feed_id AND ft.tag = $2
+  ) OR EXISTS (
+    SELECT 1 FROM post_tags pt WHERE pt.post_id = p.id AND pt.tag = $2
+  ))
 ORDER BY COALESCE(p.published_at, p.created_at) DESC
-LIMIT $2 OFFSET $3
+LIMIT $3 OFFSET $4
 `
 
-type GetPostsForUserPaginatedParams struct {
+type GetPostsForUserByTagParams struct {
 	UserID uuid.UUID
+	Tag    string
 	Limit  int32
 	Offset int32
 }
 
-func (q *Queries) GetPostsForUserPaginated(ctx context.Context, arg GetPostsForUserPaginatedParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, getPostsForUserPaginated, arg.UserID, arg.Limit, arg.Offset)
+// Posts match when their feed has the tag or a tag rule gave it to the post itself
+func (q *Queries) GetPostsForUserByTag(ctx context.Context, arg GetPostsForUserByTagParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserByTag,
+		arg.UserID,
+		arg.Tag,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -123,6 +464,10 @@ func (q *Queries) GetPostsForUserPaginated(ctx context.Context, arg GetPostsForU
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.NormalizedUrl,
+			&i.Author,
+			&i.Language,
+			&i.RedirectFrom,
 		); err != nil {
 			return nil, err
 		}
@@ -137,21 +482,227 @@ func (q *Queries) GetPostsForUserPaginated(ctx context.Context, arg GetPostsForU
 	return items, nil
 }
 
-const searchPosts = `-- name: SearchPosts :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+const getPostsForUserFeed = `-- name: GetPostsForUserFeed :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+    EXISTS (
+        SELECT 1 FROM read_posts rp
+        WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.user_id = ff.user_id AND b.post_id = p.id
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM pinned_posts pp
+        WHERE pp.user_id = ff.user_id AND pp.post_id = p.id
+    ) AS is_pinned
 FROM posts p
 JOIN feed_follows ff ON p.feed_id = ff.feed_id
-WHERE ff.user_id = $1 AND (p.title ILIKE $2 OR p.description ILIKE $2)
+WHERE ff.user_id = $1 AND p.feed_id = $2
 ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT $3 OFFSET $4
 `
 
-type SearchPostsParams struct {
+type GetPostsForUserFeedParams struct {
 	UserID uuid.UUID
-	Title  string
+	FeedID uuid.UUID
+	Limit  int32
+	Offset int32
+}
+
+type GetPostsForUserFeedRow struct {
+	ID           uuid.UUID
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Title        string
+	Url          string
+	Description  sql.NullString
+	PublishedAt  sql.NullTime
+	FeedID       uuid.UUID
+	IsRead       bool
+	IsBookmarked bool
+	IsPinned     bool
+}
+
+func (q *Queries) GetPostsForUserFeed(ctx context.Context, arg GetPostsForUserFeedParams) ([]GetPostsForUserFeedRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserFeed, arg.UserID, arg.FeedID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPostsForUserFeedRow
+	for rows.Next() {
+		var i GetPostsForUserFeedRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.IsRead,
+			&i.IsBookmarked,
+			&i.IsPinned,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPostsForUserPaginated = `-- name: GetPostsForUserPaginated :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1
+  AND (NOT $2::bool OR NOT EXISTS (
+    SELECT 1 FROM read_posts rp
+    WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+  ))
+  AND ($3::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) >= $3::timestamp)
+  AND ($4::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) < $4::timestamp)
+  AND ($5::uuid IS NULL OR p.feed_id = $5::uuid)
+  AND ($6::text IS NULL OR p.language IS NULL OR p.language = $6::text)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT $7 OFFSET $8
+`
+
+type GetPostsForUserPaginatedParams struct {
+	UserID          uuid.UUID
+	UnreadOnly      bool
+	PublishedAfter  sql.NullTime
+	PublishedBefore sql.NullTime
+	FeedID          uuid.NullUUID
+	Language        sql.NullString
+	Limit           int32
+	Offset          int32
+}
+
+func (q *Queries) GetPostsForUserPaginated(ctx context.Context, arg GetPostsForUserPaginatedParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getPostsForUserPaginated,
+		arg.UserID,
+		arg.UnreadOnly,
+		arg.PublishedAfter,
+		arg.PublishedBefore,
+		arg.FeedID,
+		arg.Language,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Post
+	for rows.Next() {
+		var i Post
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.NormalizedUrl,
+			&i.Author,
+			&i.Language,
+			&i.RedirectFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getStoredNormalizedURLs = `-- name: GetStoredNormalizedURLs :many
+SELECT normalized_url
+FROM posts
+WHERE normalized_url = ANY($1::text[])
+`
+
+// Which of the given normalized URLs already belong to a post
+func (q *Queries) GetStoredNormalizedURLs(ctx context.Context, normalizedUrls []string) ([]string, error) {
+	rows, err := q.db.QueryContext(ctx, getStoredNormalizedURLs, pq.Array(normalizedUrls))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var normalized_url string
+		if err := rows.Scan(&normalized_url); err != nil {
+			return nil, err
+		}
+		items = append(items, normalized_url)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
-func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]Post, error) {
-	rows, err := q.db.QueryContext(ctx, searchPosts, arg.UserID, arg.Title)
+const getUnreadPostsForUser = `-- name: GetUnreadPostsForUser :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1
+  AND NOT EXISTS (
+    SELECT 1 FROM read_posts rp
+    WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+  )
+  AND ($2::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) >= $2::timestamp)
+  AND ($3::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) < $3::timestamp)
+  AND ($4::uuid IS NULL OR p.feed_id = $4::uuid)
+  AND ($5::text IS NULL OR p.language IS NULL OR p.language = $5::text)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT $6 OFFSET $7
+`
+
+type GetUnreadPostsForUserParams struct {
+	UserID          uuid.UUID
+	PublishedAfter  sql.NullTime
+	PublishedBefore sql.NullTime
+	FeedID          uuid.NullUUID
+	Language        sql.NullString
+	Limit           int32
+	Offset          int32
+}
+
+func (q *Queries) GetUnreadPostsForUser(ctx context.Context, arg GetUnreadPostsForUserParams) ([]Post, error) {
+	rows, err := q.db.QueryContext(ctx, getUnreadPostsForUser,
+		arg.UserID,
+		arg.PublishedAfter,
+		arg.PublishedBefore,
+		arg.FeedID,
+		arg.Language,
+		arg.Limit,
+		arg.Offset,
+	)
 	if err != nil {
 		return nil, err
 	}
@@ -168,6 +719,145 @@ func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]Pos
 			&i.Description,
 			&i.PublishedAt,
 			&i.FeedID,
+			&i.NormalizedUrl,
+			&i.Author,
+			&i.Language,
+			&i.RedirectFrom,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const movePostsToFeed = `-- name: MovePostsToFeed :execrows
+UPDATE posts
+SET feed_id = $1, updated_at = NOW()
+WHERE feed_id = $2
+`
+
+type MovePostsToFeedParams struct {
+	ToFeedID   uuid.UUID
+	FromFeedID uuid.UUID
+}
+
+func (q *Queries) MovePostsToFeed(ctx context.Context, arg MovePostsToFeedParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, movePostsToFeed, arg.ToFeedID, arg.FromFeedID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const searchPosts = `-- name: SearchPosts :many
+WITH matches AS (
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+       (CASE
+           WHEN $1::text = 'fulltext' THEN ts_rank(
+               setweight(to_tsvector('english', p.title), 'A') ||
+               setweight(to_tsvector('english', COALESCE(p.description, '')), 'B') ||
+               setweight(to_tsvector('simple', p.url), 'C'),
+               websearch_to_tsquery('english', $2::text))
+           WHEN p.title ILIKE '%' || $2::text || '%' THEN 1
+           ELSE 0.5
+       END)::real AS rank
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $3
+  AND (
+    ($1::text = 'fulltext' AND (
+        setweight(to_tsvector('english', p.title), 'A') ||
+        setweight(to_tsvector('english', COALESCE(p.description, '')), 'B') ||
+        setweight(to_tsvector('simple', p.url), 'C')
+    ) @@ websearch_to_tsquery('english', $2::text))
+    OR p.author ILIKE '%' || $2::text || '%'
+    OR
+    ($1::text = 'like' AND (
+        p.title ILIKE '%' || $2::text || '%'
+        OR p.description ILIKE '%' || $2::text || '%'
+        OR p.url ILIKE '%' || $2::text || '%'
+        OR p.normalized_url = $2::text
+    ))
+    OR
+    ($1::text = 'word' AND (
+        p.title ~* $4::text
+        OR p.description ~* $4::text
+    ))
+  )
+  AND ($5::uuid IS NULL OR p.feed_id = $5::uuid)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, rank
+FROM matches
+ORDER BY
+    CASE WHEN $6::text = 'title' THEN lower(title) END,
+    CASE WHEN $6::text = 'relevance' THEN rank END DESC,
+    COALESCE(published_at, created_at) DESC
+LIMIT NULLIF($7::int, 0) OFFSET $8
+`
+
+type SearchPostsParams struct {
+	SearchMode  string
+	Query       string
+	UserID      uuid.UUID
+	WordPattern string
+	FeedID      uuid.NullUUID
+	SortBy      string
+	Limit       int32
+	Offset      int32
+}
+
+type SearchPostsRow struct {
+	ID          uuid.UUID
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+	Title       string
+	Url         string
+	Description sql.NullString
+	PublishedAt sql.NullTime
+	FeedID      uuid.UUID
+	Rank        float32
+}
+
+// The tsvector expression must match posts_search_idx exactly for the index to be used.
+// A limit of 0 returns every match; like mode with an empty query matches every post.
+// Word mode matches word_pattern, a case-insensitive regular expression, against titles
+// and descriptions. Author names match as substrings in every mode. Matches come newest
+// first unless sort_by is title or relevance.
+func (q *Queries) SearchPosts(ctx context.Context, arg SearchPostsParams) ([]SearchPostsRow, error) {
+	rows, err := q.db.QueryContext(ctx, searchPosts,
+		arg.SearchMode,
+		arg.Query,
+		arg.UserID,
+		arg.WordPattern,
+		arg.FeedID,
+		arg.SortBy,
+		arg.Limit,
+		arg.Offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SearchPostsRow
+	for rows.Next() {
+		var i SearchPostsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Title,
+			&i.Url,
+			&i.Description,
+			&i.PublishedAt,
+			&i.FeedID,
+			&i.Rank,
 		); err != nil {
 			return nil, err
 		}
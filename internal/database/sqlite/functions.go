@@ -0,0 +1,91 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// functions are registered on every connection under these names for the translated
+// queries to call in place of PostgreSQL built-ins
+var functions = map[string]any{
+	// SQLite parses x REGEXP y but leaves the regexp function itself to the application
+	"regexp":          matchRegexp,
+	"regexp_replace":  replaceRegexp,
+	"word_count":      wordCount,
+	"websearch_match": websearchMatch,
+}
+
+// volatileFunctions return something new on every call, so SQLite mustn't treat them as
+// deterministic
+var volatileFunctions = map[string]any{
+	"gen_random_uuid": uuid.NewString,
+}
+
+// compiled caches patterns, since queries call the functions once per row
+var compiled sync.Map
+
+func compile(pattern string) (*regexp.Regexp, error) {
+	if re, ok := compiled.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, err
+	}
+	compiled.Store(pattern, re)
+	return re, nil
+}
+
+// matchRegexp reports whether text matches pattern; SQLite calls it for text REGEXP pattern
+func matchRegexp(pattern, text string) (bool, error) {
+	re, err := compile(pattern)
+	if err != nil {
+		return false, err
+	}
+	return re.MatchString(text), nil
+}
+
+// replaceRegexp replaces every match of pattern in text, as PostgreSQL's regexp_replace
+// does with the g flag
+func replaceRegexp(text, pattern, replacement string) (string, error) {
+	re, err := compile(pattern)
+	if err != nil {
+		return "", err
+	}
+	return re.ReplaceAllString(text, replacement), nil
+}
+
+// wordCount counts the whitespace-separated words in text
+func wordCount(text string) int {
+	return len(strings.Fields(text))
+}
+
+// websearchMatch stands in for PostgreSQL's full-text search with websearch_to_tsquery:
+// document matches when it contains every word of query, ignoring case, and none of
+// the words marked with a leading minus. Quotes are dropped and OR is treated as a word
+// separator rather than an alternative, and there is no stemming.
+func websearchMatch(document, query string) bool {
+	document = strings.ToLower(document)
+	matched := false
+	for _, word := range strings.Fields(strings.ToLower(strings.ReplaceAll(query, `"`, " "))) {
+		if word == "or" {
+			continue
+		}
+		if excluded, ok := strings.CutPrefix(word, "-"); ok {
+			if excluded != "" && strings.Contains(document, excluded) {
+				return false
+			}
+			continue
+		}
+		if !strings.Contains(document, word) {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
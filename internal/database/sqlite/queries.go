@@ -0,0 +1,257 @@
+//go:build sqlite
+
+package sqlite
+
+// overrides are SQLite versions of the generated queries that translate can't derive by
+// swapping syntax, keyed by query name. They still go through translate, so they keep
+// the PostgreSQL placeholders, NOW() and casts of the originals.
+//
+// Timestamps computed by an expression come back from SQLite as plain text, which
+// doesn't scan into time.Time. Where PostgreSQL returns MIN or MAX of a timestamp, these
+// select a real column instead: posts.posted_at, and the bare column SQLite takes from
+// the row MIN or MAX picked.
+var overrides = map[string]string{
+	// A CTE can't hold an INSERT in SQLite; the names come from subqueries in RETURNING
+	"CreateFeedFollow": `
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, created_at, updated_at, user_id, feed_id,
+    (SELECT feeds.name FROM feeds WHERE feeds.id = feed_follows.feed_id) AS feed_name,
+    (SELECT users.name FROM users WHERE users.id = feed_follows.user_id) AS user_name`,
+
+	"DeleteOldPostsForUser": `
+DELETE FROM posts
+WHERE feed_id IN (SELECT feed_id FROM feed_follows WHERE user_id = $1)
+  AND posted_at < $2::timestamp
+RETURNING id, title, url, published_at`,
+
+	"GetFeedFrequencyStats": `
+WITH recent AS (
+    SELECT p.feed_id, p.posted_at
+    FROM posts p
+    JOIN feed_follows ff ON ff.feed_id = p.feed_id
+    WHERE ff.user_id = $1
+      AND p.posted_at >= $2::timestamp
+),
+newest AS (
+    SELECT feed_id, COUNT(*) AS post_count, MAX(posted_at), posted_at
+    FROM recent
+    GROUP BY feed_id
+),
+by_day AS (
+    SELECT feed_id, CAST(strftime('%w', posted_at) AS INTEGER) AS day,
+           ROW_NUMBER() OVER (PARTITION BY feed_id ORDER BY COUNT(*) DESC, CAST(strftime('%w', posted_at) AS INTEGER)) AS place
+    FROM recent
+    GROUP BY feed_id, CAST(strftime('%w', posted_at) AS INTEGER)
+),
+by_hour AS (
+    SELECT feed_id, CAST(strftime('%H', posted_at) AS INTEGER) AS hour,
+           ROW_NUMBER() OVER (PARTITION BY feed_id ORDER BY COUNT(*) DESC, CAST(strftime('%H', posted_at) AS INTEGER)) AS place
+    FROM recent
+    GROUP BY feed_id, CAST(strftime('%H', posted_at) AS INTEGER)
+)
+SELECT f.id, f.name, f.url,
+       COALESCE(n.post_count, 0) AS post_count,
+       n.posted_at AS newest_post,
+       d.day AS peak_day,
+       h.hour AS peak_hour
+FROM feed_follows ff
+JOIN feeds f ON f.id = ff.feed_id
+LEFT JOIN newest n ON n.feed_id = f.id
+LEFT JOIN by_day d ON d.feed_id = f.id AND d.place = 1
+LEFT JOIN by_hour h ON h.feed_id = f.id AND h.place = 1
+WHERE ff.user_id = $1
+ORDER BY post_count DESC, f.name`,
+
+	// SQLite has no intervals; julianday counts in days
+	"GetFeedsDueForFetch": `
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.last_etag, feeds.last_modified, feeds.interval_secs, feeds.consecutive_errors, feeds.last_error, feeds.last_attempted_at, feeds.fetch_timeout_secs, feeds.user_agent
+FROM feeds
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feeds.last_fetched_at IS NULL
+   OR julianday(feeds.last_fetched_at) + COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0) / 86400.0 <= julianday('now')
+ORDER BY julianday(feeds.last_fetched_at) + COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0) / 86400.0 NULLS FIRST`,
+
+	"GetNextFeedToFetch": `
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.last_etag, feeds.last_modified, feeds.interval_secs, feeds.consecutive_errors, feeds.last_error, feeds.last_attempted_at, feeds.fetch_timeout_secs, feeds.user_agent
+FROM feeds
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feeds.last_fetched_at IS NULL
+   OR julianday(feeds.last_fetched_at) + COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0) / 86400.0 <= julianday('now')
+ORDER BY julianday(feeds.last_fetched_at) + COALESCE(feeds.interval_secs, CASE WHEN $1::bool THEN feed_adaptive_intervals.interval_secs END, 0) / 86400.0 NULLS FIRST
+LIMIT 1`,
+
+	"GetPodcastFeedsForUser": `
+SELECT id, name, url, episodes, latest
+FROM (
+    SELECT feeds.id, feeds.name, feeds.url, COUNT(*) AS episodes, MAX(posts.posted_at), posts.posted_at AS latest
+    FROM post_enclosures
+    JOIN posts ON posts.id = post_enclosures.post_id
+    JOIN feeds ON feeds.id = posts.feed_id
+    JOIN feed_follows ON feed_follows.feed_id = feeds.id
+    WHERE feed_follows.user_id = $1
+    GROUP BY feeds.id, feeds.name, feeds.url
+)
+ORDER BY name`,
+
+	// The days come from a recursive CTE instead of generate_series, and reads_per_day is
+	// built as the text of a PostgreSQL array, which is what pq.Array scans
+	"GetReadingStats": `
+WITH RECURSIVE reads AS (
+    SELECT rp.post_id, rp.read_at, p.feed_id, p.description
+    FROM read_posts rp
+    JOIN posts p ON p.id = rp.post_id
+    WHERE rp.user_id = $1
+      AND rp.read_at >= $2::timestamp
+      AND rp.read_at < $3::timestamp
+),
+feed_reads AS (
+    SELECT feeds.name, COUNT(*) AS read_count,
+           ROW_NUMBER() OVER (ORDER BY COUNT(*) DESC, feeds.name) AS place
+    FROM reads
+    JOIN feeds ON feeds.id = reads.feed_id
+    GROUP BY feeds.id, feeds.name
+),
+days(day) AS (
+    SELECT date($3, '-' || ($4 - 1) || ' days') WHERE $4 > 0
+    UNION ALL
+    SELECT date(day, '+1 day') FROM days WHERE day < date($3)
+)
+SELECT
+    (SELECT COUNT(*) FROM reads) AS posts_read,
+    '{' || COALESCE((
+        SELECT group_concat(day_reads, ',' ORDER BY day)
+        FROM (
+            SELECT days.day, COUNT(rp.post_id) AS day_reads
+            FROM days
+            LEFT JOIN read_posts rp ON rp.user_id = $1 AND date(rp.read_at) = days.day
+            GROUP BY days.day
+        )
+    ), '') || '}' AS reads_per_day,
+    COALESCE((SELECT name FROM feed_reads WHERE place = 1), '') AS most_read_feed,
+    COALESCE((SELECT read_count FROM feed_reads WHERE place = 1), 0) AS most_read_feed_posts,
+    COALESCE((SELECT AVG(NULLIF(word_count(regexp_replace(COALESCE(description, ''), '<[^>]*>', ' ')), 0))
+        FROM reads), 0.0) AS avg_words,
+    (SELECT COUNT(*) FROM bookmarks b
+        WHERE b.user_id = $1
+          AND b.created_at >= $2::timestamp
+          AND b.created_at < $3::timestamp) AS posts_bookmarked,
+    (SELECT COUNT(*) FROM pinned_posts pp
+        WHERE pp.user_id = $1
+          AND pp.pinned_at >= $2::timestamp
+          AND pp.pinned_at < $3::timestamp) AS posts_pinned`,
+
+	"GetUserStats": `
+WITH followed AS (
+    SELECT feed_id FROM feed_follows WHERE user_id = $1
+),
+user_posts AS (
+    SELECT p.id, p.feed_id, p.posted_at
+    FROM posts p
+    JOIN followed f ON p.feed_id = f.feed_id
+    WHERE p.posted_at >= $2::timestamp
+),
+most_active AS (
+    SELECT feeds.name, COUNT(*) AS post_count
+    FROM user_posts
+    JOIN feeds ON feeds.id = user_posts.feed_id
+    GROUP BY feeds.id, feeds.name
+    ORDER BY post_count DESC, feeds.name
+    LIMIT 1
+),
+oldest AS (
+    SELECT MIN(posted_at), posted_at FROM user_posts
+),
+newest AS (
+    SELECT MAX(posted_at), posted_at FROM user_posts
+)
+SELECT
+    (SELECT COUNT(*) FROM followed) AS feeds_followed,
+    (SELECT COUNT(*) FROM user_posts) AS total_posts,
+    (SELECT COUNT(*) FROM read_posts rp JOIN user_posts up ON rp.post_id = up.id
+        WHERE rp.user_id = $1) AS read_posts,
+    (SELECT COUNT(*) FROM bookmarks b JOIN user_posts up ON b.post_id = up.id
+        WHERE b.user_id = $1) AS bookmarked_posts,
+    oldest.posted_at AS oldest_post,
+    newest.posted_at AS newest_post,
+    COALESCE((SELECT name FROM most_active), '') AS most_active_feed,
+    COALESCE((SELECT post_count FROM most_active), 0) AS most_active_feed_posts
+FROM oldest, newest`,
+
+	// Full-text search falls back to websearch_match, with titles that contain the query
+	// ranking above other matches. The REGEXP function can't take NULL descriptions.
+	"SearchPosts": `
+WITH matches AS (
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+       (CASE
+           WHEN p.title ILIKE '%' || $2::text || '%' THEN 1
+           WHEN $1::text = 'fulltext' AND websearch_match(p.title, $2::text) THEN 1
+           ELSE 0.5
+       END) AS rank
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $3
+  AND (
+    ($1::text = 'fulltext' AND
+        websearch_match(p.title || ' ' || COALESCE(p.description, '') || ' ' || p.url, $2::text))
+    OR p.author ILIKE '%' || $2::text || '%'
+    OR
+    ($1::text = 'like' AND (
+        p.title ILIKE '%' || $2::text || '%'
+        OR p.description ILIKE '%' || $2::text || '%'
+        OR p.url ILIKE '%' || $2::text || '%'
+        OR p.normalized_url = $2::text
+    ))
+    OR
+    ($1::text = 'word' AND (
+        p.title ~* $4::text
+        OR COALESCE(p.description, '') ~* $4::text
+    ))
+  )
+  AND ($5::uuid IS NULL OR p.feed_id = $5::uuid)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, rank
+FROM matches
+ORDER BY
+    CASE WHEN $6::text = 'title' THEN lower(title) END,
+    CASE WHEN $6::text = 'relevance' THEN rank END DESC,
+    COALESCE(published_at, created_at) DESC
+LIMIT NULLIF($7::int, 0) OFFSET $8`,
+
+	// UPDATE takes an alias only after AS in SQLite
+	"ReorderQueueItem": `
+WITH moved AS (
+    SELECT position AS old_position FROM read_queue
+    WHERE user_id = $1 AND post_id = $2
+)
+UPDATE read_queue AS q
+SET position = CASE
+    WHEN q.post_id = $2 THEN $3::int
+    WHEN moved.old_position < $3::int THEN q.position - 1
+    ELSE q.position + 1
+END
+FROM moved
+WHERE q.user_id = $1
+  AND q.position BETWEEN LEAST(moved.old_position, $3::int)
+                     AND GREATEST(moved.old_position, $3::int)`,
+
+	// The upsert's SELECT needs a WHERE clause for SQLite to parse ON CONFLICT
+	"UpdateAdaptiveInterval": `
+INSERT INTO feed_adaptive_intervals (feed_id, interval_secs, updated_at)
+SELECT $1::uuid,
+       CAST(ROUND(LEAST(86400, GREATEST(300,
+           (julianday(MAX(recent.published)) - julianday(MIN(recent.published))) * 86400 / (COUNT(*) - 1) / 2
+       ))) AS INTEGER),
+       NOW()
+FROM (
+    SELECT posted_at AS published
+    FROM posts
+    WHERE feed_id = $1::uuid
+    ORDER BY posted_at DESC
+    LIMIT 20
+) recent
+WHERE true
+HAVING COUNT(*) >= 2
+ON CONFLICT (feed_id) DO UPDATE
+SET interval_secs = EXCLUDED.interval_secs, updated_at = EXCLUDED.updated_at`,
+}
@@ -0,0 +1,66 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	_ "embed"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//go:embed schema.sql
+var schema string
+
+// createTable matches the table each CREATE TABLE statement in the schema creates
+var createTable = regexp.MustCompile(`(?i)\bCREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+
+// Migrate creates whatever the schema has that db is missing. SQLite databases don't
+// track migrations; the schema is applied whole each time.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, schema); err != nil {
+		return fmt.Errorf("couldn't apply SQLite schema: %w", err)
+	}
+	return nil
+}
+
+// Tables lists the tables the schema creates, sorted
+func Tables() []string {
+	var tables []string
+	for _, match := range createTable.FindAllStringSubmatch(schema, -1) {
+		tables = append(tables, strings.ToLower(match[1]))
+	}
+	sort.Strings(tables)
+	return tables
+}
+
+// MissingTables returns the tables the schema creates that db doesn't have, sorted
+func MissingTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	rows, err := db.QueryContext(ctx, "SELECT name FROM sqlite_master WHERE type = 'table'")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, table := range Tables() {
+		if !existing[table] {
+			missing = append(missing, table)
+		}
+	}
+	return missing, nil
+}
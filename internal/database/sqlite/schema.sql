@@ -0,0 +1,209 @@
+-- The whole gator schema for SQLite, matching the PostgreSQL migrations. Statements are
+-- idempotent, so applying it again only adds what is missing.
+--
+-- Timestamps are stored as text in UTC, the layout go-sqlite3 writes; NOW is the same
+-- layout for column defaults. UUIDs are stored as text.
+
+CREATE TABLE IF NOT EXISTS users (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT UNIQUE NOT NULL,
+    preferred_language TEXT
+);
+
+CREATE TABLE IF NOT EXISTS feeds (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    name TEXT NOT NULL,
+    url TEXT NOT NULL UNIQUE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_fetched_at TIMESTAMP NULL,
+    last_etag TEXT NULL,
+    last_modified TEXT NULL,
+    interval_secs INTEGER NULL,
+    consecutive_errors INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NULL,
+    last_attempted_at TIMESTAMP NULL,
+    fetch_timeout_secs INTEGER NULL,
+    user_agent TEXT NULL
+);
+
+CREATE TABLE IF NOT EXISTS feed_follows (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    UNIQUE(user_id, feed_id)
+);
+
+-- posted_at is when a post counts as published. Queries select it where PostgreSQL
+-- computes COALESCE(published_at, created_at), since only a real column comes back
+-- from SQLite typed as a timestamp.
+CREATE TABLE IF NOT EXISTS posts (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP NOT NULL,
+    title TEXT NOT NULL,
+    url TEXT NOT NULL,
+    description TEXT NULL,
+    published_at TIMESTAMP NULL,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    normalized_url TEXT NOT NULL,
+    author TEXT,
+    language TEXT,
+    redirect_from TEXT,
+    posted_at TIMESTAMP GENERATED ALWAYS AS (COALESCE(published_at, created_at)) VIRTUAL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS posts_normalized_url_key ON posts (normalized_url);
+CREATE INDEX IF NOT EXISTS posts_url_idx ON posts (url);
+CREATE INDEX IF NOT EXISTS posts_redirect_from_idx ON posts (redirect_from) WHERE redirect_from IS NOT NULL;
+
+CREATE TABLE IF NOT EXISTS bookmarks (
+    id UUID PRIMARY KEY DEFAULT (lower(hex(randomblob(4)) || '-' || hex(randomblob(2)) || '-4' ||
+        substr(hex(randomblob(2)), 2) || '-' || substr('89ab', 1 + abs(random()) % 4, 1) ||
+        substr(hex(randomblob(2)), 2) || '-' || hex(randomblob(6)))),
+    created_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    updated_at TIMESTAMP DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    UNIQUE (user_id, post_id)
+);
+
+CREATE TABLE IF NOT EXISTS read_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    read_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE TABLE IF NOT EXISTS feed_tags (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS feed_tags_tag_idx ON feed_tags (tag);
+
+CREATE TABLE IF NOT EXISTS keyword_filters (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    filter_type TEXT NOT NULL CHECK (filter_type IN ('include', 'exclude')),
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any'))
+);
+
+CREATE TABLE IF NOT EXISTS agg_runs (
+    id UUID PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    feeds_fetched INTEGER NOT NULL,
+    feeds_failed INTEGER NOT NULL,
+    posts_saved INTEGER NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    key_hash TEXT NOT NULL UNIQUE,
+    key_prefix TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP NULL
+);
+
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    feed_filter_id UUID NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    keyword_filter TEXT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS notifications (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (feed_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS post_enclosures (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    media_type TEXT NOT NULL DEFAULT '',
+    length BIGINT NULL,
+    episode_number INT NULL,
+    season_number INT NULL,
+    duration_secs INT NULL
+);
+
+CREATE TABLE IF NOT EXISTS podcast_feeds (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    author TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    explicit BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS pinned_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    pinned_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    note TEXT,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE TABLE IF NOT EXISTS read_queue (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    added_at TIMESTAMP NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'reading', 'done')),
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE TABLE IF NOT EXISTS feed_groups (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE IF NOT EXISTS feed_group_members (
+    group_id UUID NOT NULL REFERENCES feed_groups(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, feed_id)
+);
+
+CREATE TABLE IF NOT EXISTS feed_adaptive_intervals (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    interval_secs INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS tag_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    case_sensitive BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE IF NOT EXISTS post_tags (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (post_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS post_tags_tag_idx ON post_tags (tag);
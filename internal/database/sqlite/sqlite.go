@@ -0,0 +1,100 @@
+//go:build sqlite
+
+// Package sqlite stores gator's data in a SQLite file instead of PostgreSQL. It runs the
+// queries sqlc generates for PostgreSQL, translating each one for SQLite on the way to
+// the driver, so both backends share database.Querier and its row types. It needs cgo
+// and is only built with the sqlite build tag.
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+// driverName is go-sqlite3 registered with the functions the translated queries call
+const driverName = "gator_sqlite3"
+
+func init() {
+	sql.Register(driverName, &sqlite3.SQLiteDriver{ConnectHook: connect})
+}
+
+// connect turns on foreign keys, which SQLite leaves off unless asked, and adds the
+// functions PostgreSQL has built in
+func connect(c *sqlite3.SQLiteConn) error {
+	if _, err := c.Exec("PRAGMA foreign_keys = ON", nil); err != nil {
+		return err
+	}
+	for name, impl := range functions {
+		if err := c.RegisterFunc(name, impl, true); err != nil {
+			return fmt.Errorf("couldn't register %s: %w", name, err)
+		}
+	}
+	for name, impl := range volatileFunctions {
+		if err := c.RegisterFunc(name, impl, false); err != nil {
+			return fmt.Errorf("couldn't register %s: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// Open opens the SQLite database at path, creating the file if needed, and brings its
+// schema up to date. WAL mode and a busy timeout let agg's workers write side by side.
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open(driverName, "file:"+path+"?_busy_timeout=5000&_journal_mode=WAL")
+	if err != nil {
+		return nil, err
+	}
+	if err := Migrate(context.Background(), db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Queries is database.Queries running on SQLite
+type Queries struct {
+	*database.Queries
+	db database.DBTX
+}
+
+var _ database.Store = (*Queries)(nil)
+
+// New returns the queries for a SQLite database or transaction
+func New(db database.DBTX) *Queries {
+	translated := conn{db: db}
+	return &Queries{Queries: database.New(translated), db: translated}
+}
+
+// InTx returns q's queries run inside tx
+func (q *Queries) InTx(tx *sql.Tx) database.Querier {
+	return New(tx)
+}
+
+// UseAPIKey replaces the generated UseAPIKey, which updates the key and returns its user
+// in one statement: SQLite's RETURNING can only name columns of the updated table, so
+// the user is looked up afterwards
+func (q *Queries) UseAPIKey(ctx context.Context, keyHash string) (database.User, error) {
+	var userID uuid.UUID
+	err := q.db.QueryRowContext(ctx,
+		"UPDATE api_keys SET last_used_at = NOW() WHERE key_hash = $1 RETURNING user_id", keyHash).Scan(&userID)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	var user database.User
+	err = q.db.QueryRowContext(ctx,
+		"SELECT id, created_at, updated_at, name, preferred_language FROM users WHERE id = $1", userID).Scan(
+		&user.ID,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.Name,
+		&user.PreferredLanguage,
+	)
+	return user, err
+}
@@ -0,0 +1,508 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"gator/internal/database"
+	"gator/internal/migrations"
+
+	"github.com/google/uuid"
+	"github.com/mattn/go-sqlite3"
+)
+
+func openTest(t *testing.T) (*sql.DB, *Queries) {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "gator.db"))
+	if err != nil {
+		t.Fatalf("couldn't open database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db, New(db)
+}
+
+// fixture is a user following one feed with two posts, one of them a podcast episode
+type fixture struct {
+	user          database.User
+	feed          database.CreateFeedRow
+	older, newer  uuid.UUID
+	olderPostedAt time.Time
+	newerPostedAt time.Time
+}
+
+func seed(t *testing.T, q *Queries) fixture {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Now()
+	var f fixture
+	var err error
+
+	f.user, err = q.CreateUser(ctx, database.CreateUserParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "alice"})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	f.feed, err = q.CreateFeed(ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "Go Blog", Url: "https://go.dev/blog/feed.atom", UserID: f.user.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	follow, err := q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: f.user.ID, FeedID: f.feed.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeedFollow: %v", err)
+	}
+	if follow.FeedName != "Go Blog" || follow.UserName != "alice" {
+		t.Errorf("CreateFeedFollow returned names %q and %q", follow.FeedName, follow.UserName)
+	}
+
+	f.older, f.newer = uuid.New(), uuid.New()
+	f.olderPostedAt = now.Add(-48 * time.Hour).Truncate(time.Second)
+	f.newerPostedAt = now.Add(-2 * time.Hour).Truncate(time.Second)
+	posts := []database.CreatePostParams{
+		{ID: f.older, Title: "Generic methods", Url: "https://go.dev/blog/generics", Description: sql.NullString{String: "<p>Type parameters in depth</p>", Valid: true}, PublishedAt: sql.NullTime{Time: f.olderPostedAt, Valid: true}},
+		{ID: f.newer, Title: "Episode 12: Range over func", Url: "https://go.dev/blog/range", Author: sql.NullString{String: "Russ Cox", Valid: true}, PublishedAt: sql.NullTime{Time: f.newerPostedAt, Valid: true}},
+	}
+	for _, post := range posts {
+		post.CreatedAt, post.UpdatedAt, post.FeedID, post.NormalizedUrl = now, now, f.feed.ID, post.Url
+		if _, err := q.CreatePost(ctx, post); err != nil {
+			t.Fatalf("CreatePost: %v", err)
+		}
+	}
+	if err := q.CreatePostEnclosure(ctx, database.CreatePostEnclosureParams{PostID: f.newer, Url: "https://go.dev/ep12.mp3", MediaType: "audio/mpeg"}); err != nil {
+		t.Fatalf("CreatePostEnclosure: %v", err)
+	}
+	return f
+}
+
+// TestEveryQuery runs each query with zero-valued arguments against a seeded database,
+// so any that SQLite can't parse or whose results don't scan fail here
+func TestEveryQuery(t *testing.T) {
+	_, q := openTest(t)
+	seed(t, q)
+
+	ctx := reflect.ValueOf(context.Background())
+	querier := reflect.TypeOf((*database.Querier)(nil)).Elem()
+	value := reflect.ValueOf(database.Querier(q))
+	for i := range querier.NumMethod() {
+		method := querier.Method(i)
+		args := []reflect.Value{ctx}
+		for j := 1; j < method.Type.NumIn(); j++ {
+			args = append(args, reflect.Zero(method.Type.In(j)))
+		}
+		results := value.MethodByName(method.Name).Call(args)
+		err, _ := results[len(results)-1].Interface().(error)
+		var sqliteErr sqlite3.Error
+		if err == nil || errors.Is(err, sql.ErrNoRows) || errors.As(err, &sqliteErr) && sqliteErr.Code == sqlite3.ErrConstraint {
+			continue
+		}
+		t.Errorf("%s: %v", method.Name, err)
+	}
+}
+
+func TestQueries(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+
+	stats, err := q.GetUserStats(ctx, database.GetUserStatsParams{UserID: f.user.ID, Since: time.Now().Add(-7 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("GetUserStats: %v", err)
+	}
+	oldest, ok := stats.OldestPost.(time.Time)
+	if !ok || !oldest.Equal(f.olderPostedAt) {
+		t.Errorf("expected the oldest post at %v, got %#v", f.olderPostedAt, stats.OldestPost)
+	}
+	if newest, ok := stats.NewestPost.(time.Time); !ok || !newest.Equal(f.newerPostedAt) {
+		t.Errorf("expected the newest post at %v, got %#v", f.newerPostedAt, stats.NewestPost)
+	}
+	if stats.TotalPosts != 2 || stats.MostActiveFeed != "Go Blog" {
+		t.Errorf("unexpected stats %+v", stats)
+	}
+
+	podcasts, err := q.GetPodcastFeedsForUser(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("GetPodcastFeedsForUser: %v", err)
+	}
+	if len(podcasts) != 1 || podcasts[0].Episodes != 1 || !podcasts[0].Latest.Equal(f.newerPostedAt) {
+		t.Errorf("unexpected podcast feeds %+v", podcasts)
+	}
+
+	frequency, err := q.GetFeedFrequencyStats(ctx, database.GetFeedFrequencyStatsParams{UserID: f.user.ID, Since: time.Now().Add(-7 * 24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("GetFeedFrequencyStats: %v", err)
+	}
+	if len(frequency) != 1 || frequency[0].PostCount != 2 || !frequency[0].PeakHour.Valid {
+		t.Errorf("unexpected frequency stats %+v", frequency)
+	}
+	if newest, ok := frequency[0].NewestPost.(time.Time); !ok || !newest.Equal(f.newerPostedAt) {
+		t.Errorf("expected the feed's newest post at %v, got %#v", f.newerPostedAt, frequency[0].NewestPost)
+	}
+
+	if err := q.PinPost(ctx, database.PinPostParams{UserID: f.user.ID, PostID: f.newer}); err != nil {
+		t.Fatalf("PinPost: %v", err)
+	}
+	pinned, err := q.GetPinnedPostIDs(ctx, database.GetPinnedPostIDsParams{UserID: f.user.ID, PostIds: []uuid.UUID{f.older, f.newer}})
+	if err != nil {
+		t.Fatalf("GetPinnedPostIDs: %v", err)
+	}
+	if !slices.Equal(pinned, []uuid.UUID{f.newer}) {
+		t.Errorf("expected only the newer post pinned, got %v", pinned)
+	}
+
+	until := time.Now()
+	if _, err := q.MarkPostRead(ctx, database.MarkPostReadParams{UserID: f.user.ID, PostID: f.older, ReadAt: until.Add(-time.Minute)}); err != nil {
+		t.Fatalf("MarkPostRead: %v", err)
+	}
+	reading, err := q.GetReadingStats(ctx, database.GetReadingStatsParams{UserID: f.user.ID, Since: until.Add(-72 * time.Hour), Until: until, Days: 3})
+	if err != nil {
+		t.Fatalf("GetReadingStats: %v", err)
+	}
+	if reading.PostsRead != 1 || len(reading.ReadsPerDay) != 3 || reading.AvgWords != 4 || reading.PostsPinned != 1 {
+		t.Errorf("unexpected reading stats %+v", reading)
+	}
+
+	for _, search := range []database.SearchPostsParams{
+		{SearchMode: "fulltext", Query: "type parameters"},
+		{SearchMode: "like", Query: "GENERIC"},
+		{SearchMode: "word", Query: "generic", WordPattern: `(^|\W)generic(\W|$)`},
+	} {
+		search.UserID = f.user.ID
+		found, err := q.SearchPosts(ctx, search)
+		if err != nil {
+			t.Fatalf("SearchPosts(%s): %v", search.SearchMode, err)
+		}
+		if len(found) != 1 || found[0].ID != f.older {
+			t.Errorf("%s search for %q found %+v", search.SearchMode, search.Query, found)
+		}
+	}
+
+	for _, post := range []uuid.UUID{f.older, f.newer} {
+		if _, err := q.AddToQueue(ctx, database.AddToQueueParams{UserID: f.user.ID, PostID: post}); err != nil {
+			t.Fatalf("AddToQueue: %v", err)
+		}
+	}
+	if _, err := q.ReorderQueueItem(ctx, database.ReorderQueueItemParams{UserID: f.user.ID, PostID: f.newer, NewPosition: 1}); err != nil {
+		t.Fatalf("ReorderQueueItem: %v", err)
+	}
+	queue, err := q.GetQueueForUser(ctx, f.user.ID)
+	if err != nil {
+		t.Fatalf("GetQueueForUser: %v", err)
+	}
+	if len(queue) != 2 || queue[0].ID != f.newer || queue[1].Position != 2 {
+		t.Errorf("expected the newer post moved to the front, got %+v", queue)
+	}
+
+	page, err := q.GetPostsForUser(ctx, database.GetPostsForUserParams{UserID: f.user.ID, Limit: 1, Offset: 1})
+	if err != nil {
+		t.Fatalf("GetPostsForUser: %v", err)
+	}
+	if len(page) != 1 || page[0].ID != f.older || !page[0].IsRead {
+		t.Errorf("expected the second page to hold the older, read post, got %+v", page)
+	}
+
+	unread, err := q.GetUnreadPostsForUser(ctx, database.GetUnreadPostsForUserParams{UserID: f.user.ID, Limit: 10})
+	if err != nil {
+		t.Fatalf("GetUnreadPostsForUser: %v", err)
+	}
+	if len(unread) != 1 || unread[0].ID != f.newer {
+		t.Errorf("expected only the newer post unread, got %+v", unread)
+	}
+}
+
+func TestClaimFeedForFetch(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+
+	claim := func() int64 {
+		t.Helper()
+		claimed, err := q.ClaimFeedForFetch(ctx, database.ClaimFeedForFetchParams{ID: f.feed.ID, ClaimedBefore: time.Now().Add(-time.Minute)})
+		if err != nil {
+			t.Fatalf("ClaimFeedForFetch: %v", err)
+		}
+		return claimed
+	}
+	if claim() != 1 {
+		t.Fatal("expected the unclaimed feed to be claimed")
+	}
+	if claim() != 0 {
+		t.Error("expected a second run to skip the claimed feed")
+	}
+	if err := q.MarkFeedFetchedOK(ctx, f.feed.ID); err != nil {
+		t.Fatalf("MarkFeedFetchedOK: %v", err)
+	}
+	if claim() != 1 {
+		t.Error("expected a fetched feed to be claimable again")
+	}
+}
+
+func TestFeedsDueForFetch(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+
+	due, err := q.GetFeedsDueForFetch(ctx, false)
+	if err != nil {
+		t.Fatalf("GetFeedsDueForFetch: %v", err)
+	}
+	if len(due) != 1 {
+		t.Fatalf("expected the never-fetched feed to be due, got %d feeds", len(due))
+	}
+
+	if err := q.MarkFeedFetchedOK(ctx, f.feed.ID); err != nil {
+		t.Fatalf("MarkFeedFetchedOK: %v", err)
+	}
+	if err := q.SetFeedInterval(ctx, database.SetFeedIntervalParams{ID: f.feed.ID, IntervalSecs: sql.NullInt32{Int32: 3600, Valid: true}}); err != nil {
+		t.Fatalf("SetFeedInterval: %v", err)
+	}
+	if _, err := q.GetNextFeedToFetch(ctx, false); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected no feed due within its hour, got %v", err)
+	}
+
+	if err := q.UpdateAdaptiveInterval(ctx, f.feed.ID); err != nil {
+		t.Fatalf("UpdateAdaptiveInterval: %v", err)
+	}
+	feeds, err := q.GetFeedsVerbose(ctx, database.GetFeedsVerboseParams{Filter: "%"})
+	if err != nil {
+		t.Fatalf("GetFeedsVerbose: %v", err)
+	}
+	// Two posts 46 hours apart give half the gap
+	if len(feeds) != 1 || feeds[0].AdaptiveIntervalSecs.Int32 != 23*3600 || feeds[0].PostCount != 2 {
+		t.Errorf("unexpected feeds %+v", feeds)
+	}
+}
+
+func TestBulkFollows(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+	now := time.Now()
+
+	other, err := q.CreateFeed(ctx, database.CreateFeedParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Name: "Hacker News", Url: "https://hnrss.org/newest", UserID: f.user.ID,
+	})
+	if err != nil {
+		t.Fatalf("CreateFeed: %v", err)
+	}
+	if _, err := q.CreateFeedFollow(ctx, database.CreateFeedFollowParams{ID: uuid.New(), CreatedAt: now, UpdatedAt: now, UserID: f.user.ID, FeedID: other.ID}); err != nil {
+		t.Fatalf("CreateFeedFollow: %v", err)
+	}
+	if _, err := q.AddTagToFeed(ctx, database.AddTagToFeedParams{FeedID: other.ID, Tag: "news", CreatedAt: now}); err != nil {
+		t.Fatalf("AddTagToFeed: %v", err)
+	}
+	group, err := q.CreateFeedGroup(ctx, database.CreateFeedGroupParams{ID: uuid.New(), UserID: f.user.ID, Name: "Tech", CreatedAt: now})
+	if err != nil {
+		t.Fatalf("CreateFeedGroup: %v", err)
+	}
+	if _, err := q.AddFeedToGroup(ctx, database.AddFeedToGroupParams{GroupID: group.ID, FeedID: f.feed.ID}); err != nil {
+		t.Fatalf("AddFeedToGroup: %v", err)
+	}
+
+	byTag := database.GetFollowedFeedsMatchingParams{UserID: f.user.ID, Tag: sql.NullString{String: "news", Valid: true}}
+	feeds, err := q.GetFollowedFeedsMatching(ctx, byTag)
+	if err != nil {
+		t.Fatalf("GetFollowedFeedsMatching: %v", err)
+	}
+	if len(feeds) != 1 || feeds[0].ID != other.ID {
+		t.Fatalf("expected only the tagged feed, got %+v", feeds)
+	}
+	byGroup := database.GetFollowedFeedsMatchingParams{UserID: f.user.ID, GroupID: uuid.NullUUID{UUID: group.ID, Valid: true}}
+	if feeds, err := q.GetFollowedFeedsMatching(ctx, byGroup); err != nil || len(feeds) != 1 || feeds[0].ID != f.feed.ID {
+		t.Fatalf("expected only the grouped feed, got %+v (%v)", feeds, err)
+	}
+
+	if feeds, err := q.GetUnfollowedFeeds(ctx, database.GetUnfollowedFeedsParams{UserID: f.user.ID}); err != nil || len(feeds) != 0 {
+		t.Fatalf("expected every feed to be followed, got %+v (%v)", feeds, err)
+	}
+
+	deleted, err := q.DeleteFeedFollowsMatching(ctx, database.DeleteFeedFollowsMatchingParams(byTag))
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected to unfollow the tagged feed, got %d (%v)", deleted, err)
+	}
+	deleted, err = q.DeleteFeedFollowsMatching(ctx, database.DeleteFeedFollowsMatchingParams{UserID: f.user.ID})
+	if err != nil || deleted != 1 {
+		t.Fatalf("expected to unfollow the remaining feed, got %d (%v)", deleted, err)
+	}
+
+	followed, err := q.FollowAllFeeds(ctx, database.FollowAllFeedsParams{Now: now, UserID: f.user.ID, Tag: byTag.Tag})
+	if err != nil || followed != 1 {
+		t.Fatalf("expected to follow the tagged feed again, got %d (%v)", followed, err)
+	}
+	followed, err = q.FollowAllFeeds(ctx, database.FollowAllFeedsParams{Now: now, UserID: f.user.ID})
+	if err != nil || followed != 1 {
+		t.Fatalf("expected to follow only the feed not followed yet, got %d (%v)", followed, err)
+	}
+}
+
+func TestPostRedirects(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+	now := time.Now()
+
+	if _, err := q.CreatePost(ctx, database.CreatePostParams{
+		ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Title: "Tracked", Url: "https://go.dev/blog/tracked",
+		FeedID: f.feed.ID, NormalizedUrl: "https://go.dev/blog/tracked",
+		RedirectFrom: sql.NullString{String: "https://bit.ly/abc", Valid: true},
+	}); err != nil {
+		t.Fatalf("CreatePost: %v", err)
+	}
+	redirects, err := q.GetPostRedirects(ctx, []string{"https://bit.ly/abc", "https://bit.ly/other"})
+	if err != nil {
+		t.Fatalf("GetPostRedirects: %v", err)
+	}
+	if len(redirects) != 1 || redirects[0].Url != "https://go.dev/blog/tracked" {
+		t.Errorf("unexpected redirects %+v", redirects)
+	}
+	stored, err := q.GetStoredNormalizedURLs(ctx, []string{"https://go.dev/blog/generics", "https://go.dev/blog/new"})
+	if err != nil {
+		t.Fatalf("GetStoredNormalizedURLs: %v", err)
+	}
+	if len(stored) != 1 || stored[0] != "https://go.dev/blog/generics" {
+		t.Errorf("unexpected stored URLs %v", stored)
+	}
+}
+
+// TestConcurrentCreatePost inserts the same post from several goroutines at once, as agg
+// workers do when two feeds carry one article; exactly one insert wins and the rest
+// are skipped without an error
+func TestConcurrentCreatePost(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+
+	const writers = 16
+	inserted := make([]int64, writers)
+	errs := make([]error, writers)
+	var wg sync.WaitGroup
+	for i := range writers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			now := time.Now()
+			inserted[i], errs[i] = q.CreatePost(ctx, database.CreatePostParams{
+				ID: uuid.New(), CreatedAt: now, UpdatedAt: now, Title: "Shared", Url: "https://go.dev/blog/shared",
+				FeedID: f.feed.ID, NormalizedUrl: "https://go.dev/blog/shared",
+			})
+		}()
+	}
+	wg.Wait()
+
+	var total int64
+	for i := range writers {
+		if errs[i] != nil {
+			t.Fatalf("CreatePost %d: %v", i, errs[i])
+		}
+		total += inserted[i]
+	}
+	if total != 1 {
+		t.Errorf("expected exactly one insert to win, got %d", total)
+	}
+	count, err := q.CountPostsForFeed(ctx, f.feed.ID)
+	if err != nil {
+		t.Fatalf("CountPostsForFeed: %v", err)
+	}
+	if count != 3 {
+		t.Errorf("expected the seeded posts plus one shared post, got %d", count)
+	}
+}
+
+func TestMarkAllRead(t *testing.T) {
+	_, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+
+	if _, err := q.MarkPostRead(ctx, database.MarkPostReadParams{UserID: f.user.ID, PostID: f.older, ReadAt: time.Now()}); err != nil {
+		t.Fatalf("MarkPostRead: %v", err)
+	}
+	marked, err := q.MarkAllPostsReadForFeed(ctx, database.MarkAllPostsReadForFeedParams{UserID: f.user.ID, FeedID: f.feed.ID, ReadAt: time.Now()})
+	if err != nil {
+		t.Fatalf("MarkAllPostsReadForFeed: %v", err)
+	}
+	if marked != 1 {
+		t.Errorf("expected only the unread post marked, got %d", marked)
+	}
+	read, err := q.IsPostRead(ctx, database.IsPostReadParams{UserID: f.user.ID, PostID: f.newer})
+	if err != nil || !read {
+		t.Errorf("expected the newer post read, got %v, %v", read, err)
+	}
+
+	if _, err := q.DeleteReadPostsForUser(ctx, f.user.ID); err != nil {
+		t.Fatalf("DeleteReadPostsForUser: %v", err)
+	}
+	marked, err = q.MarkAllPostsReadForUser(ctx, database.MarkAllPostsReadForUserParams{UserID: f.user.ID, ReadAt: time.Now()})
+	if err != nil {
+		t.Fatalf("MarkAllPostsReadForUser: %v", err)
+	}
+	if marked != 2 {
+		t.Errorf("expected both followed posts marked, got %d", marked)
+	}
+}
+
+func TestTransactionsAndAPIKeys(t *testing.T) {
+	db, q := openTest(t)
+	f := seed(t, q)
+	ctx := context.Background()
+
+	if _, err := q.CreateAPIKey(ctx, database.CreateAPIKeyParams{ID: uuid.New(), UserID: f.user.ID, KeyHash: "hash", KeyPrefix: "gk_", CreatedAt: time.Now()}); err != nil {
+		t.Fatalf("CreateAPIKey: %v", err)
+	}
+	user, err := q.UseAPIKey(ctx, "hash")
+	if err != nil {
+		t.Fatalf("UseAPIKey: %v", err)
+	}
+	if user.ID != f.user.ID || user.Name != "alice" {
+		t.Errorf("UseAPIKey returned %+v", user)
+	}
+	if _, err := q.UseAPIKey(ctx, "unknown"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected no user for an unknown key, got %v", err)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	deleted, err := q.InTx(tx).DeleteOldPostsForUser(ctx, database.DeleteOldPostsForUserParams{UserID: f.user.ID, Cutoff: time.Now().Add(-24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("DeleteOldPostsForUser: %v", err)
+	}
+	if len(deleted) != 1 || deleted[0].ID != f.older {
+		t.Errorf("expected the older post deleted, got %+v", deleted)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+	if count, err := q.CountPostsForFeed(ctx, f.feed.ID); err != nil || count != 2 {
+		t.Errorf("expected the rollback to keep both posts, got %d (%v)", count, err)
+	}
+}
+
+func TestMissingTables(t *testing.T) {
+	db, _ := openTest(t)
+	missing, err := MissingTables(context.Background(), db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("expected a complete schema, missing %v", missing)
+	}
+	// dbswitch copies the tables the PostgreSQL migrations create
+	want, err := migrations.Tables()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(Tables(), want) {
+		t.Errorf("expected the schema to create %v, got %v", want, Tables())
+	}
+}
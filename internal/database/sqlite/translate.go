@@ -0,0 +1,118 @@
+//go:build sqlite
+
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"gator/internal/database"
+
+	"github.com/lib/pq"
+)
+
+// now is NOW() in the layout go-sqlite3 stores timestamps in, so the two compare as text
+const now = `strftime('%Y-%m-%d %H:%M:%f+00:00', 'now')`
+
+var (
+	queryName   = regexp.MustCompile(`^-- name: (\w+)`)
+	placeholder = regexp.MustCompile(`\$(\d+)`)
+	typeCast    = regexp.MustCompile(`::(bool|text|int|uuid|timestamp|bigint|real|float8|date)\b(\[\])?`)
+	anyArray    = regexp.MustCompile(`= ANY\((\?\d+)\)`)
+	noLimit     = regexp.MustCompile(`LIMIT NULLIF\((\?\d+), 0\)`)
+)
+
+// translated caches each query's SQLite version, keyed by the PostgreSQL text
+var translated sync.Map
+
+// translate returns the SQLite version of a PostgreSQL query: the hand-written one from
+// overrides when the query has one, and otherwise the query itself with PostgreSQL's
+// syntax swapped for SQLite's
+func translate(query string) string {
+	if cached, ok := translated.Load(query); ok {
+		return cached.(string)
+	}
+
+	sqlite := query
+	if match := queryName.FindStringSubmatch(query); match != nil {
+		if override, ok := overrides[match[1]]; ok {
+			sqlite = override
+		}
+	}
+	sqlite = placeholder.ReplaceAllString(sqlite, "?$1")
+	sqlite = typeCast.ReplaceAllString(sqlite, "")
+	sqlite = anyArray.ReplaceAllString(sqlite, "IN (SELECT value FROM json_each($1))")
+	// A NULL limit means no limit in PostgreSQL but is an error in SQLite, where -1 is
+	sqlite = noLimit.ReplaceAllString(sqlite, "LIMIT COALESCE(NULLIF($1, 0), -1)")
+	sqlite = strings.NewReplacer(
+		"NOW()", now,
+		" ILIKE ", " LIKE ", // SQLite's LIKE already ignores case
+		" ~* ", " REGEXP '(?i)' || ",
+		"LEAST(", "MIN(",
+		"GREATEST(", "MAX(",
+	).Replace(sqlite)
+
+	translated.Store(query, sqlite)
+	return sqlite
+}
+
+// bindArgs converts query arguments SQLite can't take as they are. Times go in as UTC so
+// that stored timestamps sort as text, and PostgreSQL arrays become the JSON arrays
+// json_each reads.
+func bindArgs(args []interface{}) []interface{} {
+	bound := make([]interface{}, len(args))
+	for i, arg := range args {
+		switch v := arg.(type) {
+		case time.Time:
+			bound[i] = v.UTC()
+		case sql.NullTime:
+			v.Time = v.Time.UTC()
+			bound[i] = v
+		case pq.GenericArray:
+			encoded, err := json.Marshal(v.A)
+			if err != nil {
+				// Leave it for the driver to reject
+				bound[i] = arg
+				continue
+			}
+			bound[i] = string(encoded)
+		case *pq.StringArray:
+			encoded, err := json.Marshal([]string(*v))
+			if err != nil {
+				bound[i] = arg
+				continue
+			}
+			bound[i] = string(encoded)
+		default:
+			bound[i] = arg
+		}
+	}
+	return bound
+}
+
+// conn runs the generated PostgreSQL queries against a SQLite connection, translating
+// each query and its arguments before passing it on
+type conn struct {
+	db database.DBTX
+}
+
+func (c conn) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.db.ExecContext(ctx, translate(query), bindArgs(args)...)
+}
+
+func (c conn) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return c.db.PrepareContext(ctx, translate(query))
+}
+
+func (c conn) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	return c.db.QueryContext(ctx, translate(query), bindArgs(args)...)
+}
+
+func (c conn) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return c.db.QueryRowContext(ctx, translate(query), bindArgs(args)...)
+}
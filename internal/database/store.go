@@ -0,0 +1,17 @@
+package database
+
+import "database/sql"
+
+// Store is what each database backend hands out: its queries, and the same queries
+// bound to a transaction
+type Store interface {
+	Querier
+	InTx(tx *sql.Tx) Querier
+}
+
+var _ Store = (*Queries)(nil)
+
+// InTx returns q's queries run inside tx
+func (q *Queries) InTx(tx *sql.Tx) Querier {
+	return q.WithTx(tx)
+}
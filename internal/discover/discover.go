@@ -0,0 +1,113 @@
+package discover
+
+import (
+	"context"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Client is the HTTP client used to fetch pages during discovery
+var Client = &http.Client{Timeout: 30 * time.Second}
+
+// maxPageSize caps how much of an HTML page is scanned for feed links
+const maxPageSize = 2 << 20
+
+var (
+	linkTagPattern   = regexp.MustCompile(`(?is)<link\b[^>]*>`)
+	attributePattern = regexp.MustCompile(`(?is)([a-z][a-z0-9:_-]*)\s*=\s*("[^"]*"|'[^']*'|[^\s"'>]+)`)
+)
+
+// feedTypes are the alternate link types that point at a feed
+var feedTypes = map[string]bool{
+	"application/rss+xml":   true,
+	"application/atom+xml":  true,
+	"application/feed+json": true,
+}
+
+// FindFeedURL returns the feed URLs for pageURL. If pageURL already serves a
+// feed it is returned as is; if it serves HTML, the page's alternate feed links
+// are returned in document order.
+func FindFeedURL(ctx context.Context, pageURL string) ([]string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "gator")
+
+	resp, err := Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't make request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if mediaType != "text/html" && mediaType != "application/xhtml+xml" {
+		return []string{pageURL}, nil
+	}
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxPageSize))
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read response body: %w", err)
+	}
+
+	links := FeedLinks(resp.Request.URL, string(body))
+	if len(links) == 0 {
+		return nil, fmt.Errorf("no RSS/Atom feed link found on %s", pageURL)
+	}
+	return links, nil
+}
+
+// FeedLinks extracts <link rel="alternate"> feed URLs from an HTML document,
+// resolving relative hrefs against base and dropping duplicates
+func FeedLinks(base *url.URL, document string) []string {
+	var links []string
+	seen := map[string]bool{}
+
+	for _, tag := range linkTagPattern.FindAllString(document, -1) {
+		attrs := map[string]string{}
+		for _, match := range attributePattern.FindAllStringSubmatch(tag, -1) {
+			value := strings.Trim(match[2], `"'`)
+			attrs[strings.ToLower(match[1])] = html.UnescapeString(value)
+		}
+
+		if !hasToken(attrs["rel"], "alternate") || !feedTypes[strings.ToLower(strings.TrimSpace(attrs["type"]))] {
+			continue
+		}
+		href := strings.TrimSpace(attrs["href"])
+		if href == "" {
+			continue
+		}
+
+		resolved, err := base.Parse(href)
+		if err != nil {
+			continue
+		}
+		link := resolved.String()
+		if !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
+// hasToken reports whether a space-separated attribute value contains token
+func hasToken(value, token string) bool {
+	for _, field := range strings.Fields(value) {
+		if strings.EqualFold(field, token) {
+			return true
+		}
+	}
+	return false
+}
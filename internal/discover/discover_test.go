@@ -0,0 +1,60 @@
+package discover
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestFeedLinks(t *testing.T) {
+	base, _ := url.Parse("https://example.com/blog/")
+	page := `<html><head>
+<link rel="stylesheet" href="/style.css">
+<link rel="alternate" type="application/rss+xml" title="RSS" href="feed.xml">
+<LINK REL='alternate home' TYPE='application/atom+xml' HREF='https://example.com/atom.xml?a=1&amp;b=2'>
+<link rel="alternate" type="application/rss+xml" href="/blog/feed.xml">
+<link rel="alternate" hreflang="de" href="/de/">
+</head><body></body></html>`
+
+	links := FeedLinks(base, page)
+	want := []string{"https://example.com/blog/feed.xml", "https://example.com/atom.xml?a=1&b=2"}
+	if strings.Join(links, " ") != strings.Join(want, " ") {
+		t.Fatalf("expected %v, got %v", want, links)
+	}
+}
+
+func TestFindFeedURL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/page", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write([]byte(`<link rel="alternate" type="application/rss+xml" href="/feed.xml">`))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte(`<html><head><title>No feeds</title></head></html>`))
+	})
+	mux.HandleFunc("/feed.xml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/rss+xml")
+		w.Write([]byte(`<rss></rss>`))
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	links, err := FindFeedURL(context.Background(), srv.URL+"/page")
+	if err != nil || len(links) != 1 || links[0] != srv.URL+"/feed.xml" {
+		t.Fatalf("expected discovered feed link, got %v, %v", links, err)
+	}
+
+	links, err = FindFeedURL(context.Background(), srv.URL+"/feed.xml")
+	if err != nil || len(links) != 1 || links[0] != srv.URL+"/feed.xml" {
+		t.Fatalf("expected feed URL to be returned as is, got %v, %v", links, err)
+	}
+
+	_, err = FindFeedURL(context.Background(), srv.URL+"/empty")
+	if err == nil || !strings.Contains(err.Error(), "no RSS/Atom feed link found on") {
+		t.Fatalf("expected no feed link error, got %v", err)
+	}
+}
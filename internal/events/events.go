@@ -0,0 +1,90 @@
+// Package events passes new-post notifications from the scraper to subscribers in
+// the same process, such as API WebSocket clients.
+package events
+
+import (
+	"sync"
+
+	"github.com/google/uuid"
+)
+
+// subscriberBuffer is how many events a slow subscriber may fall behind before
+// further events for it are dropped
+const subscriberBuffer = 64
+
+// PostEvent announces a newly saved post
+type PostEvent struct {
+	PostID   uuid.UUID `json:"post_id"`
+	Title    string    `json:"title"`
+	FeedName string    `json:"feed_name"`
+	URL      string    `json:"url"`
+
+	// Recipients are the users following the post's feed
+	Recipients []uuid.UUID `json:"-"`
+}
+
+// Bus fans events out to per-user subscriptions. The zero value is not usable;
+// a nil *Bus ignores everything, so publishers needn't check whether anyone listens.
+type Bus struct {
+	mu          sync.Mutex
+	subscribers map[uuid.UUID]map[chan PostEvent]struct{}
+}
+
+// NewBus returns an empty bus
+func NewBus() *Bus {
+	return &Bus{subscribers: make(map[uuid.UUID]map[chan PostEvent]struct{})}
+}
+
+// Subscribe returns a channel receiving events addressed to userID until Unsubscribe
+func (b *Bus) Subscribe(userID uuid.UUID) <-chan PostEvent {
+	ch := make(chan PostEvent, subscriberBuffer)
+	if b == nil {
+		return ch
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan PostEvent]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+	return ch
+}
+
+// Unsubscribe stops deliveries to a channel returned by Subscribe and closes it
+func (b *Bus) Unsubscribe(userID uuid.UUID, sub <-chan PostEvent) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for ch := range b.subscribers[userID] {
+		if ch == sub {
+			delete(b.subscribers[userID], ch)
+			close(ch)
+		}
+	}
+	if len(b.subscribers[userID]) == 0 {
+		delete(b.subscribers, userID)
+	}
+}
+
+// Publish delivers an event to every subscription of its recipients without
+// blocking; subscribers whose buffer is full miss the event
+func (b *Bus) Publish(event PostEvent) {
+	if b == nil {
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, userID := range event.Recipients {
+		for ch := range b.subscribers[userID] {
+			select {
+			case ch <- event:
+			default:
+			}
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestPublishReachesRecipientsOnly(t *testing.T) {
+	bus := NewBus()
+	alice, bob := uuid.New(), uuid.New()
+	aliceCh := bus.Subscribe(alice)
+	bobCh := bus.Subscribe(bob)
+
+	event := PostEvent{PostID: uuid.New(), Title: "Hello", Recipients: []uuid.UUID{alice}}
+	bus.Publish(event)
+
+	select {
+	case got := <-aliceCh:
+		if got.PostID != event.PostID {
+			t.Errorf("alice got %+v, want %+v", got, event)
+		}
+	default:
+		t.Error("alice didn't receive the event")
+	}
+	select {
+	case got := <-bobCh:
+		t.Errorf("bob received %+v, which wasn't addressed to bob", got)
+	default:
+	}
+}
+
+func TestUnsubscribeClosesChannel(t *testing.T) {
+	bus := NewBus()
+	user := uuid.New()
+	ch := bus.Subscribe(user)
+	bus.Unsubscribe(user, ch)
+
+	if _, open := <-ch; open {
+		t.Error("channel still open after Unsubscribe")
+	}
+	// Publishing afterwards must not panic on the closed channel
+	bus.Publish(PostEvent{Recipients: []uuid.UUID{user}})
+}
+
+func TestPublishDoesNotBlockOnSlowSubscribers(t *testing.T) {
+	bus := NewBus()
+	user := uuid.New()
+	bus.Subscribe(user)
+	for range subscriberBuffer * 2 {
+		bus.Publish(PostEvent{Recipients: []uuid.UUID{user}})
+	}
+}
+
+func TestNilBusIsANoOp(t *testing.T) {
+	var bus *Bus
+	user := uuid.New()
+	ch := bus.Subscribe(user)
+	bus.Publish(PostEvent{Recipients: []uuid.UUID{user}})
+	bus.Unsubscribe(user, ch)
+}
@@ -0,0 +1,140 @@
+// Package export writes posts as JSON or CSV for use outside gator.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportPost is a post together with the feed it came from
+type ExportPost struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description"`
+	PublishedAt *time.Time `json:"published_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	FeedURL     string     `json:"feed_url"`
+}
+
+// csvHeader names the CSV columns, in the order of ExportPost's fields
+var csvHeader = []string{"id", "title", "url", "description", "published_at", "created_at", "updated_at", "feed_id", "feed_name", "feed_url"}
+
+// Writer writes posts a batch at a time, so a large export needn't be held in memory.
+// Close finishes the document; nothing else may be written after it.
+type Writer interface {
+	Write(posts []ExportPost) error
+	Close() error
+}
+
+// WriteJSON writes posts as a JSON array of objects
+func WriteJSON(w io.Writer, posts []ExportPost) error {
+	return writeAll(NewJSONWriter(w), posts)
+}
+
+// WriteCSV writes posts as CSV with a header row
+func WriteCSV(w io.Writer, posts []ExportPost) error {
+	return writeAll(NewCSVWriter(w), posts)
+}
+
+func writeAll(w Writer, posts []ExportPost) error {
+	if err := w.Write(posts); err != nil {
+		return err
+	}
+	return w.Close()
+}
+
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+// NewJSONWriter returns a Writer producing a JSON array with one post per line
+func NewJSONWriter(w io.Writer) Writer {
+	return &jsonWriter{w: w}
+}
+
+func (j *jsonWriter) Write(posts []ExportPost) error {
+	for _, post := range posts {
+		data, err := json.Marshal(post)
+		if err != nil {
+			return err
+		}
+		sep := ",\n  "
+		if j.count == 0 {
+			sep = "[\n  "
+		}
+		if _, err := io.WriteString(j.w, sep); err != nil {
+			return err
+		}
+		if _, err := j.w.Write(data); err != nil {
+			return err
+		}
+		j.count++
+	}
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	end := "\n]\n"
+	if j.count == 0 {
+		end = "[]\n"
+	}
+	_, err := io.WriteString(j.w, end)
+	return err
+}
+
+type csvWriter struct {
+	w           *csv.Writer
+	wroteHeader bool
+}
+
+// NewCSVWriter returns a Writer producing CSV with a header row. Times are RFC 3339;
+// a post without a publication date has an empty published_at.
+func NewCSVWriter(w io.Writer) Writer {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) Write(posts []ExportPost) error {
+	if !c.wroteHeader {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.wroteHeader = true
+	}
+	for _, post := range posts {
+		published := ""
+		if post.PublishedAt != nil {
+			published = post.PublishedAt.Format(time.RFC3339)
+		}
+		err := c.w.Write([]string{
+			post.ID.String(),
+			post.Title,
+			post.URL,
+			post.Description,
+			published,
+			post.CreatedAt.Format(time.RFC3339),
+			post.UpdatedAt.Format(time.RFC3339),
+			post.FeedID.String(),
+			post.FeedName,
+			post.FeedURL,
+		})
+		if err != nil {
+			return err
+		}
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	// An empty export still gets its header
+	return c.Write(nil)
+}
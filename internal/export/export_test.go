@@ -0,0 +1,111 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func testPosts() []ExportPost {
+	published := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	created := time.Date(2025, 3, 2, 8, 30, 0, 0, time.UTC)
+	return []ExportPost{
+		{
+			ID: uuid.New(), Title: `Quotes "and", commas`, URL: "https://example.com/1",
+			Description: "two\nlines", PublishedAt: &published, CreatedAt: created, UpdatedAt: created,
+			FeedID: uuid.New(), FeedName: "Example", FeedURL: "https://example.com/rss",
+		},
+		{
+			ID: uuid.New(), Title: "Undated", URL: "https://example.com/2",
+			CreatedAt: created, UpdatedAt: created,
+			FeedID: uuid.New(), FeedName: "Other", FeedURL: "https://other.example/feed",
+		},
+	}
+}
+
+func TestWriteJSON(t *testing.T) {
+	posts := testPosts()
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, posts); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []ExportPost
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output isn't a JSON array: %v\n%s", err, buf.String())
+	}
+	if len(got) != len(posts) {
+		t.Fatalf("expected %d posts, got %d", len(posts), len(got))
+	}
+	if got[0].Title != posts[0].Title || got[0].FeedName != "Example" || !got[0].PublishedAt.Equal(*posts[0].PublishedAt) {
+		t.Errorf("first post round-tripped to %+v", got[0])
+	}
+	if got[1].PublishedAt != nil {
+		t.Errorf("undated post got published_at %v", got[1].PublishedAt)
+	}
+
+	buf.Reset()
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "[]\n" {
+		t.Errorf("empty export = %q, want an empty array", buf.String())
+	}
+}
+
+func TestJSONWriterBatches(t *testing.T) {
+	posts := testPosts()
+	var buf bytes.Buffer
+	w := NewJSONWriter(&buf)
+	for _, post := range posts {
+		if err := w.Write([]ExportPost{post}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []ExportPost
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil || len(got) != len(posts) {
+		t.Fatalf("batched output = %d posts, %v:\n%s", len(got), err, buf.String())
+	}
+}
+
+func TestWriteCSV(t *testing.T) {
+	posts := testPosts()
+	var buf bytes.Buffer
+	if err := WriteCSV(&buf, posts); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output isn't valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected a header and 2 rows, got %d records", len(records))
+	}
+	if records[0][0] != "id" || records[0][len(records[0])-1] != "feed_url" {
+		t.Errorf("unexpected header %v", records[0])
+	}
+	first := records[1]
+	if first[1] != posts[0].Title || first[3] != "two\nlines" || first[4] != "2025-03-01T12:00:00Z" || first[8] != "Example" {
+		t.Errorf("unexpected first row %q", first)
+	}
+	if records[2][4] != "" {
+		t.Errorf("undated post has published_at %q", records[2][4])
+	}
+
+	buf.Reset()
+	if err := WriteCSV(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if records, _ := csv.NewReader(&buf).ReadAll(); len(records) != 1 {
+		t.Errorf("empty export has %d records, want just the header", len(records))
+	}
+}
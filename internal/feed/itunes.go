@@ -0,0 +1,54 @@
+package feed
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ITunesChannel holds the iTunes podcast extensions of an RSS channel. Embed it in the
+// channel struct so the itunes: elements are decoded alongside the plain ones.
+type ITunesChannel struct {
+	Author   string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+	Image    ITunesImage    `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Category ITunesCategory `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd category"`
+	Explicit string         `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd explicit"`
+}
+
+// ITunesItem holds the iTunes podcast extensions of an RSS item
+type ITunesItem struct {
+	Duration string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd duration"`
+	Episode  string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd episode"`
+	Season   string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd season"`
+	Image    ITunesImage `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd image"`
+	Author   string      `xml:"http://www.itunes.com/dtds/podcast-1.0.dtd author"`
+}
+
+// ITunesImage is <itunes:image href="...">; the URL is an attribute, not text
+type ITunesImage struct {
+	Href string `xml:"href,attr"`
+}
+
+// ITunesCategory is <itunes:category text="...">
+type ITunesCategory struct {
+	Text string `xml:"text,attr"`
+}
+
+// ParseDuration reads an itunes:duration, which is either a number of seconds or
+// [HH:]MM:SS
+func ParseDuration(raw string) (time.Duration, bool) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" || strings.Count(raw, ":") > 2 {
+		return 0, false
+	}
+
+	var secs int
+	for _, part := range strings.Split(raw, ":") {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return 0, false
+		}
+		secs = secs*60 + n
+	}
+	return time.Duration(secs) * time.Second, true
+}
@@ -0,0 +1,56 @@
+package feed
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+)
+
+func TestParseDuration(t *testing.T) {
+	cases := map[string]time.Duration{
+		"3723":     time.Hour + 2*time.Minute + 3*time.Second,
+		"62:03":    time.Hour + 2*time.Minute + 3*time.Second,
+		"01:02:03": time.Hour + 2*time.Minute + 3*time.Second,
+		" 45 ":     45 * time.Second,
+	}
+	for raw, want := range cases {
+		if got, ok := ParseDuration(raw); !ok || got != want {
+			t.Errorf("ParseDuration(%q) = %v, %v; want %v", raw, got, ok, want)
+		}
+	}
+	for _, raw := range []string{"", "1:2:3:4", "ten minutes", "-5", "1:-2"} {
+		if _, ok := ParseDuration(raw); ok {
+			t.Errorf("ParseDuration(%q) should fail", raw)
+		}
+	}
+}
+
+func TestITunesFieldsDecode(t *testing.T) {
+	var doc struct {
+		Channel struct {
+			ITunesChannel
+			Item struct {
+				ITunesItem
+				Title string `xml:"title"`
+			} `xml:"item"`
+		} `xml:"channel"`
+	}
+	body := `<rss xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd"><channel>
+		<itunes:author>Jane</itunes:author>
+		<itunes:image href="https://example.com/cover.jpg"/>
+		<itunes:category text="Technology"/>
+		<itunes:explicit>no</itunes:explicit>
+		<item><title>Ep</title><itunes:duration>30:00</itunes:duration><itunes:episode>12</itunes:episode><itunes:season>2</itunes:season></item>
+	</channel></rss>`
+	if err := xml.Unmarshal([]byte(body), &doc); err != nil {
+		t.Fatal(err)
+	}
+	channel := doc.Channel.ITunesChannel
+	if channel.Author != "Jane" || channel.Image.Href != "https://example.com/cover.jpg" || channel.Category.Text != "Technology" || channel.Explicit != "no" {
+		t.Errorf("unexpected channel fields %+v", channel)
+	}
+	item := doc.Channel.Item
+	if item.Title != "Ep" || item.Duration != "30:00" || item.Episode != "12" || item.Season != "2" {
+		t.Errorf("unexpected item fields %+v", item)
+	}
+}
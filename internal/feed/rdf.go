@@ -0,0 +1,70 @@
+// Package feed decodes feed formats beyond the RSS 2.0 that gator parses natively.
+package feed
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+)
+
+// rdfNS is the namespace of RSS 1.0's rdf:RDF root element
+const rdfNS = "http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+
+// RDFFeed is an RSS 1.0 document. Unlike RSS 2.0, its items are siblings of the
+// channel under the rdf:RDF root rather than children of it.
+type RDFFeed struct {
+	XMLName xml.Name   `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# RDF"`
+	Channel RDFChannel `xml:"http://purl.org/rss/1.0/ channel"`
+	Items   []RDFItem  `xml:"http://purl.org/rss/1.0/ item"`
+}
+
+// RDFChannel describes the feed itself
+type RDFChannel struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"http://purl.org/rss/1.0/ title"`
+	Link        string `xml:"http://purl.org/rss/1.0/ link"`
+	Description string `xml:"http://purl.org/rss/1.0/ description"`
+}
+
+// RDFItem is a single item. RSS 1.0 has no publication date of its own; feeds use
+// Dublin Core's dc:date instead.
+type RDFItem struct {
+	About       string `xml:"http://www.w3.org/1999/02/22-rdf-syntax-ns# about,attr"`
+	Title       string `xml:"http://purl.org/rss/1.0/ title"`
+	Link        string `xml:"http://purl.org/rss/1.0/ link"`
+	Description string `xml:"http://purl.org/rss/1.0/ description"`
+	Date        string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	Creator     string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+}
+
+// IsRDF reports whether body's root element is rdf:RDF, whatever prefix it's written with
+func IsRDF(body []byte) bool {
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return false
+		}
+		if start, ok := token.(xml.StartElement); ok {
+			return start.Name.Space == rdfNS && start.Name.Local == "RDF"
+		}
+	}
+}
+
+// ParseRDF decodes an RSS 1.0 document. An item without a link falls back to its
+// rdf:about, which is usually the same URL.
+func ParseRDF(body []byte) (*RDFFeed, error) {
+	var feed RDFFeed
+	if err := xml.Unmarshal(body, &feed); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal RDF: %w", err)
+	}
+	if feed.Channel.Link == "" {
+		feed.Channel.Link = feed.Channel.About
+	}
+	for i := range feed.Items {
+		if feed.Items[i].Link == "" {
+			feed.Items[i].Link = feed.Items[i].About
+		}
+	}
+	return &feed, nil
+}
@@ -0,0 +1,59 @@
+package feed
+
+import "testing"
+
+const slashdot = `<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#"
+         xmlns="http://purl.org/rss/1.0/"
+         xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://slashdot.org/">
+    <title>Slashdot</title>
+    <link>https://slashdot.org/</link>
+    <description>News for nerds</description>
+  </channel>
+  <item rdf:about="https://slashdot.org/story/1">
+    <title>First story</title>
+    <link>https://slashdot.org/story/1</link>
+    <description>Something happened</description>
+    <dc:date>2025-03-01T12:00:00+00:00</dc:date>
+  </item>
+  <item rdf:about="https://slashdot.org/story/2">
+    <title>No link</title>
+  </item>
+</rdf:RDF>`
+
+func TestParseRDF(t *testing.T) {
+	feed, err := ParseRDF([]byte(slashdot))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if feed.Channel.Title != "Slashdot" || feed.Channel.Link != "https://slashdot.org/" {
+		t.Errorf("unexpected channel %+v", feed.Channel)
+	}
+	if len(feed.Items) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.Items))
+	}
+	first := feed.Items[0]
+	if first.Title != "First story" || first.Description != "Something happened" || first.Date != "2025-03-01T12:00:00+00:00" {
+		t.Errorf("unexpected first item %+v", first)
+	}
+	if feed.Items[1].Link != "https://slashdot.org/story/2" {
+		t.Errorf("item without a link should use rdf:about, got %q", feed.Items[1].Link)
+	}
+}
+
+func TestIsRDF(t *testing.T) {
+	cases := map[string]bool{
+		slashdot: true,
+		`<?xml version="1.0"?><RDF:RDF xmlns:RDF="http://www.w3.org/1999/02/22-rdf-syntax-ns#"/>`: true,
+		`<rss version="2.0"><channel><title>RSS</title></channel></rss>`:                          false,
+		`<feed xmlns="http://www.w3.org/2005/Atom"></feed>`:                                       false,
+		`<RDF>no namespace</RDF>`: false,
+		`not xml`:                 false,
+	}
+	for body, want := range cases {
+		if got := IsRDF([]byte(body)); got != want {
+			t.Errorf("IsRDF(%.40q) = %v, want %v", body, got, want)
+		}
+	}
+}
@@ -0,0 +1,129 @@
+// Package langdetect guesses the language of short texts from their letter trigrams.
+package langdetect
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+)
+
+const (
+	// profileSize is how many of the most frequent trigrams make up a profile
+	profileSize = 300
+	// minLetters is the least text worth guessing at; shorter snippets are too noisy
+	minLetters = 20
+	// minMargin is how far, as a share of the worst possible distance, the best
+	// language must beat the runner-up before Detect commits to it
+	minMargin = 0.02
+)
+
+// profiles holds each sample language's trigrams, most frequent first, keyed by code
+var profiles = buildProfiles()
+
+func buildProfiles() map[string]map[string]int {
+	built := make(map[string]map[string]int, len(samples))
+	for lang, text := range samples {
+		ranks := make(map[string]int, profileSize)
+		for i, gram := range topTrigrams(text) {
+			ranks[gram] = i
+		}
+		built[lang] = ranks
+	}
+	return built
+}
+
+// Languages lists the codes Detect can return, sorted
+func Languages() []string {
+	langs := make([]string, 0, len(samples))
+	for lang := range samples {
+		langs = append(langs, lang)
+	}
+	sort.Strings(langs)
+	return langs
+}
+
+// Detect returns the BCP 47 code of the language text is most likely written in, or ""
+// when the text is too short or no language is a clear match. Scoring follows Cavnar and
+// Trenkle's out-of-place measure over the text's most frequent trigrams.
+func Detect(text string) string {
+	letters := 0
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			letters++
+		}
+	}
+	if letters < minLetters {
+		return ""
+	}
+
+	grams := topTrigrams(text)
+	if len(grams) == 0 {
+		return ""
+	}
+	worst := len(grams) * profileSize
+	best, bestDist, runnerUpDist := "", worst+1, worst+1
+	for _, lang := range Languages() {
+		dist := distance(grams, profiles[lang])
+		switch {
+		case dist < bestDist:
+			best, bestDist, runnerUpDist = lang, dist, bestDist
+		case dist < runnerUpDist:
+			runnerUpDist = dist
+		}
+	}
+	if float64(runnerUpDist-bestDist) < minMargin*float64(worst) {
+		return ""
+	}
+	return best
+}
+
+// distance sums how far each of grams sits from its rank in profile, counting trigrams
+// the profile lacks as maximally out of place
+func distance(grams []string, profile map[string]int) int {
+	total := 0
+	for i, gram := range grams {
+		rank, ok := profile[gram]
+		if !ok {
+			total += profileSize
+			continue
+		}
+		if rank > i {
+			total += rank - i
+		} else {
+			total += i - rank
+		}
+	}
+	return total
+}
+
+// topTrigrams returns up to profileSize of the trigrams in text, most frequent first.
+// Words are lowercased and padded with a space on each side so that word starts and
+// endings count; anything that isn't a letter separates words.
+func topTrigrams(text string) []string {
+	counts := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r)
+	})
+	for _, word := range words {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+3 <= len(runes); i++ {
+			counts[string(runes[i:i+3])]++
+		}
+	}
+
+	grams := make([]string, 0, len(counts))
+	for gram := range counts {
+		grams = append(grams, gram)
+	}
+	// Ties break alphabetically so profiles don't depend on map order
+	sort.Slice(grams, func(i, j int) bool {
+		if counts[grams[i]] != counts[grams[j]] {
+			return counts[grams[i]] > counts[grams[j]]
+		}
+		return grams[i] < grams[j]
+	})
+	if len(grams) > profileSize {
+		grams = grams[:profileSize]
+	}
+	return grams
+}
@@ -0,0 +1,39 @@
+package langdetect
+
+import "testing"
+
+func TestDetect(t *testing.T) {
+	cases := []struct {
+		text string
+		want string
+	}{
+		{"Go 1.25 is released with a new garbage collector and faster builds for everyone", "en"},
+		{"Die Bundesregierung hat heute neue Regeln für den Klimaschutz beschlossen", "de"},
+		{"Le gouvernement a présenté mercredi un nouveau projet de loi sur le logement", "fr"},
+		{"El gobierno ha presentado hoy una nueva ley sobre la vivienda para los jóvenes", "es"},
+		{"Il governo ha presentato oggi una nuova legge sulla casa per i giovani", "it"},
+		{"Het kabinet heeft vandaag een nieuwe wet over woningen voor jongeren gepresenteerd", "nl"},
+		{"O governo apresentou hoje uma nova lei sobre a habitação para os jovens", "pt"},
+		{"Go 1.25", ""},
+		{"", ""},
+		{"1234567890 1234567890 1234567890", ""},
+	}
+	for _, c := range cases {
+		if got := Detect(c.text); got != c.want {
+			t.Errorf("Detect(%q) = %q, want %q", c.text, got, c.want)
+		}
+	}
+}
+
+func TestLanguages(t *testing.T) {
+	got := Languages()
+	want := []string{"de", "en", "es", "fr", "it", "nl", "pt"}
+	if len(got) != len(want) {
+		t.Fatalf("Languages() = %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("Languages() = %v, want %v", got, want)
+		}
+	}
+}
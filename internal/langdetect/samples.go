@@ -0,0 +1,68 @@
+package langdetect
+
+// samples are everyday prose in each language Detect knows; their trigram profiles are
+// what texts get compared against
+var samples = map[string]string{
+	"en": `The city council met on Tuesday evening to discuss the new budget for the coming
+year. Many of the people who live in the area have said that they want more money to be
+spent on schools, roads and public transport. The mayor told reporters that the plan would
+be ready before the end of the month, and that everyone will have the chance to share their
+thoughts with the council. There is still a lot of work to do, but we are confident that this
+is the right way forward for the whole community. What do you think about these changes?
+Scientists have found that the weather this summer was the warmest they have ever recorded,
+which could have an effect on farming and on the health of those who work outside.`,
+
+	"de": `Der Stadtrat hat sich am Dienstagabend getroffen, um über den neuen Haushalt für
+das kommende Jahr zu sprechen. Viele Menschen, die in der Gegend wohnen, haben gesagt, dass
+sie mehr Geld für Schulen, Straßen und den öffentlichen Verkehr ausgeben wollen. Der
+Bürgermeister sagte den Journalisten, dass der Plan noch vor dem Ende des Monats fertig sein
+werde und dass alle die Möglichkeit haben werden, ihre Gedanken mit dem Rat zu teilen. Es gibt
+noch viel zu tun, aber wir sind sicher, dass dies der richtige Weg für die ganze Gemeinschaft
+ist. Was halten Sie von diesen Veränderungen? Wissenschaftler haben herausgefunden, dass das
+Wetter in diesem Sommer das wärmste war, das sie jemals gemessen haben.`,
+
+	"fr": `Le conseil municipal s'est réuni mardi soir pour discuter du nouveau budget de
+l'année prochaine. Beaucoup de personnes qui habitent dans le quartier ont dit qu'elles
+voulaient que plus d'argent soit dépensé pour les écoles, les routes et les transports
+publics. Le maire a déclaré aux journalistes que le projet serait prêt avant la fin du mois,
+et que tout le monde aura la possibilité de partager ses idées avec le conseil. Il reste
+encore beaucoup de travail à faire, mais nous sommes convaincus que c'est la bonne voie pour
+toute la communauté. Que pensez-vous de ces changements? Les scientifiques ont découvert que
+le temps de cet été était le plus chaud qu'ils aient jamais enregistré.`,
+
+	"es": `El ayuntamiento se reunió el martes por la noche para hablar del nuevo presupuesto
+para el próximo año. Muchas de las personas que viven en la zona han dicho que quieren que se
+gaste más dinero en las escuelas, las carreteras y el transporte público. El alcalde dijo a
+los periodistas que el plan estaría listo antes de que termine el mes, y que todos tendrán la
+oportunidad de compartir sus ideas con el consejo. Todavía queda mucho trabajo por hacer,
+pero estamos seguros de que este es el camino correcto para toda la comunidad. ¿Qué piensa
+usted de estos cambios? Los científicos han descubierto que el tiempo de este verano fue el
+más caluroso que jamás han registrado.`,
+
+	"it": `Il consiglio comunale si è riunito martedì sera per discutere del nuovo bilancio
+per il prossimo anno. Molte delle persone che vivono nella zona hanno detto che vogliono che
+si spendano più soldi per le scuole, le strade e i trasporti pubblici. Il sindaco ha detto ai
+giornalisti che il piano sarà pronto prima della fine del mese, e che tutti avranno la
+possibilità di condividere le proprie idee con il consiglio. C'è ancora molto lavoro da fare,
+ma siamo sicuri che questa sia la strada giusta per tutta la comunità. Che cosa ne pensate di
+questi cambiamenti? Gli scienziati hanno scoperto che il tempo di questa estate è stato il
+più caldo che abbiano mai registrato.`,
+
+	"nl": `De gemeenteraad kwam dinsdagavond bij elkaar om over de nieuwe begroting voor het
+komende jaar te praten. Veel mensen die in de buurt wonen, hebben gezegd dat ze willen dat er
+meer geld wordt uitgegeven aan scholen, wegen en het openbaar vervoer. De burgemeester vertelde
+de journalisten dat het plan voor het einde van de maand klaar zou zijn, en dat iedereen de
+kans krijgt om zijn gedachten met de raad te delen. Er is nog veel werk te doen, maar we zijn
+ervan overtuigd dat dit de juiste weg is voor de hele gemeenschap. Wat vindt u van deze
+veranderingen? Wetenschappers hebben ontdekt dat het weer deze zomer het warmste was dat ze
+ooit hebben gemeten.`,
+
+	"pt": `A câmara municipal reuniu-se na terça-feira à noite para discutir o novo orçamento
+para o próximo ano. Muitas das pessoas que vivem na região disseram que querem que se gaste
+mais dinheiro nas escolas, nas estradas e nos transportes públicos. O presidente da câmara
+disse aos jornalistas que o plano estaria pronto antes do fim do mês, e que todos terão a
+oportunidade de partilhar as suas ideias com o conselho. Ainda há muito trabalho a fazer, mas
+estamos confiantes de que este é o caminho certo para toda a comunidade. O que você pensa
+destas mudanças? Os cientistas descobriram que o tempo deste verão foi o mais quente que já
+registaram.`,
+}
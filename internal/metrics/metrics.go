@@ -0,0 +1,187 @@
+// Package metrics tracks aggregator metrics and serves them in the Prometheus
+// text exposition format. It implements the handful of counters, gauges and the
+// histogram gator needs directly rather than depending on client_golang.
+package metrics
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// fetchBuckets are the upper bounds, in seconds, of the fetch duration histogram
+var fetchBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// Metrics holds the aggregator's metrics. All methods are safe for concurrent use
+// and do nothing on a nil *Metrics, so callers don't need to check whether
+// metrics are enabled.
+type Metrics struct {
+	mu             sync.Mutex
+	fetchedOK      uint64
+	fetchedError   uint64
+	postsSaved     uint64
+	durationCounts []uint64 // per bucket, not cumulative
+	durationSum    float64
+	durationCount  uint64
+	feedsTotal     int64
+	activeFeeds    int64
+	lastScrapeUnix float64
+}
+
+// New returns an empty set of metrics
+func New() *Metrics {
+	return &Metrics{durationCounts: make([]uint64, len(fetchBuckets))}
+}
+
+// FetchStarted marks a feed fetch as in flight
+func (m *Metrics) FetchStarted() {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.activeFeeds++
+}
+
+// FetchFinished records the outcome and duration of a feed fetch and the posts it saved
+func (m *Metrics) FetchFinished(ok bool, duration time.Duration, postsSaved int64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.activeFeeds--
+	if ok {
+		m.fetchedOK++
+	} else {
+		m.fetchedError++
+	}
+	if postsSaved > 0 {
+		m.postsSaved += uint64(postsSaved)
+	}
+
+	seconds := duration.Seconds()
+	m.durationSum += seconds
+	m.durationCount++
+	for i, bound := range fetchBuckets {
+		if seconds <= bound {
+			m.durationCounts[i]++
+			break
+		}
+	}
+}
+
+// ScrapeFinished records the end of an aggregator tick and how many feeds exist
+func (m *Metrics) ScrapeFinished(at time.Time, feedsTotal int64) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lastScrapeUnix = float64(at.UnixNano()) / 1e9
+	m.feedsTotal = feedsTotal
+}
+
+// WriteTo writes the metrics in the Prometheus text exposition format
+func (m *Metrics) WriteTo(w io.Writer) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	ew := &errWriter{w: w}
+	ew.printf("# HELP gator_feeds_fetched_total Feed fetches by outcome.\n")
+	ew.printf("# TYPE gator_feeds_fetched_total counter\n")
+	ew.printf("gator_feeds_fetched_total{status=\"ok\"} %d\n", m.fetchedOK)
+	ew.printf("gator_feeds_fetched_total{status=\"error\"} %d\n", m.fetchedError)
+
+	ew.printf("# HELP gator_posts_saved_total New posts saved.\n")
+	ew.printf("# TYPE gator_posts_saved_total counter\n")
+	ew.printf("gator_posts_saved_total %d\n", m.postsSaved)
+
+	ew.printf("# HELP gator_fetch_duration_seconds Time taken to fetch and store a feed.\n")
+	ew.printf("# TYPE gator_fetch_duration_seconds histogram\n")
+	var cumulative uint64
+	for i, bound := range fetchBuckets {
+		cumulative += m.durationCounts[i]
+		ew.printf("gator_fetch_duration_seconds_bucket{le=\"%s\"} %d\n", formatFloat(bound), cumulative)
+	}
+	ew.printf("gator_fetch_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.durationCount)
+	ew.printf("gator_fetch_duration_seconds_sum %s\n", formatFloat(m.durationSum))
+	ew.printf("gator_fetch_duration_seconds_count %d\n", m.durationCount)
+
+	ew.printf("# HELP gator_feeds_total Feeds in the database.\n")
+	ew.printf("# TYPE gator_feeds_total gauge\n")
+	ew.printf("gator_feeds_total %d\n", m.feedsTotal)
+
+	ew.printf("# HELP gator_active_feeds Feed fetches in progress.\n")
+	ew.printf("# TYPE gator_active_feeds gauge\n")
+	ew.printf("gator_active_feeds %d\n", m.activeFeeds)
+
+	ew.printf("# HELP gator_last_scrape_timestamp Unix time the last aggregator tick finished.\n")
+	ew.printf("# TYPE gator_last_scrape_timestamp gauge\n")
+	ew.printf("gator_last_scrape_timestamp %s\n", formatFloat(m.lastScrapeUnix))
+	return ew.n, ew.err
+}
+
+// ServeHTTP serves the metrics, so a *Metrics can be mounted at /metrics
+func (m *Metrics) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteTo(w)
+}
+
+// Serve exposes /metrics on addr until ctx is cancelled
+func Serve(ctx context.Context, addr string, m *Metrics) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("couldn't listen on %s: %w", addr, err)
+	}
+
+	errCh := make(chan error, 1)
+	go func() { errCh <- srv.Serve(ln) }()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return srv.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		if errors.Is(err, http.ErrServerClosed) {
+			return nil
+		}
+		return err
+	}
+}
+
+func formatFloat(f float64) string {
+	if math.IsInf(f, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// errWriter keeps the first write error so WriteTo can check it once
+type errWriter struct {
+	w   io.Writer
+	n   int64
+	err error
+}
+
+func (ew *errWriter) printf(format string, args ...any) {
+	if ew.err != nil {
+		return
+	}
+	n, err := fmt.Fprintf(ew.w, format, args...)
+	ew.n += int64(n)
+	ew.err = err
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWriteTo(t *testing.T) {
+	m := New()
+	m.FetchStarted()
+	m.FetchFinished(true, 300*time.Millisecond, 4)
+	m.FetchStarted()
+	m.FetchFinished(false, 20*time.Second, 0)
+	m.FetchStarted()
+	m.ScrapeFinished(time.Unix(1700000000, 0), 12)
+
+	rec := httptest.NewRecorder()
+	m.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	body := rec.Body.String()
+
+	for _, want := range []string{
+		`gator_feeds_fetched_total{status="ok"} 1`,
+		`gator_feeds_fetched_total{status="error"} 1`,
+		`gator_posts_saved_total 4`,
+		`gator_fetch_duration_seconds_bucket{le="0.25"} 0`,
+		`gator_fetch_duration_seconds_bucket{le="0.5"} 1`,
+		`gator_fetch_duration_seconds_bucket{le="30"} 2`,
+		`gator_fetch_duration_seconds_bucket{le="+Inf"} 2`,
+		`gator_fetch_duration_seconds_sum 20.3`,
+		`gator_fetch_duration_seconds_count 2`,
+		`gator_feeds_total 12`,
+		`gator_active_feeds 1`,
+		`gator_last_scrape_timestamp 1.7e+09`,
+	} {
+		if !strings.Contains(body, want+"\n") {
+			t.Errorf("missing %q in:\n%s", want, body)
+		}
+	}
+}
+
+func TestNilMetrics(t *testing.T) {
+	var m *Metrics
+	m.FetchStarted()
+	m.FetchFinished(true, time.Second, 1)
+	m.ScrapeFinished(time.Now(), 1)
+}
@@ -0,0 +1,292 @@
+// Package migrations applies the embedded database schema migrations and tracks
+// them in the schema_migrations table.
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var files embed.FS
+
+// Up and Down sections of a migration file start with these lines
+const (
+	upMarker   = "-- Up:"
+	downMarker = "-- Down:"
+)
+
+const createTrackingTable = `CREATE TABLE IF NOT EXISTS schema_migrations (
+    version INTEGER PRIMARY KEY,
+    name TEXT NOT NULL,
+    applied_at TIMESTAMP NOT NULL
+)`
+
+// createTable matches the table each CREATE TABLE statement in an Up section creates
+var createTable = regexp.MustCompile(`(?i)\bCREATE TABLE (?:IF NOT EXISTS )?(\w+)`)
+
+// Migration is one numbered schema change, e.g. 002_feed_cache_headers.sql
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status is a migration and, if it has been applied, when
+type Status struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Load returns the embedded migrations ordered by version
+func Load() ([]Migration, error) {
+	entries, err := files.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]Migration, 0, len(entries))
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		content, err := files.ReadFile(path.Join("migrations", entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		migration, err := parse(entry.Name(), string(content))
+		if err != nil {
+			return nil, err
+		}
+		if other, ok := seen[migration.Version]; ok {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", other, entry.Name(), migration.Version)
+		}
+		seen[migration.Version] = entry.Name()
+		migrations = append(migrations, migration)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// parse splits a migration file named NNN_name.sql into its Up and Down sections
+func parse(filename, content string) (Migration, error) {
+	base := strings.TrimSuffix(filename, ".sql")
+	number, name, ok := strings.Cut(base, "_")
+	version, err := strconv.Atoi(number)
+	if !ok || err != nil || version <= 0 || name == "" {
+		return Migration{}, fmt.Errorf("migration %s must be named NNN_name.sql", filename)
+	}
+
+	upStart := strings.Index(content, upMarker)
+	downStart := strings.Index(content, downMarker)
+	if upStart < 0 || downStart < 0 || downStart < upStart {
+		return Migration{}, fmt.Errorf("migration %s needs an %q section followed by a %q section", filename, upMarker, downMarker)
+	}
+
+	migration := Migration{
+		Version: version,
+		Name:    name,
+		Up:      strings.TrimSpace(content[upStart+len(upMarker) : downStart]),
+		Down:    strings.TrimSpace(content[downStart+len(downMarker):]),
+	}
+	if migration.Up == "" {
+		return Migration{}, fmt.Errorf("migration %s has an empty %q section", filename, upMarker)
+	}
+	return migration, nil
+}
+
+// applied returns when each applied migration version was applied
+func applied(ctx context.Context, db *sql.DB) (map[int]time.Time, error) {
+	if _, err := db.ExecContext(ctx, createTrackingTable); err != nil {
+		return nil, fmt.Errorf("couldn't create schema_migrations: %w", err)
+	}
+
+	rows, err := db.QueryContext(ctx, "SELECT version, applied_at FROM schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	versions := make(map[int]time.Time)
+	for rows.Next() {
+		var version int
+		var appliedAt time.Time
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		versions[version] = appliedAt
+	}
+	return versions, rows.Err()
+}
+
+// GetStatus lists every embedded migration with its applied time, if any
+func GetStatus(ctx context.Context, db *sql.DB) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	versions, err := applied(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, len(migrations))
+	for i, migration := range migrations {
+		statuses[i] = Status{Migration: migration}
+		if appliedAt, ok := versions[migration.Version]; ok {
+			statuses[i].AppliedAt = &appliedAt
+		}
+	}
+	return statuses, nil
+}
+
+// Tables lists the tables the embedded migrations create, sorted
+func Tables() ([]string, error) {
+	tables, err := CreationOrder()
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(tables)
+	return tables, nil
+}
+
+// CreationOrder lists the tables the embedded migrations create in the order they are
+// created, so every table comes after the tables its foreign keys reference
+func CreationOrder() ([]string, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	var tables []string
+	for _, migration := range migrations {
+		for _, match := range createTable.FindAllStringSubmatch(migration.Up, -1) {
+			table := strings.ToLower(match[1])
+			if !seen[table] {
+				seen[table] = true
+				tables = append(tables, table)
+			}
+		}
+	}
+	return tables, nil
+}
+
+// MissingTables returns the tables the embedded migrations create that the database
+// doesn't have, sorted; none means the schema is complete
+func MissingTables(ctx context.Context, db *sql.DB) ([]string, error) {
+	tables, err := Tables()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := db.QueryContext(ctx,
+		"SELECT table_name FROM information_schema.tables WHERE table_schema = current_schema()")
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, err
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	for _, table := range tables {
+		if !existing[table] {
+			missing = append(missing, table)
+		}
+	}
+	return missing, nil
+}
+
+// Up applies every pending migration in order, each in its own transaction, and
+// calls onApply after each one. It stops at the first failure.
+func Up(ctx context.Context, db *sql.DB, onApply func(Migration)) error {
+	statuses, err := GetStatus(ctx, db)
+	if err != nil {
+		return err
+	}
+
+	for _, status := range statuses {
+		if status.AppliedAt != nil {
+			continue
+		}
+		migration := status.Migration
+		err := inTx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Up); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx,
+				"INSERT INTO schema_migrations (version, name, applied_at) VALUES ($1, $2, $3)",
+				migration.Version, migration.Name, time.Now().UTC())
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("migration %03d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		if onApply != nil {
+			onApply(migration)
+		}
+	}
+	return nil
+}
+
+// Rollback runs the Down section of the most recently applied migration and
+// returns it. It returns false if no migration has been applied.
+func Rollback(ctx context.Context, db *sql.DB) (Migration, bool, error) {
+	statuses, err := GetStatus(ctx, db)
+	if err != nil {
+		return Migration{}, false, err
+	}
+
+	for i := len(statuses) - 1; i >= 0; i-- {
+		if statuses[i].AppliedAt == nil {
+			continue
+		}
+		migration := statuses[i].Migration
+		if migration.Down == "" {
+			return migration, false, fmt.Errorf("migration %03d_%s can't be rolled back: it has no %q section", migration.Version, migration.Name, downMarker)
+		}
+		err := inTx(ctx, db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, migration.Down); err != nil {
+				return err
+			}
+			_, err := tx.ExecContext(ctx, "DELETE FROM schema_migrations WHERE version = $1", migration.Version)
+			return err
+		})
+		if err != nil {
+			return migration, false, fmt.Errorf("rolling back %03d_%s failed: %w", migration.Version, migration.Name, err)
+		}
+		return migration, true, nil
+	}
+	return Migration{}, false, nil
+}
+
+// inTx runs fn in a transaction, committing only if it succeeds
+func inTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
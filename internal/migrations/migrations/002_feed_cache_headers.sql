@@ -0,0 +1,9 @@
+-- Up:
+ALTER TABLE feeds
+ADD COLUMN IF NOT EXISTS last_etag TEXT NULL,
+ADD COLUMN IF NOT EXISTS last_modified TEXT NULL;
+
+-- Down:
+ALTER TABLE feeds
+DROP COLUMN last_modified,
+DROP COLUMN last_etag;
@@ -0,0 +1,7 @@
+-- Up:
+ALTER TABLE feeds
+ADD COLUMN IF NOT EXISTS interval_secs INTEGER NULL;
+
+-- Down:
+ALTER TABLE feeds
+DROP COLUMN interval_secs;
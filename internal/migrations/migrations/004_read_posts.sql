@@ -0,0 +1,10 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS read_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    read_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+-- Down:
+DROP TABLE read_posts;
@@ -0,0 +1,11 @@
+-- Up:
+ALTER TABLE feeds
+ADD COLUMN IF NOT EXISTS consecutive_errors INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN IF NOT EXISTS last_error TEXT NULL,
+ADD COLUMN IF NOT EXISTS last_attempted_at TIMESTAMP NULL;
+
+-- Down:
+ALTER TABLE feeds
+DROP COLUMN last_attempted_at,
+DROP COLUMN last_error,
+DROP COLUMN consecutive_errors;
@@ -0,0 +1,10 @@
+-- Up:
+-- Must match the expression repeated in SearchPosts
+CREATE INDEX IF NOT EXISTS posts_search_idx ON posts USING GIN ((
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('simple', url), 'C')
+));
+
+-- Down:
+DROP INDEX posts_search_idx;
@@ -0,0 +1,12 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS feed_tags (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_id, tag)
+);
+
+CREATE INDEX IF NOT EXISTS feed_tags_tag_idx ON feed_tags (tag);
+
+-- Down:
+DROP TABLE feed_tags;
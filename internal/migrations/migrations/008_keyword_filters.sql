@@ -0,0 +1,12 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS keyword_filters (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    filter_type TEXT NOT NULL CHECK (filter_type IN ('include', 'exclude')),
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any'))
+);
+
+-- Down:
+DROP TABLE keyword_filters;
@@ -0,0 +1,7 @@
+-- Up:
+ALTER TABLE feeds
+ADD COLUMN IF NOT EXISTS fetch_timeout_secs INTEGER NULL;
+
+-- Down:
+ALTER TABLE feeds
+DROP COLUMN fetch_timeout_secs;
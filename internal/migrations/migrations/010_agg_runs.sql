@@ -0,0 +1,12 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS agg_runs (
+    id UUID PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    feeds_fetched INTEGER NOT NULL,
+    feeds_failed INTEGER NOT NULL,
+    posts_saved INTEGER NOT NULL
+);
+
+-- Down:
+DROP TABLE agg_runs;
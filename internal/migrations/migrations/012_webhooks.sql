@@ -0,0 +1,13 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS webhooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    feed_filter_id UUID NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    keyword_filter TEXT NULL,
+    created_at TIMESTAMP NOT NULL
+);
+
+-- Down:
+DROP TABLE webhooks;
@@ -0,0 +1,10 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS notifications (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (feed_id, user_id)
+);
+
+-- Down:
+DROP TABLE notifications;
@@ -0,0 +1,10 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS post_enclosures (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    media_type TEXT NOT NULL DEFAULT '',
+    length BIGINT NULL
+);
+
+-- Down:
+DROP TABLE post_enclosures;
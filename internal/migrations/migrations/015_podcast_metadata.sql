@@ -0,0 +1,21 @@
+-- Up:
+ALTER TABLE post_enclosures
+ADD COLUMN IF NOT EXISTS episode_number INT NULL,
+ADD COLUMN IF NOT EXISTS season_number INT NULL,
+ADD COLUMN IF NOT EXISTS duration_secs INT NULL;
+
+CREATE TABLE IF NOT EXISTS podcast_feeds (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    author TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    explicit BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Down:
+DROP TABLE podcast_feeds;
+ALTER TABLE post_enclosures
+DROP COLUMN episode_number,
+DROP COLUMN season_number,
+DROP COLUMN duration_secs;
@@ -0,0 +1,9 @@
+-- Up:
+CREATE TABLE IF NOT EXISTS feed_adaptive_intervals (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    interval_secs INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- Down:
+DROP TABLE feed_adaptive_intervals;
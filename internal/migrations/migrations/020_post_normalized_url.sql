@@ -0,0 +1,13 @@
+-- Up:
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS normalized_url TEXT;
+UPDATE posts SET normalized_url = url WHERE normalized_url IS NULL;
+ALTER TABLE posts ALTER COLUMN normalized_url SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_url_key;
+CREATE UNIQUE INDEX IF NOT EXISTS posts_normalized_url_key ON posts (normalized_url);
+CREATE INDEX IF NOT EXISTS posts_url_idx ON posts (url);
+
+-- Down:
+DROP INDEX posts_url_idx;
+DROP INDEX posts_normalized_url_key;
+ALTER TABLE posts DROP COLUMN normalized_url;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
@@ -0,0 +1,5 @@
+-- Up:
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS author TEXT;
+
+-- Down:
+ALTER TABLE posts DROP COLUMN author;
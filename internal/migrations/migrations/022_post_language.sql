@@ -0,0 +1,7 @@
+-- Up:
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS language TEXT;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS preferred_language TEXT;
+
+-- Down:
+ALTER TABLE users DROP COLUMN preferred_language;
+ALTER TABLE posts DROP COLUMN language;
@@ -0,0 +1,5 @@
+-- Up:
+ALTER TABLE feeds ADD COLUMN IF NOT EXISTS user_agent TEXT;
+
+-- Down:
+ALTER TABLE feeds DROP COLUMN user_agent;
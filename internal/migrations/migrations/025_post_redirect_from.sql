@@ -0,0 +1,7 @@
+-- Up:
+ALTER TABLE posts ADD COLUMN IF NOT EXISTS redirect_from TEXT;
+CREATE INDEX IF NOT EXISTS posts_redirect_from_idx ON posts (redirect_from) WHERE redirect_from IS NOT NULL;
+
+-- Down:
+DROP INDEX posts_redirect_from_idx;
+ALTER TABLE posts DROP COLUMN redirect_from;
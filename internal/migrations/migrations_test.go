@@ -0,0 +1,72 @@
+package migrations
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	migrations, err := Load()
+	if err != nil {
+		t.Fatalf("embedded migrations don't load: %v", err)
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			t.Fatalf("expected versions to run 1..n without gaps, got %d at position %d", migration.Version, i)
+		}
+		if migration.Down == "" {
+			t.Errorf("migration %03d_%s has no Down section", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	migration, err := parse("002_add_tags.sql", "-- Up:\nCREATE TABLE tags (name TEXT);\n\n-- Down:\nDROP TABLE tags;\n")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if migration.Version != 2 || migration.Name != "add_tags" ||
+		migration.Up != "CREATE TABLE tags (name TEXT);" || migration.Down != "DROP TABLE tags;" {
+		t.Fatalf("unexpected migration: %+v", migration)
+	}
+
+	for name, content := range map[string]string{
+		"add_tags.sql":    "-- Up:\nSELECT 1;\n-- Down:\n",
+		"002_.sql":        "-- Up:\nSELECT 1;\n-- Down:\n",
+		"003_nodown.sql":  "-- Up:\nSELECT 1;\n",
+		"004_reverse.sql": "-- Down:\nSELECT 1;\n-- Up:\nSELECT 1;\n",
+		"005_empty.sql":   "-- Up:\n-- Down:\nSELECT 1;\n",
+	} {
+		if _, err := parse(name, content); err == nil {
+			t.Errorf("expected %s to be rejected", name)
+		}
+	}
+}
+
+func TestTables(t *testing.T) {
+	tables, err := Tables()
+	if err != nil {
+		t.Fatalf("embedded migrations don't load: %v", err)
+	}
+	if !slices.IsSorted(tables) || len(slices.Compact(slices.Clone(tables))) != len(tables) {
+		t.Errorf("expected sorted, unique table names, got %v", tables)
+	}
+	for _, table := range []string{"users", "feeds", "feed_follows", "posts", "post_tags"} {
+		if !slices.Contains(tables, table) {
+			t.Errorf("expected %s among the migrated tables, got %v", table, tables)
+		}
+	}
+}
+
+func TestCreationOrder(t *testing.T) {
+	tables, err := CreationOrder()
+	if err != nil {
+		t.Fatalf("embedded migrations don't load: %v", err)
+	}
+	// Each pair is a table and one it references, which must be created first
+	for _, pair := range [][2]string{{"feeds", "users"}, {"posts", "feeds"}, {"post_tags", "posts"}, {"feed_group_members", "feed_groups"}} {
+		if slices.Index(tables, pair[0]) < slices.Index(tables, pair[1]) {
+			t.Errorf("expected %s after %s, got %v", pair[0], pair[1], tables)
+		}
+	}
+}
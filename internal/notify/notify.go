@@ -0,0 +1,75 @@
+// Package notify shows desktop notifications.
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"runtime"
+	"sync"
+	"time"
+)
+
+// sendTimeout bounds how long the platform notifier may take
+const sendTimeout = 5 * time.Second
+
+// warnUnavailable makes sure a missing notifier is reported once rather than per post
+var warnUnavailable sync.Once
+
+// SendNotification shows a desktop notification. When the platform's notifier isn't
+// installed a warning is logged once and nil is returned, so callers can treat
+// notifications as optional.
+func SendNotification(title, body string) error {
+	name, args, env, err := command(runtime.GOOS, title, body)
+	if err == nil {
+		_, err = exec.LookPath(name)
+	}
+	if err != nil {
+		warnUnavailable.Do(func() {
+			slog.Warn("desktop notifications are unavailable", "error", err)
+		})
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, name, args...)
+	if env != nil {
+		cmd.Env = append(os.Environ(), env...)
+	}
+	if out, err := cmd.CombinedOutput(); err != nil {
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) && len(out) > 0 {
+			return fmt.Errorf("couldn't run %s: %w: %s", name, err, out)
+		}
+		return fmt.Errorf("couldn't run %s: %w", name, err)
+	}
+	return nil
+}
+
+// command returns the notifier for a platform. The title and body never pass through
+// a shell or get spliced into a script, so quotes in post titles are harmless: macOS
+// gets them as script arguments and Windows as environment variables.
+func command(goos, title, body string) (name string, args, env []string, err error) {
+	switch goos {
+	case "linux", "freebsd", "openbsd", "netbsd":
+		return "notify-send", []string{"--app-name=gator", "--", title, body}, nil, nil
+	case "darwin":
+		return "osascript", []string{
+			"-e", "on run argv",
+			"-e", "display notification (item 2 of argv) with title (item 1 of argv)",
+			"-e", "end run",
+			title, body,
+		}, nil, nil
+	case "windows":
+		// Needs the BurntToast module: Install-Module BurntToast
+		return "powershell", []string{
+			"-NoProfile", "-NonInteractive", "-Command",
+			"New-BurntToastNotification -Text $env:GATOR_NOTIFY_TITLE, $env:GATOR_NOTIFY_BODY",
+		}, []string{"GATOR_NOTIFY_TITLE=" + title, "GATOR_NOTIFY_BODY=" + body}, nil
+	}
+	return "", nil, nil, fmt.Errorf("desktop notifications aren't supported on %s", goos)
+}
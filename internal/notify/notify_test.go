@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	const title, body = `Feed "quoted"`, "-starts with a dash"
+
+	name, args, env, err := command("linux", title, body)
+	if err != nil || name != "notify-send" || !slices.Equal(args[len(args)-3:], []string{"--", title, body}) || env != nil {
+		t.Errorf("linux: got %s %q %q %v", name, args, env, err)
+	}
+
+	name, args, _, err = command("darwin", title, body)
+	if err != nil || name != "osascript" || !slices.Equal(args[len(args)-2:], []string{title, body}) {
+		t.Errorf("darwin: got %s %q %v", name, args, err)
+	}
+	for _, arg := range args[:len(args)-2] {
+		if strings.Contains(arg, title) {
+			t.Errorf("darwin: title was spliced into the script %q", arg)
+		}
+	}
+
+	name, args, env, err = command("windows", title, body)
+	if err != nil || name != "powershell" || !slices.Contains(env, "GATOR_NOTIFY_TITLE="+title) || slices.Contains(args, title) {
+		t.Errorf("windows: got %s %q %q %v", name, args, env, err)
+	}
+
+	if _, _, _, err := command("plan9", title, body); err == nil {
+		t.Error("expected an error for an unsupported platform")
+	}
+}
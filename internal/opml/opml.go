@@ -0,0 +1,139 @@
+package opml
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// OPML represents an OPML 2.0 document
+type OPML struct {
+	XMLName xml.Name `xml:"opml"`
+	Version string   `xml:"version,attr"`
+	Head    Head     `xml:"head"`
+	Body    Body     `xml:"body"`
+}
+
+// Head holds the document metadata
+type Head struct {
+	Title       string `xml:"title,omitempty"`
+	DateCreated string `xml:"dateCreated,omitempty"`
+}
+
+// Body holds the top-level outlines
+type Body struct {
+	Outlines []Outline `xml:"outline"`
+}
+
+// Outline is either a feed subscription or a folder containing more outlines
+type Outline struct {
+	Text     string    `xml:"text,attr"`
+	Title    string    `xml:"title,attr,omitempty"`
+	Type     string    `xml:"type,attr,omitempty"`
+	XMLURL   string    `xml:"xmlUrl,attr,omitempty"`
+	HTMLURL  string    `xml:"htmlUrl,attr,omitempty"`
+	Outlines []Outline `xml:"outline"`
+}
+
+// Feed is a single subscription flattened out of the outline tree
+type Feed struct {
+	Name    string
+	URL     string
+	SiteURL string
+	Folder  string
+}
+
+// New builds an OPML 2.0 document listing feeds as RSS outlines. Feeds with a Folder
+// are nested in a folder outline of that name, in the order the folders first appear.
+func New(title string, created time.Time, feeds []Feed) *OPML {
+	doc := &OPML{
+		Version: "2.0",
+		Head: Head{
+			Title:       title,
+			DateCreated: created.Format(time.RFC1123),
+		},
+	}
+	folders := make(map[string]int) // folder name -> index of its outline in the body
+	for _, feed := range feeds {
+		outline := Outline{
+			Text:    feed.Name,
+			Type:    "rss",
+			XMLURL:  feed.URL,
+			HTMLURL: feed.SiteURL,
+		}
+		if feed.Folder == "" {
+			doc.Body.Outlines = append(doc.Body.Outlines, outline)
+			continue
+		}
+		i, ok := folders[feed.Folder]
+		if !ok {
+			i = len(doc.Body.Outlines)
+			folders[feed.Folder] = i
+			doc.Body.Outlines = append(doc.Body.Outlines, Outline{Text: feed.Folder, Title: feed.Folder})
+		}
+		doc.Body.Outlines[i].Outlines = append(doc.Body.Outlines[i].Outlines, outline)
+	}
+	return doc
+}
+
+// Write serializes the document as indented XML with an XML declaration
+func (o *OPML) Write(w io.Writer) error {
+	data, err := xml.MarshalIndent(o, "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't marshal OPML: %w", err)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, "\n")
+	return err
+}
+
+// Parse decodes an OPML document
+func Parse(r io.Reader) (*OPML, error) {
+	var doc OPML
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("couldn't parse OPML: %w", err)
+	}
+	return &doc, nil
+}
+
+// Feeds returns every RSS subscription in the document as a flat list.
+// Folder is the slash-separated path of the folders a feed was nested in.
+func (o *OPML) Feeds() []Feed {
+	var feeds []Feed
+	collectFeeds(o.Body.Outlines, "", &feeds)
+	return feeds
+}
+
+// collectFeeds walks outlines depth-first, recording feeds with their folder path
+func collectFeeds(outlines []Outline, folder string, feeds *[]Feed) {
+	for _, outline := range outlines {
+		name := outline.Text
+		if name == "" {
+			name = outline.Title
+		}
+
+		// Many exporters omit type="rss", so an xmlUrl is enough to count as a feed
+		isFeed := outline.XMLURL != "" && (outline.Type == "" || strings.EqualFold(outline.Type, "rss"))
+		if isFeed {
+			if name == "" {
+				name = outline.XMLURL
+			}
+			*feeds = append(*feeds, Feed{Name: name, URL: outline.XMLURL, SiteURL: outline.HTMLURL, Folder: folder})
+		}
+
+		if len(outline.Outlines) > 0 {
+			child := name
+			if folder != "" {
+				child = folder + "/" + name
+			}
+			collectFeeds(outline.Outlines, child, feeds)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package opml
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseFlattensFolders(t *testing.T) {
+	doc := `<?xml version="1.0" encoding="UTF-8"?>
+<opml version="2.0">
+  <head><title>Subscriptions</title></head>
+  <body>
+    <outline type="rss" text="Top Level" xmlUrl="https://example.com/top.xml"/>
+    <outline text="Tech">
+      <outline type="rss" text="Go Blog" xmlUrl="https://go.dev/blog/feed.atom"/>
+      <outline text="Security">
+        <outline text="No Type" xmlUrl="https://example.com/sec.xml"/>
+      </outline>
+    </outline>
+    <outline type="link" text="Not a feed" xmlUrl="https://example.com/link"/>
+  </body>
+</opml>`
+
+	parsed, err := Parse(strings.NewReader(doc))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	feeds := parsed.Feeds()
+	want := []Feed{
+		{Name: "Top Level", URL: "https://example.com/top.xml"},
+		{Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", Folder: "Tech"},
+		{Name: "No Type", URL: "https://example.com/sec.xml", Folder: "Tech/Security"},
+	}
+	if len(feeds) != len(want) {
+		t.Fatalf("expected %d feeds, got %d: %+v", len(want), len(feeds), feeds)
+	}
+	for i := range want {
+		if feeds[i] != want[i] {
+			t.Fatalf("feed %d: expected %+v, got %+v", i, want[i], feeds[i])
+		}
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	if _, err := Parse(strings.NewReader("<opml><body>")); err == nil {
+		t.Fatalf("expected error for truncated document")
+	}
+}
+
+func TestWriteRoundTrip(t *testing.T) {
+	created := time.Date(2024, 2, 15, 14, 30, 0, 0, time.UTC)
+	doc := New("Gator export for alice", created, []Feed{
+		{Name: "Go & Friends", URL: "https://go.dev/blog/feed.atom", SiteURL: "https://go.dev/"},
+	})
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	if !strings.HasPrefix(out, "<?xml") {
+		t.Fatalf("expected XML declaration, got %q", out)
+	}
+	if !strings.Contains(out, "<dateCreated>Thu, 15 Feb 2024 14:30:00 UTC</dateCreated>") {
+		t.Fatalf("expected RFC 1123 dateCreated, got %q", out)
+	}
+
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("couldn't parse written OPML: %v", err)
+	}
+	if parsed.Version != "2.0" || parsed.Head.Title != "Gator export for alice" {
+		t.Fatalf("unexpected head: %+v", parsed.Head)
+	}
+	feeds := parsed.Feeds()
+	if len(feeds) != 1 || feeds[0].Name != "Go & Friends" || feeds[0].SiteURL != "https://go.dev/" {
+		t.Fatalf("unexpected feeds: %+v", feeds)
+	}
+	if parsed.Body.Outlines[0].Type != "rss" {
+		t.Fatalf("expected type=rss outline, got %+v", parsed.Body.Outlines[0])
+	}
+}
+
+func TestWriteFolders(t *testing.T) {
+	doc := New("Gator export for alice", time.Now(), []Feed{
+		{Name: "Go Blog", URL: "https://go.dev/blog/feed.atom", Folder: "Tech"},
+		{Name: "Loose", URL: "https://example.com/loose.xml"},
+		{Name: "Rust Blog", URL: "https://blog.rust-lang.org/feed.xml", Folder: "Tech"},
+		{Name: "Daily", URL: "https://news.example.com/rss", Folder: "News"},
+	})
+	if len(doc.Body.Outlines) != 3 {
+		t.Fatalf("expected 2 folders and 1 loose feed at the top level, got %+v", doc.Body.Outlines)
+	}
+	if tech := doc.Body.Outlines[0]; tech.Text != "Tech" || tech.XMLURL != "" || len(tech.Outlines) != 2 {
+		t.Fatalf("unexpected Tech folder: %+v", tech)
+	}
+
+	var buf bytes.Buffer
+	if err := doc.Write(&buf); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	parsed, err := Parse(&buf)
+	if err != nil {
+		t.Fatalf("couldn't parse written OPML: %v", err)
+	}
+	folders := make(map[string]string)
+	for _, feed := range parsed.Feeds() {
+		folders[feed.Name] = feed.Folder
+	}
+	want := map[string]string{"Go Blog": "Tech", "Rust Blog": "Tech", "Loose": "", "Daily": "News"}
+	for name, folder := range want {
+		if got, ok := folders[name]; !ok || got != folder {
+			t.Errorf("feed %s: folder %q, want %q", name, got, folder)
+		}
+	}
+}
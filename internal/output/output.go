@@ -0,0 +1,423 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Output formats accepted by the global --format flag
+const (
+	Text = "text"
+	JSON = "json"
+	CSV  = "csv"
+)
+
+// ParseFormat validates a --format value, defaulting to Text when empty
+func ParseFormat(format string) (string, error) {
+	switch format {
+	case "", Text:
+		return Text, nil
+	case JSON, CSV:
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown output format %q: use text, json or csv", format)
+}
+
+// PostJSON is a post as printed by browse and search
+type PostJSON struct {
+	ID          uuid.UUID `json:"id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	Description string    `json:"description,omitempty"`
+	Author      string    `json:"author,omitempty"`
+	PublishedAt time.Time `json:"published_at"`
+	FeedID      uuid.UUID `json:"feed_id"`
+}
+
+// BrowseResult is the output of the browse command
+type BrowseResult struct {
+	Posts []PostJSON `json:"posts"`
+}
+
+// SearchResult is the output of the search command
+type SearchResult struct {
+	Query string     `json:"query"`
+	Posts []PostJSON `json:"posts"`
+}
+
+// BookmarkJSON is a bookmarked post as printed by bookmarks
+type BookmarkJSON struct {
+	PostJSON
+	FeedName     string     `json:"feed_name"`
+	BookmarkedAt *time.Time `json:"bookmarked_at,omitempty"`
+}
+
+// BookmarksResult is the output of the bookmarks command
+type BookmarksResult struct {
+	Bookmarks []BookmarkJSON `json:"bookmarks"`
+}
+
+// PinnedJSON is a pinned post as printed by pinned
+type PinnedJSON struct {
+	PostJSON
+	FeedName string    `json:"feed_name"`
+	PinnedAt time.Time `json:"pinned_at"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// PinnedResult is the output of the pinned command
+type PinnedResult struct {
+	Pinned []PinnedJSON `json:"pinned"`
+}
+
+// FeedJSON is a feed as printed by feeds
+type FeedJSON struct {
+	ID                uuid.UUID  `json:"id"`
+	Name              string     `json:"name"`
+	URL               string     `json:"url"`
+	User              string     `json:"user,omitempty"`
+	Tags              []string   `json:"tags,omitempty"`
+	ConsecutiveErrors int32      `json:"consecutive_errors,omitempty"`
+	LastError         string     `json:"last_error,omitempty"`
+	Podcast           bool       `json:"podcast,omitempty"`
+	IntervalSecs      int32      `json:"interval_secs,omitempty"`
+	AdaptiveSecs      int32      `json:"adaptive_interval_secs,omitempty"`
+	LastFetchedAt     *time.Time `json:"last_fetched_at,omitempty"`
+	PostCount         int64      `json:"post_count"`
+}
+
+// FeedsResult is the output of the feeds command
+type FeedsResult struct {
+	Feeds []FeedJSON `json:"feeds"`
+}
+
+// FeedErrorJSON is a failing feed as printed by feederrors
+type FeedErrorJSON struct {
+	Name              string     `json:"name"`
+	URL               string     `json:"url"`
+	ConsecutiveErrors int32      `json:"consecutive_errors"`
+	LastError         string     `json:"last_error"`
+	LastAttemptedAt   *time.Time `json:"last_attempted_at"`
+}
+
+// FeedErrorsResult is the output of the feederrors command
+type FeedErrorsResult struct {
+	Feeds []FeedErrorJSON `json:"feeds"`
+}
+
+// FoundFeedJSON is a feed as printed by findfeed
+type FoundFeedJSON struct {
+	ID        uuid.UUID `json:"id"`
+	Name      string    `json:"name"`
+	URL       string    `json:"url"`
+	Creator   string    `json:"creator"`
+	Following bool      `json:"following"`
+}
+
+// FindFeedResult is the output of the findfeed command
+type FindFeedResult struct {
+	Query string          `json:"query"`
+	Feeds []FoundFeedJSON `json:"feeds"`
+}
+
+// DeadFeedJSON is a followed feed that stopped working, as printed by deadfeeds
+type DeadFeedJSON struct {
+	ID                uuid.UUID  `json:"id"`
+	Name              string     `json:"name"`
+	URL               string     `json:"url"`
+	ConsecutiveErrors int32      `json:"consecutive_errors"`
+	LastError         string     `json:"last_error,omitempty"`
+	LastFetchedAt     *time.Time `json:"last_fetched_at"`
+}
+
+// DeadFeedsResult is the output of the deadfeeds command
+type DeadFeedsResult struct {
+	Feeds []DeadFeedJSON `json:"feeds"`
+}
+
+// FollowJSON is a followed feed as printed by following
+type FollowJSON struct {
+	FeedID uuid.UUID `json:"feed_id"`
+	Name   string    `json:"name"`
+	URL    string    `json:"url"`
+	Groups []string  `json:"groups,omitempty"`
+}
+
+// FollowingResult is the output of the following command
+type FollowingResult struct {
+	User  string       `json:"user"`
+	Feeds []FollowJSON `json:"feeds"`
+}
+
+// GroupJSON is a feed group as printed by group list
+type GroupJSON struct {
+	ID    uuid.UUID `json:"id"`
+	Name  string    `json:"name"`
+	Feeds int64     `json:"feeds"`
+}
+
+// GroupsResult is the output of group list
+type GroupsResult struct {
+	Groups []GroupJSON `json:"groups"`
+}
+
+// GroupFeedJSON is a feed as printed by group feeds
+type GroupFeedJSON struct {
+	ID   uuid.UUID `json:"id"`
+	Name string    `json:"name"`
+	URL  string    `json:"url"`
+}
+
+// GroupFeedsResult is the output of group feeds
+type GroupFeedsResult struct {
+	Group string          `json:"group"`
+	Feeds []GroupFeedJSON `json:"feeds"`
+}
+
+// UserJSON is a user as printed by users
+type UserJSON struct {
+	Name    string `json:"name"`
+	Current bool   `json:"current"`
+}
+
+// UsersResult is the output of the users command
+type UsersResult struct {
+	Users []UserJSON `json:"users"`
+}
+
+// ProbeJSON is one feed check as printed by check
+type ProbeJSON struct {
+	URL         string `json:"url"`
+	StatusCode  int    `json:"status_code,omitempty"`
+	DurationMS  int64  `json:"duration_ms"`
+	FinalURL    string `json:"final_url,omitempty"`
+	ContentType string `json:"content_type,omitempty"`
+	Format      string `json:"format,omitempty"`
+	Error       string `json:"error,omitempty"`
+}
+
+// CheckResult is the output of the check command
+type CheckResult struct {
+	Feeds []ProbeJSON `json:"feeds"`
+}
+
+// ValidateCheck is one diagnostic as printed by validate
+type ValidateCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// ValidateResult is the output of the validate command; Status is the worst of the checks
+type ValidateResult struct {
+	Status string          `json:"status"`
+	Checks []ValidateCheck `json:"checks"`
+}
+
+// VersionResult is the output of the version command
+type VersionResult struct {
+	Module    string `json:"module"`
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`
+	BuildDate string `json:"build_date,omitempty"`
+	GoVersion string `json:"go_version"`
+}
+
+// QueueItemJSON is a queued post as printed by queue list
+type QueueItemJSON struct {
+	Position    int32     `json:"position"`
+	Status      string    `json:"status"`
+	PostID      uuid.UUID `json:"post_id"`
+	Title       string    `json:"title"`
+	URL         string    `json:"url"`
+	FeedName    string    `json:"feed_name"`
+	AddedAt     time.Time `json:"added_at"`
+	ReadingMins int       `json:"reading_minutes,omitempty"`
+}
+
+// QueueResult is the output of queue list
+type QueueResult struct {
+	Items []QueueItemJSON `json:"items"`
+}
+
+// FilterJSON is a keyword filter as printed by filter list
+type FilterJSON struct {
+	ID        uuid.UUID `json:"id"`
+	Type      string    `json:"type"`
+	Field     string    `json:"field"`
+	Keyword   string    `json:"keyword"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// FiltersResult is the output of filter list
+type FiltersResult struct {
+	Filters []FilterJSON `json:"filters"`
+}
+
+// TagRuleJSON is an autotag rule as printed by autotag list
+type TagRuleJSON struct {
+	ID            uuid.UUID `json:"id"`
+	Tag           string    `json:"tag"`
+	Field         string    `json:"field"`
+	CaseSensitive bool      `json:"case_sensitive"`
+	Keyword       string    `json:"keyword"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// TagRulesResult is the output of autotag list
+type TagRulesResult struct {
+	Rules []TagRuleJSON `json:"rules"`
+}
+
+// APIKeyJSON is an API key as printed by apikey list; only its prefix is shown, never
+// the hash
+type APIKeyJSON struct {
+	Prefix     string     `json:"prefix"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// APIKeysResult is the output of apikey list
+type APIKeysResult struct {
+	Keys []APIKeyJSON `json:"keys"`
+}
+
+// WebhookJSON is a webhook as printed by webhook list; its signing secret is left out
+type WebhookJSON struct {
+	ID        uuid.UUID `json:"id"`
+	URL       string    `json:"url"`
+	FeedURL   string    `json:"feed_url,omitempty"`
+	Keyword   string    `json:"keyword,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhooksResult is the output of webhook list
+type WebhooksResult struct {
+	Webhooks []WebhookJSON `json:"webhooks"`
+}
+
+// AggRunJSON is an aggregator run as printed by stats
+type AggRunJSON struct {
+	FinishedAt   time.Time `json:"finished_at"`
+	FeedsFetched int32     `json:"feeds_fetched"`
+	FeedsFailed  int32     `json:"feeds_failed"`
+	PostsSaved   int32     `json:"posts_saved"`
+}
+
+// StatsResult is the output of the stats command; Since is only set with --period
+type StatsResult struct {
+	User                string      `json:"user"`
+	Since               *time.Time  `json:"since,omitempty"`
+	FeedsFollowed       int64       `json:"feeds_followed"`
+	Posts               int64       `json:"posts"`
+	Read                int64       `json:"read"`
+	Bookmarked          int64       `json:"bookmarked"`
+	OldestPost          *time.Time  `json:"oldest_post"`
+	NewestPost          *time.Time  `json:"newest_post"`
+	MostActiveFeed      string      `json:"most_active_feed,omitempty"`
+	MostActiveFeedPosts int64       `json:"most_active_feed_posts,omitempty"`
+	LastAggregation     *AggRunJSON `json:"last_aggregation"`
+}
+
+// ReadingStatsJSON is one period's reading as printed by readstats
+type ReadingStatsJSON struct {
+	PostsRead         int64   `json:"posts_read"`
+	ReadsPerDay       []int64 `json:"reads_per_day"`
+	MostReadFeed      string  `json:"most_read_feed,omitempty"`
+	MostReadFeedPosts int64   `json:"most_read_feed_posts,omitempty"`
+	AvgReadMinutes    int     `json:"avg_read_minutes,omitempty"`
+	Bookmarked        int64   `json:"bookmarked"`
+	Pinned            int64   `json:"pinned"`
+}
+
+// ReadStatsResult is the output of the readstats command. Days is 0 for --period all;
+// Previous is only set with --compare.
+type ReadStatsResult struct {
+	User     string            `json:"user"`
+	Days     int               `json:"days"`
+	Current  ReadingStatsJSON  `json:"current"`
+	Previous *ReadingStatsJSON `json:"previous,omitempty"`
+}
+
+// FrequencyJSON is a feed's publishing rate as printed by frequency; the peaks are in
+// UTC and left out for feeds without posts in the period
+type FrequencyJSON struct {
+	Rank         int        `json:"rank"`
+	FeedID       uuid.UUID  `json:"feed_id"`
+	Name         string     `json:"name"`
+	URL          string     `json:"url"`
+	Posts        int64      `json:"posts"`
+	PostsPerWeek float64    `json:"posts_per_week"`
+	PeakDay      string     `json:"peak_day,omitempty"`
+	PeakHour     *int32     `json:"peak_hour,omitempty"`
+	NewestPost   *time.Time `json:"newest_post,omitempty"`
+}
+
+// FrequencyResult is the output of the frequency command
+type FrequencyResult struct {
+	Since time.Time       `json:"since"`
+	Feeds []FrequencyJSON `json:"feeds"`
+}
+
+// PodcastJSON is a feed with episodes as printed by podcast list
+type PodcastJSON struct {
+	ID       uuid.UUID `json:"id"`
+	Name     string    `json:"name"`
+	URL      string    `json:"url"`
+	Episodes int64     `json:"episodes"`
+	Latest   time.Time `json:"latest"`
+}
+
+// PodcastsResult is the output of podcast list
+type PodcastsResult struct {
+	Podcasts []PodcastJSON `json:"podcasts"`
+}
+
+// EpisodeJSON is an episode as printed by podcast list <feed-url>
+type EpisodeJSON struct {
+	PostID      uuid.UUID  `json:"post_id"`
+	Title       string     `json:"title"`
+	PublishedAt *time.Time `json:"published_at"`
+	MediaURL    string     `json:"media_url"`
+	MediaType   string     `json:"media_type,omitempty"`
+	Length      int64      `json:"length,omitempty"`
+}
+
+// EpisodesResult is the output of podcast list <feed-url>
+type EpisodesResult struct {
+	Feed     string        `json:"feed"`
+	Episodes []EpisodeJSON `json:"episodes"`
+}
+
+// WriteJSON encodes result as a single JSON document
+func WriteJSON(w io.Writer, result any) error {
+	return json.NewEncoder(w).Encode(result)
+}
+
+// WritePostsCSV writes posts as CSV with a header row
+func WritePostsCSV(w io.Writer, posts []PostJSON) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"id", "title", "url", "published_at", "feed_id", "description"}); err != nil {
+		return err
+	}
+	for _, post := range posts {
+		record := []string{
+			post.ID.String(),
+			post.Title,
+			post.URL,
+			post.PublishedAt.Format(time.RFC3339),
+			post.FeedID.String(),
+			post.Description,
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
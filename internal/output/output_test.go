@@ -0,0 +1,45 @@
+package output
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+func TestParseFormat(t *testing.T) {
+	for in, want := range map[string]string{"": Text, "text": Text, "json": JSON, "csv": CSV} {
+		got, err := ParseFormat(in)
+		if err != nil || got != want {
+			t.Fatalf("ParseFormat(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("yaml"); err == nil {
+		t.Fatal("expected unknown format to fail")
+	}
+}
+
+func TestWritePostsCSV(t *testing.T) {
+	id := uuid.MustParse("7b1c6a24-3a4f-4a5d-9cb1-0c4f0d4f2a11")
+	feedID := uuid.MustParse("0d0c8c2e-52c9-4ad5-9b53-9e2f5d0e8a42")
+	posts := []PostJSON{{
+		ID:          id,
+		Title:       `Commas, "quotes"`,
+		URL:         "https://example.com/a",
+		Description: "line one\nline two",
+		PublishedAt: time.Date(2024, 5, 1, 9, 30, 0, 0, time.UTC),
+		FeedID:      feedID,
+	}}
+
+	var buf bytes.Buffer
+	if err := WritePostsCSV(&buf, posts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := "id,title,url,published_at,feed_id,description\n" +
+		id.String() + `,"Commas, ""quotes""",https://example.com/a,2024-05-01T09:30:00Z,` + feedID.String() + ",\"line one\nline two\"\n"
+	if buf.String() != want {
+		t.Fatalf("unexpected CSV:\n%s\nwant:\n%s", buf.String(), want)
+	}
+}
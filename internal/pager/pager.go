@@ -0,0 +1,109 @@
+// Package pager shows long text through the user's pager.
+package pager
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"regexp"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// ansiCodes matches the colour and style escapes NO_COLOR asks us to leave out
+var ansiCodes = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+
+// Command returns the pager to run: $PAGER when set, otherwise less, with -R so it
+// passes colours through unless NO_COLOR is set
+func Command() []string {
+	if pager := strings.Fields(os.Getenv("PAGER")); len(pager) > 0 {
+		return pager
+	}
+	if NoColor() {
+		return []string{"less"}
+	}
+	return []string{"less", "-R"}
+}
+
+// NoColor reports whether the user asked for output without colour (https://no-color.org)
+func NoColor() bool {
+	return os.Getenv("NO_COLOR") != ""
+}
+
+// StartPager starts the pager on gator's stdout and returns the writer that feeds it,
+// along with a wait function that closes the writer and waits for the user to quit the
+// pager. When stdout isn't a terminal or the pager can't be found, the writer is stdout
+// itself. ANSI styles are stripped under NO_COLOR.
+func StartPager() (io.WriteCloser, func() error, error) {
+	return start(os.Stdout, term.IsTerminal(int(os.Stdout.Fd())))
+}
+
+// start is StartPager for out, which the pager only takes over when it's a terminal
+func start(out io.Writer, isTerminal bool) (io.WriteCloser, func() error, error) {
+	direct := func() (io.WriteCloser, func() error, error) {
+		return writeCloser{stripStyles(out), nil}, func() error { return nil }, nil
+	}
+	if !isTerminal {
+		return direct()
+	}
+
+	args := Command()
+	path, err := exec.LookPath(args[0])
+	if err != nil {
+		return direct()
+	}
+	cmd := exec.Command(path, args[1:]...)
+	cmd.Stdout = out
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't run pager %s: %w", args[0], err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, nil, fmt.Errorf("couldn't run pager %s: %w", args[0], err)
+	}
+	wait := func() error {
+		stdin.Close()
+		if err := cmd.Wait(); err != nil {
+			return fmt.Errorf("couldn't run pager %s: %w", args[0], err)
+		}
+		return nil
+	}
+	return writeCloser{stripStyles(stdin), stdin.Close}, wait, nil
+}
+
+// stripStyles drops ANSI styles from whatever is written to w under NO_COLOR. Each write
+// is cleaned on its own, which is enough for output written a line or more at a time.
+func stripStyles(w io.Writer) io.Writer {
+	if !NoColor() {
+		return w
+	}
+	return styleStripper{w}
+}
+
+type styleStripper struct {
+	w io.Writer
+}
+
+func (s styleStripper) Write(p []byte) (int, error) {
+	if _, err := s.w.Write(ansiCodes.ReplaceAll(p, nil)); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// writeCloser pairs a writer with how to close it; without close, as for stdout,
+// closing does nothing
+type writeCloser struct {
+	io.Writer
+	close func() error
+}
+
+func (c writeCloser) Close() error {
+	if c.close == nil {
+		return nil
+	}
+	return c.close()
+}
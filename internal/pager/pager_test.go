@@ -0,0 +1,73 @@
+package pager
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"testing"
+)
+
+func TestCommand(t *testing.T) {
+	t.Setenv("PAGER", "")
+	t.Setenv("NO_COLOR", "")
+	if got := Command(); !reflect.DeepEqual(got, []string{"less", "-R"}) {
+		t.Errorf("default pager = %v, want less -R", got)
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	if got := Command(); !reflect.DeepEqual(got, []string{"less"}) {
+		t.Errorf("NO_COLOR pager = %v, want plain less", got)
+	}
+
+	t.Setenv("PAGER", "more -d")
+	if got := Command(); !reflect.DeepEqual(got, []string{"more", "-d"}) {
+		t.Errorf("$PAGER = %v, want more -d", got)
+	}
+}
+
+func TestStartWithoutTerminal(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	var out bytes.Buffer
+	w, wait, err := start(&out, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	io.WriteString(w, "\x1b[1mbold\x1b[22m\n")
+	if err := wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "\x1b[1mbold\x1b[22m\n" {
+		t.Errorf("got %q", out.String())
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	out.Reset()
+	w, wait, err = start(&out, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	io.WriteString(w, "\x1b[1mbold\x1b[22m\n")
+	if err := wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "bold\n" {
+		t.Errorf("NO_COLOR output kept styles: %q", out.String())
+	}
+}
+
+func TestStartPipesThroughPager(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+	t.Setenv("PAGER", "tr a-z A-Z")
+	var out bytes.Buffer
+	w, wait, err := start(&out, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	io.WriteString(w, "paged\n")
+	if err := wait(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "PAGED\n" {
+		t.Errorf("pager output = %q, want PAGED", out.String())
+	}
+}
@@ -0,0 +1,160 @@
+// Package podcast downloads podcast episodes, the media files enclosed in feed items.
+package podcast
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// redrawInterval throttles progress updates so fast downloads don't flood the terminal
+const redrawInterval = 100 * time.Millisecond
+
+// illegalChars can't appear in file names on Windows, and / can't anywhere
+const illegalChars = `<>:"/\|?*`
+
+// mediaExtensions covers the usual podcast types; mime.ExtensionsByType sorts its
+// answers alphabetically, which would save MP3s as .m2a on many systems
+var mediaExtensions = map[string]string{
+	"audio/mpeg":  ".mp3",
+	"audio/mp4":   ".m4a",
+	"audio/x-m4a": ".m4a",
+	"audio/aac":   ".aac",
+	"audio/ogg":   ".ogg",
+	"audio/opus":  ".opus",
+	"video/mp4":   ".mp4",
+}
+
+// SafeFilename strips characters that aren't allowed in file names, along with control
+// characters and leading or trailing dots and spaces. An empty result becomes "episode".
+func SafeFilename(name string) string {
+	cleaned := strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(illegalChars, r) {
+			return -1
+		}
+		return r
+	}, name)
+	cleaned = strings.Trim(cleaned, " .")
+	if cleaned == "" {
+		return "episode"
+	}
+	return cleaned
+}
+
+// EpisodePath returns where an episode is saved: dir/<feed name>/<episode title>.<ext>
+func EpisodePath(dir, feedName, title, mediaURL, mediaType string) string {
+	return filepath.Join(dir, SafeFilename(feedName), SafeFilename(title)+Extension(mediaURL, mediaType))
+}
+
+// Extension returns the file extension for an episode, taken from its URL's path or,
+// failing that, its media type; ".bin" when neither gives one
+func Extension(mediaURL, mediaType string) string {
+	if parsed, err := neturl.Parse(mediaURL); err == nil {
+		if ext := path.Ext(parsed.Path); ext != "" && len(ext) <= 6 {
+			return strings.ToLower(ext)
+		}
+	}
+	mediaType, _, _ = mime.ParseMediaType(mediaType)
+	if ext, ok := mediaExtensions[mediaType]; ok {
+		return ext
+	}
+	if exts, err := mime.ExtensionsByType(mediaType); err == nil && len(exts) > 0 {
+		return exts[0]
+	}
+	return ".bin"
+}
+
+// Download saves the file at mediaURL to dest, reporting progress on a single line of
+// progressOut. The data goes to dest+".part" first so an interrupted download never
+// looks finished.
+func Download(ctx context.Context, client *http.Client, mediaURL, dest string, progressOut io.Writer) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, mediaURL, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("User-Agent", "gator")
+	resp, err := client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("server answered %s", resp.Status)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return 0, err
+	}
+	partial := dest + ".part"
+	file, err := os.Create(partial)
+	if err != nil {
+		return 0, err
+	}
+
+	bar := &progress{out: progressOut, total: resp.ContentLength}
+	written, err := io.Copy(file, io.TeeReader(resp.Body, bar))
+	bar.finish()
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(partial)
+		return written, err
+	}
+	return written, os.Rename(partial, dest)
+}
+
+// progress counts the bytes written through it and redraws a one-line display
+type progress struct {
+	out     io.Writer
+	total   int64 // -1 when the server didn't say
+	written int64
+	drawn   time.Time
+}
+
+func (p *progress) Write(b []byte) (int, error) {
+	p.written += int64(len(b))
+	if time.Since(p.drawn) >= redrawInterval {
+		p.draw()
+	}
+	return len(b), nil
+}
+
+func (p *progress) draw() {
+	p.drawn = time.Now()
+	if p.total > 0 {
+		fmt.Fprintf(p.out, "\r%s / %s  %3d%%", formatBytes(p.written), formatBytes(p.total), p.written*100/p.total)
+		return
+	}
+	fmt.Fprintf(p.out, "\r%s", formatBytes(p.written))
+}
+
+// finish draws the final state and ends the line
+func (p *progress) finish() {
+	p.draw()
+	fmt.Fprintln(p.out)
+}
+
+// formatBytes renders a size in B, KB, MB or GB
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	value, suffix := float64(n)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
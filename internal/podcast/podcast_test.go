@@ -0,0 +1,83 @@
+package podcast
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSafeFilename(t *testing.T) {
+	cases := map[string]string{
+		"Episode 1: The Beginning":  "Episode 1 The Beginning",
+		`a/b\c<d>e|f?g*h"i`:         "abcdefghi",
+		"  ..hidden..  ":            "hidden",
+		"tab\there":                 "tabhere",
+		"???":                       "episode",
+		"Ünïcode is fine — really!": "Ünïcode is fine — really!",
+	}
+	for in, want := range cases {
+		if got := SafeFilename(in); got != want {
+			t.Errorf("SafeFilename(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestEpisodePath(t *testing.T) {
+	cases := []struct {
+		url, mediaType, want string
+	}{
+		{"https://cdn.example.com/ep1.MP3?token=x", "audio/mpeg", filepath.Join("pods", "Show: Name", "Ep 1.mp3")},
+		{"https://cdn.example.com/download/123", "audio/mpeg", filepath.Join("pods", "Show: Name", "Ep 1.mp3")},
+		{"https://cdn.example.com/download/123", "", filepath.Join("pods", "Show: Name", "Ep 1.bin")},
+	}
+	for _, tc := range cases {
+		// The feed name is sanitized too, so the colon goes
+		want := strings.Replace(tc.want, "Show: Name", "Show Name", 1)
+		if got := EpisodePath("pods", "Show: Name", "Ep 1", tc.url, tc.mediaType); got != want {
+			t.Errorf("EpisodePath(%q, %q) = %q, want %q", tc.url, tc.mediaType, got, want)
+		}
+	}
+}
+
+func TestDownload(t *testing.T) {
+	audio := bytes.Repeat([]byte("x"), 5000)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing.mp3" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(audio)))
+		w.Write(audio)
+	}))
+	defer srv.Close()
+
+	dest := filepath.Join(t.TempDir(), "Show", "Episode.mp3")
+	var progressOut bytes.Buffer
+	n, err := Download(context.Background(), srv.Client(), srv.URL+"/ep.mp3", dest, &progressOut)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if n != int64(len(audio)) {
+		t.Errorf("wrote %d bytes, want %d", n, len(audio))
+	}
+	if saved, err := os.ReadFile(dest); err != nil || !bytes.Equal(saved, audio) {
+		t.Errorf("saved file doesn't match the download (err %v)", err)
+	}
+	if out := progressOut.String(); !strings.HasPrefix(out, "\r") || !strings.HasSuffix(out, "4.9 KB / 4.9 KB  100%\n") {
+		t.Errorf("unexpected progress output %q", out)
+	}
+
+	missing := filepath.Join(filepath.Dir(dest), "Missing.mp3")
+	if _, err := Download(context.Background(), srv.Client(), srv.URL+"/missing.mp3", missing, &progressOut); err == nil {
+		t.Error("expected an error for a 404")
+	}
+	if _, err := os.Stat(missing + ".part"); !os.IsNotExist(err) {
+		t.Error("failed download left a partial file behind")
+	}
+}
@@ -0,0 +1,101 @@
+// Package proxy builds the HTTP transport used for all outgoing requests,
+// routing them through an optional HTTP or SOCKS5 proxy.
+package proxy
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy types accepted in the config
+const (
+	TypeHTTP   = "http"
+	TypeSOCKS5 = "socks5"
+)
+
+// NewTransport returns a transport that sends requests through proxyURL, or
+// directly when proxyURL is empty. Credentials in the URL (user:pass@) are used
+// for proxy authentication. Hosts matching an entry in noProxy bypass the proxy.
+// An empty proxyType is inferred from the URL scheme.
+func NewTransport(proxyURL, proxyType string, noProxy []string) (*http.Transport, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if proxyURL == "" {
+		return transport, nil
+	}
+
+	parsed, err := Parse(proxyURL, proxyType)
+	if err != nil {
+		return nil, err
+	}
+	if proxyType == "" {
+		proxyType = TypeHTTP
+		if parsed.Scheme == "socks5" || parsed.Scheme == "socks5h" {
+			proxyType = TypeSOCKS5
+		}
+	}
+
+	switch proxyType {
+	case TypeHTTP:
+		transport.Proxy = func(req *http.Request) (*url.URL, error) {
+			if Bypass(req.URL.Hostname(), noProxy) {
+				return nil, nil
+			}
+			return parsed, nil
+		}
+	case TypeSOCKS5:
+		dialer := &socks5Dialer{proxyAddr: parsed.Host, auth: parsed.User}
+		direct := transport.DialContext
+		transport.Proxy = nil
+		transport.DialContext = dialer.dialContext(direct, noProxy)
+	default:
+		return nil, fmt.Errorf("unsupported proxy type %q: use %s or %s", proxyType, TypeHTTP, TypeSOCKS5)
+	}
+	return transport, nil
+}
+
+// Parse validates a proxy URL. proxyType, if set, must agree with the scheme.
+func Parse(proxyURL, proxyType string) (*url.URL, error) {
+	parsed, err := url.Parse(proxyURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	if parsed.Host == "" {
+		return nil, fmt.Errorf("invalid proxy URL %q: missing host", proxyURL)
+	}
+
+	switch parsed.Scheme {
+	case "http", "https":
+		if proxyType == TypeSOCKS5 {
+			return nil, fmt.Errorf("proxy type socks5 needs a socks5:// URL")
+		}
+	case "socks5", "socks5h":
+		if proxyType == TypeHTTP {
+			return nil, fmt.Errorf("proxy type http needs an http:// or https:// URL")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme %q: use http, https or socks5", parsed.Scheme)
+	}
+	return parsed, nil
+}
+
+// Bypass reports whether host matches a no-proxy entry. An entry matches the
+// domain itself and all of its subdomains; a leading dot is optional.
+func Bypass(host string, noProxy []string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	for _, entry := range noProxy {
+		domain := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(entry), "."))
+		if domain == "" {
+			continue
+		}
+		if domain == "*" || host == domain || strings.HasSuffix(host, "."+domain) {
+			return true
+		}
+	}
+	return false
+}
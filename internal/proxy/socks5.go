@@ -0,0 +1,179 @@
+package proxy
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// SOCKS5 constants from RFC 1928 and RFC 1929
+const (
+	socksVersion        = 0x05
+	socksAuthNone       = 0x00
+	socksAuthPassword   = 0x02
+	socksAuthNoMatch    = 0xff
+	socksCmdConnect     = 0x01
+	socksAddrIPv4       = 0x01
+	socksAddrDomain     = 0x03
+	socksAddrIPv6       = 0x04
+	socksPasswordVer    = 0x01
+	socksReplySucceeded = 0x00
+)
+
+// socks5Dialer opens TCP connections through a SOCKS5 proxy. Host names are
+// resolved by the proxy, which keeps DNS lookups on the proxy side (as Tor expects).
+type socks5Dialer struct {
+	proxyAddr string
+	auth      *url.Userinfo
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// dialContext returns a DialContext that tunnels through the proxy, except for
+// hosts in noProxy, which are dialled directly
+func (d *socks5Dialer) dialContext(direct dialFunc, noProxy []string) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		host, _, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if Bypass(host, noProxy) {
+			return direct(ctx, network, addr)
+		}
+
+		conn, err := direct(ctx, "tcp", d.proxyAddr)
+		if err != nil {
+			return nil, fmt.Errorf("couldn't reach SOCKS5 proxy: %w", err)
+		}
+		if err := d.handshake(ctx, conn, addr); err != nil {
+			conn.Close()
+			return nil, err
+		}
+		return conn, nil
+	}
+}
+
+// handshake negotiates authentication and asks the proxy to connect to addr
+func (d *socks5Dialer) handshake(ctx context.Context, conn net.Conn, addr string) error {
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	methods := []byte{socksAuthNone}
+	if d.auth != nil {
+		methods = []byte{socksAuthNone, socksAuthPassword}
+	}
+	if _, err := conn.Write(append([]byte{socksVersion, byte(len(methods))}, methods...)); err != nil {
+		return fmt.Errorf("socks5 greeting: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5 greeting: %w", err)
+	}
+	if reply[0] != socksVersion {
+		return fmt.Errorf("socks5: unexpected protocol version %d", reply[0])
+	}
+	switch reply[1] {
+	case socksAuthNone:
+	case socksAuthPassword:
+		if d.auth == nil {
+			return errors.New("socks5: proxy requires a username and password")
+		}
+		if err := d.authenticate(conn); err != nil {
+			return err
+		}
+	case socksAuthNoMatch:
+		return errors.New("socks5: proxy rejected all authentication methods")
+	default:
+		return fmt.Errorf("socks5: unsupported authentication method %d", reply[1])
+	}
+
+	host, portText, err := net.SplitHostPort(addr)
+	if err != nil {
+		return err
+	}
+	port, err := strconv.ParseUint(portText, 10, 16)
+	if err != nil {
+		return fmt.Errorf("socks5: invalid port %q", portText)
+	}
+
+	req := []byte{socksVersion, socksCmdConnect, 0x00}
+	if ip := net.ParseIP(host); ip != nil {
+		if ip4 := ip.To4(); ip4 != nil {
+			req = append(append(req, socksAddrIPv4), ip4...)
+		} else {
+			req = append(append(req, socksAddrIPv6), ip.To16()...)
+		}
+	} else {
+		if len(host) > 255 {
+			return fmt.Errorf("socks5: host name too long: %s", host)
+		}
+		req = append(append(req, socksAddrDomain, byte(len(host))), host...)
+	}
+	req = binary.BigEndian.AppendUint16(req, uint16(port))
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("socks5 connect: %w", err)
+	}
+
+	// VER REP RSV ATYP, then the bound address, which isn't needed
+	header := make([]byte, 4)
+	if _, err := io.ReadFull(conn, header); err != nil {
+		return fmt.Errorf("socks5 connect: %w", err)
+	}
+	if header[1] != socksReplySucceeded {
+		return fmt.Errorf("socks5: proxy couldn't connect to %s (reply code %d)", addr, header[1])
+	}
+	var skip int
+	switch header[3] {
+	case socksAddrIPv4:
+		skip = net.IPv4len
+	case socksAddrIPv6:
+		skip = net.IPv6len
+	case socksAddrDomain:
+		length := make([]byte, 1)
+		if _, err := io.ReadFull(conn, length); err != nil {
+			return fmt.Errorf("socks5 connect: %w", err)
+		}
+		skip = int(length[0])
+	default:
+		return fmt.Errorf("socks5: unknown address type %d", header[3])
+	}
+	if _, err := io.CopyN(io.Discard, conn, int64(skip+2)); err != nil {
+		return fmt.Errorf("socks5 connect: %w", err)
+	}
+	return nil
+}
+
+// authenticate sends the username and password (RFC 1929)
+func (d *socks5Dialer) authenticate(conn net.Conn) error {
+	user := d.auth.Username()
+	pass, _ := d.auth.Password()
+	if len(user) > 255 || len(pass) > 255 {
+		return errors.New("socks5: username or password too long")
+	}
+
+	req := []byte{socksPasswordVer, byte(len(user))}
+	req = append(req, user...)
+	req = append(req, byte(len(pass)))
+	req = append(req, pass...)
+	if _, err := conn.Write(req); err != nil {
+		return fmt.Errorf("socks5 auth: %w", err)
+	}
+
+	reply := make([]byte, 2)
+	if _, err := io.ReadFull(conn, reply); err != nil {
+		return fmt.Errorf("socks5 auth: %w", err)
+	}
+	if reply[1] != 0x00 {
+		return errors.New("socks5: proxy rejected the username or password")
+	}
+	return nil
+}
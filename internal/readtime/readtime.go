@@ -0,0 +1,52 @@
+// Package readtime estimates how long a post takes to read.
+package readtime
+
+import (
+	"html"
+	"math"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultWPM is the reading speed assumed when none is configured
+const DefaultWPM = 200
+
+var (
+	// hiddenElements are dropped along with their contents, which nobody reads
+	hiddenElements = regexp.MustCompile(`(?is)<(script|style)\b.*?</(script|style)\s*>`)
+	tags           = regexp.MustCompile(`(?s)<[^>]*>`)
+)
+
+// EstimateReadingTime estimates how long text, which may contain HTML, takes to read
+// at DefaultWPM. Empty text takes 0.
+func EstimateReadingTime(text string) time.Duration {
+	return EstimateReadingTimeAt(text, DefaultWPM)
+}
+
+// EstimateReadingTimeAt is EstimateReadingTime at wpm words per minute; wpm <= 0 means
+// DefaultWPM
+func EstimateReadingTimeAt(text string, wpm int) time.Duration {
+	return ForWords(CountWords(text), wpm)
+}
+
+// ForWords is how long the given number of words takes to read at wpm words per minute;
+// wpm <= 0 means DefaultWPM
+func ForWords(words, wpm int) time.Duration {
+	if wpm <= 0 {
+		wpm = DefaultWPM
+	}
+	return time.Duration(words) * time.Minute / time.Duration(wpm)
+}
+
+// CountWords counts the words in text once HTML tags are stripped and entities decoded
+func CountWords(text string) int {
+	text = hiddenElements.ReplaceAllString(text, " ")
+	text = tags.ReplaceAllString(text, " ")
+	return len(strings.Fields(html.UnescapeString(text)))
+}
+
+// Minutes rounds an estimate up to whole minutes, so any text reads as at least 1 min
+func Minutes(d time.Duration) int {
+	return int(math.Ceil(d.Minutes()))
+}
@@ -0,0 +1,55 @@
+package readtime
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCountWords(t *testing.T) {
+	cases := map[string]int{
+		"":                               0,
+		"   \n\t ":                       0,
+		"plain words here":               3,
+		"<p>Hello <b>bold</b> world</p>": 3,
+		"one<br/>two":                    2,
+		"fish &amp; chips":               3,
+		"<style>p { color: red }</style><p>Only this</p><script>var x = 1;</script>": 2,
+	}
+	for in, want := range cases {
+		if got := CountWords(in); got != want {
+			t.Errorf("CountWords(%q) = %d, want %d", in, got, want)
+		}
+	}
+}
+
+func TestEstimateReadingTime(t *testing.T) {
+	if got := EstimateReadingTime(""); got != 0 {
+		t.Errorf("empty text: got %v, want 0", got)
+	}
+
+	words := strings.Repeat("word ", 500)
+	if got := EstimateReadingTime(words); got != 150*time.Second {
+		t.Errorf("500 words at 200 wpm: got %v, want 2m30s", got)
+	}
+	if got := EstimateReadingTimeAt(words, 250); got != 2*time.Minute {
+		t.Errorf("500 words at 250 wpm: got %v, want 2m", got)
+	}
+	if got := EstimateReadingTimeAt(words, 0); got != 150*time.Second {
+		t.Errorf("unset wpm should fall back to the default: got %v", got)
+	}
+}
+
+func TestMinutes(t *testing.T) {
+	cases := map[time.Duration]int{
+		0:                 0,
+		time.Second:       1,
+		time.Minute:       1,
+		150 * time.Second: 3,
+	}
+	for in, want := range cases {
+		if got := Minutes(in); got != want {
+			t.Errorf("Minutes(%v) = %d, want %d", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,212 @@
+// Package render turns stored post HTML into formatted text for the terminal.
+package render
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// ANSI escape sequences; each style is turned off on its own so nesting works
+const (
+	boldOn    = "\x1b[1m"
+	boldOff   = "\x1b[22m"
+	italicOn  = "\x1b[3m"
+	italicOff = "\x1b[23m"
+	codeOn    = "\x1b[36m"
+	codeOff   = "\x1b[39m"
+)
+
+// bullet starts each list item; wrapped item lines are indented to match
+const bullet = "• "
+
+var (
+	spaces     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+	ansiCodes  = regexp.MustCompile(`\x1b\[[0-9;]*m`)
+)
+
+// RenderHTML renders sanitized HTML as terminal text wrapped to width columns: paragraphs
+// are separated by blank lines, bold and italic use ANSI styles, code is coloured, links
+// show their URL in brackets and list items get bullets. A width <= 0 doesn't wrap.
+func RenderHTML(src string, width int) string {
+	nodes, err := html.ParseFragment(strings.NewReader(src), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+	if err != nil {
+		return ""
+	}
+	r := &renderer{width: width}
+	for _, n := range nodes {
+		r.walk(n)
+	}
+	r.flush()
+	return strings.TrimSpace(blankLines.ReplaceAllString(r.out.String(), "\n\n"))
+}
+
+// renderer collects inline text into paragraphs, which are wrapped as each block ends
+type renderer struct {
+	width  int
+	out    strings.Builder
+	inline strings.Builder
+	prefix string // starts the next paragraph, e.g. a bullet
+	pre    bool
+}
+
+func (r *renderer) walk(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		if r.pre {
+			r.out.WriteString(n.Data)
+			return
+		}
+		r.inline.WriteString(spaces.ReplaceAllString(n.Data, " "))
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.Data {
+	case "script", "style":
+		return
+	case "br":
+		if !r.flush() {
+			r.out.WriteString("\n")
+		}
+		return
+	case "img":
+		if alt := strings.TrimSpace(attr(n, "alt")); alt != "" {
+			r.inline.WriteString("[image: " + alt + "]")
+		}
+		return
+	case "p", "div", "blockquote", "ul", "ol", "h1", "h2", "h3", "h4", "h5", "h6":
+		r.block(func() { r.children(n) })
+		return
+	case "li":
+		r.flush()
+		r.prefix = bullet
+		r.children(n)
+		r.flush()
+		return
+	case "pre":
+		r.block(func() {
+			r.pre = true
+			r.out.WriteString(codeOn)
+			r.children(n)
+			r.out.WriteString(codeOff + "\n")
+			r.pre = false
+		})
+		return
+	case "strong", "b":
+		r.styled(n, boldOn, boldOff)
+	case "em", "i":
+		r.styled(n, italicOn, italicOff)
+	case "code":
+		if r.pre {
+			r.children(n)
+			return
+		}
+		r.styled(n, codeOn, codeOff)
+	case "a":
+		start := r.inline.Len()
+		r.children(n)
+		text := ""
+		// A block inside the link may have flushed the text already
+		if r.inline.Len() >= start {
+			text = strings.TrimSpace(ansiCodes.ReplaceAllString(r.inline.String()[start:], ""))
+		}
+		if href := strings.TrimSpace(attr(n, "href")); href != "" && href != text {
+			r.inline.WriteString(" [" + href + "]")
+		}
+	default:
+		r.children(n)
+	}
+}
+
+func (r *renderer) children(n *html.Node) {
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		r.walk(c)
+	}
+}
+
+func (r *renderer) styled(n *html.Node, on, off string) {
+	r.inline.WriteString(on)
+	r.children(n)
+	r.inline.WriteString(off)
+}
+
+// block sets what body writes apart from the text around it with blank lines
+func (r *renderer) block(body func()) {
+	r.flush()
+	r.out.WriteString("\n\n")
+	body()
+	r.flush()
+	r.out.WriteString("\n\n")
+}
+
+// flush wraps the pending inline text onto the output and reports whether there was any
+func (r *renderer) flush() bool {
+	text := strings.TrimSpace(r.inline.String())
+	prefix := r.prefix
+	r.inline.Reset()
+	r.prefix = ""
+	if ansiCodes.ReplaceAllString(text, "") == "" {
+		return false
+	}
+	r.out.WriteString(wrap(prefix+text, r.width, strings.Repeat(" ", utf8.RuneCountInString(prefix))))
+	r.out.WriteString("\n")
+	return true
+}
+
+// wrap breaks text into lines of at most width visible columns, starting each line
+// after the first with indent. Words longer than a line are left whole.
+func wrap(text string, width int, indent string) string {
+	if width <= 0 {
+		return text
+	}
+	var b strings.Builder
+	lineLen := 0
+	// A style code split off by a space sticks to the next word rather than making one
+	pending := ""
+	for _, word := range strings.Fields(text) {
+		wordLen := visibleLen(word)
+		if wordLen == 0 {
+			pending += word
+			continue
+		}
+		switch {
+		case b.Len() == 0:
+		case lineLen+1+wordLen > width:
+			b.WriteString("\n" + indent)
+			lineLen = len(indent)
+		default:
+			b.WriteString(" ")
+			lineLen++
+		}
+		b.WriteString(pending + word)
+		pending = ""
+		lineLen += wordLen
+	}
+	b.WriteString(pending)
+	return b.String()
+}
+
+// visibleLen counts the columns s takes up, ignoring ANSI escapes
+func visibleLen(s string) int {
+	return utf8.RuneCountInString(ansiCodes.ReplaceAllString(s, ""))
+}
+
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
@@ -0,0 +1,41 @@
+package render
+
+import "testing"
+
+func TestRenderHTML(t *testing.T) {
+	cases := map[string]string{
+		"":                                   "",
+		"plain   text":                       "plain text",
+		"<p>First</p><p>Second</p>":          "First\n\nSecond",
+		"one<br>two":                         "one\ntwo",
+		"<strong>bold</strong> and <b>b</b>": "\x1b[1mbold\x1b[22m and \x1b[1mb\x1b[22m",
+		"<em>it</em> <i>i</i>":               "\x1b[3mit\x1b[23m \x1b[3mi\x1b[23m",
+		"run <code>go test</code>":           "run \x1b[36mgo test\x1b[39m",
+		`<a href="https://go.dev">Go</a>`:    "Go [https://go.dev]",
+		`<a href="https://go.dev">https://go.dev</a>`: "https://go.dev",
+		"<p>Intro</p><ul><li>a</li><li>b</li></ul>":   "Intro\n\n• a\n• b",
+		"<pre>x := 1\n  y := 2</pre>":                 "\x1b[36mx := 1\n  y := 2\x1b[39m",
+		"fish &amp; chips":                            "fish & chips",
+		`<img src="a.png" alt="Chart">`:               "[image: Chart]",
+	}
+	for in, want := range cases {
+		if got := RenderHTML(in, 0); got != want {
+			t.Errorf("RenderHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestRenderHTMLWraps(t *testing.T) {
+	got := RenderHTML("<p>the quick brown fox jumps</p><ul><li>over the lazy dog</li></ul>", 12)
+	want := "the quick\nbrown fox\njumps\n\n• over the\n  lazy dog"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	// Styles don't count towards the width
+	got = RenderHTML("<b>aaaa bbbb</b> cccc", 9)
+	want = "\x1b[1maaaa bbbb\x1b[22m\ncccc"
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
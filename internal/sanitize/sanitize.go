@@ -0,0 +1,159 @@
+// Package sanitize cleans the HTML in feed descriptions down to a small, safe subset
+// before it's stored.
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+)
+
+// Policy is an allowlist of elements, each with the attributes it may keep. Elements
+// that aren't listed are unwrapped, keeping their text.
+type Policy map[string][]string
+
+// DefaultPolicy keeps basic formatting, links and images
+var DefaultPolicy = Policy{
+	"p":          nil,
+	"br":         nil,
+	"a":          {"href", "title"},
+	"strong":     nil,
+	"em":         nil,
+	"ul":         nil,
+	"ol":         nil,
+	"li":         nil,
+	"blockquote": nil,
+	"code":       nil,
+	"pre":        nil,
+	"img":        {"src", "alt"},
+}
+
+// dropped elements go along with everything inside them, whatever the policy says
+var dropped = map[string]bool{
+	"script":   true,
+	"style":    true,
+	"iframe":   true,
+	"object":   true,
+	"embed":    true,
+	"noscript": true,
+	"template": true,
+}
+
+// voidElements have no closing tag
+var voidElements = map[string]bool{
+	"br":  true,
+	"hr":  true,
+	"img": true,
+	"wbr": true,
+}
+
+// safeScheme matches the URL schemes links and images may use; relative URLs have none
+var safeScheme = regexp.MustCompile(`(?i)^(https?|mailto):`)
+
+// SanitizeHTML cleans raw with DefaultPolicy
+func SanitizeHTML(raw string) string {
+	return DefaultPolicy.Sanitize(raw)
+}
+
+// Sanitize parses raw as an HTML fragment and renders it again keeping only what p
+// allows. Event handler attributes (on*) and script URLs are removed even when listed,
+// as are 1x1 tracking images.
+func (p Policy) Sanitize(raw string) string {
+	nodes, err := parseFragment(raw)
+	if err != nil {
+		// The parser accepts any input, so this is a reader failure; keep nothing
+		return ""
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		p.render(&b, n)
+	}
+	return strings.TrimSpace(b.String())
+}
+
+func (p Policy) render(b *strings.Builder, n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		b.WriteString(html.EscapeString(n.Data))
+		return
+	case html.ElementNode:
+	default:
+		// Comments and doctypes carry nothing worth keeping
+		return
+	}
+
+	if dropped[n.Data] || isTrackingPixel(n) {
+		return
+	}
+	allowedAttrs, allowed := p[n.Data]
+	if allowed {
+		b.WriteString("<" + n.Data)
+		for _, attr := range n.Attr {
+			if keepAttr(attr, allowedAttrs) {
+				b.WriteString(" " + attr.Key + `="` + html.EscapeString(attr.Val) + `"`)
+			}
+		}
+		b.WriteString(">")
+		if voidElements[n.Data] {
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		p.render(b, c)
+	}
+	if allowed {
+		b.WriteString("</" + n.Data + ">")
+	}
+}
+
+// keepAttr reports whether attr is on the allowlist and harmless
+func keepAttr(attr html.Attribute, allowed []string) bool {
+	if attr.Namespace != "" || strings.HasPrefix(strings.ToLower(attr.Key), "on") {
+		return false
+	}
+	found := false
+	for _, key := range allowed {
+		if attr.Key == key {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return false
+	}
+	if attr.Key == "href" || attr.Key == "src" {
+		val := strings.TrimSpace(attr.Val)
+		if i := strings.IndexAny(val, ":/?#"); i >= 0 && val[i] == ':' && !safeScheme.MatchString(val) {
+			return false
+		}
+	}
+	return true
+}
+
+// isTrackingPixel spots the invisible images newsletters use to count opens
+func isTrackingPixel(n *html.Node) bool {
+	if n.Data != "img" {
+		return false
+	}
+	var width, height string
+	for _, attr := range n.Attr {
+		switch attr.Key {
+		case "width":
+			width = strings.TrimSpace(attr.Val)
+		case "height":
+			height = strings.TrimSpace(attr.Val)
+		}
+	}
+	return (width == "0" || width == "1") && (height == "0" || height == "1")
+}
+
+// parseFragment parses raw as the contents of a <div>
+func parseFragment(raw string) ([]*html.Node, error) {
+	return html.ParseFragment(strings.NewReader(raw), &html.Node{
+		Type:     html.ElementNode,
+		Data:     "div",
+		DataAtom: atom.Div,
+	})
+}
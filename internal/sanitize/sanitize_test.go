@@ -0,0 +1,58 @@
+package sanitize
+
+import "testing"
+
+func TestSanitizeHTML(t *testing.T) {
+	cases := map[string]string{
+		"plain text":                                                               "plain text",
+		"<p>Hello <strong>world</strong></p>":                                      "<p>Hello <strong>world</strong></p>",
+		`<p>Hi</p><script>alert("x")</script>`:                                     "<p>Hi</p>",
+		"<style>p{color:red}</style><em>styled</em>":                               "<em>styled</em>",
+		`<iframe src="https://ads.example.com"></iframe>`:                          "",
+		`<object data="x.swf"><embed src="x.swf"></object>after`:                   "after",
+		`<p onclick="steal()" class="lead">Click</p>`:                              "<p>Click</p>",
+		`<a href="https://example.com" onmouseover="x()" target="_blank">link</a>`: `<a href="https://example.com">link</a>`,
+		`<a href="javascript:alert(1)">bad</a>`:                                    "<a>bad</a>",
+		`<a href="/relative?a=1&b=2">rel</a>`:                                      `<a href="/relative?a=1&amp;b=2">rel</a>`,
+		`<img src="https://example.com/a.png" alt="A" width="600" onerror="x()">`:  `<img src="https://example.com/a.png" alt="A">`,
+		`<img src="https://track.example.com/p.gif" width="1" height="1">`:         "",
+		"<div><span>unwrapped</span></div>":                                        "unwrapped",
+		"<!-- comment -->text":                                                     "text",
+		"a < b &amp; c":                                                            "a &lt; b &amp; c",
+		"<ul><li>one</li><li>two</li></ul>":                                        "<ul><li>one</li><li>two</li></ul>",
+		"line<br>break":                                                            "line<br>break",
+	}
+	for in, want := range cases {
+		if got := SanitizeHTML(in); got != want {
+			t.Errorf("SanitizeHTML(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPolicySanitize(t *testing.T) {
+	policy := Policy{"p": nil, "a": {"href", "onclick"}}
+	got := policy.Sanitize(`<p><em>kept text</em> <a href="https://example.com" onclick="x()">a</a></p><script>x()</script>`)
+	want := `<p>kept text <a href="https://example.com">a</a></p>`
+	if got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestHTMLToText(t *testing.T) {
+	cases := map[string]string{
+		"":                                 "",
+		"plain   text":                     "plain text",
+		"<p>First</p><p>Second   para</p>": "First\n\nSecond para",
+		"one<br>two":                       "one\ntwo",
+		"<p>Intro</p><ul><li>a</li><li>b</li></ul>":    "Intro\n\n- a\n- b",
+		"fish &amp; chips":                             "fish & chips",
+		`<p>See <a href="https://x.test">this</a></p>`: "See this",
+		`<img src="a.png" alt="A chart">`:              "[A chart]",
+		"<pre>  indented\n    code</pre>":              "indented\n    code",
+	}
+	for in, want := range cases {
+		if got := HTMLToText(in); got != want {
+			t.Errorf("HTMLToText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
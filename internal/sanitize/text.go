@@ -0,0 +1,99 @@
+package sanitize
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// blockElements start on a line of their own
+var blockElements = map[string]bool{
+	"p":          true,
+	"div":        true,
+	"ul":         true,
+	"ol":         true,
+	"blockquote": true,
+	"pre":        true,
+	"h1":         true,
+	"h2":         true,
+	"h3":         true,
+	"h4":         true,
+	"h5":         true,
+	"h6":         true,
+}
+
+var (
+	spaces     = regexp.MustCompile(`[ \t\r\n\f]+`)
+	blankLines = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText renders sanitized HTML as plain text: paragraphs are separated by blank
+// lines, list items start with "- ", images show their alt text and preformatted text
+// keeps its spacing
+func HTMLToText(sanitized string) string {
+	nodes, err := parseFragment(sanitized)
+	if err != nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, n := range nodes {
+		writeText(&b, n, false)
+	}
+
+	lines := strings.Split(b.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " ")
+	}
+	return strings.TrimSpace(blankLines.ReplaceAllString(strings.Join(lines, "\n"), "\n\n"))
+}
+
+func writeText(b *strings.Builder, n *html.Node, pre bool) {
+	switch n.Type {
+	case html.TextNode:
+		if pre {
+			b.WriteString(n.Data)
+			return
+		}
+		text := spaces.ReplaceAllString(n.Data, " ")
+		// Don't start a line with the space that followed a tag
+		if b.Len() == 0 || strings.HasSuffix(b.String(), "\n") || strings.HasSuffix(b.String(), " ") {
+			text = strings.TrimLeft(text, " ")
+		}
+		b.WriteString(text)
+		return
+	case html.ElementNode:
+	default:
+		return
+	}
+
+	switch n.Data {
+	case "br":
+		b.WriteString("\n")
+		return
+	case "img":
+		for _, attr := range n.Attr {
+			if attr.Key == "alt" && strings.TrimSpace(attr.Val) != "" {
+				b.WriteString("[" + strings.TrimSpace(attr.Val) + "]")
+			}
+		}
+		return
+	}
+	if dropped[n.Data] {
+		return
+	}
+
+	// List items sit on consecutive lines, other blocks get a blank line around them
+	separator := ""
+	if blockElements[n.Data] {
+		separator = "\n\n"
+	}
+	b.WriteString(separator)
+	if n.Data == "li" {
+		b.WriteString("\n- ")
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		writeText(b, c, pre || n.Data == "pre")
+	}
+	b.WriteString(separator)
+}
@@ -0,0 +1,26 @@
+// Package spark draws small inline charts out of Unicode block characters.
+package spark
+
+import "strings"
+
+// blocks go from lowest to highest
+var blocks = []rune("▁▂▃▄▅▆▇█")
+
+// Line draws one block per value, scaled so the largest value gets a full block and
+// zero the lowest. Negative values count as zero.
+func Line(values []int64) string {
+	var highest int64
+	for _, v := range values {
+		highest = max(highest, v)
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		level := 0
+		if highest > 0 && v > 0 {
+			level = int(v * int64(len(blocks)-1) / highest)
+		}
+		b.WriteRune(blocks[level])
+	}
+	return b.String()
+}
@@ -0,0 +1,21 @@
+package spark
+
+import "testing"
+
+func TestLine(t *testing.T) {
+	cases := []struct {
+		values []int64
+		want   string
+	}{
+		{nil, ""},
+		{[]int64{0, 0, 0}, "▁▁▁"},
+		{[]int64{0, 7, 14}, "▁▄█"},
+		{[]int64{1, 2, 3, 4, 5, 6, 7, 8}, "▁▂▃▄▅▆▇█"},
+		{[]int64{-3, 5}, "▁█"},
+	}
+	for _, c := range cases {
+		if got := Line(c.values); got != c.want {
+			t.Errorf("Line(%v) = %q, want %q", c.values, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package tui
+
+import (
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Keybinding is a key the TUI responds to and what it does, as the help overlay lists it
+type Keybinding struct {
+	Key         string
+	Description string
+}
+
+// keybindings are every key the TUI handles. The help overlay is built from them, so a
+// new binding only needs adding here to show up there.
+var keybindings = []Keybinding{
+	{"j / ↓", "next item"},
+	{"k / ↑", "previous item"},
+	{"gg / G", "top / bottom"},
+	{"Enter", "select feed / show post / fold group"},
+	{"o", "open post in browser"},
+	{"b", "toggle bookmark"},
+	{"r", "mark post read (in the post panel)"},
+	{"A", "mark the feed's posts / the loaded posts read"},
+	{"Esc", "close the post panel or this help"},
+	{"Tab", "switch pane"},
+	{"q", "quit, or close this help"},
+	{"?", "this help"},
+}
+
+// helpOverlay lays the keybindings out as a table centred over whatever is behind it
+func helpOverlay() (*tview.Table, tview.Primitive) {
+	table := tview.NewTable()
+	table.SetBorder(true).SetTitle(" Keyboard shortcuts ")
+	keyWidth, descriptionWidth := 0, 0
+	for row, binding := range keybindings {
+		table.SetCell(row, 0, tview.NewTableCell(" "+binding.Key).SetAttributes(tcell.AttrBold))
+		table.SetCell(row, 1, tview.NewTableCell("  "+binding.Description+" "))
+		keyWidth = max(keyWidth, len([]rune(binding.Key))+1)
+		descriptionWidth = max(descriptionWidth, len([]rune(binding.Description))+3)
+	}
+
+	// Two for the border, one for the column gap
+	width, height := keyWidth+descriptionWidth+3, len(keybindings)+2
+	overlay := tview.NewFlex().
+		AddItem(nil, 0, 1, false).
+		AddItem(tview.NewFlex().SetDirection(tview.FlexRow).
+			AddItem(nil, 0, 1, false).
+			AddItem(table, height, 0, true).
+			AddItem(nil, 0, 1, false), width, 0, true).
+		AddItem(nil, 0, 1, false)
+	return table, overlay
+}
@@ -0,0 +1,91 @@
+package tui
+
+import (
+	"sort"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+)
+
+// Theme is a color scheme for the TUI.
+type Theme struct {
+	Background         tcell.Color
+	Text               tcell.Color // list item text
+	SelectedBackground tcell.Color
+	SelectedText       tcell.Color
+	Secondary          tcell.Color // secondary line under an item, e.g. the URL
+	Border             tcell.Color
+	Title              tcell.Color
+}
+
+// DefaultTheme is used when no theme is requested
+const DefaultTheme = "dark"
+
+// Themes are the color schemes selectable with tui --theme
+var Themes = map[string]Theme{
+	"dark": {
+		Background:         tcell.ColorBlack,
+		Text:               tcell.ColorWhite,
+		SelectedBackground: tcell.ColorDodgerBlue,
+		SelectedText:       tcell.ColorWhite,
+		Secondary:          tcell.ColorDarkCyan,
+		Border:             tcell.ColorGray,
+		Title:              tcell.ColorWhite,
+	},
+	"light": {
+		Background:         tcell.ColorWhite,
+		Text:               tcell.ColorBlack,
+		SelectedBackground: tcell.NewHexColor(0x0066cc),
+		SelectedText:       tcell.ColorWhite,
+		Secondary:          tcell.ColorDimGray,
+		Border:             tcell.ColorDarkGray,
+		Title:              tcell.ColorNavy,
+	},
+	"solarized": {
+		Background:         tcell.NewHexColor(0x002b36),
+		Text:               tcell.NewHexColor(0x839496),
+		SelectedBackground: tcell.NewHexColor(0x268bd2),
+		SelectedText:       tcell.NewHexColor(0xfdf6e3),
+		Secondary:          tcell.NewHexColor(0x2aa198),
+		Border:             tcell.NewHexColor(0x586e75),
+		Title:              tcell.NewHexColor(0xb58900),
+	},
+	"nord": {
+		Background:         tcell.NewHexColor(0x2e3440),
+		Text:               tcell.NewHexColor(0xd8dee9),
+		SelectedBackground: tcell.NewHexColor(0x88c0d0),
+		SelectedText:       tcell.NewHexColor(0x2e3440),
+		Secondary:          tcell.NewHexColor(0x81a1c1),
+		Border:             tcell.NewHexColor(0x4c566a),
+		Title:              tcell.NewHexColor(0x8fbcbb),
+	},
+}
+
+// ThemeNames returns the available theme names in alphabetical order
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// applyGlobalTheme sets tview's default styles, which cover backgrounds and the help modal.
+// It has to run before any primitive is created.
+func applyGlobalTheme(theme Theme) {
+	tview.Styles.PrimitiveBackgroundColor = theme.Background
+	tview.Styles.ContrastBackgroundColor = theme.SelectedBackground
+	tview.Styles.PrimaryTextColor = theme.Text
+	tview.Styles.SecondaryTextColor = theme.Secondary
+	tview.Styles.BorderColor = theme.Border
+	tview.Styles.TitleColor = theme.Title
+}
+
+// applyListTheme colors a list's items and selection
+func applyListTheme(list *tview.List, theme Theme) {
+	list.SetMainTextColor(theme.Text).
+		SetSecondaryTextColor(theme.Secondary).
+		SetSelectedBackgroundColor(theme.SelectedBackground).
+		SetSelectedTextColor(theme.SelectedText)
+}
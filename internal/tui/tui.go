@@ -1,45 +1,607 @@
 package tui
 
 import (
+	"context"
 	"fmt"
 	"log"
-	"os"
-	"os/exec"
+	"strings"
+	"time"
 
+	"gator/internal/browser"
+	"gator/internal/pager"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/google/uuid"
 	"github.com/rivo/tview"
 )
 
 // Post represents a simplified post for display in the TUI.
 type Post struct {
-	Title string
-	URL   string
+	ID              uuid.UUID
+	Title           string
+	URL             string
+	Read            bool
+	Bookmarked      bool
+	Pinned          bool
+	FeedID          uuid.UUID
+	FeedName        string
+	ReadingTimeMins int       // estimated reading time; 0 when unknown
+	Description     string    // plain text, shown in the detail panel
+	PublishedAt     time.Time // when the feed says it was published, or when it was saved
+}
+
+// Feed represents a followed feed in the TUI's feed pane.
+type Feed struct {
+	ID     uuid.UUID
+	Name   string
+	URL    string
+	Tags   []string
+	Health Health
+}
+
+// Health is how fetching a feed is going, shown by the colour of its name
+type Health int
+
+const (
+	HealthUnknown Health = iota // not judged; the name is shown as is
+	HealthOK                    // fetched within twice its interval
+	HealthStale                 // not fetched recently, or never
+	HealthFailing               // its last fetch failed
+)
+
+// healthMarks are the colour, and under NO_COLOR the prefix, for each judged Health
+var healthMarks = map[Health]struct{ color, prefix string }{
+	HealthOK:      {"green", "*"},
+	HealthStale:   {"yellow", "~"},
+	HealthFailing: {"red", "!"},
+}
+
+// Group is a feed group, shown in the feed pane as a heading that folds its feeds away
+type Group struct {
+	Name  string
+	Feeds []Feed
+}
+
+// feedRow is what a line of the feed pane stands for: "All feeds", a group heading or
+// a feed
+type feedRow struct {
+	group *Group
+	feed  *Feed
+}
+
+// Callbacks connect the TUI to storage; the handler provides them.
+type Callbacks struct {
+	// LoadPosts returns up to PageSize posts from offset on for a feed, with uuid.Nil for
+	// "All feeds". It's called with offset 0 when a feed is selected and again, off the UI
+	// goroutine, as the selection nears the end of the posts loaded so far. Errors are
+	// shown in the status bar.
+	LoadPosts func(feedID uuid.UUID, offset int) ([]Post, error)
+	// MarkRead is called when a post is opened so the read state can be persisted
+	MarkRead func(postID uuid.UUID) error
+	// SetBookmarked adds or removes a bookmark on a post
+	SetBookmarked func(postID uuid.UUID, bookmarked bool) error
+	// MarkAllRead marks every post of a feed read, or of every followed feed for uuid.Nil,
+	// and returns how many weren't read before
+	MarkAllRead func(feedID uuid.UUID) (int, error)
 }
 
-// StartTUI initializes and runs the terminal user interface
-func StartTUI(posts []Post) {
+// allFeedsLabel is the first entry of the feed pane; selecting it shows posts from every feed
+const allFeedsLabel = "All feeds"
+
+const (
+	// InitialPosts is how many posts the handler loads for the post pane on start
+	InitialPosts = 50
+	// PageSize is how many more posts are loaded each time the list nears its end
+	PageSize = 50
+	// loadMoreThreshold is how close to the last post the selection gets before more are loaded
+	loadMoreThreshold = 5
+)
+
+// loadingLabel is the last entry of the post pane while more posts are being fetched
+const loadingLabel = "Loading more posts..."
+
+// doubleTapWindow is how quickly the second g of gg has to follow the first
+const doubleTapWindow = 500 * time.Millisecond
+
+// StartSplitTUI runs a two-pane interface: followed feeds on the left, posts on the right.
+// Feeds in groups are listed under collapsible group headings, the rest after them.
+// initialPosts fills the post pane on start.
+func StartSplitTUI(feeds []Feed, groups []Group, initialPosts []Post, callbacks Callbacks, theme Theme) {
+	applyGlobalTheme(theme)
 	app := tview.NewApplication()
+	pages := tview.NewPages()
+
+	feedList := tview.NewList()
+	feedList.SetBorder(true).SetTitle(" Feeds ")
+	applyListTheme(feedList, theme)
+
+	ungrouped := ungroupedFeeds(feeds, groups)
+	collapsed := make(map[string]bool)
+	var rows []feedRow
+	showFeeds := func() {
+		current := feedList.GetCurrentItem()
+		feedList.Clear()
+		rows = rows[:0]
+		feedList.AddItem(allFeedsLabel, "", 0, nil)
+		rows = append(rows, feedRow{})
+		for i := range groups {
+			group := &groups[i]
+			marker := "▾"
+			if collapsed[group.Name] {
+				marker = "▸"
+			}
+			feedList.AddItem(fmt.Sprintf("%s %s (%d)", marker, group.Name, len(group.Feeds)), "", 0, nil)
+			rows = append(rows, feedRow{group: group})
+			if collapsed[group.Name] {
+				continue
+			}
+			for j := range group.Feeds {
+				feed := &group.Feeds[j]
+				feedList.AddItem("  "+feedLabel(*feed), "  "+feedDetails(*feed), 0, nil)
+				rows = append(rows, feedRow{feed: feed})
+			}
+		}
+		for i := range ungrouped {
+			feedList.AddItem(feedLabel(ungrouped[i]), feedDetails(ungrouped[i]), 0, nil)
+			rows = append(rows, feedRow{feed: &ungrouped[i]})
+		}
+		feedList.SetCurrentItem(current)
+	}
+	showFeeds()
+
+	legend := tview.NewTextView().SetDynamicColors(true).SetText(healthLegend())
+	feedPane := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(feedList, 0, 1, true).
+		AddItem(legend, 1, 0, false)
+
+	postList := tview.NewList()
+	postList.SetBorder(true)
+	applyListTheme(postList, theme)
+
+	status := tview.NewTextView()
+
+	posts := initialPosts
+	var (
+		postsFeed  uuid.UUID
+		postsTitle string
+		// exhausted is set once a load comes back short, so there's nothing more to fetch
+		exhausted bool
+		loading   bool
+		// generation changes with every showPosts, so a load finishing after the user has
+		// switched feeds is dropped
+		generation int
+	)
+	showPosts := func(feedID uuid.UUID, title string, fullPage int) {
+		postsFeed, postsTitle = feedID, title
+		exhausted = len(posts) < fullPage
+		loading = false
+		generation++
+		postList.Clear()
+		for _, post := range posts {
+			postList.AddItem(postTitle(post), postDetails(post), 0, nil)
+		}
+		postList.SetTitle(fmt.Sprintf(" %s (%d) ", title, len(posts)))
+	}
+	showPosts(uuid.Nil, allFeedsLabel, InitialPosts)
+
+	// loadMore fetches the next page in the background, showing loadingLabel meanwhile;
+	// the selection stays where it was, or on the first new post if it was on the label.
+	// A failed load is reported in the status bar and tried again on the next move.
+	loadMore := func() {
+		loading = true
+		postList.AddItem(loadingLabel, "", 0, nil)
+		feedID, offset, started := postsFeed, len(posts), generation
+		go func() {
+			more, err := callbacks.LoadPosts(feedID, offset)
+			app.QueueUpdateDraw(func() {
+				if generation != started {
+					return
+				}
+				current := postList.GetCurrentItem()
+				postList.RemoveItem(len(posts))
+				if err != nil {
+					status.SetText(fmt.Sprintf("Failed to load more posts: %v", err))
+					loading = false
+					return
+				}
+				exhausted = len(more) < PageSize
+				posts = append(posts, more...)
+				for _, post := range more {
+					postList.AddItem(postTitle(post), postDetails(post), 0, nil)
+				}
+				if current < postList.GetItemCount() {
+					postList.SetCurrentItem(current)
+				}
+				postList.SetTitle(fmt.Sprintf(" %s (%d) ", postsTitle, len(posts)))
+				// Only now, so the selection moves above didn't start another load
+				loading = false
+			})
+		}()
+	}
+	postList.SetChangedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		if !loading && !exhausted && index >= len(posts)-loadMoreThreshold {
+			loadMore()
+		}
+	})
+
+	feedList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		row := rows[index]
+		if row.group != nil {
+			collapsed[row.group.Name] = !collapsed[row.group.Name]
+			showFeeds()
+			return
+		}
+		feedID, title := uuid.Nil, allFeedsLabel
+		if row.feed != nil {
+			feedID, title = row.feed.ID, row.feed.Name
+		}
+		loaded, err := callbacks.LoadPosts(feedID, 0)
+		if err != nil {
+			status.SetText(fmt.Sprintf("Failed to load posts for %s: %v", title, err))
+			return
+		}
+		posts = loaded
+		showPosts(feedID, title, PageSize)
+		app.SetFocus(postList)
+	})
+
+	markRead := func(index int) {
+		post := &posts[index]
+		if post.Read {
+			return
+		}
+		if err := callbacks.MarkRead(post.ID); err != nil {
+			status.SetText(fmt.Sprintf("Failed to mark post as read: %v", err))
+			return
+		}
+		post.Read = true
+		postList.SetItemText(index, postTitle(*post), postDetails(*post))
+	}
 
-	list := tview.NewList()
-	for _, post := range posts {
-		list.AddItem(post.Title, post.URL, 0, nil)
+	openPost := func(index int) {
+		if index >= len(posts) {
+			return
+		}
+		if err := browser.Open(context.Background(), posts[index].URL); err != nil {
+			status.SetText(err.Error())
+		}
+		markRead(index)
+	}
+
+	toggleBookmark := func(index int) {
+		if index >= len(posts) {
+			return
+		}
+		post := &posts[index]
+		if err := callbacks.SetBookmarked(post.ID, !post.Bookmarked); err != nil {
+			status.SetText(fmt.Sprintf("Failed to update bookmark: %v", err))
+			return
+		}
+		post.Bookmarked = !post.Bookmarked
+		postList.SetItemText(index, postTitle(*post), postDetails(*post))
 	}
 
-	list.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
-		fmt.Printf("Opening post: %s\n", secondaryText)
-		if err := openBrowser(secondaryText); err != nil {
-			log.Printf("Failed to open browser: %v", err)
+	// The detail panel shows one post in full over the panes; its keys act on that post
+	detail := tview.NewTextView().SetScrollable(true).SetWrap(true).SetWordWrap(true)
+	detail.SetBorder(true)
+	detailIndex := -1
+	showDetail := func(index int) {
+		if index >= len(posts) {
+			return
 		}
+		detailIndex = index
+		detail.SetTitle(" " + postTitle(posts[index]) + " ")
+		detail.SetText(postDetail(posts[index])).ScrollToBeginning()
+		pages.ShowPage("detail")
+		app.SetFocus(detail)
+	}
+	closeDetail := func() {
+		detailIndex = -1
+		pages.HidePage("detail")
+		app.SetFocus(postList)
+	}
+	detail.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape {
+			closeDetail()
+			return nil
+		}
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case 'o':
+			openPost(detailIndex)
+		case 'b':
+			toggleBookmark(detailIndex)
+		case 'r':
+			markRead(detailIndex)
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		default:
+			return event
+		}
+		detail.SetTitle(" " + postTitle(posts[detailIndex]) + " ")
+		return nil
+	})
+
+	postList.SetSelectedFunc(func(index int, mainText string, secondaryText string, shortcut rune) {
+		showDetail(index)
 	})
 
-	if err := app.SetRoot(list, true).Run(); err != nil {
+	// markLoadedRead shows the loaded posts of a feed, or all of them for uuid.Nil, as read
+	markLoadedRead := func(feedID uuid.UUID) {
+		for i := range posts {
+			post := &posts[i]
+			if post.Read || feedID != uuid.Nil && post.FeedID != feedID {
+				continue
+			}
+			post.Read = true
+			postList.SetItemText(i, postTitle(*post), postDetails(*post))
+		}
+	}
+
+	markFeedRead := func(feedID uuid.UUID, name string) {
+		marked, err := callbacks.MarkAllRead(feedID)
+		if err != nil {
+			status.SetText(fmt.Sprintf("Failed to mark %s read: %v", name, err))
+			return
+		}
+		markLoadedRead(feedID)
+		status.SetText(fmt.Sprintf("Marked %d post(s) as read · %s (all read)", marked, name))
+	}
+
+	confirmAllRead := tview.NewModal().
+		SetText("Mark every post in all feeds as read?").
+		AddButtons([]string{"Mark all read", "Cancel"}).
+		SetDoneFunc(func(buttonIndex int, buttonLabel string) {
+			pages.HidePage("confirm")
+			app.SetFocus(feedList)
+			if buttonIndex == 0 {
+				markFeedRead(uuid.Nil, allFeedsLabel)
+			}
+		})
+
+	feedNavigation := vimNavigation(feedList)
+	feedList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune || event.Rune() != 'A' {
+			return feedNavigation(event)
+		}
+		row := rows[feedList.GetCurrentItem()]
+		switch {
+		case row.feed != nil:
+			markFeedRead(row.feed.ID, row.feed.Name)
+		case row.group == nil:
+			pages.ShowPage("confirm")
+		}
+		return nil
+	})
+	postNavigation := vimNavigation(postList)
+	postList.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if postList.GetItemCount() > 0 && event.Key() == tcell.KeyRune {
+			switch event.Rune() {
+			case 'o':
+				openPost(postList.GetCurrentItem())
+				return nil
+			case 'b':
+				toggleBookmark(postList.GetCurrentItem())
+				return nil
+			case 'A':
+				// Posts are marked one by one, so a failure leaves the rest unread
+				marked := 0
+				for i := range posts {
+					if posts[i].Read {
+						continue
+					}
+					if err := callbacks.MarkRead(posts[i].ID); err != nil {
+						status.SetText(fmt.Sprintf("Failed to mark posts read: %v", err))
+						return nil
+					}
+					posts[i].Read = true
+					postList.SetItemText(i, postTitle(posts[i]), postDetails(posts[i]))
+					marked++
+				}
+				status.SetText(fmt.Sprintf("Marked %d post(s) as read · %s (all read)", marked, postsTitle))
+				return nil
+			}
+		}
+		return postNavigation(event)
+	})
+
+	// The help overlay goes back to whatever had focus when it was opened
+	helpTable, help := helpOverlay()
+	var beforeHelp tview.Primitive
+	helpTable.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() == tcell.KeyEscape || event.Key() == tcell.KeyRune && event.Rune() == 'q' {
+			pages.HidePage("help")
+			app.SetFocus(beforeHelp)
+			return nil
+		}
+		return event
+	})
+
+	// Tab and Shift-Tab move focus between the panes; q works in both, and ? over the
+	// post panel too
+	app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		name, _ := pages.GetFrontPage()
+		if event.Key() == tcell.KeyRune && event.Rune() == '?' && (name == "main" || name == "detail") {
+			beforeHelp = app.GetFocus()
+			pages.ShowPage("help")
+			app.SetFocus(helpTable)
+			return nil
+		}
+		if name != "main" {
+			return event
+		}
+		switch event.Key() {
+		case tcell.KeyTab, tcell.KeyBacktab:
+			if feedList.HasFocus() {
+				app.SetFocus(postList)
+			} else {
+				app.SetFocus(feedList)
+			}
+			return nil
+		case tcell.KeyRune:
+			switch event.Rune() {
+			case 'q':
+				app.Stop()
+				return nil
+			}
+		}
+		return event
+	})
+
+	panes := tview.NewFlex().
+		AddItem(feedPane, 0, 1, true).
+		AddItem(postList, 0, 2, false)
+	layout := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(panes, 0, 1, true).
+		AddItem(status, 1, 0, false)
+	pages.AddPage("main", layout, true, true)
+	pages.AddPage("detail", detail, true, false)
+	pages.AddPage("help", help, true, false)
+	pages.AddPage("confirm", confirmAllRead, true, false)
+
+	if err := app.SetRoot(pages, true).Run(); err != nil {
 		log.Fatalf("Error running TUI: %v", err)
 	}
 }
 
-// openBrowser opens the given URL in the default web browser
-func openBrowser(url string) error {
-	cmd := exec.Command("xdg-open", url)
-	cmd.Stderr = os.Stderr
-	cmd.Stdout = os.Stdout
-	return cmd.Run()
+// vimNavigation returns an input capture that adds j/k, G and gg movement to a list
+func vimNavigation(list *tview.List) func(event *tcell.EventKey) *tcell.EventKey {
+	var lastG time.Time
+	return func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyRune {
+			return event
+		}
+		switch event.Rune() {
+		case 'j':
+			return tcell.NewEventKey(tcell.KeyDown, 0, tcell.ModNone)
+		case 'k':
+			return tcell.NewEventKey(tcell.KeyUp, 0, tcell.ModNone)
+		case 'G':
+			list.SetCurrentItem(list.GetItemCount() - 1)
+			return nil
+		case 'g':
+			// A single g only arms the double tap
+			if time.Since(lastG) <= doubleTapWindow {
+				list.SetCurrentItem(0)
+				lastG = time.Time{}
+			} else {
+				lastG = time.Now()
+			}
+			return nil
+		}
+		return event
+	}
+}
+
+// postTitle prefixes the title with a read/unread indicator and marks bookmarks with a
+// star and pins with a flag
+func postTitle(post Post) string {
+	title := "• " + post.Title
+	if post.Read {
+		title = "✓ " + post.Title
+	}
+	if post.Bookmarked {
+		title += " ★"
+	}
+	if post.Pinned {
+		title += " ⚑"
+	}
+	return title
+}
+
+// postDetails is the secondary line under a post: its feed and URL, then its reading time
+func postDetails(post Post) string {
+	details := post.URL
+	if post.FeedName != "" {
+		details = post.FeedName + " · " + details
+	}
+	if post.ReadingTimeMins > 0 {
+		details += fmt.Sprintf(" · %d min read", post.ReadingTimeMins)
+	}
+	return details
+}
+
+// postDetail is the detail panel's text: where and when the post appeared, its URL and
+// its whole description
+func postDetail(post Post) string {
+	var b strings.Builder
+	b.WriteString(post.Title + "\n")
+	if post.FeedName != "" {
+		b.WriteString(post.FeedName + " · ")
+	}
+	b.WriteString(post.PublishedAt.Format("Mon, 2 Jan 2006 15:04") + "\n")
+	b.WriteString(post.URL + "\n\n")
+	if post.Description == "" {
+		b.WriteString("(no description)")
+	} else {
+		b.WriteString(post.Description)
+	}
+	b.WriteString("\n\no open in browser · b bookmark · r mark read · Esc close")
+	return b.String()
+}
+
+// ungroupedFeeds returns the feeds that aren't in any of groups, in their original order
+func ungroupedFeeds(feeds []Feed, groups []Group) []Feed {
+	grouped := make(map[uuid.UUID]bool)
+	for _, group := range groups {
+		for _, feed := range group.Feeds {
+			grouped[feed.ID] = true
+		}
+	}
+	var rest []Feed
+	for _, feed := range feeds {
+		if !grouped[feed.ID] {
+			rest = append(rest, feed)
+		}
+	}
+	return rest
+}
+
+// feedLabel is a feed's name as the feed pane shows it: coloured by its health, or
+// under NO_COLOR prefixed with a mark that stands for it
+func feedLabel(feed Feed) string {
+	name := tview.Escape(feed.Name)
+	mark, ok := healthMarks[feed.Health]
+	switch {
+	case !ok:
+		return name
+	case pager.NoColor():
+		return mark.prefix + " " + name
+	default:
+		return "[" + mark.color + "]" + name + "[-]"
+	}
+}
+
+// healthLegend explains feedLabel's colours or marks, for the bottom of the feed pane
+func healthLegend() string {
+	labels := []struct {
+		health Health
+		text   string
+	}{
+		{HealthOK, "ok"},
+		{HealthStale, "not fetched lately"},
+		{HealthFailing, "failing"},
+	}
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		mark := healthMarks[label.health]
+		if pager.NoColor() {
+			parts[i] = mark.prefix + " " + label.text
+		} else {
+			parts[i] = "[" + mark.color + "]" + label.text + "[-]"
+		}
+	}
+	return " " + strings.Join(parts, "  ")
+}
+
+// feedDetails is the secondary line under a feed: its tags, or its URL if it has none
+func feedDetails(feed Feed) string {
+	if len(feed.Tags) == 0 {
+		return feed.URL
+	}
+	return "tags: " + strings.Join(feed.Tags, ", ")
 }
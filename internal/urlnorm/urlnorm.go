@@ -0,0 +1,40 @@
+// Package urlnorm reduces post URLs to a canonical form, so the same article linked
+// with tracking parameters or over plain HTTP is recognised as one post.
+package urlnorm
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// NormalizeURL returns raw with utm_* query parameters and the fragment removed, the
+// scheme and host lowercased, trailing slashes trimmed from the path and http upgraded
+// to https. It fails only when raw doesn't parse as a URL.
+func NormalizeURL(raw string) (string, error) {
+	parsed, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil {
+		return "", fmt.Errorf("couldn't parse URL %q: %w", raw, err)
+	}
+
+	parsed.Scheme = strings.ToLower(parsed.Scheme)
+	if parsed.Scheme == "http" {
+		parsed.Scheme = "https"
+	}
+	parsed.Host = strings.ToLower(parsed.Host)
+	parsed.Fragment, parsed.RawFragment = "", ""
+
+	parsed.Path = strings.TrimRight(parsed.Path, "/")
+	parsed.RawPath = strings.TrimRight(parsed.RawPath, "/")
+
+	if parsed.RawQuery != "" {
+		query := parsed.Query()
+		for key := range query {
+			if strings.HasPrefix(strings.ToLower(key), "utm_") {
+				query.Del(key)
+			}
+		}
+		parsed.RawQuery = query.Encode()
+	}
+	return parsed.String(), nil
+}
@@ -0,0 +1,40 @@
+package urlnorm
+
+import "testing"
+
+func TestNormalizeURL(t *testing.T) {
+	cases := map[string]string{
+		"https://example.com/post":                                               "https://example.com/post",
+		"http://example.com/post":                                                "https://example.com/post",
+		"HTTPS://Example.COM/Post/":                                              "https://example.com/Post",
+		"https://example.com/post/#comments":                                     "https://example.com/post",
+		"https://example.com/post?utm_source=rss":                                "https://example.com/post",
+		"https://example.com/post?utm_source=rss&utm_medium=feed&utm_campaign=x": "https://example.com/post",
+		"https://example.com/post?id=7&utm_source=rss":                           "https://example.com/post?id=7",
+		"https://example.com/post?UTM_Content=a&page=2":                          "https://example.com/post?page=2",
+		"https://example.com/post?utmost=1":                                      "https://example.com/post?utmost=1",
+		"https://example.com/":                                                   "https://example.com",
+		"  https://example.com/post  ":                                           "https://example.com/post",
+	}
+	for in, want := range cases {
+		got, err := NormalizeURL(in)
+		if err != nil {
+			t.Errorf("NormalizeURL(%q): unexpected error %v", in, err)
+			continue
+		}
+		if got != want {
+			t.Errorf("NormalizeURL(%q) = %q, want %q", in, got, want)
+		}
+	}
+
+	// Variants of one article all come out the same
+	a, _ := NormalizeURL("http://blog.example.com/2025/10/go/?utm_source=feedly#top")
+	b, _ := NormalizeURL("https://blog.example.com/2025/10/go")
+	if a != b {
+		t.Errorf("variants normalized differently: %q and %q", a, b)
+	}
+
+	if _, err := NormalizeURL("https://exa mple.com/%zz"); err == nil {
+		t.Error("expected an error for an unparsable URL")
+	}
+}
@@ -0,0 +1,93 @@
+// Package webhook delivers new-post notifications to user-configured URLs.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// SignatureHeader carries "sha256=<hex HMAC-SHA256 of the body>", keyed with the webhook's secret
+const SignatureHeader = "X-Gator-Signature"
+
+// Timeout bounds a single delivery, including reading the response
+const Timeout = 10 * time.Second
+
+// EventNewPost is the event sent when a post is saved
+const EventNewPost = "new_post"
+
+// Post is the post sent in a delivery
+type Post struct {
+	ID          uuid.UUID  `json:"id"`
+	Title       string     `json:"title"`
+	URL         string     `json:"url"`
+	Description string     `json:"description,omitempty"`
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+	FeedID      uuid.UUID  `json:"feed_id"`
+	FeedName    string     `json:"feed_name"`
+	FeedURL     string     `json:"feed_url"`
+}
+
+// Payload is the JSON body of a delivery
+type Payload struct {
+	Event string `json:"event"`
+	Post  Post   `json:"post"`
+}
+
+// GenerateSecret returns a random hex secret for signing a new webhook's deliveries
+func GenerateSecret() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(secret), nil
+}
+
+// Sign returns the SignatureHeader value for body
+func Sign(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Deliver POSTs payload to url as JSON, signed with secret. Any status other
+// than 2xx is an error.
+func Deliver(ctx context.Context, client *http.Client, url, secret string, payload Payload) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "gator-webhook")
+	req.Header.Set(SignatureHeader, Sign(secret, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	// Drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook answered %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliverSignsPayload(t *testing.T) {
+	const secret = "s3cret"
+	var got Payload
+	var signatureOK bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		signatureOK = r.Header.Get(SignatureHeader) == Sign(secret, body)
+		json.Unmarshal(body, &got)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	payload := Payload{Event: EventNewPost, Post: Post{ID: uuid.New(), Title: "Hello", URL: "https://example.com/hello", FeedName: "Example"}}
+	if err := Deliver(context.Background(), srv.Client(), srv.URL, secret, payload); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !signatureOK {
+		t.Error("signature header doesn't match the body")
+	}
+	if got.Event != EventNewPost || got.Post.ID != payload.Post.ID || got.Post.Title != "Hello" {
+		t.Errorf("received %+v, want %+v", got, payload)
+	}
+}
+
+func TestDeliverRejectsErrorStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	if err := Deliver(context.Background(), srv.Client(), srv.URL, "secret", Payload{Event: EventNewPost}); err == nil {
+		t.Fatal("expected an error for a 500 response")
+	}
+}
+
+func TestSign(t *testing.T) {
+	// Known HMAC-SHA256 vector, so receivers in other languages can check their implementation
+	want := "sha256=f7bc83f430538424b13298e6aa6fb143ef4d59a14946175997479dbc2d1a3cd8"
+	if got := Sign("key", []byte("The quick brown fox jumps over the lazy dog")); got != want {
+		t.Errorf("Sign = %s, want %s", got, want)
+	}
+}
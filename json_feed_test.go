@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFeedJSONFeed(t *testing.T) {
+	body := []byte(`{
+		"version": "https://jsonfeed.org/version/1.1",
+		"title": "Example",
+		"home_page_url": "https://example.org/",
+		"items": [
+			{"id": "1", "url": "https://example.org/first", "title": "First", "content_html": "<p>Hi</p>", "date_published": "2024-02-15T14:30:00Z"},
+			{"id": "https://example.org/second", "content_text": "No title here"}
+		]
+	}`)
+
+	feed, err := parseFeed("application/feed+json; charset=utf-8", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if feed.Channel.Title != "Example" || feed.Channel.Link != "https://example.org/" {
+		t.Fatalf("unexpected channel: %+v", feed.Channel)
+	}
+	if len(feed.Channel.Item) != 2 {
+		t.Fatalf("expected 2 items, got %d", len(feed.Channel.Item))
+	}
+
+	first := feed.Channel.Item[0]
+	if first.Description != "<p>Hi</p>" {
+		t.Fatalf("expected content_html description, got %q", first.Description)
+	}
+	published, ok := parsePublished(first.PubDate)
+	if !ok || !published.Equal(time.Date(2024, 2, 15, 14, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected RFC 3339 date_published to parse, got %v (ok=%v)", published, ok)
+	}
+
+	second := feed.Channel.Item[1]
+	if second.Link != "https://example.org/second" {
+		t.Fatalf("expected id fallback for link, got %q", second.Link)
+	}
+	if second.Title != second.Link {
+		t.Fatalf("expected missing title to fall back to URL, got %q", second.Title)
+	}
+	if second.Description != "No title here" {
+		t.Fatalf("expected content_text description, got %q", second.Description)
+	}
+}
+
+func TestParseFeedJSONFeedNullItems(t *testing.T) {
+	body := []byte(`{"version": "https://jsonfeed.org/version/1.1", "title": "Empty", "items": null}`)
+
+	feed, err := parseFeed("application/feed+json", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(feed.Channel.Item) != 0 {
+		t.Fatalf("expected no items, got %d", len(feed.Channel.Item))
+	}
+}
+
+func TestParseFeedSniffsAmbiguousContentType(t *testing.T) {
+	jsonBody := []byte(`  {"version": "https://jsonfeed.org/version/1", "title": "Sniffed"}`)
+	feed, err := parseFeed("text/plain", jsonBody)
+	if err != nil || feed.Channel.Title != "Sniffed" {
+		t.Fatalf("expected JSON body to be sniffed, got %+v, %v", feed, err)
+	}
+
+	xmlBody := []byte(`<rss><channel><title>XML</title></channel></rss>`)
+	feed, err = parseFeed("", xmlBody)
+	if err != nil || feed.Channel.Title != "XML" {
+		t.Fatalf("expected XML body to parse, got %+v, %v", feed, err)
+	}
+}
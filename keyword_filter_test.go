@@ -0,0 +1,52 @@
+package main
+
+import (
+	"testing"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestPassesKeywordFilters(t *testing.T) {
+	excludeTitle := database.KeywordFilter{Keyword: "Sponsored", FilterType: "exclude", Field: "title"}
+	includeAny := database.KeywordFilter{Keyword: "go", FilterType: "include", Field: "any"}
+	includeDesc := database.KeywordFilter{Keyword: "rust", FilterType: "include", Field: "description"}
+
+	cases := []struct {
+		name        string
+		filters     []database.KeywordFilter
+		title, desc string
+		want        bool
+	}{
+		{"no filters", nil, "anything", "", true},
+		{"exclude matches case-insensitively", []database.KeywordFilter{excludeTitle}, "SPONSORED: buy now", "", false},
+		{"exclude only checks its field", []database.KeywordFilter{excludeTitle}, "News", "sponsored content", true},
+		{"include matches description", []database.KeywordFilter{includeAny}, "Release notes", "New Go version", true},
+		{"include required", []database.KeywordFilter{includeAny}, "Weather", "Sunny", false},
+		{"any include is enough", []database.KeywordFilter{includeAny, includeDesc}, "Weather", "rust belt", true},
+		{"exclude beats include", []database.KeywordFilter{includeAny, excludeTitle}, "Sponsored Go course", "", false},
+	}
+	for _, tc := range cases {
+		if got := passesKeywordFilters(tc.filters, tc.title, tc.desc); got != tc.want {
+			t.Errorf("%s: passesKeywordFilters = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestWantedByFollowers(t *testing.T) {
+	alice, bob := uuid.New(), uuid.New()
+	filters := map[uuid.UUID][]database.KeywordFilter{
+		alice: {{Keyword: "nft", FilterType: "exclude", Field: "any"}},
+	}
+
+	if !wantedByFollowers(nil, filters, "NFT drop", "") {
+		t.Fatal("expected posts of unfollowed feeds to be kept")
+	}
+	if wantedByFollowers([]uuid.UUID{alice}, filters, "NFT drop", "") {
+		t.Fatal("expected post to be skipped when its only follower excludes it")
+	}
+	if !wantedByFollowers([]uuid.UUID{alice, bob}, filters, "NFT drop", "") {
+		t.Fatal("expected post to be kept for a follower without filters")
+	}
+}
@@ -1,36 +1,92 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"crypto/sha256"
+	"crypto/tls"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
+	"flag"
 	"fmt"
 	"html"
 	"io"
-	"log"
+	"log/slog"
+	"math"
+	"mime"
+	"net"
 	"net/http"
+	neturl "net/url"
 	"os"
 	"os/exec"
 	"os/signal"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"runtime/debug"
+	"slices"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"gator/internal/api"
+	"gator/internal/article"
+	"gator/internal/browser"
 	"gator/internal/config"
 	"gator/internal/database"
+	"gator/internal/discover"
+	"gator/internal/events"
+	"gator/internal/export"
+	feedxml "gator/internal/feed"
+	"gator/internal/langdetect"
+	"gator/internal/metrics"
+	"gator/internal/migrations"
+	"gator/internal/notify"
+	"gator/internal/opml"
+	"gator/internal/output"
+	"gator/internal/pager"
+	"gator/internal/podcast"
+	"gator/internal/proxy"
+	"gator/internal/readtime"
+	"gator/internal/render"
+	"gator/internal/sanitize"
+	"gator/internal/spark"
 	"gator/internal/tui"
+	"gator/internal/urlnorm"
+	"gator/internal/webhook"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
+	"golang.org/x/term"
 )
 
 // state struct holds a pointer to a config and database
 type state struct {
-	db  *database.Queries
-	cfg *config.Config
+	db      database.Store
+	conn    *sql.DB // raw connection for transactions
+	backend backend // the kind of database conn is
+	cfg     *config.Config
+	logger  *slog.Logger
+
+	// metrics is only set when agg runs with --metrics-port; its methods are no-ops on nil
+	metrics *metrics.Metrics
+	// bus is only set while the API server runs; publishing to nil does nothing
+	bus *events.Bus
+	// webhooks tracks deliveries in flight so the process can wait for them before exiting
+	webhooks sync.WaitGroup
+	// resolvePostURLs is set by agg --resolve-urls or resolve_post_urls in the config
+	resolvePostURLs bool
+
+	outputFormat string // output.Text, output.JSON or output.CSV
 }
 
 // command represents a parsed CLI command
@@ -47,6 +103,7 @@ type commands struct {
 // RSSFeed represents the structure of an RSS feed
 type RSSFeed struct {
 	Channel struct {
+		feedxml.ITunesChannel
 		Title       string    `xml:"title"`
 		Link        string    `xml:"link"`
 		Description string    `xml:"description"`
@@ -56,10 +113,96 @@ type RSSFeed struct {
 
 // RSSItem represents a single item in an RSS feed
 type RSSItem struct {
+	feedxml.ITunesItem
 	Title       string `xml:"title"`
 	Link        string `xml:"link"`
 	Description string `xml:"description"`
-	PubDate     string `xml:"pubDate"`
+	// ContentEncoded is content:encoded, the full HTML body that many feeds carry
+	// alongside a shorter description
+	ContentEncoded string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	PubDate        string `xml:"pubDate"`
+	// DCDate is Dublin Core's dc:date, which RSS 1.0 feeds and some RSS 2.0 feeds use instead of pubDate
+	DCDate string `xml:"http://purl.org/dc/elements/1.1/ date"`
+	// Author is RSS 2.0's author, officially an email address; DCCreator is Dublin Core's
+	// dc:creator, which usually holds a full name
+	Author    string `xml:"author"`
+	DCCreator string `xml:"http://purl.org/dc/elements/1.1/ creator"`
+	// Enclosure attaches a media file, e.g. a podcast episode; Media RSS feeds use media:content
+	Enclosure struct {
+		URL    string `xml:"url,attr"`
+		Length string `xml:"length,attr"`
+		Type   string `xml:"type,attr"`
+	} `xml:"enclosure"`
+	MediaContent struct {
+		URL  string `xml:"url,attr"`
+		Type string `xml:"type,attr"`
+	} `xml:"http://search.yahoo.com/mrss/ content"`
+}
+
+// content returns the item's body: content:encoded when the feed has it, otherwise
+// the description
+func (item RSSItem) content() string {
+	if strings.TrimSpace(item.ContentEncoded) != "" {
+		return item.ContentEncoded
+	}
+	return item.Description
+}
+
+// author returns who wrote the item, preferring dc:creator, then itunes:author, then
+// author. An author written as "email (Name)" is shortened to the name.
+func (item RSSItem) author() string {
+	for _, candidate := range []string{item.DCCreator, item.ITunesItem.Author} {
+		if name := strings.TrimSpace(candidate); name != "" {
+			return name
+		}
+	}
+	author := strings.TrimSpace(item.Author)
+	if open := strings.Index(author, "("); open > 0 && strings.HasSuffix(author, ")") {
+		if name := strings.TrimSpace(author[open+1 : len(author)-1]); name != "" {
+			return name
+		}
+	}
+	return author
+}
+
+// enclosure returns the item's media file, preferring <enclosure> over <media:content>
+func (item RSSItem) enclosure() (url, mediaType string, length sql.NullInt64, ok bool) {
+	if url := strings.TrimSpace(item.Enclosure.URL); url != "" {
+		n, err := strconv.ParseInt(strings.TrimSpace(item.Enclosure.Length), 10, 64)
+		return url, item.Enclosure.Type, sql.NullInt64{Int64: n, Valid: err == nil && n > 0}, true
+	}
+	if url := strings.TrimSpace(item.MediaContent.URL); url != "" {
+		return url, item.MediaContent.Type, sql.NullInt64{}, true
+	}
+	return "", "", sql.NullInt64{}, false
+}
+
+// JSONFeed represents the structure of a JSON Feed 1.1 document (https://jsonfeed.org)
+type JSONFeed struct {
+	Version     string         `json:"version"`
+	Title       string         `json:"title"`
+	HomePageURL string         `json:"home_page_url"`
+	Description string         `json:"description"`
+	Items       []JSONFeedItem `json:"items"`
+}
+
+// JSONFeedItem represents a single item in a JSON Feed
+type JSONFeedItem struct {
+	ID            string `json:"id"`
+	URL           string `json:"url"`
+	Title         string `json:"title"`
+	ContentHTML   string `json:"content_html"`
+	ContentText   string `json:"content_text"`
+	Summary       string `json:"summary"`
+	DatePublished string `json:"date_published"`
+	// Authors replaced JSON Feed 1.0's single author in 1.1; both are read
+	Authors []JSONFeedAuthor `json:"authors"`
+	Author  JSONFeedAuthor   `json:"author"`
+}
+
+// JSONFeedAuthor names an item's author
+type JSONFeedAuthor struct {
+	Name string `json:"name"`
 }
 
 // middlewareLoggedIn wraps handlers that require a logged-in user
@@ -97,35 +240,312 @@ func (c *commands) register(name string, f func(*state, command) error) {
 	c.handlers[name] = f
 }
 
-// fetchFeed fetches an RSS feed from the given URL and returns a parsed RSSFeed struct
-func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
-	// Create HTTP request with context
-	req, err := http.NewRequestWithContext(ctx, "GET", feedURL, nil)
+// newFlagSet creates a flag set for a command that reports errors instead of exiting
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	return fs
+}
+
+// parseCommandFlags parses fs from args, allowing flags to appear before or after
+// positional arguments, and returns the positional arguments in order
+func parseCommandFlags(fs *flag.FlagSet, args []string) ([]string, error) {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional, nil
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
+
+// ErrNotModified is returned by fetchFeed when the server answers 304 Not Modified
+var ErrNotModified = errors.New("feed not modified")
+
+// feedCache holds the HTTP cache validators remembered for a feed between fetches
+type feedCache struct {
+	ETag         string
+	LastModified string
+}
+
+// FetchResult describes how a fetch went beyond the feed itself
+type FetchResult struct {
+	// Cache holds the response's validators, or the ones sent when there was no response
+	Cache feedCache
+	// WasRedirected reports that the feed has moved permanently (301 or 308), and
+	// FinalURL is where those permanent redirects led. A temporary redirect anywhere in
+	// the chain ends it, since the URL after it may not last.
+	FinalURL      string
+	WasRedirected bool
+}
+
+// fetchTimeout bounds how long a single feed request may take; main sets it from
+// the config and feeds can override it with settimeout --feed
+var fetchTimeout = 30 * time.Second
+
+// defaultUserAgent identifies feed requests from feeds without their own User-Agent;
+// main sets it from the config
+var defaultUserAgent = "gator"
+
+// backend is a kind of database gator can keep its data in
+type backend struct {
+	open    func(dbURL string) (*sql.DB, error)
+	queries func(db *sql.DB) database.Store
+	// migrate brings the schema up to date
+	migrate       func(ctx context.Context, db *sql.DB) error
+	missingTables func(ctx context.Context, db *sql.DB) ([]string, error)
+}
+
+// backends are the databases db_driver can name. SQLite needs cgo, so it is only
+// registered in binaries built with -tags sqlite.
+var backends = map[string]backend{
+	config.DriverPostgres: {
+		open:    func(dbURL string) (*sql.DB, error) { return sql.Open("postgres", dbURL) },
+		queries: func(db *sql.DB) database.Store { return database.New(db) },
+		migrate: func(ctx context.Context, db *sql.DB) error {
+			return migrations.Up(ctx, db, nil)
+		},
+		missingTables: migrations.MissingTables,
+	},
+}
+
+// lookupBackend returns the backend for a db_driver value
+func lookupBackend(driver string) (backend, error) {
+	b, ok := backends[driver]
+	switch {
+	case ok:
+		return b, nil
+	case driver == config.DriverSQLite:
+		return backend{}, errors.New("this gator was built without SQLite support; rebuild it with -tags sqlite")
+	default:
+		return backend{}, fmt.Errorf("unknown database driver %q", driver)
+	}
+}
+
+// httpTransport carries every outgoing request so the configured proxy applies
+// everywhere; main replaces it once the config is read
+var httpTransport http.RoundTripper = http.DefaultTransport
+
+// fetchFeed fetches an RSS or JSON feed from the given URL and returns a parsed RSSFeed struct.
+// The cache validators are sent as conditional headers and the response's validators are
+// returned along with any permanent redirect. An empty userAgent sends defaultUserAgent.
+func fetchFeed(ctx context.Context, client *http.Client, feedURL string, cache feedCache, userAgent string) (*RSSFeed, FetchResult, error) {
+	result := FetchResult{Cache: cache, FinalURL: feedURL}
+	req, err := newFeedRequest(ctx, "GET", feedURL, userAgent)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't create request: %w", err)
+		return nil, result, err
+	}
+
+	// Record permanent redirects on a copy so the caller's client stays untouched
+	permanentChain := true
+	checkRedirect := client.CheckRedirect
+	redirectClient := *client
+	redirectClient.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		if checkRedirect != nil {
+			if err := checkRedirect(req, via); err != nil {
+				return err
+			}
+		} else if len(via) >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		if code := req.Response.StatusCode; code != http.StatusMovedPermanently && code != http.StatusPermanentRedirect {
+			permanentChain = false
+		}
+		if permanentChain {
+			result.FinalURL = req.URL.String()
+			result.WasRedirected = true
+		}
+		return nil
 	}
+	client = &redirectClient
 
-	// Set User-Agent header to identify our program
-	req.Header.Set("User-Agent", "gator")
+	// Ask the server to skip the body if nothing changed since the last fetch
+	if cache.ETag != "" {
+		req.Header.Set("If-None-Match", cache.ETag)
+	}
+	if cache.LastModified != "" {
+		req.Header.Set("If-Modified-Since", cache.LastModified)
+	}
 
-	// Create HTTP client and make request
-	client := http.Client{}
+	// Make request with the caller's client
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't make request: %w", err)
+		return nil, result, fmt.Errorf("couldn't make request: %w", err)
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		return nil, result, ErrNotModified
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, result, &statusError{
+			StatusCode: resp.StatusCode,
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+
+	newCache := feedCache{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+
 	// Read response body
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("couldn't read response body: %w", err)
+		return nil, result, fmt.Errorf("couldn't read response body: %w", err)
 	}
 
-	// Parse XML into RSSFeed struct
-	var feed RSSFeed
-	err = xml.Unmarshal(body, &feed)
+	feed, err := parseFeed(resp.Header.Get("Content-Type"), body)
+	if err != nil {
+		return nil, result, err
+	}
+	feed.Channel.Item = dedupeItems(feed.Channel.Item)
+	result.Cache = newCache
+	return feed, result, nil
+}
+
+// dedupeItems drops items whose link normalizes to that of an earlier item, as feeds
+// sometimes list a post twice with different tracking parameters. Items without a
+// link are kept.
+func dedupeItems(items []RSSItem) []RSSItem {
+	seen := make(map[string]bool, len(items))
+	kept := items[:0]
+	for _, item := range items {
+		if link := strings.TrimSpace(item.Link); link != "" {
+			normalized := normalizedPostURL(link)
+			if seen[normalized] {
+				continue
+			}
+			seen[normalized] = true
+		}
+		kept = append(kept, item)
+	}
+	return kept
+}
+
+// normalizedPostURL is the key posts are deduplicated on. A link that doesn't parse
+// is its own key.
+func normalizedPostURL(link string) string {
+	normalized, err := urlnorm.NormalizeURL(link)
+	if err != nil {
+		return strings.TrimSpace(link)
+	}
+	return normalized
+}
+
+// statusError is returned by fetchFeed when the server answers with a non-2xx status
+type statusError struct {
+	StatusCode int
+	RetryAfter time.Duration // from the Retry-After header, zero if absent
+}
+
+func (e *statusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+}
+
+// fetchBackoff is how long fetchFeedWithRetry waits before each retry
+var fetchBackoff = []time.Duration{1 * time.Second, 2 * time.Second, 4 * time.Second}
+
+// maxRetryAfter caps how long a server's Retry-After may hold up a worker
+const maxRetryAfter = time.Minute
+
+// fetchFeedWithRetry calls fetchFeed, retrying transient failures with exponential
+// backoff. A 429 response waits for its Retry-After instead when one is given.
+func fetchFeedWithRetry(ctx context.Context, logger *slog.Logger, client *http.Client, feedURL string, cache feedCache, userAgent string) (*RSSFeed, FetchResult, error) {
+	for attempt := 0; ; attempt++ {
+		feed, result, err := fetchFeed(ctx, client, feedURL, cache, userAgent)
+		if err == nil || ctx.Err() != nil || !isTransientFetchError(err) || attempt >= len(fetchBackoff) {
+			return feed, result, err
+		}
+
+		wait := fetchBackoff[attempt]
+		var statusErr *statusError
+		if errors.As(err, &statusErr) && statusErr.RetryAfter > 0 {
+			wait = statusErr.RetryAfter
+		}
+		logger.Warn("retrying feed fetch",
+			"feed_url", feedURL,
+			"attempt", attempt+1,
+			"wait_ms", wait.Milliseconds(),
+			"error", err,
+		)
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, FetchResult{Cache: cache, FinalURL: feedURL}, ctx.Err()
+		}
+	}
+}
+
+// isTransientFetchError reports whether a fetch error is worth retrying:
+// 429 and 5xx responses and network failures are, other 4xx and parse errors aren't
+func isTransientFetchError(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.StatusCode == http.StatusTooManyRequests || statusErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// parseRetryAfter reads a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	header = strings.TrimSpace(header)
+	if header == "" {
+		return 0
+	}
+
+	var wait time.Duration
+	if secs, err := strconv.Atoi(header); err == nil {
+		wait = time.Duration(secs) * time.Second
+	} else if at, err := http.ParseTime(header); err == nil {
+		wait = at.Sub(now)
+	}
+
+	if wait < 0 {
+		return 0
+	}
+	return min(wait, maxRetryAfter)
+}
+
+// newFeedRequest builds a request for a feed URL with the headers every feed request
+// carries; an empty userAgent means defaultUserAgent
+func newFeedRequest(ctx context.Context, method, feedURL, userAgent string) (*http.Request, error) {
+	// Create HTTP request with context
+	req, err := http.NewRequestWithContext(ctx, method, feedURL, nil)
 	if err != nil {
+		return nil, fmt.Errorf("couldn't create request: %w", err)
+	}
+
+	// Set User-Agent header to identify our program, or whatever the feed's site wants
+	if userAgent == "" {
+		userAgent = defaultUserAgent
+	}
+	req.Header.Set("User-Agent", userAgent)
+	return req, nil
+}
+
+// parseFeed decodes a feed body as RSS 2.0, RSS 1.0 (RDF) or JSON Feed depending on
+// its content type and root element
+func parseFeed(contentType string, body []byte) (*RSSFeed, error) {
+	if isJSONFeed(contentType, body) {
+		return parseJSONFeed(body)
+	}
+
+	var feed RSSFeed
+	if feedxml.IsRDF(body) {
+		rdf, err := feedxml.ParseRDF(body)
+		if err != nil {
+			return nil, err
+		}
+		feed = rssFromRDF(rdf)
+	} else if err := xml.Unmarshal(body, &feed); err != nil {
 		return nil, fmt.Errorf("couldn't unmarshal XML: %w", err)
 	}
 
@@ -136,7 +556,101 @@ func fetchFeed(ctx context.Context, feedURL string) (*RSSFeed, error) {
 	// Unescape HTML entities in item fields
 	for i := range feed.Channel.Item {
 		feed.Channel.Item[i].Title = html.UnescapeString(feed.Channel.Item[i].Title)
-		feed.Channel.Item[i].Description = html.UnescapeString(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].Description = unescapeHTMLBody(feed.Channel.Item[i].Description)
+		feed.Channel.Item[i].ContentEncoded = unescapeHTMLBody(feed.Channel.Item[i].ContentEncoded)
+	}
+
+	return &feed, nil
+}
+
+// unescapeHTMLBody undoes a second layer of entity escaping in an item body. A body
+// that already starts with a tag came from CDATA or single escaping and is HTML as it
+// stands; unescaping it again would turn escaped text such as &lt;b&gt; into markup.
+func unescapeHTMLBody(body string) string {
+	if strings.HasPrefix(strings.TrimSpace(body), "<") {
+		return body
+	}
+	return html.UnescapeString(body)
+}
+
+// rssFromRDF converts an RSS 1.0 document into the RSSFeed shape
+func rssFromRDF(rdf *feedxml.RDFFeed) RSSFeed {
+	var feed RSSFeed
+	feed.Channel.Title = rdf.Channel.Title
+	feed.Channel.Link = rdf.Channel.Link
+	feed.Channel.Description = rdf.Channel.Description
+	for _, item := range rdf.Items {
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			Description: item.Description,
+			DCDate:      item.Date,
+			DCCreator:   item.Creator,
+		})
+	}
+	return feed
+}
+
+// isJSONFeed reports whether a response should be decoded as JSON Feed.
+// The Content-Type header wins; ambiguous types fall back to sniffing the body.
+func isJSONFeed(contentType string, body []byte) bool {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		switch {
+		case mediaType == "application/feed+json", mediaType == "application/json":
+			return true
+		case strings.Contains(mediaType, "xml"):
+			return false
+		}
+	}
+	return strings.HasPrefix(strings.TrimSpace(string(body)), "{")
+}
+
+// parseJSONFeed decodes a JSON Feed document and converts it into the RSSFeed shape
+func parseJSONFeed(body []byte) (*RSSFeed, error) {
+	var jsonFeed JSONFeed
+	if err := json.Unmarshal(body, &jsonFeed); err != nil {
+		return nil, fmt.Errorf("couldn't unmarshal JSON feed: %w", err)
+	}
+
+	var feed RSSFeed
+	feed.Channel.Title = jsonFeed.Title
+	feed.Channel.Link = jsonFeed.HomePageURL
+	feed.Channel.Description = jsonFeed.Description
+
+	for _, item := range jsonFeed.Items {
+		// The id is frequently the permalink when url is omitted
+		link := item.URL
+		if link == "" {
+			link = item.ID
+		}
+
+		// Items may omit the title entirely, so fall back to the URL
+		title := item.Title
+		if title == "" {
+			title = link
+		}
+
+		description := item.ContentHTML
+		if description == "" {
+			description = item.ContentText
+		}
+		if description == "" {
+			description = item.Summary
+		}
+
+		author := item.Author.Name
+		if len(item.Authors) > 0 {
+			author = item.Authors[0].Name
+		}
+
+		feed.Channel.Item = append(feed.Channel.Item, RSSItem{
+			Title:       title,
+			Link:        link,
+			Description: description,
+			PubDate:     item.DatePublished,
+			DCCreator:   author,
+		})
 	}
 
 	return &feed, nil
@@ -199,9 +713,27 @@ func handlerLogin(s *state, cmd command) error {
 	return nil
 }
 
-// handlerReset handles the reset command
+// handlerReset deletes every user and, through them, all feeds, follows and posts.
+// It asks for "yes" first unless --confirm is given, and won't run unattended without it.
 func handlerReset(s *state, cmd command) error {
-	err := s.db.ResetUsers(context.Background())
+	fs := newFlagSet(cmd.name)
+	confirm := fs.Bool("confirm", false, "reset without asking")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) > 0 {
+		return fmt.Errorf("usage: %s [--confirm]", cmd.name)
+	}
+	if !*confirm {
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return errors.New("refusing to reset in non-interactive mode without --confirm")
+		}
+		answer, err := promptLine("This will delete ALL users, feeds, follows, and posts. Type 'yes' to confirm: ")
+		if err != nil || answer != "yes" {
+			fmt.Println("Reset cancelled.")
+			return nil
+		}
+	}
+
+	err = s.db.ResetUsers(context.Background())
 	if err != nil {
 		return fmt.Errorf("couldn't reset users: %w", err)
 	}
@@ -210,422 +742,7035 @@ func handlerReset(s *state, cmd command) error {
 	return nil
 }
 
-// handlerUsers handles the users command
-func handlerUsers(s *state, cmd command) error {
-	users, err := s.db.GetUsers(context.Background())
-	if err != nil {
-		return fmt.Errorf("couldn't get users: %w", err)
+// handlerDeleteUser deletes a user with their follows, bookmarks and read state.
+// Feeds the user created are handed to their oldest other follower, or deleted
+// with --cascade-feeds when nobody else follows them.
+func handlerDeleteUser(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	admin := fs.Bool("admin", false, "delete another user (requires admin_user in the config)")
+	cascadeFeeds := fs.Bool("cascade-feeds", false, "delete the user's feeds that nobody else follows, with their posts")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: %s <username> [--admin] [--cascade-feeds]", cmd.name)
 	}
 
-	currentUser := s.cfg.CurrentUser
-
-	for _, user := range users {
-		if user.Name == currentUser {
-			fmt.Printf("* %s (current)\n", user.Name)
-		} else {
-			fmt.Printf("* %s\n", user.Name)
+	ctx := context.Background()
+	target, err := s.db.GetUser(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find user %s: %w", args[0], err)
+	}
+	if target.ID != user.ID {
+		if !*admin {
+			return fmt.Errorf("you can only delete your own account; use --admin to delete %s", target.Name)
+		}
+		if s.cfg.AdminUser == "" || s.cfg.AdminUser != user.Name {
+			return fmt.Errorf("--admin requires being logged in as the admin_user from the config")
 		}
 	}
 
-	return nil
-}
-
-// Enhanced handlerAgg to fetch feeds concurrently
-func handlerAgg(s *state, cmd command) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("usage: agg <time_between_reqs>")
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
 	}
+	defer tx.Rollback()
+	qtx := s.db.InTx(tx)
 
-	timeBetweenReqs, err := time.ParseDuration(cmd.args[0])
+	// Deleting the user would cascade to their feeds, so deal with those first
+	owned, err := qtx.GetFeedsCreatedByUser(ctx, target.ID)
 	if err != nil {
-		return fmt.Errorf("invalid duration: %v", err)
+		return fmt.Errorf("couldn't get feeds created by %s: %w", target.Name, err)
 	}
+	var reassigned, deletedFeeds, orphaned []string
+	for _, feed := range owned {
+		followers, err := qtx.GetFeedFollowersForFeed(ctx, feed.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get followers of %s: %w", feed.Name, err)
+		}
 
-	fmt.Printf("Collecting feeds every %s\n", timeBetweenReqs)
-	ticker := time.NewTicker(timeBetweenReqs)
-	defer ticker.Stop()
-
-	feedChan := make(chan struct{}, 5) // Limit concurrency to 5 feeds at a time
+		// Followers are ordered by when they followed, so the first other one is the oldest
+		var heir *database.GetFeedFollowersForFeedRow
+		for i := range followers {
+			if followers[i].ID != target.ID {
+				heir = &followers[i]
+				break
+			}
+		}
 
-	for {
-		feedChan <- struct{}{} // Block if limit is reached
-		go func() {
-			scrapeFeeds(s)
-			<-feedChan // Release slot after completion
-		}()
-		<-ticker.C
+		switch {
+		case heir != nil:
+			if err := qtx.ReassignFeed(ctx, database.ReassignFeedParams{ID: feed.ID, UserID: heir.ID}); err != nil {
+				return fmt.Errorf("couldn't reassign %s: %w", feed.Name, err)
+			}
+			reassigned = append(reassigned, fmt.Sprintf("%s -> %s", feed.Name, heir.Name))
+		case *cascadeFeeds:
+			if _, err := qtx.DeleteFeedFollowsForFeed(ctx, feed.ID); err != nil {
+				return fmt.Errorf("couldn't delete follows of %s: %w", feed.Name, err)
+			}
+			if _, err := qtx.DeletePostsForFeed(ctx, feed.ID); err != nil {
+				return fmt.Errorf("couldn't delete posts of %s: %w", feed.Name, err)
+			}
+			if _, err := qtx.DeleteFeed(ctx, feed.ID); err != nil {
+				return fmt.Errorf("couldn't delete %s: %w", feed.Name, err)
+			}
+			deletedFeeds = append(deletedFeeds, feed.Name)
+		default:
+			orphaned = append(orphaned, feed.Name)
+		}
 	}
-}
-
-// handlerAddfeed handles the addfeed command to create new feeds
-func handlerAddfeed(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 2 {
-		return fmt.Errorf("usage: %s <name> <url>", cmd.name)
+	if len(orphaned) > 0 {
+		return fmt.Errorf("%s created feeds nobody else follows (%s); use --cascade-feeds to delete them", target.Name, strings.Join(orphaned, ", "))
 	}
 
-	name := cmd.args[0]
-	url := cmd.args[1]
-
-	// Create new feed
-	now := time.Now().UTC()
-	feedParams := database.CreateFeedParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		Name:      name,
-		Url:       url,
-		UserID:    user.ID,
+	follows, err := qtx.DeleteFeedFollowsForUser(ctx, target.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete follows: %w", err)
 	}
-
-	feed, err := s.db.CreateFeed(context.Background(), feedParams)
+	bookmarks, err := qtx.DeleteBookmarksForUser(ctx, target.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete bookmarks: %w", err)
+	}
+	reads, err := qtx.DeleteReadPostsForUser(ctx, target.ID)
 	if err != nil {
-		return fmt.Errorf("couldn't create feed: %w", err)
+		return fmt.Errorf("couldn't delete read state: %w", err)
+	}
+	if _, err := qtx.DeleteUser(ctx, target.ID); err != nil {
+		return fmt.Errorf("couldn't delete user: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit user deletion: %w", err)
 	}
 
-	// Automatically create a feed follow record for the user who created the feed
-	followParams := database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		UserID:    user.ID,
-		FeedID:    feed.ID,
+	if target.Name == s.cfg.CurrentUser {
+		if err := s.cfg.ClearUser(); err != nil {
+			return fmt.Errorf("user deleted but couldn't log out: %w", err)
+		}
 	}
 
-	_, err = s.db.CreateFeedFollow(context.Background(), followParams)
-	if err != nil {
-		return fmt.Errorf("couldn't create feed follow: %w", err)
+	fmt.Printf("Deleted user %s (%d follows, %d bookmarks, %d read records)\n", target.Name, follows, bookmarks, reads)
+	for _, feed := range reassigned {
+		fmt.Printf("  Reassigned feed %s\n", feed)
 	}
+	for _, feed := range deletedFeeds {
+		fmt.Printf("  Deleted feed %s\n", feed)
+	}
+	return nil
+}
 
-	fmt.Printf("Feed created successfully!\n")
-	fmt.Printf("Feed data: %+v\n", feed)
+// handlerWhoami prints the logged-in user from the config without touching the database
+func handlerWhoami(s *state, cmd command) error {
+	if s.cfg.CurrentUser == "" {
+		return fmt.Errorf("not logged in; use login <username>")
+	}
+	fmt.Printf("Logged in as: %s\n", s.cfg.CurrentUser)
 	return nil
 }
 
-// handlerFeeds handles the feeds command to list all feeds
-func handlerFeeds(s *state, cmd command) error {
-	feeds, err := s.db.GetFeeds(context.Background())
-	if err != nil {
-		return fmt.Errorf("couldn't get feeds: %w", err)
+// handlerLogout clears the logged-in user from the config
+func handlerLogout(s *state, cmd command) error {
+	username := s.cfg.CurrentUser
+	if username == "" {
+		return fmt.Errorf("not logged in")
 	}
 
-	if len(feeds) == 0 {
-		fmt.Println("No feeds found.")
-		return nil
+	// A user missing from the database means the config is stale; log out anyway
+	_, err := s.db.GetUser(context.Background(), username)
+	if errors.Is(err, sql.ErrNoRows) {
+		fmt.Printf("Warning: config refers to unknown user %s\n", username)
+	} else if err != nil {
+		return fmt.Errorf("couldn't look up user %s: %w", username, err)
 	}
 
-	for _, feed := range feeds {
-		fmt.Printf("* %s (%s) - %s\n", feed.FeedName, feed.FeedUrl, feed.UserName)
+	if err := s.cfg.ClearUser(); err != nil {
+		return fmt.Errorf("couldn't clear current user: %w", err)
 	}
 
+	fmt.Println("Logged out.")
 	return nil
 }
 
-// handlerFollow handles the follow command to follow existing feeds by URL
-func handlerFollow(s *state, cmd command, user database.User) error {
-	if len(cmd.args) == 0 {
-		return fmt.Errorf("usage: %s <url>", cmd.name)
+// handlerMigrate applies pending schema migrations, or with --status lists them
+// and with --rollback undoes the most recent one
+func handlerMigrate(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	status := fs.Bool("status", false, "list applied and pending migrations")
+	rollback := fs.Bool("rollback", false, "undo the most recently applied migration")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--status | --rollback]: %w", cmd.name, err)
+	}
+	if *status && *rollback {
+		return fmt.Errorf("--status and --rollback can't be combined")
 	}
 
-	url := cmd.args[0]
-
-	// Get feed by URL
-	feed, err := s.db.GetFeedByURL(context.Background(), url)
-	if err != nil {
-		return fmt.Errorf("couldn't find feed with URL %s: %w", url, err)
+	ctx := context.Background()
+	if driver := s.cfg.Driver(); driver != config.DriverPostgres {
+		// Only PostgreSQL tracks migrations; other backends apply their whole schema
+		if *status || *rollback {
+			return fmt.Errorf("--status and --rollback only work with the %s driver, not %s", config.DriverPostgres, driver)
+		}
+		if err := s.backend.migrate(ctx, s.conn); err != nil {
+			return err
+		}
+		fmt.Println("Database is up to date")
+		return nil
 	}
 
-	// Create feed follow record
-	now := time.Now().UTC()
-	followParams := database.CreateFeedFollowParams{
-		ID:        uuid.New(),
-		CreatedAt: now,
-		UpdatedAt: now,
-		UserID:    user.ID,
-		FeedID:    feed.ID,
+	switch {
+	case *status:
+		statuses, err := migrations.GetStatus(ctx, s.conn)
+		if err != nil {
+			return fmt.Errorf("couldn't get migration status: %w", err)
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+		for _, st := range statuses {
+			applied := "pending"
+			if st.AppliedAt != nil {
+				applied = st.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%03d\t%s\t%s\n", st.Version, st.Name, applied)
+		}
+		return w.Flush()
+
+	case *rollback:
+		migration, ok, err := migrations.Rollback(ctx, s.conn)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			fmt.Println("No migrations to roll back")
+			return nil
+		}
+		fmt.Printf("Rolled back %03d_%s\n", migration.Version, migration.Name)
+		return nil
 	}
 
-	followRecord, err := s.db.CreateFeedFollow(context.Background(), followParams)
+	count := 0
+	err := migrations.Up(ctx, s.conn, func(migration migrations.Migration) {
+		fmt.Printf("Applied %03d_%s\n", migration.Version, migration.Name)
+		count++
+	})
 	if err != nil {
-		return fmt.Errorf("couldn't follow feed: %w", err)
+		return err
+	}
+	if count == 0 {
+		fmt.Println("Database is up to date")
+	}
+	return nil
+}
+
+// handlerVersion prints the module version, VCS commit and build date embedded at build time
+func handlerVersion(s *state, cmd command) error {
+	info := versionInfo()
+	if s.outputFormat == output.JSON {
+		return output.WriteJSON(os.Stdout, info)
 	}
 
-	fmt.Printf("Following %s by %s\n", followRecord.FeedName, followRecord.UserName)
+	fmt.Printf("%s %s\n", info.Module, info.Version)
+	if info.Commit != "" {
+		fmt.Printf("commit: %s\n", info.Commit)
+	}
+	if info.BuildDate != "" {
+		fmt.Printf("built: %s\n", info.BuildDate)
+	}
+	fmt.Printf("go: %s\n", info.GoVersion)
 	return nil
 }
 
-// handlerFollowing handles the following command to list feeds current user is following
-func handlerFollowing(s *state, cmd command, user database.User) error {
-	// Get feed follows for user
-	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+// versionInfo reads the build info; binaries built without module or VCS data
+// report a development build
+func versionInfo() output.VersionResult {
+	info := output.VersionResult{Module: "gator", Version: "development build", GoVersion: runtime.Version()}
+	build, ok := debug.ReadBuildInfo()
+	if !ok {
+		return info
+	}
+
+	info.Module = build.Main.Path
+	if build.Main.Version != "" && build.Main.Version != "(devel)" {
+		info.Version = build.Main.Version
+	}
+	for _, setting := range build.Settings {
+		switch setting.Key {
+		case "vcs.revision":
+			info.Commit = setting.Value
+		case "vcs.time":
+			info.BuildDate = setting.Value
+		}
+	}
+	return info
+}
+
+// handlerConfigShow prints the active config file and its contents, with passwords masked
+func handlerConfigShow(s *state, cmd command) error {
+	configPath, err := s.cfg.Path()
 	if err != nil {
-		return fmt.Errorf("couldn't get feed follows: %w", err)
+		return fmt.Errorf("couldn't find config file: %w", err)
 	}
 
-	if len(follows) == 0 {
-		fmt.Println("You are not following any feeds.")
+	data, err := json.MarshalIndent(s.cfg.Redacted(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("couldn't encode config: %w", err)
+	}
+	fmt.Printf("Config file: %s\n%s\n", configPath, data)
+	return nil
+}
+
+// handlerConfigEdit opens the config file in $EDITOR (vi when unset). The previous
+// content is saved next to it as a .bak file first, and is put back if the edited file
+// doesn't parse or fails validation.
+func handlerConfigEdit(s *state, cmd command) error {
+	if len(cmd.args) > 0 {
+		return fmt.Errorf("usage: %s", cmd.name)
+	}
+	configPath, err := s.cfg.Path()
+	if err != nil {
+		return fmt.Errorf("couldn't find config file: %w", err)
+	}
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		return fmt.Errorf("couldn't read config: %w", err)
+	}
+	backupPath := configPath + ".bak"
+	if err := os.WriteFile(backupPath, original, 0644); err != nil {
+		return fmt.Errorf("couldn't back up config: %w", err)
+	}
+
+	editor := strings.Fields(os.Getenv("EDITOR"))
+	if len(editor) == 0 {
+		editor = []string{"vi"}
+	}
+	edit := exec.Command(editor[0], append(editor[1:], configPath)...)
+	edit.Stdin = os.Stdin
+	edit.Stdout = os.Stdout
+	edit.Stderr = os.Stderr
+	if err := edit.Run(); err != nil {
+		// A failed editor, e.g. vim's :cq, may still have saved part of the file
+		err = fmt.Errorf("couldn't run editor %s: %w", editor[0], err)
+		if restoreErr := os.WriteFile(configPath, original, 0644); restoreErr != nil {
+			return fmt.Errorf("%w; couldn't restore the previous config from %s: %v", err, backupPath, restoreErr)
+		}
+		return fmt.Errorf("%w; the previous config was restored", err)
+	}
+
+	edited, err := config.ReadFrom(configPath)
+	if err != nil {
+		err = fmt.Errorf("couldn't parse config: %w", err)
+	} else {
+		err = config.Validate(edited)
+	}
+	if err != nil {
+		if restoreErr := os.WriteFile(configPath, original, 0644); restoreErr != nil {
+			return fmt.Errorf("%w; couldn't restore the previous config from %s: %v", err, backupPath, restoreErr)
+		}
+		return fmt.Errorf("%w; the previous config was restored", err)
+	}
+	fmt.Println("Config updated")
+	return nil
+}
+
+// handlerDBStats connects to the database and prints the connection pool's limits and
+// statistics. They cover this process only, so they mostly show whether the database
+// can be reached and how the pool is sized.
+func handlerDBStats(s *state, cmd command) error {
+	if len(cmd.args) > 0 {
+		return fmt.Errorf("usage: %s", cmd.name)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+	start := time.Now()
+	if err := s.conn.PingContext(ctx); err != nil {
+		return fmt.Errorf("couldn't connect to the database: %w", err)
+	}
+	pingTime := time.Since(start)
+
+	pool := s.cfg.DBPool()
+	stats := s.conn.Stats()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Max open:\t%d\n", stats.MaxOpenConnections)
+	fmt.Fprintf(w, "Max idle:\t%d\n", pool.MaxIdleConns)
+	fmt.Fprintf(w, "Max lifetime:\t%s\n", pool.ConnMaxLifetime)
+	fmt.Fprintf(w, "Max idle time:\t%s\n", pool.ConnMaxIdleTime)
+	fmt.Fprintf(w, "Open:\t%d\n", stats.OpenConnections)
+	fmt.Fprintf(w, "In use:\t%d\n", stats.InUse)
+	fmt.Fprintf(w, "Idle:\t%d\n", stats.Idle)
+	fmt.Fprintf(w, "Waits:\t%d\n", stats.WaitCount)
+	fmt.Fprintf(w, "Wait time:\t%s\n", stats.WaitDuration)
+	fmt.Fprintf(w, "Closed (idle limit):\t%d\n", stats.MaxIdleClosed)
+	fmt.Fprintf(w, "Closed (idle time):\t%d\n", stats.MaxIdleTimeClosed)
+	fmt.Fprintf(w, "Closed (lifetime):\t%d\n", stats.MaxLifetimeClosed)
+	fmt.Fprintf(w, "Ping:\t%s\n", pingTime.Round(time.Microsecond))
+	return w.Flush()
+}
+
+// handlerDBConnect points gator at another PostgreSQL database, such as a staging copy.
+// The URL is only saved once a SELECT 1 succeeds against it; --test stops after that
+// check. Switching logs the current user out.
+func handlerDBConnect(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	testOnly := fs.Bool("test", false, "check the connection without saving the URL")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %s <postgres://...> [--test]", cmd.name)
+	}
+	dbURL := args[0]
+	if err := config.Validate(config.Config{DbURL: dbURL}); err != nil {
+		return err
+	}
+	if err := pingPostgres(dbURL); err != nil {
+		return fmt.Errorf("couldn't connect to the database: %w", err)
+	}
+	if *testOnly {
+		fmt.Println("Connection OK")
 		return nil
 	}
 
-	fmt.Printf("Following feeds for %s:\n", user.Name)
-	for _, follow := range follows {
-		fmt.Printf("* %s\n", follow.FeedName)
+	if err := s.cfg.SetDBURL(dbURL); err != nil {
+		return fmt.Errorf("couldn't update config: %w", err)
+	}
+	fmt.Println("Connected; gator now uses the new database. Run gator login or gator register to pick a user.")
+	return nil
+}
+
+// pingPostgres opens dbURL and runs SELECT 1 against it
+func pingPostgres(dbURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+	db, err := backends[config.DriverPostgres].open(dbURL)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+	var one int
+	return db.QueryRowContext(ctx, "SELECT 1").Scan(&one)
+}
+
+// handlerDBSwitch moves gator's data to a database using the other driver: it creates
+// the schema there, copies every table across in one transaction and then points the
+// config at the new database. The old database is left as it was.
+func handlerDBSwitch(s *state, cmd command) error {
+	if len(cmd.args) != 2 {
+		return fmt.Errorf("usage: %s <postgres|sqlite> <db-url>", cmd.name)
+	}
+	driver, dbURL := cmd.args[0], cmd.args[1]
+	if driver == s.cfg.Driver() {
+		return fmt.Errorf("gator already uses %s; dbswitch moves data between postgres and sqlite", driver)
+	}
+	if err := config.Validate(config.Config{DbURL: dbURL, DbDriver: driver}); err != nil {
+		return err
+	}
+	target, err := lookupBackend(driver)
+	if err != nil {
+		return err
+	}
+	tables, err := migrations.CreationOrder()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	db, err := target.open(dbURL)
+	if err != nil {
+		return fmt.Errorf("couldn't open the %s database: %w", driver, err)
+	}
+	defer db.Close()
+	if err := target.migrate(ctx, db); err != nil {
+		return fmt.Errorf("couldn't create the %s schema: %w", driver, err)
+	}
+	users, err := target.queries(db).GetUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't check the %s database: %w", driver, err)
+	}
+	if len(users) > 0 {
+		return fmt.Errorf("the %s database already has users; dbswitch only copies into an empty database", driver)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	rows := 0
+	for _, table := range tables {
+		copied, err := copyTable(ctx, s.conn, tx, table)
+		if err != nil {
+			return fmt.Errorf("couldn't copy %s: %w", table, err)
+		}
+		rows += copied
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit the copy: %w", err)
 	}
 
+	if err := s.cfg.SetDatabase(driver, dbURL); err != nil {
+		return fmt.Errorf("couldn't update config: %w", err)
+	}
+	fmt.Printf("Copied %d row(s) from %d table(s); gator now uses the %s database\n", rows, len(tables), driver)
 	return nil
 }
 
-// handlerUnfollow allows a user to unfollow a feed by its URL
-func handlerUnfollow(s *state, cmd command, user database.User) error {
+// copyTable inserts every row of table in src into dst and returns how many it copied.
+// Only columns both databases have are copied, which leaves out SQLite's computed
+// posts.posted_at.
+func copyTable(ctx context.Context, src, dst database.DBTX, table string) (int, error) {
+	srcColumns, err := tableColumns(ctx, src, table)
+	if err != nil {
+		return 0, err
+	}
+	dstColumns, err := tableColumns(ctx, dst, table)
+	if err != nil {
+		return 0, err
+	}
+	var columns, placeholders []string
+	for _, column := range srcColumns {
+		if slices.Contains(dstColumns, column) {
+			columns = append(columns, column)
+			placeholders = append(placeholders, fmt.Sprintf("$%d", len(columns)))
+		}
+	}
+
+	insert, err := dst.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)",
+		table, strings.Join(columns, ", "), strings.Join(placeholders, ", ")))
+	if err != nil {
+		return 0, err
+	}
+	defer insert.Close()
+
+	rows, err := src.QueryContext(ctx, fmt.Sprintf("SELECT %s FROM %s", strings.Join(columns, ", "), table))
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+	values := make([]any, len(columns))
+	pointers := make([]any, len(columns))
+	for i := range values {
+		pointers[i] = &values[i]
+	}
+	copied := 0
+	for rows.Next() {
+		if err := rows.Scan(pointers...); err != nil {
+			return copied, err
+		}
+		for i, value := range values {
+			switch v := value.(type) {
+			case []byte:
+				// lib/pq hands UUIDs over as bytes, which SQLite would store as blobs
+				values[i] = string(v)
+			case time.Time:
+				values[i] = v.UTC()
+			}
+		}
+		if _, err := insert.ExecContext(ctx, values...); err != nil {
+			return copied, err
+		}
+		copied++
+	}
+	return copied, rows.Err()
+}
+
+// tableColumns lists table's columns in db
+func tableColumns(ctx context.Context, db database.DBTX, table string) ([]string, error) {
+	rows, err := db.QueryContext(ctx, fmt.Sprintf("SELECT * FROM %s WHERE 1 = 0", table))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return rows.Columns()
+}
+
+// Statuses a validate check ends with, best first
+const (
+	checkPass = "PASS"
+	checkWarn = "WARN"
+	checkFail = "FAIL"
+)
+
+// validateTimeout bounds the database checks so a hung server can't stall a health check
+const validateTimeout = 5 * time.Second
+
+// exitStatus is returned by commands whose outcome is their exit code; main exits with
+// it without printing anything more
+type exitStatus int
+
+func (e exitStatus) Error() string {
+	return fmt.Sprintf("exit status %d", int(e))
+}
+
+// handlerValidate checks the config, database, schema and followed feeds without
+// changing anything. It exits 0 when every check passes, 1 when one fails and 2 when
+// there are only warnings.
+func handlerValidate(s *state, cmd command) error {
+	if len(cmd.args) > 0 {
+		return fmt.Errorf("usage: %s", cmd.name)
+	}
+	result := output.ValidateResult{Checks: validateChecks(s)}
+	result.Status = worstStatus(result.Checks)
+
+	if s.outputFormat == output.JSON {
+		if err := output.WriteJSON(os.Stdout, result); err != nil {
+			return err
+		}
+	} else {
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		for _, check := range result.Checks {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", check.Status, check.Name, check.Detail)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	switch result.Status {
+	case checkFail:
+		return exitStatus(1)
+	case checkWarn:
+		return exitStatus(2)
+	}
+	return nil
+}
+
+// validateChecks runs validate's checks in order. Checks that need something an earlier
+// check found broken are reported as skipped warnings.
+func validateChecks(s *state) []output.ValidateCheck {
+	var checks []output.ValidateCheck
+	add := func(name, status, detail string) {
+		checks = append(checks, output.ValidateCheck{Name: name, Status: status, Detail: detail})
+	}
+	skip := func(name, reason string) {
+		add(name, checkWarn, "skipped: "+reason)
+	}
+
+	// The config is read again so a broken file is reported rather than stopping gator
+	cfg, err := config.Read()
+	configPath, _ := cfg.Path()
+	if err != nil {
+		add("config", checkFail, fmt.Sprintf("couldn't read %s: %v", configPath, err))
+	} else if err := config.Validate(cfg); err != nil {
+		add("config", checkFail, err.Error())
+	} else if _, err := proxy.NewTransport(cfg.ProxyURL, cfg.ProxyType, cfg.NoProxy); err != nil {
+		add("config", checkFail, fmt.Sprintf("invalid proxy: %v", err))
+	} else if _, err := lookupBackend(cfg.Driver()); err != nil {
+		add("config", checkFail, err.Error())
+	} else {
+		add("config", checkPass, configPath)
+	}
+	if checks[0].Status != checkPass {
+		for _, name := range []string{"database", "user", "tables", "feed urls"} {
+			skip(name, "config is invalid")
+		}
+		return checks
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), validateTimeout)
+	defer cancel()
+	if err := s.conn.PingContext(ctx); err != nil {
+		add("database", checkFail, fmt.Sprintf("couldn't connect: %v", err))
+		for _, name := range []string{"user", "tables", "feed urls"} {
+			skip(name, "no database connection")
+		}
+		return checks
+	}
+	add("database", checkPass, "connected")
+
+	var user *database.User
+	if cfg.CurrentUser == "" {
+		add("user", checkWarn, "nobody is logged in; run register or login")
+	} else if found, err := s.db.GetUser(ctx, cfg.CurrentUser); errors.Is(err, sql.ErrNoRows) {
+		add("user", checkFail, fmt.Sprintf("%s doesn't exist; run register or login", cfg.CurrentUser))
+	} else if err != nil {
+		add("user", checkFail, fmt.Sprintf("couldn't look up %s: %v", cfg.CurrentUser, err))
+	} else {
+		user = &found
+		add("user", checkPass, found.Name)
+	}
+
+	missing, err := s.backend.missingTables(ctx, s.conn)
+	switch {
+	case err != nil:
+		add("tables", checkFail, fmt.Sprintf("couldn't list tables: %v", err))
+	case len(missing) > 0:
+		add("tables", checkFail, fmt.Sprintf("missing %s; run migrate", strings.Join(missing, ", ")))
+	default:
+		add("tables", checkPass, "schema is complete")
+	}
+
+	if user == nil {
+		skip("feed urls", "no logged-in user")
+		return checks
+	}
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		add("feed urls", checkFail, fmt.Sprintf("couldn't get followed feeds: %v", err))
+		return checks
+	}
+	var invalid []string
+	for _, follow := range follows {
+		if !isHTTPURL(follow.FeedUrl) {
+			invalid = append(invalid, follow.FeedUrl)
+		}
+	}
+	if len(invalid) > 0 {
+		add("feed urls", checkFail, fmt.Sprintf("%d of %d followed feed(s) aren't http(s) URLs: %s", len(invalid), len(follows), strings.Join(invalid, ", ")))
+	} else {
+		add("feed urls", checkPass, fmt.Sprintf("%d followed feed(s)", len(follows)))
+	}
+	return checks
+}
+
+// worstStatus is FAIL if any check failed, WARN if any warned and PASS otherwise
+func worstStatus(checks []output.ValidateCheck) string {
+	status := checkPass
+	for _, check := range checks {
+		switch check.Status {
+		case checkFail:
+			return checkFail
+		case checkWarn:
+			status = checkWarn
+		}
+	}
+	return status
+}
+
+// isHTTPURL reports whether raw is an absolute http or https URL with a host
+func isHTTPURL(raw string) bool {
+	parsed, err := neturl.Parse(raw)
+	return err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != ""
+}
+
+// handlerProfile manages named config profiles: create, switch, list and delete
+func handlerProfile(s *state, cmd command) error {
+	usage := fmt.Errorf("usage: %s create <name> [--db-url URL] | switch <name> | list | delete <name>", cmd.name)
 	if len(cmd.args) < 1 {
-		return fmt.Errorf("usage: unfollow <feed-url>")
+		return usage
 	}
 
-	feedURL := cmd.args[0]
-	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	switch cmd.args[0] {
+	case "create":
+		fs := newFlagSet(cmd.name + " create")
+		dbURL := fs.String("db-url", "", "database for the profile (default: the current one)")
+		args, err := parseCommandFlags(fs, cmd.args[1:])
+		if err != nil || len(args) < 1 {
+			return usage
+		}
+		if err := s.cfg.CreateProfile(args[0], *dbURL); err != nil {
+			return fmt.Errorf("couldn't create profile: %w", err)
+		}
+		fmt.Printf("Created profile %s\n", args[0])
+		return nil
+
+	case "switch":
+		if len(cmd.args) < 2 {
+			return usage
+		}
+		if err := s.cfg.SwitchProfile(cmd.args[1]); err != nil {
+			return fmt.Errorf("couldn't switch profile: %w", err)
+		}
+		user := s.cfg.CurrentUser
+		if user == "" {
+			user = "nobody logged in"
+		}
+		fmt.Printf("Switched to profile %s (%s)\n", cmd.args[1], user)
+		return nil
+
+	case "list":
+		active := s.cfg.ActiveProfile
+		if active == "" {
+			active = config.DefaultProfile
+		}
+		for _, name := range append([]string{config.DefaultProfile}, s.cfg.ProfileNames()...) {
+			marker := " "
+			if name == active {
+				marker = "*"
+			}
+			fmt.Printf("%s %s\n", marker, name)
+		}
+		return nil
+
+	case "delete":
+		if len(cmd.args) < 2 {
+			return usage
+		}
+		if err := s.cfg.DeleteProfile(cmd.args[1]); err != nil {
+			return fmt.Errorf("couldn't delete profile: %w", err)
+		}
+		fmt.Printf("Deleted profile %s\n", cmd.args[1])
+		return nil
+	}
+	return usage
+}
+
+// handlerSetProxy saves or clears the proxy used for all outgoing HTTP requests
+func handlerSetProxy(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	clearProxy := fs.Bool("clear", false, "remove the proxy settings")
+	proxyType := fs.String("type", "", "proxy type: http or socks5 (default: from the URL scheme)")
+	noProxy := fs.String("no-proxy", "", "comma-separated domains that bypass the proxy")
+	args, err := parseCommandFlags(fs, cmd.args)
+	usage := fmt.Errorf("usage: %s <proxy-url> [--type http|socks5] [--no-proxy domain,...] | %s --clear", cmd.name, cmd.name)
 	if err != nil {
-		return fmt.Errorf("could not find feed with URL %s: %w", feedURL, err)
+		return fmt.Errorf("%w: %v", usage, err)
 	}
 
-	// Adjusted to handle two return values from DeleteFeedFollowByUserAndFeed
-	rowsAffected, err := s.db.DeleteFeedFollowByUserAndFeed(context.Background(), database.DeleteFeedFollowByUserAndFeedParams{
-		UserID: user.ID,
-		FeedID: feed.ID,
-	})
+	if *clearProxy {
+		if err := s.cfg.ClearProxy(); err != nil {
+			return fmt.Errorf("couldn't clear proxy: %w", err)
+		}
+		fmt.Println("Proxy cleared")
+		return nil
+	}
+	if len(args) < 1 {
+		return usage
+	}
+
+	if _, err := proxy.NewTransport(args[0], *proxyType, nil); err != nil {
+		return err
+	}
+	var domains []string
+	for _, domain := range strings.Split(*noProxy, ",") {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			domains = append(domains, domain)
+		}
+	}
+
+	if err := s.cfg.SetProxy(args[0], *proxyType, domains); err != nil {
+		return fmt.Errorf("couldn't save proxy: %w", err)
+	}
+	fmt.Printf("Proxy set to %s\n", redactProxyURL(args[0]))
+	if len(domains) > 0 {
+		fmt.Printf("Bypassing the proxy for: %s\n", strings.Join(domains, ", "))
+	}
+	return nil
+}
+
+// redactProxyURL hides the password of a proxy URL for display
+func redactProxyURL(raw string) string {
+	parsed, err := neturl.Parse(raw)
 	if err != nil {
-		return fmt.Errorf("could not unfollow feed: %w", err)
+		return raw
 	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("no feed follow record found to delete")
+	return parsed.Redacted()
+}
+
+// handlerUsers handles the users command
+func handlerUsers(s *state, cmd command) error {
+	users, err := s.db.GetUsers(context.Background())
+	if err != nil {
+		return fmt.Errorf("couldn't get users: %w", err)
+	}
+
+	currentUser := s.cfg.CurrentUser
+
+	if s.outputFormat == output.JSON {
+		result := output.UsersResult{Users: make([]output.UserJSON, 0, len(users))}
+		for _, user := range users {
+			result.Users = append(result.Users, output.UserJSON{Name: user.Name, Current: user.Name == currentUser})
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	for _, user := range users {
+		if user.Name == currentUser {
+			fmt.Printf("* %s (current)\n", user.Name)
+		} else {
+			fmt.Printf("* %s\n", user.Name)
+		}
+	}
+
+	return nil
+}
+
+// handlerAgg fetches all due feeds concurrently on every tick
+func handlerAgg(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	workers := fs.Int("workers", 5, "number of feeds fetched in parallel")
+	logLevel := fs.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logJSON := fs.Bool("log-json", false, "write logs as JSON")
+	metricsPort := fs.Int("metrics-port", 0, "serve Prometheus metrics on this port, e.g. 9090")
+	adaptive := fs.Bool("adaptive", false, "poll each feed about as often as it publishes")
+	resolveURLs := fs.Bool("resolve-urls", false, "store where new posts' links redirect to")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil {
+		return fmt.Errorf("usage: agg <time_between_reqs> [--workers N] [--log-level L] [--log-json] [--metrics-port N] [--adaptive] [--resolve-urls]: %w", err)
+	}
+	if len(args) < 1 {
+		return fmt.Errorf("usage: agg <time_between_reqs> [--workers N] [--log-level L] [--log-json] [--metrics-port N] [--adaptive] [--resolve-urls]")
+	}
+	if *workers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+	if *metricsPort < 0 || *metricsPort > 65535 {
+		return fmt.Errorf("--metrics-port must be between 1 and 65535")
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logJSON)
+	if err != nil {
+		return err
+	}
+	s.logger = logger
+	s.resolvePostURLs = *resolveURLs || s.cfg.ResolvePostURLs
+	if pool := s.cfg.DBPool(); *workers > pool.MaxOpenConns {
+		s.logger.Warn("more workers than database connections; raise db_max_open_conns or workers will queue",
+			"workers", *workers,
+			"db_max_open_conns", pool.MaxOpenConns,
+		)
+	}
+
+	timeBetweenReqs, err := time.ParseDuration(args[0])
+	if err != nil {
+		return fmt.Errorf("invalid duration: %v", err)
+	}
+
+	// Stop scraping cleanly on Ctrl+C or when the service wrapper stops us
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if *metricsPort > 0 {
+		s.metrics = metrics.New()
+		addr := fmt.Sprintf(":%d", *metricsPort)
+		go func() {
+			if err := metrics.Serve(ctx, addr, s.metrics); err != nil {
+				s.logger.Error("metrics server failed", "addr", addr, "error", err)
+			}
+		}()
+		fmt.Printf("Serving metrics on http://localhost%s/metrics\n", addr)
+	}
+
+	// Feeds with their own interval are only fetched once they are due, so the
+	// ticker acts as the minimum polling heartbeat
+	fmt.Printf("Collecting feeds every %s with %d workers\n", timeBetweenReqs, *workers)
+	ticker := time.NewTicker(timeBetweenReqs)
+	defer ticker.Stop()
+
+	for {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			// Errors are logged; the next tick tries again
+			scrapeAllDueFeeds(ctx, s, *workers, *adaptive)
+		}()
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("shutting down aggregator")
+			<-done // let in-flight workers finish their writes
+			return nil
+		case <-done:
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Println("shutting down aggregator")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// handlerAggOnce fetches the feeds that are due a single time and exits, for cron jobs
+// and scripts that don't want agg's loop
+func handlerAggOnce(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	workers := fs.Int("workers", 5, "number of feeds fetched in parallel")
+	logLevel := fs.String("log-level", "info", "minimum log level: debug, info, warn or error")
+	logJSON := fs.Bool("log-json", false, "write logs as JSON")
+	adaptive := fs.Bool("adaptive", false, "poll each feed about as often as it publishes")
+	resolveURLs := fs.Bool("resolve-urls", false, "store where new posts' links redirect to")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: aggonce [--workers N] [--log-level L] [--log-json] [--adaptive] [--resolve-urls]: %w", err)
+	}
+	if *workers < 1 {
+		return fmt.Errorf("--workers must be at least 1")
+	}
+
+	logger, err := newLogger(os.Stderr, *logLevel, *logJSON)
+	if err != nil {
+		return err
+	}
+	s.logger = logger
+	s.resolvePostURLs = *resolveURLs || s.cfg.ResolvePostURLs
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	run, err := scrapeAllDueFeeds(ctx, s, *workers, *adaptive)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Checked %d feed(s), saved %d new post(s)", run.feeds, run.saved)
+	if run.failed > 0 {
+		fmt.Printf(", %d failed", run.failed)
+	}
+	fmt.Println()
+	return nil
+}
+
+// refreshTimeout bounds the fetch behind the refresh command
+const refreshTimeout = 30 * time.Second
+
+// handlerRefresh fetches one feed straight away, whether or not it's due, and saves
+// its new posts the same way agg does
+func handlerRefresh(s *state, cmd command) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <feed-url>", cmd.name)
+	}
+
+	ctx := context.Background()
+	row, err := s.db.GetFeedByURL(ctx, cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", cmd.args[0], err)
+	}
+	feed, err := s.db.GetFeedByID(ctx, row.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed: %w", err)
+	}
+
+	counts, err := refreshFeed(ctx, s, feed)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Refreshed %s: %s\n", feed.Name, refreshSummary(counts))
+	return nil
+}
+
+// refreshFeed fetches a feed outside the schedule and saves its new posts, recording
+// the outcome on the feed like a scheduled fetch
+func refreshFeed(ctx context.Context, s *state, feed database.Feed) (feedItemCounts, error) {
+	// No validators are sent, so the server can't answer 304 and skip the items
+	fetchCtx, cancel := context.WithTimeout(ctx, refreshTimeout)
+	defer cancel()
+	client := feedClient(&http.Client{Timeout: fetchTimeout, Transport: httpTransport}, feed)
+	claimed, err := claimFeed(ctx, s, feed, client)
+	if err != nil {
+		return feedItemCounts{}, err
+	}
+	if !claimed {
+		return feedItemCounts{}, fmt.Errorf("%s was claimed for a fetch by agg moments ago; try again shortly", feed.Name)
+	}
+	rssFeed, result, err := fetchFeed(fetchCtx, client, feed.Url, feedCache{}, feed.UserAgent.String)
+	if err != nil {
+		if recordErr := s.db.MarkFeedFetchedError(ctx, database.MarkFeedFetchedErrorParams{ID: feed.ID, LastError: err.Error()}); recordErr != nil {
+			s.logger.Warn("couldn't record fetch error",
+				"feed_url", feed.Url,
+				"feed_id", feed.ID,
+				"error", recordErr,
+			)
+		}
+		return feedItemCounts{}, fmt.Errorf("couldn't fetch feed: %w", err)
+	}
+
+	if err := s.db.MarkFeedFetchedOK(ctx, feed.ID); err != nil {
+		return feedItemCounts{}, fmt.Errorf("couldn't mark feed as fetched: %w", err)
+	}
+	followPermanentRedirect(ctx, s, feed, result)
+	followChannelTitle(ctx, s, feed, rssFeed.Channel.Title)
+	counts, err := processFeedItems(ctx, s, feed, rssFeed)
+	if err != nil {
+		return counts, err
+	}
+	saveFeedCacheHeaders(ctx, s, feed, result.Cache)
+	return counts, nil
+}
+
+// handlerRefreshMeta renames a feed the user created, or with --all every one of them,
+// after the title its channel has now, e.g. when a site rebrands
+func handlerRefreshMeta(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	all := fs.Bool("all", false, "refresh every feed you created")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) > 1 || *all == (len(args) == 1) {
+		return fmt.Errorf("usage: %s <feed-url> | --all", cmd.name)
+	}
+
+	ctx := context.Background()
+	var ids []uuid.UUID
+	if *all {
+		owned, err := s.db.GetFeedsCreatedByUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get your feeds: %w", err)
+		}
+		for _, feed := range owned {
+			ids = append(ids, feed.ID)
+		}
+	} else {
+		row, err := s.db.GetFeedByURL(ctx, args[0])
+		if err != nil {
+			return fmt.Errorf("couldn't find feed with URL %s: %w", args[0], err)
+		}
+		if row.UserID != user.ID {
+			return fmt.Errorf("only the user who created %s can rename it", row.Name)
+		}
+		ids = append(ids, row.ID)
+	}
+
+	client := &http.Client{Timeout: fetchTimeout, Transport: httpTransport}
+	renamed := 0
+	for _, id := range ids {
+		feed, err := s.db.GetFeedByID(ctx, id)
+		if err != nil {
+			return fmt.Errorf("couldn't get feed: %w", err)
+		}
+		rssFeed, _, err := fetchFeed(ctx, client, feed.Url, feedCache{}, feed.UserAgent.String)
+		if err != nil {
+			if !*all {
+				return fmt.Errorf("couldn't fetch feed: %w", err)
+			}
+			fmt.Printf("Skipped %s: %v\n", feed.Name, err)
+			continue
+		}
+		name, ok := renamedFeedTitle(feed.Name, rssFeed.Channel.Title)
+		if !ok {
+			continue
+		}
+		if err := s.db.UpdateFeedName(ctx, database.UpdateFeedNameParams{ID: feed.ID, Name: name}); err != nil {
+			return fmt.Errorf("couldn't rename %s: %w", feed.Name, err)
+		}
+		fmt.Printf("Updated: '%s' → '%s'\n", feed.Name, name)
+		renamed++
+	}
+
+	if *all {
+		fmt.Printf("Renamed %d of %d feed(s).\n", renamed, len(ids))
+	} else if renamed == 0 {
+		fmt.Println("The feed's name already matches its channel title.")
+	}
+	return nil
+}
+
+// refreshSummary describes what a refresh did with a feed's items
+func refreshSummary(counts feedItemCounts) string {
+	summary := fmt.Sprintf("%d new post(s), %d duplicate(s)", counts.saved, counts.duplicates)
+	if counts.filtered > 0 {
+		summary += fmt.Sprintf(", %d filtered out", counts.filtered)
+	}
+	return summary
+}
+
+// newLogger builds the structured logger for the given level name, writing text or JSON records
+func newLogger(w io.Writer, level string, asJSON bool) (*slog.Logger, error) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(level)); err != nil {
+		return nil, fmt.Errorf("invalid log level %q: use debug, info, warn or error", level)
+	}
+
+	opts := &slog.HandlerOptions{Level: lvl}
+	if asJSON {
+		return slog.New(slog.NewJSONHandler(w, opts)), nil
+	}
+	return slog.New(slog.NewTextHandler(w, opts)), nil
+}
+
+// handlerAddfeed handles the addfeed command to create new feeds
+func handlerAddfeed(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("usage: %s <name|auto> <url>", cmd.name)
+	}
+
+	name := cmd.args[0]
+
+	url, err := discoverFeedURL(context.Background(), cmd.args[1])
+	if err != nil {
+		return err
+	}
+	// "auto" names the feed after its channel title
+	if name == "auto" {
+		client := &http.Client{Timeout: fetchTimeout, Transport: httpTransport}
+		name, err = feedNameFor(context.Background(), client, url, false)
+		if err != nil {
+			return fmt.Errorf("couldn't fetch %s for its title: %w", url, err)
+		}
+	}
+
+	feed, err := createFeedWithFollow(context.Background(), s, user, name, url)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Feed created successfully!\n")
+	fmt.Printf("Feed data: %+v\n", feed)
+	return nil
+}
+
+// discoverFeedURL resolves pageURL to a feed URL, asking the user to choose
+// when the page advertises more than one feed
+func discoverFeedURL(ctx context.Context, pageURL string) (string, error) {
+	links, err := discover.FindFeedURL(ctx, pageURL)
+	if err != nil {
+		return "", err
+	}
+	if len(links) == 1 {
+		if links[0] != pageURL {
+			fmt.Printf("Discovered feed: %s\n", links[0])
+		}
+		return links[0], nil
+	}
+
+	fmt.Printf("Found %d feeds on %s:\n", len(links), pageURL)
+	for i, link := range links {
+		fmt.Printf("  [%d] %s\n", i+1, link)
+	}
+
+	line, err := promptLine("Choose a feed: ")
+	if err != nil {
+		return "", fmt.Errorf("couldn't read choice: %w", err)
+	}
+	choice, err := strconv.Atoi(line)
+	if err != nil || choice < 1 || choice > len(links) {
+		return "", fmt.Errorf("invalid choice: %q", line)
+	}
+	return links[choice-1], nil
+}
+
+// stdin is shared by every prompt so input buffered for one isn't lost to the next
+var stdin = bufio.NewReader(os.Stdin)
+
+// promptLine prints prompt and reads one trimmed line of input from stdin
+func promptLine(prompt string) (string, error) {
+	fmt.Print(prompt)
+	line, err := stdin.ReadString('\n')
+	if err != nil && line == "" {
+		return "", err
+	}
+	return strings.TrimSpace(line), nil
+}
+
+// createFeedWithFollow creates a feed owned by user and automatically follows it
+func createFeedWithFollow(ctx context.Context, s *state, user database.User, name, url string) (database.CreateFeedRow, error) {
+	// Create new feed
+	now := time.Now().UTC()
+	feedParams := database.CreateFeedParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		Name:      name,
+		Url:       url,
+		UserID:    user.ID,
+	}
+
+	feed, err := s.db.CreateFeed(ctx, feedParams)
+	if err != nil {
+		return feed, fmt.Errorf("couldn't create feed: %w", err)
+	}
+
+	// Automatically create a feed follow record for the user who created the feed
+	followParams := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+
+	_, err = s.db.CreateFeedFollow(ctx, followParams)
+	if err != nil {
+		return feed, fmt.Errorf("couldn't create feed follow: %w", err)
+	}
+
+	return feed, nil
+}
+
+// handlerImportOPML subscribes to every feed listed in an OPML file
+func handlerImportOPML(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	folderPrefix := fs.Bool("folder-prefix", false, "prepend the OPML folder to each feed name")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: %s <file> [--folder-prefix]", cmd.name)
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't open OPML file: %w", err)
+	}
+	defer file.Close()
+
+	doc, err := opml.Parse(file)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	imported, duplicates, failed := 0, 0, 0
+	groups := make(map[string]uuid.UUID) // OPML folders become feed groups of the same name
+	for _, entry := range doc.Feeds() {
+		name := entry.Name
+		if *folderPrefix && entry.Folder != "" {
+			name = entry.Folder + "/" + name
+		}
+
+		if _, err := s.db.GetFeedByURL(ctx, entry.URL); err == nil {
+			fmt.Printf("warning: skipping %s, feed already exists\n", entry.URL)
+			duplicates++
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("warning: couldn't check %s: %v\n", entry.URL, err)
+			failed++
+			continue
+		}
+
+		feed, err := createFeedWithFollow(ctx, s, user, name, entry.URL)
+		if err != nil {
+			fmt.Printf("warning: couldn't import %s: %v\n", entry.URL, err)
+			failed++
+			continue
+		}
+		imported++
+
+		if entry.Folder != "" {
+			if err := addImportedFeedToGroup(ctx, s, user, groups, entry.Folder, feed.ID); err != nil {
+				fmt.Printf("warning: couldn't add %s to group %s: %v\n", entry.URL, entry.Folder, err)
+			}
+		}
+	}
+
+	fmt.Printf("Imported %d feeds, skipped %d duplicates, failed %d\n", imported, duplicates, failed)
+	return nil
+}
+
+// addImportedFeedToGroup puts an imported feed in the group named after its OPML
+// folder, creating the group the first time the folder comes up
+func addImportedFeedToGroup(ctx context.Context, s *state, user database.User, groups map[string]uuid.UUID, folder string, feedID uuid.UUID) error {
+	groupID, ok := groups[folder]
+	if !ok {
+		group, err := s.db.GetFeedGroupByName(ctx, database.GetFeedGroupByNameParams{UserID: user.ID, Name: folder})
+		if errors.Is(err, sql.ErrNoRows) {
+			group, err = createFeedGroup(ctx, s, user, folder)
+		}
+		if err != nil {
+			return err
+		}
+		groupID = group.ID
+		groups[folder] = groupID
+	}
+	_, err := s.db.AddFeedToGroup(ctx, database.AddFeedToGroupParams{GroupID: groupID, FeedID: feedID})
+	return err
+}
+
+// handlerImportURLs subscribes to every feed URL listed one per line in a text file
+func handlerImportURLs(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	noFetch := fs.Bool("no-fetch", false, "name feeds after their hostname instead of fetching the title")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: %s <file> [name-prefix] [--no-fetch]", cmd.name)
+	}
+
+	prefix := ""
+	if len(args) > 1 {
+		prefix = args[1]
+	}
+
+	file, err := os.Open(args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't open URL list: %w", err)
+	}
+	defer file.Close()
+
+	ctx := context.Background()
+	client := &http.Client{Timeout: fetchTimeout, Transport: httpTransport}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		feedURL := strings.TrimSpace(scanner.Text())
+		if feedURL == "" || strings.HasPrefix(feedURL, "#") {
+			continue
+		}
+
+		fmt.Printf("Adding: %s ... ", feedURL)
+		if _, err := s.db.GetFeedByURL(ctx, feedURL); err == nil {
+			fmt.Println("duplicate")
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+
+		name, err := feedNameFor(ctx, client, feedURL, *noFetch)
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+		if prefix != "" {
+			name = prefix + " " + name
+		}
+
+		if _, err := createFeedWithFollow(ctx, s, user, name, feedURL); err != nil {
+			fmt.Printf("error: %v\n", err)
+			continue
+		}
+		fmt.Println("ok")
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("couldn't read URL list: %w", err)
+	}
+	return nil
+}
+
+// feedNameFor picks a display name for a feed, either its channel title or its hostname
+func feedNameFor(ctx context.Context, client *http.Client, feedURL string, noFetch bool) (string, error) {
+	hostname := feedURL
+	if parsed, err := neturl.Parse(feedURL); err == nil && parsed.Hostname() != "" {
+		hostname = parsed.Hostname()
+	}
+	if noFetch {
+		return hostname, nil
+	}
+
+	rssFeed, _, err := fetchFeed(ctx, client, feedURL, feedCache{}, "")
+	if err != nil {
+		return "", err
+	}
+	if title := strings.TrimSpace(rssFeed.Channel.Title); title != "" {
+		return title, nil
+	}
+	return hostname, nil
+}
+
+// handlerExportOPML writes the current user's subscriptions as an OPML document
+func handlerExportOPML(s *state, cmd command, user database.User) error {
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	members, err := s.db.GetFeedGroupMembersForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed groups: %w", err)
+	}
+	feedGroups := groupsByFeed(members)
+
+	// OPML outlines nest in a single folder, so a feed in several groups goes in the first
+	feeds := make([]opml.Feed, 0, len(follows))
+	for _, follow := range follows {
+		folder := ""
+		if groups := feedGroups[follow.FeedID]; len(groups) > 0 {
+			folder = groups[0]
+		}
+		feeds = append(feeds, opml.Feed{
+			Name:    follow.FeedName,
+			URL:     follow.FeedUrl,
+			SiteURL: siteURL(follow.FeedUrl),
+			Folder:  folder,
+		})
+	}
+	doc := opml.New(fmt.Sprintf("Gator export for %s", user.Name), time.Now().UTC(), feeds)
+
+	// Write to stdout unless a file path is given
+	if len(cmd.args) == 0 {
+		return doc.Write(os.Stdout)
+	}
+
+	file, err := os.Create(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't create export file: %w", err)
+	}
+	if err := doc.Write(file); err != nil {
+		file.Close()
+		return fmt.Errorf("couldn't write export file: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("couldn't write export file: %w", err)
+	}
+
+	fmt.Printf("Exported %d feeds to %s\n", len(feeds), cmd.args[0])
+	return nil
+}
+
+// exportPageSize is how many posts the export command reads per query
+const exportPageSize = 500
+
+// handlerExport writes the current user's posts, with their feeds, as JSON or CSV
+func handlerExport(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	format := fs.String("format", "", "json or csv; defaults to the global --format, or json")
+	outputPath := fs.String("output", "", "file to write instead of stdout")
+	period := fs.String("since", "", "only posts published within this age, e.g. 30d")
+	feedURL := fs.String("feed", "", "only posts from the feed with this URL")
+	usage := fmt.Sprintf("usage: %s [--format json|csv] [--output file] [--since 30d] [--feed url]", cmd.name)
+	if rest, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("%s: %w", usage, err)
+	} else if len(rest) > 0 {
+		return errors.New(usage)
+	}
+
+	// The global --format picks the format too, as long as the two don't disagree
+	if s.outputFormat != output.Text {
+		if *format != "" && *format != s.outputFormat {
+			return fmt.Errorf("--format %s conflicts with the global --format %s", *format, s.outputFormat)
+		}
+		*format = s.outputFormat
+	}
+	if *format == "" {
+		*format = output.JSON
+	}
+
+	var since time.Time
+	if *period != "" {
+		var err error
+		since, err = parseAge(*period, time.Now().UTC())
+		if err != nil {
+			return fmt.Errorf("invalid --since: %w", err)
+		}
+	}
+
+	ctx := context.Background()
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	feeds := make(map[uuid.UUID]database.GetFeedFollowsForUserRow, len(follows))
+	var onlyFeed uuid.UUID
+	for _, follow := range follows {
+		feeds[follow.FeedID] = follow
+		if *feedURL != "" && follow.FeedUrl == *feedURL {
+			onlyFeed = follow.FeedID
+		}
+	}
+	if *feedURL != "" && onlyFeed == uuid.Nil {
+		return fmt.Errorf("you don't follow a feed with URL %s", *feedURL)
+	}
+
+	out := io.Writer(os.Stdout)
+	var file *os.File
+	if *outputPath != "" {
+		file, err = os.Create(*outputPath)
+		if err != nil {
+			return fmt.Errorf("couldn't create export file: %w", err)
+		}
+		defer file.Close()
+		out = file
+	}
+
+	var w export.Writer
+	switch *format {
+	case "json":
+		w = export.NewJSONWriter(out)
+	case "csv":
+		w = export.NewCSVWriter(out)
+	default:
+		return fmt.Errorf("unknown format %q: use json or csv", *format)
+	}
+
+	// Posts come newest first, so paging stops at the first one older than --since
+	exported := 0
+	for offset := 0; ; offset += exportPageSize {
+		posts, err := s.db.GetPostsForUserPaginated(ctx, database.GetPostsForUserPaginatedParams{
+			UserID: user.ID,
+			Limit:  exportPageSize,
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't get posts: %w", err)
+		}
+
+		page := make([]export.ExportPost, 0, len(posts))
+		done := len(posts) < exportPageSize
+		for _, post := range posts {
+			postTime := post.CreatedAt
+			if post.PublishedAt.Valid {
+				postTime = post.PublishedAt.Time
+			}
+			if !since.IsZero() && postTime.Before(since) {
+				done = true
+				break
+			}
+			if onlyFeed != uuid.Nil && post.FeedID != onlyFeed {
+				continue
+			}
+			page = append(page, exportPost(post, feeds[post.FeedID]))
+		}
+
+		if err := w.Write(page); err != nil {
+			return fmt.Errorf("couldn't write export: %w", err)
+		}
+		exported += len(page)
+		if done {
+			break
+		}
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("couldn't write export: %w", err)
+	}
+
+	if file != nil {
+		if err := file.Close(); err != nil {
+			return fmt.Errorf("couldn't write export file: %w", err)
+		}
+		fmt.Printf("Exported %d posts to %s\n", exported, *outputPath)
+	}
+	return nil
+}
+
+// exportPost converts a post and the feed it belongs to into the export shape
+func exportPost(post database.Post, feed database.GetFeedFollowsForUserRow) export.ExportPost {
+	exported := export.ExportPost{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: post.Description.String,
+		CreatedAt:   post.CreatedAt,
+		UpdatedAt:   post.UpdatedAt,
+		FeedID:      post.FeedID,
+		FeedName:    feed.FeedName,
+		FeedURL:     feed.FeedUrl,
+	}
+	if post.PublishedAt.Valid {
+		exported.PublishedAt = &post.PublishedAt.Time
+	}
+	return exported
+}
+
+// siteURL guesses a feed's website from its URL since only the feed URL is stored
+func siteURL(feedURL string) string {
+	parsed, err := neturl.Parse(feedURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	return parsed.Scheme + "://" + parsed.Host + "/"
+}
+
+// handlerFeeds handles the feeds command to list all feeds
+func handlerFeeds(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	verbose := fs.Bool("verbose", false, "show each feed's last fetch, post count, errors and polling interval")
+	fs.BoolVar(verbose, "v", false, "shorthand for --verbose")
+	showTags := fs.Bool("tags", false, "show each feed's tags")
+	limit := fs.Int("limit", 0, "maximum number of feeds to show (0 for all)")
+	offset := fs.Int("offset", 0, "number of feeds to skip")
+	page := fs.Int("page", 0, "show this page of --limit feeds (20 when no limit is given)")
+	filter := fs.String("filter", "", "only show feeds whose name contains this")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--verbose|-v] [--tags] [--limit N] [--offset M | --page P] [--filter F]: %w", cmd.name, err)
+	}
+	pages, err := newFeedPage(*limit, *offset, *page, *filter)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	podcastIDs, err := s.db.GetPodcastFeedIDs(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get podcast feeds: %w", err)
+	}
+	podcasts := make(map[uuid.UUID]bool, len(podcastIDs))
+	for _, id := range podcastIDs {
+		podcasts[id] = true
+	}
+
+	if *verbose || s.outputFormat == output.JSON {
+		return printFeedsVerbose(ctx, s, pages, *showTags, podcasts)
+	}
+
+	feeds, err := s.db.GetFeeds(ctx, database.GetFeedsParams{
+		Filter: pages.pattern(),
+		Limit:  int32(pages.limit),
+		Offset: int32(pages.offset),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get feeds: %w", err)
+	}
+	if len(feeds) == 0 && !pages.active() {
+		fmt.Println("No feeds found.")
+		return nil
+	}
+
+	for _, feed := range feeds {
+		podcast := ""
+		if podcasts[feed.FeedID] {
+			podcast = " (podcast)"
+		}
+		fmt.Printf("* %s (%s) - %s%s\n", feed.FeedName, feed.FeedUrl, feed.UserName, podcast)
+		if !*showTags {
+			continue
+		}
+		tags, err := s.db.GetTagsForFeed(ctx, feed.FeedID)
+		if err != nil {
+			return fmt.Errorf("couldn't get tags for %s: %w", feed.FeedName, err)
+		}
+		if len(tags) > 0 {
+			fmt.Printf("  Tags: %s\n", strings.Join(tags, ", "))
+		}
+	}
+
+	return printFeedPageFooter(ctx, s, pages, len(feeds))
+}
+
+// feedPage is the slice of feeds the feeds command shows
+type feedPage struct {
+	limit, offset int
+	filter        string
+}
+
+// newFeedPage checks the feeds command's paging flags. --page counts from 1 and turns
+// into an offset, using pages of 20 feeds unless --limit says otherwise.
+func newFeedPage(limit, offset, page int, filter string) (feedPage, error) {
+	if limit < 0 || offset < 0 || page < 0 {
+		return feedPage{}, fmt.Errorf("--limit, --offset and --page can't be negative")
+	}
+	if page > 0 {
+		if offset > 0 {
+			return feedPage{}, fmt.Errorf("--offset and --page can't be combined")
+		}
+		if limit == 0 {
+			limit = 20
+		}
+		offset = (page - 1) * limit
+	}
+	return feedPage{limit: limit, offset: offset, filter: filter}, nil
+}
+
+// active reports whether only part of the feeds is shown
+func (p feedPage) active() bool {
+	return p.limit > 0 || p.offset > 0 || p.filter != ""
+}
+
+// pattern is the LIKE pattern for the filter, with LIKE's wildcards in it taken literally
+func (p feedPage) pattern() string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(p.filter)
+	return "%" + escaped + "%"
+}
+
+// printFeedPageFooter says which feeds were shown, e.g. "Showing 21-40 of 153", when
+// the list was paged or filtered
+func printFeedPageFooter(ctx context.Context, s *state, pages feedPage, shown int) error {
+	if !pages.active() {
+		return nil
+	}
+	total, err := s.db.CountFeedsMatching(ctx, pages.pattern())
+	if err != nil {
+		return fmt.Errorf("couldn't count feeds: %w", err)
+	}
+	fmt.Println(feedPageFooter(pages.offset, shown, total))
+	return nil
+}
+
+// feedPageFooter describes shown feeds starting after offset out of total
+func feedPageFooter(offset, shown int, total int64) string {
+	if shown == 0 {
+		return fmt.Sprintf("Showing 0 of %d", total)
+	}
+	return fmt.Sprintf("Showing %d-%d of %d", offset+1, offset+shown, total)
+}
+
+// printFeedsVerbose lists every feed with its fetch health in aligned columns, or as
+// JSON. Feeds with errors are printed in red when the terminal supports colour.
+func printFeedsVerbose(ctx context.Context, s *state, pages feedPage, showTags bool, podcasts map[uuid.UUID]bool) error {
+	feeds, err := s.db.GetFeedsVerbose(ctx, database.GetFeedsVerboseParams{
+		Filter: pages.pattern(),
+		Limit:  int32(pages.limit),
+		Offset: int32(pages.offset),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get feeds: %w", err)
+	}
+
+	tags := map[uuid.UUID][]string{}
+	if showTags {
+		for _, feed := range feeds {
+			feedTags, err := s.db.GetTagsForFeed(ctx, feed.FeedID)
+			if err != nil {
+				return fmt.Errorf("couldn't get tags for %s: %w", feed.FeedName, err)
+			}
+			tags[feed.FeedID] = feedTags
+		}
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.FeedsResult{Feeds: make([]output.FeedJSON, 0, len(feeds))}
+		for _, feed := range feeds {
+			var lastFetched *time.Time
+			if feed.LastFetchedAt.Valid {
+				lastFetched = &feed.LastFetchedAt.Time
+			}
+			result.Feeds = append(result.Feeds, output.FeedJSON{
+				ID:                feed.FeedID,
+				Name:              feed.FeedName,
+				URL:               feed.FeedUrl,
+				User:              feed.UserName,
+				Tags:              tags[feed.FeedID],
+				ConsecutiveErrors: feed.ConsecutiveErrors,
+				LastError:         feed.LastError.String,
+				Podcast:           podcasts[feed.FeedID],
+				IntervalSecs:      feed.IntervalSecs.Int32,
+				AdaptiveSecs:      feed.AdaptiveIntervalSecs.Int32,
+				LastFetchedAt:     lastFetched,
+				PostCount:         feed.PostCount,
+			})
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(feeds) == 0 && !pages.active() {
+		fmt.Println("No feeds found.")
+		return nil
+	}
+
+	// Every line starts with a colour code of the same length, red or default, so the
+	// codes don't throw the columns out of line
+	color := os.Getenv("TERM") != "dumb" && term.IsTerminal(int(os.Stdout.Fd())) && !pager.NoColor()
+	lineStart := func(failing bool) string {
+		switch {
+		case !color:
+			return ""
+		case failing:
+			return ansiRed
+		default:
+			return ansiDefault
+		}
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	header := "NAME\tURL\tUSER\tLAST FETCHED\tPOSTS\tERRORS\tINTERVAL"
+	if showTags {
+		header += "\tTAGS"
+	}
+	fmt.Fprintln(w, lineStart(false)+header)
+	for _, feed := range feeds {
+		name := feed.FeedName
+		if podcasts[feed.FeedID] {
+			name += " (podcast)"
+		}
+		lastFetched := "never"
+		if feed.LastFetchedAt.Valid {
+			lastFetched = relativeTime(feed.LastFetchedAt.Time, now)
+		}
+		line := fmt.Sprintf("%s%s\t%s\t%s\t%s\t%d\t%d\t%s",
+			lineStart(feed.ConsecutiveErrors > 0),
+			name,
+			feed.FeedUrl,
+			feed.UserName,
+			lastFetched,
+			feed.PostCount,
+			feed.ConsecutiveErrors,
+			describeInterval(feed.IntervalSecs, feed.AdaptiveIntervalSecs),
+		)
+		if showTags {
+			line += "\t" + strings.Join(tags[feed.FeedID], ", ")
+		}
+		if color {
+			line += ansiDefault
+		}
+		fmt.Fprintln(w, line)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return printFeedPageFooter(ctx, s, pages, len(feeds))
+}
+
+// ANSI colour codes used by feeds --verbose; both are 5 bytes long
+const (
+	ansiRed     = "\x1b[31m"
+	ansiDefault = "\x1b[39m"
+)
+
+// relativeTime says how long before now t was, e.g. "2h ago"
+func relativeTime(t, now time.Time) string {
+	ago := now.Sub(t)
+	switch {
+	case ago < time.Minute:
+		return "just now"
+	case ago < time.Hour:
+		return fmt.Sprintf("%dm ago", int(ago.Minutes()))
+	case ago < 24*time.Hour:
+		return fmt.Sprintf("%dh ago", int(ago.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(ago.Hours()/24))
+	}
+}
+
+// describeInterval says how often agg polls a feed: its own setinterval value, else the
+// interval learned by agg --adaptive, else every tick
+func describeInterval(intervalSecs, adaptiveSecs sql.NullInt32) string {
+	switch {
+	case intervalSecs.Valid:
+		return fmt.Sprintf("%s (setinterval)", time.Duration(intervalSecs.Int32)*time.Second)
+	case adaptiveSecs.Valid:
+		return fmt.Sprintf("%s (adaptive, with agg --adaptive)", time.Duration(adaptiveSecs.Int32)*time.Second)
+	default:
+		return "every agg tick"
+	}
+}
+
+// resolveFeedFilter turns a --feed value into a feed ID: a UUID is used as is, anything
+// else must pick out one feed by part of its name or URL
+func resolveFeedFilter(ctx context.Context, s *state, raw string) (uuid.NullUUID, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return uuid.NullUUID{}, nil
+	}
+	if id, err := uuid.Parse(raw); err == nil {
+		return uuid.NullUUID{UUID: id, Valid: true}, nil
+	}
+	feeds, err := s.db.SearchFeedsByName(ctx, raw)
+	if err != nil {
+		return uuid.NullUUID{}, fmt.Errorf("couldn't search feeds: %w", err)
+	}
+	feed, err := pickFeed(raw, feeds)
+	if err != nil {
+		return uuid.NullUUID{}, err
+	}
+	return uuid.NullUUID{UUID: feed.ID, Valid: true}, nil
+}
+
+// pickFeed returns the one feed a name search found. A feed named exactly raw wins over
+// the others so a name contained in another feed's can still be picked.
+func pickFeed(raw string, feeds []database.Feed) (database.Feed, error) {
+	switch {
+	case len(feeds) == 0:
+		return database.Feed{}, fmt.Errorf("no feed matches %q", raw)
+	case len(feeds) == 1, strings.EqualFold(feeds[0].Name, raw):
+		return feeds[0], nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "%q matches %d feeds; be more specific or use a feed ID:", raw, len(feeds))
+	for _, feed := range feeds {
+		fmt.Fprintf(&b, "\n  %s (%s)", feed.Name, feed.Url)
+	}
+	return database.Feed{}, errors.New(b.String())
+}
+
+// handlerFindFeed looks feeds up by part of their name or URL, marking those the
+// current user already follows
+func handlerFindFeed(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 1 || strings.TrimSpace(cmd.args[0]) == "" {
+		return fmt.Errorf("usage: %s <name-or-url-substring>", cmd.name)
+	}
+
+	ctx := context.Background()
+	feeds, err := s.db.SearchFeedsByName(ctx, strings.TrimSpace(cmd.args[0]))
+	if err != nil {
+		return fmt.Errorf("couldn't search feeds: %w", err)
+	}
+	if len(feeds) == 0 && s.outputFormat != output.JSON {
+		fmt.Printf("No feeds match %q.\n", cmd.args[0])
+		return nil
+	}
+
+	users, err := s.db.GetUsers(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get users: %w", err)
+	}
+	creators := make(map[uuid.UUID]string, len(users))
+	for _, u := range users {
+		creators[u.ID] = u.Name
+	}
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	following := make(map[uuid.UUID]bool, len(follows))
+	for _, follow := range follows {
+		following[follow.FeedID] = true
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.FindFeedResult{Query: strings.TrimSpace(cmd.args[0]), Feeds: make([]output.FoundFeedJSON, 0, len(feeds))}
+		for _, feed := range feeds {
+			result.Feeds = append(result.Feeds, output.FoundFeedJSON{
+				ID:        feed.ID,
+				Name:      feed.Name,
+				URL:       feed.Url,
+				Creator:   creators[feed.UserID],
+				Following: following[feed.ID],
+			})
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tURL\tCREATOR\tFOLLOWING")
+	for _, feed := range feeds {
+		followed := "no"
+		if following[feed.ID] {
+			followed = "yes"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", feed.Name, feed.Url, creators[feed.UserID], followed)
+	}
+	return w.Flush()
+}
+
+// handlerUpgradeFeeds switches http:// feeds whose servers now redirect to https:// over
+// to the https URL, saving a redirect on every fetch
+func handlerUpgradeFeeds(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	dryRun := fs.Bool("dry-run", false, "show what would change without saving it")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--dry-run]: %w", cmd.name, err)
+	}
+
+	ctx := context.Background()
+	feeds, err := s.db.GetHTTPFeeds(ctx)
+	if err != nil {
+		return fmt.Errorf("couldn't get feeds: %w", err)
+	}
+	if len(feeds) == 0 {
+		fmt.Println("No feeds use http://.")
+		return nil
+	}
+
+	client := &http.Client{Timeout: fetchTimeout, Transport: httpTransport}
+	upgraded := 0
+	for _, feed := range feeds {
+		finalURL, err := redirectTarget(ctx, client, feed.Url)
+		if err != nil {
+			s.logger.Warn("couldn't check feed for an https redirect",
+				"feed_url", feed.Url,
+				"feed_id", feed.ID,
+				"error", err,
+			)
+			continue
+		}
+
+		switch classifyUpgrade(feed.Url, finalURL) {
+		case upgradeNone:
+			continue
+		case upgradeDomainChanged:
+			fmt.Printf("%s → %s (domain changed - review manually)\n", feed.Url, finalURL)
+			continue
+		}
+
+		if existing, err := s.db.GetFeedByURL(ctx, finalURL); err == nil {
+			fmt.Printf("%s → %s (already stored as %s - merge with updatefeed)\n", feed.Url, finalURL, existing.Name)
+			continue
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("couldn't look up feed %s: %w", finalURL, err)
+		}
+
+		fmt.Printf("%s → %s\n", feed.Url, finalURL)
+		if *dryRun {
+			continue
+		}
+		if err := s.db.UpdateFeedURL(ctx, database.UpdateFeedURLParams{ID: feed.ID, Url: finalURL}); err != nil {
+			return fmt.Errorf("couldn't update feed URL: %w", err)
+		}
+		upgraded++
+	}
+
+	if *dryRun {
+		fmt.Println("Dry run: no feeds were changed.")
+		return nil
+	}
+	fmt.Printf("Upgraded %d feed(s) to https.\n", upgraded)
+	return nil
+}
+
+// redirectTarget follows feedURL's redirects with a HEAD request and returns where they
+// end. Servers that don't allow HEAD are asked with GET instead.
+func redirectTarget(ctx context.Context, client *http.Client, feedURL string) (string, error) {
+	resp, err := probeRequest(ctx, client, http.MethodHead, feedURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = probeRequest(ctx, client, http.MethodGet, feedURL)
+	}
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", fmt.Errorf("server answered %s", resp.Status)
+	}
+	return resp.Request.URL.String(), nil
+}
+
+// feedUpgrade is what upgradefeeds does with a feed
+type feedUpgrade int
+
+const (
+	upgradeNone feedUpgrade = iota
+	upgradeHTTPS
+	upgradeDomainChanged
+)
+
+// classifyUpgrade decides whether a feed stored at storedURL whose redirects end at
+// finalURL should move there. Only moves to https on the same host are automatic; a
+// www. prefix gained or lost doesn't count as a different host.
+func classifyUpgrade(storedURL, finalURL string) feedUpgrade {
+	stored, err := neturl.Parse(storedURL)
+	if err != nil {
+		return upgradeNone
+	}
+	final, err := neturl.Parse(finalURL)
+	if err != nil || final.Scheme != "https" || finalURL == storedURL {
+		return upgradeNone
+	}
+	if siteHost(stored) != siteHost(final) {
+		return upgradeDomainChanged
+	}
+	return upgradeHTTPS
+}
+
+// siteHost is u's host name in lower case without a leading www., so a site's www and
+// bare domains compare equal
+func siteHost(u *neturl.URL) string {
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// deadFeedAge is how long a feed can go without being fetched or posting before
+// deadfeeds reports it
+const deadFeedAge = 30 * 24 * time.Hour
+
+// handlerDeadFeeds lists followed feeds that keep failing or have gone quiet, and can
+// unfollow them
+func handlerDeadFeeds(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	threshold := fs.Int("threshold", 5, "consecutive fetch errors that make a feed dead")
+	interactive := fs.Bool("interactive", false, "ask whether to unfollow each dead feed")
+	unfollowAll := fs.Bool("unfollow-all", false, "unfollow every dead feed without asking")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--threshold N] [--interactive | --unfollow-all]: %w", cmd.name, err)
+	}
+	if *interactive && *unfollowAll {
+		return fmt.Errorf("--interactive and --unfollow-all can't be combined")
+	}
+	if *threshold < 1 {
+		return fmt.Errorf("--threshold must be at least 1")
+	}
+	if s.outputFormat == output.JSON && (*interactive || *unfollowAll) {
+		return fmt.Errorf("--format json only lists dead feeds; drop --interactive and --unfollow-all")
+	}
+
+	ctx := context.Background()
+	feeds, err := s.db.GetDeadFeedsForUser(ctx, database.GetDeadFeedsForUserParams{
+		UserID:      user.ID,
+		Threshold:   int32(*threshold),
+		StaleBefore: time.Now().UTC().Add(-deadFeedAge),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get dead feeds: %w", err)
+	}
+	if s.outputFormat == output.JSON {
+		result := output.DeadFeedsResult{Feeds: make([]output.DeadFeedJSON, 0, len(feeds))}
+		for _, feed := range feeds {
+			entry := output.DeadFeedJSON{
+				ID:                feed.ID,
+				Name:              feed.Name,
+				URL:               feed.Url,
+				ConsecutiveErrors: feed.ConsecutiveErrors,
+				LastError:         feed.LastError.String,
+			}
+			if feed.LastFetchedAt.Valid {
+				entry.LastFetchedAt = &feed.LastFetchedAt.Time
+			}
+			result.Feeds = append(result.Feeds, entry)
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+	if len(feeds) == 0 {
+		fmt.Println("No dead feeds.")
+		return nil
+	}
+
+	unfollowed := 0
+feeds:
+	for i, feed := range feeds {
+		lastFetched := "never"
+		if feed.LastFetchedAt.Valid {
+			lastFetched = feed.LastFetchedAt.Time.Format(time.RFC1123)
+		}
+		lastError := "-"
+		if feed.LastError.Valid {
+			lastError = fmt.Sprintf("%s (%d in a row)", feed.LastError.String, feed.ConsecutiveErrors)
+		}
+		fmt.Printf("* %s (%s)\n  Last error:   %s\n  Last fetched: %s\n", feed.Name, feed.Url, lastError, lastFetched)
+
+		unfollow := *unfollowAll
+		if *interactive {
+			answer, err := promptLine("  Unfollow? [y/N/s(kip the rest)] ")
+			if err != nil {
+				return fmt.Errorf("couldn't read answer: %w", err)
+			}
+			switch strings.ToLower(answer) {
+			case "y", "yes":
+				unfollow = true
+			case "s", "skip":
+				fmt.Printf("Skipped the remaining %d feed(s).\n", len(feeds)-i-1)
+				break feeds
+			}
+		}
+		if !unfollow {
+			continue
+		}
+
+		if _, err := s.db.DeleteFeedFollowByUserAndFeed(ctx, database.DeleteFeedFollowByUserAndFeedParams{
+			UserID: user.ID,
+			FeedID: feed.ID,
+		}); err != nil {
+			return fmt.Errorf("couldn't unfollow %s: %w", feed.Name, err)
+		}
+		fmt.Printf("  Unfollowed %s\n", feed.Name)
+		unfollowed++
+	}
+
+	if *interactive || *unfollowAll {
+		fmt.Printf("Unfollowed %d feed(s).\n", unfollowed)
+		return nil
+	}
+	fmt.Printf("%d dead feed(s). Run with --interactive or --unfollow-all to unfollow them.\n", len(feeds))
+	return nil
+}
+
+// handlerFeedErrors lists feeds whose recent fetches failed, or clears a feed's errors with --reset
+func handlerFeedErrors(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	threshold := fs.Int("threshold", 1, "only show feeds with at least this many consecutive errors")
+	reset := fs.String("reset", "", "clear the error count of the feed with this URL")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--threshold N] [--reset <feed-url>]: %w", cmd.name, err)
+	}
+
+	ctx := context.Background()
+	if *reset != "" {
+		feed, err := s.db.GetFeedByURL(ctx, *reset)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("feed not found: %s", *reset)
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't find feed with URL %s: %w", *reset, err)
+		}
+		// Only the feed's creator or one of its followers may clear its errors
+		if feed.UserID != user.ID {
+			follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+			if err != nil {
+				return fmt.Errorf("couldn't get feed follows: %w", err)
+			}
+			following := false
+			for _, follow := range follows {
+				if follow.FeedID == feed.ID {
+					following = true
+					break
+				}
+			}
+			if !following {
+				return fmt.Errorf("you didn't create and don't follow %s", feed.Name)
+			}
+		}
+
+		rowsAffected, err := s.db.ResetFeedErrors(ctx, *reset)
+		if err != nil {
+			return fmt.Errorf("couldn't reset feed errors: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("feed not found: %s", *reset)
+		}
+		fmt.Printf("Cleared errors for %s\n", *reset)
+		return nil
+	}
+
+	feeds, err := s.db.GetFeedsWithErrors(ctx, int32(*threshold))
+	if err != nil {
+		return fmt.Errorf("couldn't get feed errors: %w", err)
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.FeedErrorsResult{Feeds: make([]output.FeedErrorJSON, 0, len(feeds))}
+		for _, feed := range feeds {
+			entry := output.FeedErrorJSON{
+				Name:              feed.Name,
+				URL:               feed.Url,
+				ConsecutiveErrors: feed.ConsecutiveErrors,
+				LastError:         feed.LastError.String,
+			}
+			if feed.LastAttemptedAt.Valid {
+				entry.LastAttemptedAt = &feed.LastAttemptedAt.Time
+			}
+			result.Feeds = append(result.Feeds, entry)
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(feeds) == 0 {
+		fmt.Println("No feeds with fetch errors.")
+		return nil
+	}
+
+	for _, feed := range feeds {
+		lastAttempt := "never"
+		if feed.LastAttemptedAt.Valid {
+			lastAttempt = feed.LastAttemptedAt.Time.Format("2006-01-02 15:04:05")
+		}
+		fmt.Printf("* %s (%s)\n", feed.Name, feed.Url)
+		fmt.Printf("  Errors: %d, last attempt: %s\n", feed.ConsecutiveErrors, lastAttempt)
+		fmt.Printf("  Last error: %s\n", feed.LastError.String)
+	}
+
+	return nil
+}
+
+// feedIntervalSecs converts a setinterval duration to the stored override. Zero clears
+// the override so the feed follows the agg heartbeat, or its adaptive interval under
+// agg --adaptive, again; anything else must be whole seconds' worth that fits the column.
+func feedIntervalSecs(interval time.Duration) (sql.NullInt32, error) {
+	switch {
+	case interval == 0:
+		return sql.NullInt32{}, nil
+	case interval < 0:
+		return sql.NullInt32{}, fmt.Errorf("invalid interval %s: must not be negative", interval)
+	case interval < time.Second:
+		return sql.NullInt32{}, fmt.Errorf("invalid interval %s: must be at least 1s, or 0 to clear it", interval)
+	case interval > math.MaxInt32*time.Second:
+		return sql.NullInt32{}, fmt.Errorf("invalid interval %s: must be at most %s", interval, math.MaxInt32*time.Second)
+	}
+	return sql.NullInt32{Int32: int32(interval / time.Second), Valid: true}, nil
+}
+
+// handlerSetInterval sets how often a single feed should be polled by agg
+func handlerSetInterval(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("usage: %s <feed-url> <duration>", cmd.name)
+	}
+
+	feedURL := cmd.args[0]
+	interval, err := time.ParseDuration(cmd.args[1])
+	if err != nil {
+		return fmt.Errorf("invalid duration: %v", err)
+	}
+	intervalSecs, err := feedIntervalSecs(interval)
+	if err != nil {
+		return err
+	}
+
+	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", feedURL, err)
+	}
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who created %s can change its interval", feed.Name)
+	}
+
+	err = s.db.SetFeedInterval(context.Background(), database.SetFeedIntervalParams{
+		ID:           feed.ID,
+		IntervalSecs: intervalSecs,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set feed interval: %w", err)
+	}
+
+	if !intervalSecs.Valid {
+		fmt.Printf("Feed %s now uses the agg interval (or its adaptive one)\n", feed.Name)
+		return nil
+	}
+	fmt.Printf("Feed %s will be polled every %s\n", feed.Name, interval)
+	return nil
+}
+
+// handlerSetTimeout sets the global fetch timeout, or one feed's override with --feed
+func handlerSetTimeout(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	feedURL := fs.String("feed", "", "only change the timeout of the feed with this URL")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: %s <seconds> [--feed <url>]", cmd.name)
+	}
+	secs, err := strconv.Atoi(args[0])
+	if err != nil || secs < 0 {
+		return fmt.Errorf("invalid timeout %q: must be a whole number of seconds", args[0])
+	}
+
+	if *feedURL == "" {
+		if err := s.cfg.SetFetchTimeout(secs); err != nil {
+			return fmt.Errorf("couldn't save fetch timeout: %w", err)
+		}
+		fmt.Printf("Feeds will time out after %s\n", s.cfg.FetchTimeout())
+		return nil
+	}
+
+	feed, err := s.db.GetFeedByURL(context.Background(), *feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", *feedURL, err)
+	}
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who created %s can change its timeout", feed.Name)
+	}
+
+	// Zero clears the override so the feed uses the global timeout again
+	timeoutSecs := sql.NullInt32{Int32: int32(secs), Valid: secs > 0}
+	err = s.db.SetFeedFetchTimeout(context.Background(), database.SetFeedFetchTimeoutParams{
+		ID:               feed.ID,
+		FetchTimeoutSecs: timeoutSecs,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set feed timeout: %w", err)
+	}
+
+	if !timeoutSecs.Valid {
+		fmt.Printf("Feed %s now uses the global timeout (%s)\n", feed.Name, s.cfg.FetchTimeout())
+		return nil
+	}
+	fmt.Printf("Feed %s will time out after %ds\n", feed.Name, secs)
+	return nil
+}
+
+// handlerSetUserAgent sets the User-Agent a feed is fetched with, for sites that block or
+// serve different content to the default one; --clear goes back to the default
+func handlerSetUserAgent(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	useDefault := fs.Bool("clear", false, "fetch the feed with the default User-Agent again")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 || (*useDefault && len(args) != 1) || (!*useDefault && len(args) < 2) {
+		return fmt.Errorf("usage: %s <feed-url> <user-agent> | %s --clear <feed-url>", cmd.name, cmd.name)
+	}
+	feed, err := s.db.GetFeedByURL(context.Background(), args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", args[0], err)
+	}
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who created %s can change its User-Agent", feed.Name)
+	}
+
+	// The agent usually has spaces in it, so the rest of the arguments make it up even
+	// when it isn't quoted
+	userAgent := strings.TrimSpace(strings.Join(args[1:], " "))
+	if !*useDefault && userAgent == "" {
+		return fmt.Errorf("user agent must not be empty")
+	}
+	err = s.db.SetFeedUserAgent(context.Background(), database.SetFeedUserAgentParams{
+		ID:        feed.ID,
+		UserAgent: sql.NullString{String: userAgent, Valid: userAgent != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't set feed user agent: %w", err)
+	}
+
+	if *useDefault {
+		fmt.Printf("Feed %s now uses the default User-Agent (%s)\n", feed.Name, s.cfg.UserAgent())
+		return nil
+	}
+	fmt.Printf("Feed %s will be fetched as %q\n", feed.Name, userAgent)
+	return nil
+}
+
+// probeTimeout and probeMaxRedirects bound a single reachability check
+const (
+	probeTimeout      = 10 * time.Second
+	probeMaxRedirects = 5
+)
+
+// FeedProbeResult describes the outcome of checking whether a feed URL is reachable
+type FeedProbeResult struct {
+	URL         string
+	StatusCode  int
+	Duration    time.Duration
+	FinalURL    string
+	ContentType string
+	Format      string // RSS, Atom, RDF or JSON Feed when the body parsed
+	Err         error
+}
+
+// handlerCheck checks that a feed URL, or every feed the user follows, is reachable and parses
+func handlerCheck(s *state, cmd command, user database.User) error {
+	ctx := context.Background()
+
+	if len(cmd.args) > 0 {
+		result := probeFeed(ctx, cmd.args[0])
+		if s.outputFormat == output.JSON {
+			if err := output.WriteJSON(os.Stdout, output.CheckResult{Feeds: []output.ProbeJSON{probeJSON(result)}}); err != nil {
+				return err
+			}
+			if result.Err != nil {
+				return fmt.Errorf("check failed: %s", describeProbeError(result.Err))
+			}
+			return nil
+		}
+
+		fmt.Printf("URL:          %s\n", result.URL)
+		if result.StatusCode != 0 {
+			fmt.Printf("Status:       %d %s\n", result.StatusCode, http.StatusText(result.StatusCode))
+			fmt.Printf("Final URL:    %s\n", result.FinalURL)
+			fmt.Printf("Content-Type: %s\n", result.ContentType)
+		}
+		fmt.Printf("Time:         %s\n", result.Duration.Round(time.Millisecond))
+		if result.Err != nil {
+			fmt.Printf("Valid feed:   no\n")
+			return fmt.Errorf("check failed: %s", describeProbeError(result.Err))
+		}
+		fmt.Printf("Valid feed:   yes (%s)\n", result.Format)
+		return nil
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	if s.outputFormat == output.JSON {
+		result := output.CheckResult{Feeds: make([]output.ProbeJSON, 0, len(follows))}
+		for _, follow := range follows {
+			result.Feeds = append(result.Feeds, probeJSON(probeFeed(ctx, follow.FeedUrl)))
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(follows) == 0 {
+		fmt.Println("You are not following any feeds.")
+		return nil
+	}
+
+	failed := 0
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "FEED\tSTATUS\tTIME\tFORMAT\tERROR")
+	for _, follow := range follows {
+		result := probeFeed(ctx, follow.FeedUrl)
+		status, format, problem := "-", "-", ""
+		if result.StatusCode != 0 {
+			status = strconv.Itoa(result.StatusCode)
+		}
+		if result.Format != "" {
+			format = result.Format
+		}
+		if result.Err != nil {
+			failed++
+			problem = describeProbeError(result.Err)
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", follow.FeedName, status, result.Duration.Round(time.Millisecond), format, problem)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Printf("\n%d of %d feeds OK\n", len(follows)-failed, len(follows))
+	return nil
+}
+
+// probeJSON converts a probe result to its --format json shape
+func probeJSON(result FeedProbeResult) output.ProbeJSON {
+	probe := output.ProbeJSON{
+		URL:         result.URL,
+		StatusCode:  result.StatusCode,
+		DurationMS:  result.Duration.Milliseconds(),
+		FinalURL:    result.FinalURL,
+		ContentType: result.ContentType,
+		Format:      result.Format,
+	}
+	if result.Err != nil {
+		probe.Error = describeProbeError(result.Err)
+	}
+	return probe
+}
+
+// probeFeed checks a feed URL with a HEAD request, falling back to GET when the server
+// doesn't support HEAD, and then downloads the body to confirm it parses as a feed
+func probeFeed(ctx context.Context, feedURL string) FeedProbeResult {
+	result := FeedProbeResult{URL: feedURL}
+
+	client := &http.Client{
+		Timeout:   probeTimeout,
+		Transport: httpTransport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) >= probeMaxRedirects {
+				return fmt.Errorf("stopped after %d redirects", probeMaxRedirects)
+			}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	resp, err := probeRequest(ctx, client, "HEAD", feedURL)
+	if err == nil && (resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented) {
+		resp.Body.Close()
+		resp, err = probeRequest(ctx, client, "GET", feedURL)
+	}
+	result.Duration = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	result.StatusCode = resp.StatusCode
+	result.FinalURL = resp.Request.URL.String()
+	result.ContentType = resp.Header.Get("Content-Type")
+
+	if resp.Request.Method != "GET" {
+		resp.Body.Close()
+		if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+			resp, err = probeRequest(ctx, client, "GET", feedURL)
+			if err != nil {
+				result.Err = err
+				return result
+			}
+		}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		result.Err = fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		return result
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		result.Err = fmt.Errorf("couldn't read response body: %w", err)
+		return result
+	}
+
+	result.Format, result.Err = feedFormat(resp.Header.Get("Content-Type"), body)
+	return result
+}
+
+// probeRequest sends a single request for probeFeed
+func probeRequest(ctx context.Context, client *http.Client, method, feedURL string) (*http.Response, error) {
+	req, err := newFeedRequest(ctx, method, feedURL, "")
+	if err != nil {
+		return nil, err
+	}
+	return client.Do(req)
+}
+
+// errInvalidFeed marks a response body that isn't an RSS, Atom or JSON feed
+var errInvalidFeed = errors.New("not an RSS, Atom or JSON feed")
+
+// feedFormat names the feed format of a response body or reports why it isn't a feed
+func feedFormat(contentType string, body []byte) (string, error) {
+	if isJSONFeed(contentType, body) {
+		if _, err := parseJSONFeed(body); err != nil {
+			return "", fmt.Errorf("%w: %w", errInvalidFeed, err)
+		}
+		return "JSON Feed", nil
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(body))
+	for {
+		token, err := decoder.Token()
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", errInvalidFeed, err)
+		}
+		start, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "rss":
+			return "RSS", nil
+		case "feed":
+			return "Atom", nil
+		case "RDF":
+			return "RDF", nil
+		}
+		return "", fmt.Errorf("%w: unexpected root element <%s>", errInvalidFeed, start.Name.Local)
+	}
+}
+
+// describeProbeError turns a probe error into a short explanation of what went wrong
+func describeProbeError(err error) string {
+	var dnsErr *net.DNSError
+	var certErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &dnsErr):
+		return "DNS failure: " + dnsErr.Error()
+	case errors.As(err, &certErr), errors.As(err, &recordErr):
+		return "TLS error: " + err.Error()
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout: " + err.Error()
+	case errors.Is(err, errInvalidFeed):
+		return "parse error: " + err.Error()
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection refused: " + err.Error()
+	}
+	return err.Error()
+}
+
+// handlerTag adds a tag to a feed
+func handlerTag(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("usage: %s <feed-url> <tag>", cmd.name)
+	}
+
+	ctx := context.Background()
+	feed, err := s.db.GetFeedByURL(ctx, cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", cmd.args[0], err)
+	}
+	tag, err := normalizeTag(cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	added, err := s.db.AddTagToFeed(ctx, database.AddTagToFeedParams{
+		FeedID:    feed.ID,
+		Tag:       tag,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't tag feed: %w", err)
+	}
+	if added == 0 {
+		fmt.Printf("%s is already tagged %s\n", feed.Name, tag)
+		return nil
+	}
+
+	fmt.Printf("Tagged %s with %s\n", feed.Name, tag)
+	return nil
+}
+
+// handlerUntag removes a tag from a feed
+func handlerUntag(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 2 {
+		return fmt.Errorf("usage: %s <feed-url> <tag>", cmd.name)
+	}
+
+	ctx := context.Background()
+	feed, err := s.db.GetFeedByURL(ctx, cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", cmd.args[0], err)
+	}
+	tag, err := normalizeTag(cmd.args[1])
+	if err != nil {
+		return err
+	}
+
+	removed, err := s.db.RemoveTagFromFeed(ctx, database.RemoveTagFromFeedParams{FeedID: feed.ID, Tag: tag})
+	if err != nil {
+		return fmt.Errorf("couldn't untag feed: %w", err)
+	}
+	if removed == 0 {
+		fmt.Printf("%s is not tagged %s\n", feed.Name, tag)
+		return nil
+	}
+
+	fmt.Printf("Removed tag %s from %s\n", tag, feed.Name)
+	return nil
+}
+
+// handlerTagged lists the feeds that carry a tag
+func handlerTagged(s *state, cmd command) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <tag>", cmd.name)
+	}
+
+	tag, err := normalizeTag(cmd.args[0])
+	if err != nil {
+		return err
+	}
+	feeds, err := s.db.GetFeedsByTag(context.Background(), tag)
+	if err != nil {
+		return fmt.Errorf("couldn't get feeds tagged %s: %w", tag, err)
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.FeedsResult{Feeds: make([]output.FeedJSON, 0, len(feeds))}
+		for _, feed := range feeds {
+			result.Feeds = append(result.Feeds, output.FeedJSON{ID: feed.ID, Name: feed.Name, URL: feed.Url, Tags: []string{tag}})
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(feeds) == 0 {
+		fmt.Printf("No feeds tagged %s.\n", tag)
+		return nil
+	}
+
+	fmt.Printf("Feeds tagged %s:\n", tag)
+	for _, feed := range feeds {
+		fmt.Printf("* %s (%s)\n", feed.Name, feed.Url)
+	}
+	return nil
+}
+
+// normalizeTag lowercases and trims a tag so "Go" and "go " are the same tag
+func normalizeTag(raw string) (string, error) {
+	tag := strings.ToLower(strings.TrimSpace(raw))
+	if tag == "" {
+		return "", fmt.Errorf("tag must not be empty")
+	}
+	return tag, nil
+}
+
+// handlerGroup manages the logged-in user's feed groups, named folders for sorting
+// their subscriptions
+func handlerGroup(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s create <name> | delete <name> | list | add <name> <feed-url> | remove <name> <feed-url> | feeds <name>", cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "create":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		name := strings.TrimSpace(cmd.args[1])
+		if name == "" {
+			return fmt.Errorf("group name must not be empty")
+		}
+		if _, err := s.db.GetFeedGroupByName(ctx, database.GetFeedGroupByNameParams{UserID: user.ID, Name: name}); err == nil {
+			return fmt.Errorf("you already have a group named %s", name)
+		} else if !errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("couldn't check existing groups: %w", err)
+		}
+		if _, err := createFeedGroup(ctx, s, user, name); err != nil {
+			return err
+		}
+		fmt.Printf("Created group %s\n", name)
+		return nil
+
+	case "delete":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		deleted, err := s.db.DeleteFeedGroup(ctx, database.DeleteFeedGroupParams{UserID: user.ID, Name: cmd.args[1]})
+		if err != nil {
+			return fmt.Errorf("couldn't delete group: %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("no group named %s", cmd.args[1])
+		}
+		fmt.Printf("Deleted group %s; its feeds are still followed\n", cmd.args[1])
+		return nil
+
+	case "list":
+		groups, err := s.db.GetFeedGroupsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get groups: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.GroupsResult{Groups: make([]output.GroupJSON, 0, len(groups))}
+			for _, group := range groups {
+				result.Groups = append(result.Groups, output.GroupJSON{ID: group.ID, Name: group.Name, Feeds: group.Feeds})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(groups) == 0 {
+			fmt.Println("No groups yet.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "GROUP\tFEEDS")
+		for _, group := range groups {
+			fmt.Fprintf(w, "%s\t%d\n", group.Name, group.Feeds)
+		}
+		return w.Flush()
+
+	case "add", "remove":
+		if len(cmd.args) != 3 {
+			return usage
+		}
+		group, err := getFeedGroup(ctx, s, user, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		feed, err := s.db.GetFeedByURL(ctx, cmd.args[2])
+		if err != nil {
+			return fmt.Errorf("couldn't find feed with URL %s: %w", cmd.args[2], err)
+		}
+
+		if cmd.args[0] == "remove" {
+			removed, err := s.db.RemoveFeedFromGroup(ctx, database.RemoveFeedFromGroupParams{GroupID: group.ID, FeedID: feed.ID})
+			if err != nil {
+				return fmt.Errorf("couldn't remove feed from group: %w", err)
+			}
+			if removed == 0 {
+				return fmt.Errorf("%s isn't in group %s", feed.Name, group.Name)
+			}
+			fmt.Printf("Removed %s from group %s\n", feed.Name, group.Name)
+			return nil
+		}
+
+		follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get feed follows: %w", err)
+		}
+		following := false
+		for _, follow := range follows {
+			if follow.FeedID == feed.ID {
+				following = true
+				break
+			}
+		}
+		if !following {
+			return fmt.Errorf("you don't follow %s", feed.Name)
+		}
+		added, err := s.db.AddFeedToGroup(ctx, database.AddFeedToGroupParams{GroupID: group.ID, FeedID: feed.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't add feed to group: %w", err)
+		}
+		if added == 0 {
+			fmt.Printf("%s is already in group %s\n", feed.Name, group.Name)
+			return nil
+		}
+		fmt.Printf("Added %s to group %s\n", feed.Name, group.Name)
+		return nil
+
+	case "feeds":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		group, err := getFeedGroup(ctx, s, user, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		feeds, err := s.db.GetFeedsInGroup(ctx, group.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get feeds in group: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.GroupFeedsResult{Group: group.Name, Feeds: make([]output.GroupFeedJSON, 0, len(feeds))}
+			for _, feed := range feeds {
+				result.Feeds = append(result.Feeds, output.GroupFeedJSON{ID: feed.ID, Name: feed.Name, URL: feed.Url})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(feeds) == 0 {
+			fmt.Printf("Group %s has no feeds.\n", group.Name)
+			return nil
+		}
+		fmt.Printf("Feeds in %s:\n", group.Name)
+		for _, feed := range feeds {
+			fmt.Printf("* %s (%s)\n", feed.Name, feed.Url)
+		}
+		return nil
+	}
+	return usage
+}
+
+// getFeedGroup looks up one of user's groups by name
+func getFeedGroup(ctx context.Context, s *state, user database.User, name string) (database.FeedGroup, error) {
+	group, err := s.db.GetFeedGroupByName(ctx, database.GetFeedGroupByNameParams{UserID: user.ID, Name: name})
+	if errors.Is(err, sql.ErrNoRows) {
+		return group, fmt.Errorf("no group named %s", name)
+	}
+	if err != nil {
+		return group, fmt.Errorf("couldn't look up group %s: %w", name, err)
+	}
+	return group, nil
+}
+
+// createFeedGroup creates an empty group named name for user
+func createFeedGroup(ctx context.Context, s *state, user database.User, name string) (database.FeedGroup, error) {
+	group, err := s.db.CreateFeedGroup(ctx, database.CreateFeedGroupParams{
+		ID:        uuid.New(),
+		UserID:    user.ID,
+		Name:      name,
+		CreatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return group, fmt.Errorf("couldn't create group %s: %w", name, err)
+	}
+	return group, nil
+}
+
+// handlerFilter manages the logged-in user's keyword filters: add, list and delete
+func handlerFilter(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s add (--include|--exclude) <keyword> [--field title|description|any] | %s list | %s delete <id>", cmd.name, cmd.name, cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "add":
+		fs := newFlagSet(cmd.name + " add")
+		include := fs.String("include", "", "only keep posts containing this keyword")
+		exclude := fs.String("exclude", "", "skip posts containing this keyword")
+		field := fs.String("field", "any", "field to match: title, description or any")
+		if _, err := parseCommandFlags(fs, cmd.args[1:]); err != nil {
+			return fmt.Errorf("%w: %v", usage, err)
+		}
+
+		if (*include == "") == (*exclude == "") {
+			return fmt.Errorf("specify exactly one of --include or --exclude")
+		}
+		filterType, keyword := "include", *include
+		if *exclude != "" {
+			filterType, keyword = "exclude", *exclude
+		}
+		keyword = strings.TrimSpace(keyword)
+		if keyword == "" {
+			return fmt.Errorf("keyword must not be empty")
+		}
+		switch *field {
+		case "title", "description", "any":
+		default:
+			return fmt.Errorf("invalid field %q: must be title, description or any", *field)
+		}
+
+		filter, err := s.db.CreateKeywordFilter(ctx, database.CreateKeywordFilterParams{
+			ID:         uuid.New(),
+			CreatedAt:  time.Now().UTC(),
+			UserID:     user.ID,
+			Keyword:    keyword,
+			FilterType: filterType,
+			Field:      *field,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create filter: %w", err)
+		}
+		fmt.Printf("Added %s filter %q on %s (%s)\n", filter.FilterType, filter.Keyword, filter.Field, filter.ID)
+		return nil
+
+	case "list":
+		filters, err := s.db.GetKeywordFiltersForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get filters: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.FiltersResult{Filters: make([]output.FilterJSON, 0, len(filters))}
+			for _, filter := range filters {
+				result.Filters = append(result.Filters, output.FilterJSON{
+					ID:        filter.ID,
+					Type:      filter.FilterType,
+					Field:     filter.Field,
+					Keyword:   filter.Keyword,
+					CreatedAt: filter.CreatedAt,
+				})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(filters) == 0 {
+			fmt.Println("No keyword filters.")
+			return nil
+		}
+		for _, filter := range filters {
+			fmt.Printf("%s  %-7s  %-11s  %s\n", filter.ID, filter.FilterType, filter.Field, filter.Keyword)
+		}
+		return nil
+
+	case "delete":
+		if len(cmd.args) < 2 {
+			return usage
+		}
+		id, err := uuid.Parse(cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("invalid filter ID %s: %w", cmd.args[1], err)
+		}
+		deleted, err := s.db.DeleteKeywordFilter(ctx, database.DeleteKeywordFilterParams{ID: id, UserID: user.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't delete filter: %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("no filter with ID %s", id)
+		}
+		fmt.Printf("Deleted filter %s\n", id)
+		return nil
+	}
+	return usage
+}
+
+// handlerAutoTag manages the logged-in user's tag rules, which tag new posts mentioning a
+// keyword: add, list, delete, and apply to run them over posts already stored
+func handlerAutoTag(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s add --keyword <keyword> --tag <tag> [--field title|description|any] [--case-sensitive] | %s list | %s delete <id> | %s apply [--reprocess]", cmd.name, cmd.name, cmd.name, cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "add":
+		fs := newFlagSet(cmd.name + " add")
+		keywordFlag := fs.String("keyword", "", "tag posts containing this keyword")
+		tagFlag := fs.String("tag", "", "tag to give matching posts")
+		field := fs.String("field", "any", "field to match: title, description or any")
+		caseSensitive := fs.Bool("case-sensitive", false, "match the keyword's case exactly")
+		if _, err := parseCommandFlags(fs, cmd.args[1:]); err != nil {
+			return fmt.Errorf("%w: %v", usage, err)
+		}
+
+		keyword := strings.TrimSpace(*keywordFlag)
+		if keyword == "" {
+			return fmt.Errorf("--keyword must not be empty")
+		}
+		tag, err := normalizeTag(*tagFlag)
+		if err != nil {
+			return err
+		}
+		switch *field {
+		case "title", "description", "any":
+		default:
+			return fmt.Errorf("invalid field %q: must be title, description or any", *field)
+		}
+
+		rule, err := s.db.CreateTagRule(ctx, database.CreateTagRuleParams{
+			ID:            uuid.New(),
+			CreatedAt:     time.Now().UTC(),
+			UserID:        user.ID,
+			Keyword:       keyword,
+			Tag:           tag,
+			Field:         *field,
+			CaseSensitive: *caseSensitive,
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't create tag rule: %w", err)
+		}
+		fmt.Printf("Posts mentioning %q in %s will be tagged %q (%s)\n", rule.Keyword, rule.Field, rule.Tag, rule.ID)
+		fmt.Printf("Run '%s apply' to tag posts already stored\n", cmd.name)
+		return nil
+
+	case "list":
+		rules, err := s.db.GetTagRulesForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get tag rules: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.TagRulesResult{Rules: make([]output.TagRuleJSON, 0, len(rules))}
+			for _, rule := range rules {
+				result.Rules = append(result.Rules, output.TagRuleJSON{
+					ID:            rule.ID,
+					Tag:           rule.Tag,
+					Field:         rule.Field,
+					CaseSensitive: rule.CaseSensitive,
+					Keyword:       rule.Keyword,
+					CreatedAt:     rule.CreatedAt,
+				})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(rules) == 0 {
+			fmt.Println("No tag rules.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tTAG\tFIELD\tCASE\tKEYWORD")
+		for _, rule := range rules {
+			matchCase := "ignore"
+			if rule.CaseSensitive {
+				matchCase = "match"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", rule.ID, rule.Tag, rule.Field, matchCase, rule.Keyword)
+		}
+		return w.Flush()
+
+	case "delete":
+		if len(cmd.args) < 2 {
+			return usage
+		}
+		id, err := uuid.Parse(cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("invalid tag rule ID %s: %w", cmd.args[1], err)
+		}
+		deleted, err := s.db.DeleteTagRule(ctx, database.DeleteTagRuleParams{ID: id, UserID: user.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't delete tag rule: %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("no tag rule with ID %s", id)
+		}
+		fmt.Printf("Deleted tag rule %s; posts it already tagged keep their tags\n", id)
+		return nil
+
+	case "apply":
+		fs := newFlagSet(cmd.name + " apply")
+		reprocess := fs.Bool("reprocess", false, "run the rules over every post, not just posts without tags")
+		if _, err := parseCommandFlags(fs, cmd.args[1:]); err != nil {
+			return fmt.Errorf("%w: %v", usage, err)
+		}
+		rules, err := s.db.GetTagRulesForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get tag rules: %w", err)
+		}
+		if len(rules) == 0 {
+			fmt.Println("No tag rules to apply.")
+			return nil
+		}
+		posts, err := s.db.GetPostsToTag(ctx, database.GetPostsToTagParams{UserID: user.ID, Reprocess: *reprocess})
+		if err != nil {
+			return fmt.Errorf("couldn't get posts: %w", err)
+		}
+
+		var tagged, added int
+		for _, post := range posts {
+			n, err := tagPost(ctx, s, rules, post.ID, post.Title, post.Description.String)
+			if err != nil {
+				return err
+			}
+			if n > 0 {
+				tagged++
+				added += n
+			}
+		}
+		fmt.Printf("Checked %d post(s): added %d tag(s) to %d post(s)\n", len(posts), added, tagged)
+		return nil
+	}
+	return usage
+}
+
+// tagRuleMatches reports whether the rule's keyword appears in the field it targets.
+// Descriptions are matched as plain text so markup and link URLs don't count.
+func tagRuleMatches(rule database.TagRule, title, description string) bool {
+	keyword := rule.Keyword
+	if !rule.CaseSensitive {
+		keyword = strings.ToLower(keyword)
+		title = strings.ToLower(title)
+		description = strings.ToLower(description)
+	}
+	inTitle := strings.Contains(title, keyword)
+	inDescription := strings.Contains(description, keyword)
+	switch rule.Field {
+	case "title":
+		return inTitle
+	case "description":
+		return inDescription
+	}
+	return inTitle || inDescription
+}
+
+// matchingTags returns the tags of every rule matching a post, each once, in rule order
+func matchingTags(rules []database.TagRule, title, description string) []string {
+	text := sanitize.HTMLToText(description)
+	var tags []string
+	for _, rule := range rules {
+		if tagRuleMatches(rule, title, text) && !slices.Contains(tags, rule.Tag) {
+			tags = append(tags, rule.Tag)
+		}
+	}
+	return tags
+}
+
+// tagPost gives a post the tags of the rules it matches and returns how many it didn't
+// already have
+func tagPost(ctx context.Context, s *state, rules []database.TagRule, postID uuid.UUID, title, description string) (int, error) {
+	added := 0
+	for _, tag := range matchingTags(rules, title, description) {
+		n, err := s.db.AddTagToPost(ctx, database.AddTagToPostParams{
+			PostID:    postID,
+			Tag:       tag,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return added, fmt.Errorf("couldn't tag post %s: %w", postID, err)
+		}
+		added += int(n)
+	}
+	return added, nil
+}
+
+// keywordFilterMatches reports whether the filter's keyword appears, case-insensitively,
+// in the field it targets
+func keywordFilterMatches(filter database.KeywordFilter, title, description string) bool {
+	keyword := strings.ToLower(filter.Keyword)
+	inTitle := strings.Contains(strings.ToLower(title), keyword)
+	inDescription := strings.Contains(strings.ToLower(description), keyword)
+	switch filter.Field {
+	case "title":
+		return inTitle
+	case "description":
+		return inDescription
+	}
+	return inTitle || inDescription
+}
+
+// passesKeywordFilters applies one user's filters: any matching exclude filter rejects
+// the post, and if the user has include filters at least one of them must match
+func passesKeywordFilters(filters []database.KeywordFilter, title, description string) bool {
+	hasInclude, included := false, false
+	for _, filter := range filters {
+		matches := keywordFilterMatches(filter, title, description)
+		if filter.FilterType == "exclude" {
+			if matches {
+				return false
+			}
+			continue
+		}
+		hasInclude = true
+		included = included || matches
+	}
+	return !hasInclude || included
+}
+
+// wantedByFollowers reports whether any follower's filters let the post through.
+// Posts are stored once per feed rather than per user, so a post is only skipped
+// when every follower would filter it out; a feed nobody follows keeps everything.
+func wantedByFollowers(followers []uuid.UUID, filters map[uuid.UUID][]database.KeywordFilter, title, description string) bool {
+	if len(followers) == 0 {
+		return true
+	}
+	for _, userID := range followers {
+		if passesKeywordFilters(filters[userID], title, description) {
+			return true
+		}
+	}
+	return false
+}
+
+// handlerFollow handles the follow command to follow existing feeds by URL
+func handlerFollow(s *state, cmd command, user database.User) error {
+	if len(cmd.args) == 0 {
+		return fmt.Errorf("usage: %s <url>", cmd.name)
+	}
+
+	url := cmd.args[0]
+
+	// Get feed by URL
+	feed, err := s.db.GetFeedByURL(context.Background(), url)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", url, err)
+	}
+
+	// Create feed follow record
+	now := time.Now().UTC()
+	followParams := database.CreateFeedFollowParams{
+		ID:        uuid.New(),
+		CreatedAt: now,
+		UpdatedAt: now,
+		UserID:    user.ID,
+		FeedID:    feed.ID,
+	}
+
+	followRecord, err := s.db.CreateFeedFollow(context.Background(), followParams)
+	if err != nil {
+		return fmt.Errorf("couldn't follow feed: %w", err)
+	}
+
+	fmt.Printf("Following %s by %s\n", followRecord.FeedName, followRecord.UserName)
+	return nil
+}
+
+// handlerFollowAll follows every feed the user doesn't follow yet, or every such feed
+// with --tag, so a new member of a shared setup can subscribe to everything at once
+func handlerFollowAll(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	tagFilter := fs.String("tag", "", "only follow feeds with this tag")
+	dryRun := fs.Bool("dry-run", false, "list the feeds that would be followed without following them")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) > 0 {
+		return fmt.Errorf("usage: %s [--tag <tag>] [--dry-run]", cmd.name)
+	}
+
+	ctx := context.Background()
+	var tag sql.NullString
+	if *tagFilter != "" {
+		normalized, err := normalizeTag(*tagFilter)
+		if err != nil {
+			return err
+		}
+		tag = sql.NullString{String: normalized, Valid: true}
+	}
+
+	following, err := s.db.GetFollowedFeedsMatching(ctx, database.GetFollowedFeedsMatchingParams{UserID: user.ID, Tag: tag})
+	if err != nil {
+		return fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	if *dryRun {
+		feeds, err := s.db.GetUnfollowedFeeds(ctx, database.GetUnfollowedFeedsParams{UserID: user.ID, Tag: tag})
+		if err != nil {
+			return fmt.Errorf("couldn't get feeds: %w", err)
+		}
+		for _, feed := range feeds {
+			fmt.Printf("* %s (%s)\n", feed.Name, feed.Url)
+		}
+		fmt.Printf("Would follow %d feed(s); already following %d.\n", len(feeds), len(following))
+		return nil
+	}
+
+	followed, err := s.db.FollowAllFeeds(ctx, database.FollowAllFeedsParams{
+		Now:    time.Now().UTC(),
+		UserID: user.ID,
+		Tag:    tag,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't follow feeds: %w", err)
+	}
+	fmt.Printf("Followed %d feed(s); skipped %d already followed.\n", followed, len(following))
+	return nil
+}
+
+// handlerFollowing handles the following command to list feeds current user is following
+func handlerFollowing(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	grouped := fs.Bool("grouped", false, "list feeds under the groups they're in")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--grouped]: %w", cmd.name, err)
+	}
+
+	ctx := context.Background()
+	// Get feed follows for user
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed follows: %w", err)
+	}
+	members, err := s.db.GetFeedGroupMembersForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed groups: %w", err)
+	}
+	feedGroups := groupsByFeed(members)
+
+	if s.outputFormat == output.JSON {
+		result := output.FollowingResult{User: user.Name, Feeds: make([]output.FollowJSON, 0, len(follows))}
+		for _, follow := range follows {
+			result.Feeds = append(result.Feeds, output.FollowJSON{FeedID: follow.FeedID, Name: follow.FeedName, URL: follow.FeedUrl, Groups: feedGroups[follow.FeedID]})
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(follows) == 0 {
+		fmt.Println("You are not following any feeds.")
+		return nil
+	}
+
+	fmt.Printf("Following feeds for %s:\n", user.Name)
+	if !*grouped {
+		for _, follow := range follows {
+			fmt.Printf("* %s\n", follow.FeedName)
+		}
+		return nil
+	}
+
+	followed := make(map[uuid.UUID]bool, len(follows))
+	for _, follow := range follows {
+		followed[follow.FeedID] = true
+	}
+	current := ""
+	for _, member := range members {
+		if !followed[member.FeedID] {
+			continue
+		}
+		if member.GroupName != current {
+			current = member.GroupName
+			fmt.Printf("%s:\n", current)
+		}
+		fmt.Printf("  * %s\n", member.FeedName)
+	}
+	var ungrouped []string
+	for _, follow := range follows {
+		if len(feedGroups[follow.FeedID]) == 0 {
+			ungrouped = append(ungrouped, follow.FeedName)
+		}
+	}
+	if len(ungrouped) > 0 {
+		fmt.Println("Ungrouped:")
+		for _, name := range ungrouped {
+			fmt.Printf("  * %s\n", name)
+		}
+	}
+
+	return nil
+}
+
+// groupsByFeed maps each feed to the names of the groups it's in, in name order
+func groupsByFeed(members []database.GetFeedGroupMembersForUserRow) map[uuid.UUID][]string {
+	groups := make(map[uuid.UUID][]string)
+	for _, member := range members {
+		groups[member.FeedID] = append(groups[member.FeedID], member.GroupName)
+	}
+	return groups
+}
+
+// handlerUnfollow allows a user to unfollow a feed by its URL
+func handlerUnfollow(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: unfollow <feed-url>")
+	}
+
+	feedURL := cmd.args[0]
+	feed, err := s.db.GetFeedByURL(context.Background(), feedURL)
+	if err != nil {
+		return fmt.Errorf("could not find feed with URL %s: %w", feedURL, err)
+	}
+
+	// Adjusted to handle two return values from DeleteFeedFollowByUserAndFeed
+	rowsAffected, err := s.db.DeleteFeedFollowByUserAndFeed(context.Background(), database.DeleteFeedFollowByUserAndFeedParams{
+		UserID: user.ID,
+		FeedID: feed.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("could not unfollow feed: %w", err)
+	}
+	if rowsAffected == 0 {
+		return fmt.Errorf("no feed follow record found to delete")
+	}
+
+	fmt.Printf("Successfully unfollowed feed: %s\n", feed.Name)
+	return nil
+}
+
+// handlerUnfollowAll unfollows every feed the user follows, or only those with --tag or
+// in --group. It lists the feeds and asks first unless --confirm is given.
+func handlerUnfollowAll(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	tagFilter := fs.String("tag", "", "only unfollow feeds with this tag")
+	groupFilter := fs.String("group", "", "only unfollow feeds in this group")
+	confirm := fs.Bool("confirm", false, "unfollow without asking")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) > 0 {
+		return fmt.Errorf("usage: %s [--tag <tag>] [--group <group>] [--confirm]", cmd.name)
+	}
+
+	ctx := context.Background()
+	params := database.GetFollowedFeedsMatchingParams{UserID: user.ID}
+	if *tagFilter != "" {
+		tag, err := normalizeTag(*tagFilter)
+		if err != nil {
+			return err
+		}
+		params.Tag = sql.NullString{String: tag, Valid: true}
+	}
+	if *groupFilter != "" {
+		group, err := getFeedGroup(ctx, s, user, *groupFilter)
+		if err != nil {
+			return err
+		}
+		params.GroupID = uuid.NullUUID{UUID: group.ID, Valid: true}
+	}
+
+	feeds, err := s.db.GetFollowedFeedsMatching(ctx, params)
+	if err != nil {
+		return fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	if len(feeds) == 0 {
+		fmt.Println("No matching feeds to unfollow.")
+		return nil
+	}
+	if !*confirm {
+		for _, feed := range feeds {
+			fmt.Printf("* %s (%s)\n", feed.Name, feed.Url)
+		}
+		answer, err := promptLine(fmt.Sprintf("Unfollow %d feed(s)? [y/N] ", len(feeds)))
+		if err != nil || !strings.EqualFold(answer, "y") {
+			fmt.Println("Nothing unfollowed.")
+			return nil
+		}
+	}
+
+	unfollowed, err := s.db.DeleteFeedFollowsMatching(ctx, database.DeleteFeedFollowsMatchingParams(params))
+	if err != nil {
+		return fmt.Errorf("couldn't unfollow feeds: %w", err)
+	}
+	fmt.Printf("Unfollowed %d feed(s).\n", unfollowed)
+	return nil
+}
+
+// handlerDeleteFeed deletes a feed owned by the current user
+func handlerDeleteFeed(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	force := fs.Bool("force", false, "delete the feed even if other users follow it")
+	// Kept so existing scripts still parse; a feed's posts always go with it
+	fs.Bool("cascade", false, "no longer needed: the feed's posts are always deleted with it")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: %s <feed-url> [--force]", cmd.name)
+	}
+
+	ctx := context.Background()
+	feedURL := args[0]
+	feed, err := s.db.GetFeedByURL(ctx, feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", feedURL, err)
+	}
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who created %s can delete it", feed.Name)
+	}
+
+	followers, err := s.db.GetFeedFollowersForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get feed followers: %w", err)
+	}
+	var others []string
+	for _, follower := range followers {
+		if follower.ID != user.ID {
+			others = append(others, follower.Name)
+		}
+	}
+	if len(others) > 0 && !*force {
+		return fmt.Errorf("feed is followed by other users (%s); use --force to delete it anyway", strings.Join(others, ", "))
+	}
+
+	// Delete the follows and the feed in one transaction so a failure can't leave
+	// orphans behind; its posts are removed along with it
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.InTx(tx)
+
+	follows, err := qtx.DeleteFeedFollowsForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete feed follows: %w", err)
+	}
+	posts, err := qtx.DeletePostsForFeed(ctx, feed.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't delete feed posts: %w", err)
+	}
+	if _, err := qtx.DeleteFeed(ctx, feed.ID); err != nil {
+		return fmt.Errorf("couldn't delete feed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit feed deletion: %w", err)
+	}
+
+	fmt.Printf("Deleted feed %s (%d follows, %d posts)\n", feed.Name, follows, posts)
+	return nil
+}
+
+// handlerUpdateFeed renames a feed or moves it to a new URL, merging it into an
+// existing feed when the new URL is already known
+func handlerUpdateFeed(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	newName := fs.String("name", "", "new name for the feed")
+	newURL := fs.String("url", "", "new URL for the feed")
+	check := fs.Bool("check", false, "verify the new URL serves a feed before saving it")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 || (*newName == "" && *newURL == "") {
+		return fmt.Errorf("usage: %s <old-url> [--name <new-name>] [--url <new-url>] [--check]", cmd.name)
+	}
+
+	ctx := context.Background()
+	feed, err := s.db.GetFeedByURL(ctx, args[0])
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", args[0], err)
+	}
+	if feed.UserID != user.ID {
+		return fmt.Errorf("only the user who created %s can update it", feed.Name)
+	}
+
+	urlChanged := *newURL != "" && *newURL != feed.Url
+	if urlChanged && *check {
+		result := probeFeed(ctx, *newURL)
+		if result.Err != nil {
+			return fmt.Errorf("new URL failed the check: %s", describeProbeError(result.Err))
+		}
+		fmt.Printf("New URL is reachable (%d, %s)\n", result.StatusCode, result.Format)
+	}
+
+	// Moving onto a URL that's already stored merges the two feeds
+	var mergeInto *database.GetFeedByURLRow
+	if urlChanged {
+		existing, err := s.db.GetFeedByURL(ctx, *newURL)
+		switch {
+		case err == nil:
+			if existing.UserID != user.ID {
+				return fmt.Errorf("%s is already stored as %q, which you didn't create; only its creator can merge feeds into it", *newURL, existing.Name)
+			}
+			answer, err := promptLine(fmt.Sprintf("%s is already stored as %q. Merge %q into it? [y/N] ", *newURL, existing.Name, feed.Name))
+			if err != nil || !strings.EqualFold(answer, "y") {
+				fmt.Println("Update cancelled.")
+				return nil
+			}
+			mergeInto = &existing
+		case !errors.Is(err, sql.ErrNoRows):
+			return fmt.Errorf("couldn't look up feed with URL %s: %w", *newURL, err)
+		}
+	}
+
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+	qtx := s.db.InTx(tx)
+
+	if mergeInto != nil {
+		// --name renames the feed that survives the merge
+		if *newName != "" && *newName != mergeInto.Name {
+			if err := qtx.UpdateFeedName(ctx, database.UpdateFeedNameParams{ID: mergeInto.ID, Name: *newName}); err != nil {
+				return fmt.Errorf("couldn't rename feed: %w", err)
+			}
+			mergeInto.Name = *newName
+		}
+		return mergeFeed(ctx, qtx, tx, feed.ID, mergeInto.ID, feed.Name, mergeInto.Name)
+	}
+	if urlChanged {
+		if err := qtx.UpdateFeedURL(ctx, database.UpdateFeedURLParams{ID: feed.ID, Url: *newURL}); err != nil {
+			return fmt.Errorf("couldn't update feed URL: %w", err)
+		}
+	}
+
+	if *newName != "" && *newName != feed.Name {
+		if err := qtx.UpdateFeedName(ctx, database.UpdateFeedNameParams{ID: feed.ID, Name: *newName}); err != nil {
+			return fmt.Errorf("couldn't rename feed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit feed update: %w", err)
+	}
+
+	fmt.Printf("Updated feed %s\n", feed.Name)
+	if *newName != "" {
+		fmt.Printf("  Name: %s\n", *newName)
+	}
+	if *newURL != "" {
+		fmt.Printf("  URL:  %s\n", *newURL)
+	}
+	return nil
+}
+
+// mergeFeed moves the follows and posts of one feed onto another and deletes the
+// emptied feed, committing tx once everything has moved
+func mergeFeed(ctx context.Context, qtx database.Querier, tx *sql.Tx, fromID, toID uuid.UUID, fromName, toName string) error {
+	move := database.MoveFeedFollowsParams{ToFeedID: toID, FromFeedID: fromID}
+	follows, err := qtx.MoveFeedFollows(ctx, move)
+	if err != nil {
+		return fmt.Errorf("couldn't move feed follows: %w", err)
+	}
+	posts, err := qtx.MovePostsToFeed(ctx, database.MovePostsToFeedParams(move))
+	if err != nil {
+		return fmt.Errorf("couldn't move feed posts: %w", err)
+	}
+	// Users who already followed both feeds keep their existing follow
+	if _, err := qtx.DeleteFeedFollowsForFeed(ctx, fromID); err != nil {
+		return fmt.Errorf("couldn't delete feed follows: %w", err)
+	}
+	if _, err := qtx.DeleteFeed(ctx, fromID); err != nil {
+		return fmt.Errorf("couldn't delete feed: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit feed merge: %w", err)
+	}
+
+	fmt.Printf("Merged %s into %s (%d follows, %d posts moved)\n", fromName, toName, follows, posts)
+	return nil
+}
+
+// handlerBrowse supports pagination, sorting, and optional feed filtering
+func handlerBrowse(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	unreadOnly := fs.Bool("unread", false, "only show posts that haven't been marked read")
+	bookmarked := fs.Bool("bookmarked", false, "only show bookmarked posts")
+	tagFilter := fs.String("tag", "", "only show posts from feeds with this tag or given it by an autotag rule")
+	groupFilter := fs.String("group", "", "only show posts from feeds in this group")
+	minRead := fs.Int("min-read", 0, "only show posts estimated to take at least this many minutes to read, out of the page fetched")
+	maxRead := fs.Int("max-read", 0, "only show posts estimated to take at most this many minutes to read, out of the page fetched")
+	asText := fs.Bool("text", false, "show descriptions as plain text instead of HTML")
+	full := fs.Bool("full", false, "show whole descriptions, formatted for the terminal")
+	authorFilter := fs.String("author", "", "only show posts whose author's name contains this")
+	sinceFlag := fs.String("since", "", "only show posts published since this age or date, e.g. 7d or 2024-01-01")
+	untilFlag := fs.String("until", "", "only show posts published before this age or up to this date, e.g. 2024-01-31")
+	today := fs.Bool("today", false, "only show posts from the last 24 hours, like --since 24h")
+	page := fs.Int("page", 0, "show this page of results, counting from 1, instead of giving an offset")
+	feedFlag := fs.String("feed", "", "only show posts from this feed, by ID or part of its name")
+	verbose := fs.Bool("verbose", false, "also show each post's detected language")
+	fs.BoolVar(verbose, "v", false, "shorthand for --verbose")
+	usePager := fs.Bool("pager", false, "show the posts through $PAGER (less by default) when stdout is a terminal")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil {
+		return fmt.Errorf("usage: %s [next | prev | limit] [offset] [sort] [order] [feed-id] [--page N] [--feed F] [--unread] [--bookmarked] [--tag T] [--group G] [--author A] [--since S] [--until U] [--today] [--min-read M] [--max-read M] [--text | --full] [--verbose] [--pager]: %w", cmd.name, err)
+	}
+	if *asText && *full {
+		return fmt.Errorf("--text and --full can't be combined")
+	}
+	if n := countTrue(*unreadOnly, *bookmarked, *tagFilter != "", *groupFilter != "", *authorFilter != ""); n > 1 {
+		return fmt.Errorf("--unread, --bookmarked, --tag, --group and --author can't be combined")
+	}
+	if *today {
+		if *sinceFlag != "" {
+			return fmt.Errorf("--today and --since can't be combined")
+		}
+		*sinceFlag = "24h"
+	}
+	var publishedAfter, publishedBefore sql.NullTime
+	if *sinceFlag != "" || *untilFlag != "" {
+		if countTrue(*bookmarked, *tagFilter != "", *groupFilter != "", *authorFilter != "") > 0 {
+			return fmt.Errorf("--since, --until and --today can't be combined with --bookmarked, --tag, --group or --author")
+		}
+		now := time.Now()
+		if *sinceFlag != "" {
+			after, err := parseTimeBound(*sinceFlag, now, false)
+			if err != nil {
+				return fmt.Errorf("invalid --since: %w", err)
+			}
+			publishedAfter = sql.NullTime{Time: after.UTC(), Valid: true}
+		}
+		if *untilFlag != "" {
+			before, err := parseTimeBound(*untilFlag, now, true)
+			if err != nil {
+				return fmt.Errorf("invalid --until: %w", err)
+			}
+			publishedBefore = sql.NullTime{Time: before.UTC(), Valid: true}
+		}
+		if publishedAfter.Valid && publishedBefore.Valid && !publishedAfter.Time.Before(publishedBefore.Time) {
+			return fmt.Errorf("--since must come before --until")
+		}
+	}
+	if *minRead < 0 || *maxRead < 0 || (*maxRead > 0 && *minRead > *maxRead) {
+		return fmt.Errorf("--min-read and --max-read must be positive, with min no more than max")
+	}
+
+	if *page < 0 {
+		return fmt.Errorf("--page must be at least 1")
+	}
+
+	limit := 2
+	offset := 0
+	sortBy := "published_at"
+	order := "desc"
+	feedFilter := ""
+
+	feedsHash, err := followedFeedsHash(context.Background(), s, user)
+	if err != nil {
+		return err
+	}
+	// next and prev move one page from where the last browse left off
+	if len(args) > 0 && (args[0] == "next" || args[0] == "prev") {
+		if len(args) > 1 || *page > 0 {
+			return fmt.Errorf("%s %s continues the last browse, so it takes no limit, offset or --page", cmd.name, args[0])
+		}
+		last, err := loadBrowseState(user.Name, feedsHash)
+		if err != nil {
+			return err
+		}
+		moved, err := last.step(args[0])
+		if err != nil {
+			return err
+		}
+		limit, offset, sortBy, order, feedFilter = moved.Limit, moved.Offset, moved.Sort, moved.Order, moved.FeedFilter
+		args = nil
+	}
+
+	if len(args) > 0 {
+		parsedLimit, err := strconv.Atoi(args[0])
+		if err != nil {
+			return fmt.Errorf("invalid limit: %v", err)
+		}
+		limit = parsedLimit
+	}
+	if len(args) > 1 {
+		parsedOffset, err := strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid offset: %v", err)
+		}
+		offset = parsedOffset
+	}
+	if len(args) > 2 {
+		sortBy = strings.ToLower(args[2])
+	}
+	if len(args) > 3 {
+		order = strings.ToLower(args[3])
+		if order != "asc" && order != "desc" {
+			return fmt.Errorf("invalid order: must be asc or desc")
+		}
+	}
+	if len(args) > 4 {
+		feedFilter = args[4]
+	}
+	if *feedFlag != "" {
+		if len(args) > 4 {
+			return fmt.Errorf("--feed and a feed-id argument can't be combined")
+		}
+		feedFilter = *feedFlag
+	}
+	feedID, err := resolveFeedFilter(context.Background(), s, feedFilter)
+	if err != nil {
+		return err
+	}
+	if feedID.Valid {
+		// Only the paginated and unread listings filter by feed in the query; doing it
+		// after the others' LIMIT would leave pages short
+		if countTrue(*bookmarked, *tagFilter != "", *groupFilter != "", *authorFilter != "") > 0 {
+			return fmt.Errorf("--feed and a feed-id argument can't be combined with --bookmarked, --tag, --group or --author")
+		}
+		feedFilter = feedID.UUID.String()
+	}
+	if *page > 0 {
+		if len(args) > 1 {
+			return fmt.Errorf("--page and an offset can't be combined")
+		}
+		offset = (*page - 1) * limit
+	}
+
+	var posts []database.Post
+	if *bookmarked {
+		bookmarks, err := s.db.GetBookmarksForUser(context.Background(), database.GetBookmarksForUserParams{
+			UserID: user.ID,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching bookmarks: %v", err)
+		}
+		posts = bookmarkedPosts(bookmarks)
+	} else if *tagFilter != "" {
+		tag, err := normalizeTag(*tagFilter)
+		if err != nil {
+			return err
+		}
+		posts, err = s.db.GetPostsForUserByTag(context.Background(), database.GetPostsForUserByTagParams{
+			UserID: user.ID,
+			Tag:    tag,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching posts tagged %s: %v", tag, err)
+		}
+	} else if *groupFilter != "" {
+		group, err := getFeedGroup(context.Background(), s, user, *groupFilter)
+		if err != nil {
+			return err
+		}
+		posts, err = s.db.GetPostsForUserByGroup(context.Background(), database.GetPostsForUserByGroupParams{
+			UserID:  user.ID,
+			GroupID: group.ID,
+			Limit:   int32(limit),
+			Offset:  int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching posts in group %s: %v", group.Name, err)
+		}
+	} else if *authorFilter != "" {
+		posts, err = s.db.GetPostsForUserByAuthor(context.Background(), database.GetPostsForUserByAuthorParams{
+			UserID: user.ID,
+			Author: *authorFilter,
+			Limit:  int32(limit),
+			Offset: int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching posts by %s: %v", *authorFilter, err)
+		}
+	} else if *unreadOnly {
+		posts, err = s.db.GetUnreadPostsForUser(context.Background(), database.GetUnreadPostsForUserParams{
+			UserID:          user.ID,
+			PublishedAfter:  publishedAfter,
+			PublishedBefore: publishedBefore,
+			FeedID:          feedID,
+			Language:        user.PreferredLanguage,
+			Limit:           int32(limit),
+			Offset:          int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching unread posts: %v", err)
+		}
+	} else {
+		posts, err = s.db.GetPostsForUserPaginated(context.Background(), database.GetPostsForUserPaginatedParams{
+			UserID:          user.ID,
+			PublishedAfter:  publishedAfter,
+			PublishedBefore: publishedBefore,
+			FeedID:          feedID,
+			Language:        user.PreferredLanguage,
+			Limit:           int32(limit),
+			Offset:          int32(offset),
+		})
+		if err != nil {
+			return fmt.Errorf("error fetching posts: %v", err)
+		}
+	}
+
+	// Reading time is estimated from the description here rather than in SQL, so the
+	// filter thins out the fetched page and the page may come back short
+	readFiltered := *minRead > 0 || *maxRead > 0
+	if readFiltered {
+		filtered := make([]database.Post, 0, len(posts))
+		for _, post := range posts {
+			mins := readingMinutes(s, post.Description)
+			if mins >= *minRead && (*maxRead == 0 || mins <= *maxRead) {
+				filtered = append(filtered, post)
+			}
+		}
+		posts = filtered
+	}
+
+	switch sortBy {
+	case "title":
+		sort.SliceStable(posts, func(i, j int) bool {
+			return strings.ToLower(posts[i].Title) < strings.ToLower(posts[j].Title)
+		})
+	case "published_at", "published":
+		sort.SliceStable(posts, func(i, j int) bool {
+			left := posts[i].PublishedAt.Time
+			if !posts[i].PublishedAt.Valid {
+				left = posts[i].CreatedAt
+			}
+			right := posts[j].PublishedAt.Time
+			if !posts[j].PublishedAt.Valid {
+				right = posts[j].CreatedAt
+			}
+			return left.Before(right)
+		})
+	default:
+		return fmt.Errorf("unsupported sort column: %s", sortBy)
+	}
+
+	if order == "desc" {
+		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
+			posts[i], posts[j] = posts[j], posts[i]
+		}
+	}
+
+	if err := saveLastBrowse(user.Name, posts); err != nil {
+		s.logger.Warn("couldn't save browse results for open", "error", err)
+	}
+	// next and prev can't step through a page thinned out by reading time, so such a
+	// browse leaves the saved position alone
+	if !readFiltered {
+		err = saveBrowseState(browseState{
+			User:       user.Name,
+			Limit:      limit,
+			Offset:     offset,
+			Sort:       sortBy,
+			Order:      order,
+			FeedFilter: feedFilter,
+			FeedsHash:  feedsHash,
+		})
+		if err != nil {
+			s.logger.Warn("couldn't save browse position for next and prev", "error", err)
+		}
+	}
+
+	switch s.outputFormat {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, output.BrowseResult{Posts: postsJSON(posts)})
+	case output.CSV:
+		return output.WritePostsCSV(os.Stdout, postsJSON(posts))
+	}
+
+	postIDs := make([]uuid.UUID, 0, len(posts))
+	for _, post := range posts {
+		postIDs = append(postIDs, post.ID)
+	}
+	episodeRows, err := s.db.GetEpisodeInfoForPosts(context.Background(), postIDs)
+	if err != nil {
+		return fmt.Errorf("error fetching episode details: %v", err)
+	}
+	episodes := make(map[uuid.UUID]database.GetEpisodeInfoForPostsRow, len(episodeRows))
+	for _, episode := range episodeRows {
+		episodes[episode.PostID] = episode
+	}
+	pinnedIDs, err := s.db.GetPinnedPostIDs(context.Background(), database.GetPinnedPostIDsParams{
+		UserID:  user.ID,
+		PostIds: postIDs,
+	})
+	if err != nil {
+		return fmt.Errorf("error fetching pinned posts: %v", err)
+	}
+	pinned := make(map[uuid.UUID]bool, len(pinnedIDs))
+	for _, id := range pinnedIDs {
+		pinned[id] = true
+	}
+
+	var out io.Writer = os.Stdout
+	wait := func() error { return nil }
+	if *usePager {
+		paged, waitPager, err := pager.StartPager()
+		if err != nil {
+			return err
+		}
+		out, wait = paged, waitPager
+	}
+	for _, post := range posts {
+		publishedAt := post.CreatedAt
+		if post.PublishedAt.Valid {
+			publishedAt = post.PublishedAt.Time
+		}
+		description := ""
+		if post.Description.Valid {
+			description = post.Description.String
+		}
+		switch {
+		case *full:
+			description = "\n" + render.RenderHTML(description, terminalWidth())
+		case *asText:
+			description = truncateRunes(sanitize.HTMLToText(description), descriptionPreviewLen)
+		default:
+			description = truncateRunes(description, descriptionPreviewLen)
+		}
+		title := post.Title
+		if pinned[post.ID] {
+			title += " (pinned)"
+		}
+		fmt.Fprintf(out, "ID: %s\nTitle: %s\n", post.ID, title)
+		if post.Author.Valid {
+			fmt.Fprintf(out, "Author: %s\n", post.Author.String)
+		}
+		fmt.Fprintf(out, "URL: %s\nPublished At: %s\n", post.Url, publishedAt.Format(time.RFC1123))
+		if episode := formatEpisode(episodes[post.ID]); episode != "" {
+			fmt.Fprintf(out, "Episode: %s\n", episode)
+		}
+		if *verbose {
+			language := "unknown"
+			if post.Language.Valid {
+				language = post.Language.String
+			}
+			fmt.Fprintf(out, "Language: %s\n", language)
+		}
+		fmt.Fprintf(out, "Description: %s\n", description)
+		if mins := readingMinutes(s, post.Description); mins > 0 {
+			fmt.Fprintf(out, "Est. read: %d min\n", mins)
+		}
+		fmt.Fprintf(out, "Feed ID: %s\n\n", post.FeedID)
+	}
+
+	return wait()
+}
+
+// descriptionPreviewLen is how much of each description browse shows without --full
+const descriptionPreviewLen = 200
+
+// truncateRunes shortens s to at most n characters, marking the cut with "..."
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return strings.TrimSpace(string(runes[:n-3])) + "..."
+}
+
+// terminalWidth is the width of the terminal browse prints to, from $COLUMNS or the
+// terminal itself; 80 when neither says
+func terminalWidth() int {
+	if columns, err := strconv.Atoi(os.Getenv("COLUMNS")); err == nil && columns > 0 {
+		return columns
+	}
+	if width, _, err := term.GetSize(int(os.Stdout.Fd())); err == nil && width > 0 {
+		return width
+	}
+	return 80
+}
+
+// readingMinutes estimates how many minutes a post's description takes to read at the
+// configured reading_wpm; 0 for posts without one
+func readingMinutes(s *state, description sql.NullString) int {
+	if !description.Valid {
+		return 0
+	}
+	return readtime.Minutes(readtime.EstimateReadingTimeAt(description.String, s.cfg.ReadingWPM))
+}
+
+// formatEpisode describes a podcast episode's number and length, e.g. "S2 E12 · 1:02:03";
+// empty for posts without either
+func formatEpisode(episode database.GetEpisodeInfoForPostsRow) string {
+	var parts []string
+	switch {
+	case episode.SeasonNumber.Valid && episode.EpisodeNumber.Valid:
+		parts = append(parts, fmt.Sprintf("S%d E%d", episode.SeasonNumber.Int32, episode.EpisodeNumber.Int32))
+	case episode.EpisodeNumber.Valid:
+		parts = append(parts, fmt.Sprintf("E%d", episode.EpisodeNumber.Int32))
+	}
+	if episode.DurationSecs.Valid {
+		secs := episode.DurationSecs.Int32
+		if secs >= 3600 {
+			parts = append(parts, fmt.Sprintf("%d:%02d:%02d", secs/3600, secs%3600/60, secs%60))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d:%02d", secs/60, secs%60))
+		}
+	}
+	return strings.Join(parts, " · ")
+}
+
+// lastBrowseFile remembers the posts printed by the most recent browse so
+// "open N" can refer to them by position
+var lastBrowseFile = filepath.Join(os.TempDir(), "gator_last_browse.json")
+
+// lastBrowse is the content of lastBrowseFile
+type lastBrowse struct {
+	User    string      `json:"user"`
+	PostIDs []uuid.UUID `json:"post_ids"`
+}
+
+// saveLastBrowse records the IDs of the posts a browse printed, in order
+func saveLastBrowse(userName string, posts []database.Post) error {
+	saved := lastBrowse{User: userName, PostIDs: make([]uuid.UUID, 0, len(posts))}
+	for _, post := range posts {
+		saved.PostIDs = append(saved.PostIDs, post.ID)
+	}
+	data, err := json.Marshal(saved)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(lastBrowseFile, data, 0600)
+}
+
+// browseStateFile remembers where the last browse left off for browse next and prev
+var browseStateFile = func() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(os.TempDir(), ".gator_browse_state.json")
+	}
+	return filepath.Join(home, ".gator_browse_state.json")
+}()
+
+// browseState is the content of browseStateFile. FeedsHash identifies the feeds the
+// user followed at the time, since following or unfollowing shifts every page.
+type browseState struct {
+	User       string `json:"user"`
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	Sort       string `json:"sort"`
+	Order      string `json:"order"`
+	FeedFilter string `json:"feed_filter"`
+	FeedsHash  string `json:"feeds_hash"`
+}
+
+// step returns the state one page forward for "next" or back for "prev"
+func (b browseState) step(direction string) (browseState, error) {
+	if direction == "next" {
+		b.Offset += b.Limit
+		return b, nil
+	}
+	if b.Offset == 0 {
+		return b, fmt.Errorf("already on the first page")
+	}
+	b.Offset = max(b.Offset-b.Limit, 0)
+	return b, nil
+}
+
+// saveBrowseState records the page a browse showed
+func saveBrowseState(state browseState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(browseStateFile, data, 0600)
+}
+
+// loadBrowseState returns the user's last browse position, discarding it when the
+// feeds they follow no longer match feedsHash
+func loadBrowseState(userName, feedsHash string) (browseState, error) {
+	var state browseState
+	data, err := os.ReadFile(browseStateFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return state, fmt.Errorf("no browse to continue; run browse first")
+	}
+	if err != nil {
+		return state, fmt.Errorf("couldn't read browse position: %w", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("couldn't parse browse position: %w", err)
+	}
+	if state.User != userName {
+		return state, fmt.Errorf("no browse to continue for %s; run browse first", userName)
+	}
+	if state.FeedsHash != feedsHash {
+		os.Remove(browseStateFile)
+		return state, fmt.Errorf("the feeds you follow changed since the last browse; run browse again")
+	}
+	return state, nil
+}
+
+// followedFeedsHash fingerprints the set of feeds the user follows
+func followedFeedsHash(ctx context.Context, s *state, user database.User) (string, error) {
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return "", fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	ids := make([]uuid.UUID, 0, len(follows))
+	for _, follow := range follows {
+		ids = append(ids, follow.FeedID)
+	}
+	return feedIDsHash(ids), nil
+}
+
+// feedIDsHash hashes feed IDs regardless of their order
+func feedIDsHash(ids []uuid.UUID) string {
+	keys := make([]string, 0, len(ids))
+	for _, id := range ids {
+		keys = append(keys, id.String())
+	}
+	sort.Strings(keys)
+	sum := sha256.Sum256([]byte(strings.Join(keys, ",")))
+	return hex.EncodeToString(sum[:8])
+}
+
+// lastBrowsedPostID returns the nth (1-indexed) post of the user's most recent browse
+func lastBrowsedPostID(userName string, n int) (uuid.UUID, error) {
+	data, err := os.ReadFile(lastBrowseFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return uuid.Nil, fmt.Errorf("no recent browse results; run browse first")
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("couldn't read browse results: %w", err)
+	}
+
+	var saved lastBrowse
+	if err := json.Unmarshal(data, &saved); err != nil {
+		return uuid.Nil, fmt.Errorf("couldn't parse browse results: %w", err)
+	}
+	if saved.User != userName {
+		return uuid.Nil, fmt.Errorf("no recent browse results for %s; run browse first", userName)
+	}
+	if n < 1 || n > len(saved.PostIDs) {
+		return uuid.Nil, fmt.Errorf("post %d is out of range: the last browse showed %d posts", n, len(saved.PostIDs))
+	}
+	return saved.PostIDs[n-1], nil
+}
+
+// handlerOpen opens a post in the browser by ID, by URL, or by its position in the last browse output
+func handlerOpen(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <post-id|url|n>", cmd.name)
+	}
+
+	ctx := context.Background()
+	arg := cmd.args[0]
+	if strings.HasPrefix(arg, "http") {
+		return browser.Open(ctx, arg)
+	}
+
+	post, err := followedPost(ctx, s, user, arg)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("Opening %s\n", post.Url)
+	return browser.Open(ctx, post.Url)
+}
+
+// followedPost looks up a post by UUID or position in the last browse, making sure it
+// belongs to a feed the user follows
+func followedPost(ctx context.Context, s *state, user database.User, arg string) (database.Post, error) {
+	var postID uuid.UUID
+	if n, err := strconv.Atoi(arg); err == nil {
+		postID, err = lastBrowsedPostID(user.Name, n)
+		if err != nil {
+			return database.Post{}, err
+		}
+	} else {
+		postID, err = uuid.Parse(arg)
+		if err != nil {
+			return database.Post{}, fmt.Errorf("invalid post ID %s: expected a UUID, URL or browse position", arg)
+		}
+	}
+
+	post, err := s.db.GetPostByID(ctx, postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return database.Post{}, fmt.Errorf("no post with ID %s", postID)
+	}
+	if err != nil {
+		return database.Post{}, fmt.Errorf("couldn't look up post %s: %w", postID, err)
+	}
+
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return database.Post{}, fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	for _, follow := range follows {
+		if follow.FeedID == post.FeedID {
+			return post, nil
+		}
+	}
+	return database.Post{}, fmt.Errorf("post %s belongs to a feed you don't follow", postID)
+}
+
+// shortDescriptionLen is the length below which read fetches the article itself, as the
+// feed probably only carried a teaser
+const shortDescriptionLen = 200
+
+// handlerRead shows a post's content in the terminal through the pager
+func handlerRead(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	noFetch := fs.Bool("no-fetch", false, "only show the stored description, even when it's short")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %s <post-id|n> [--no-fetch]", cmd.name)
+	}
+
+	ctx := context.Background()
+	post, err := followedPost(ctx, s, user, args[0])
+	if err != nil {
+		return err
+	}
+
+	content := post.Description.String
+	if !*noFetch && len([]rune(sanitize.HTMLToText(content))) < shortDescriptionLen && post.Url != "" {
+		fmt.Fprintln(os.Stderr, "Warning: the stored description is short, fetching full article...")
+		client := &http.Client{Timeout: fetchTimeout, Transport: httpTransport}
+		fetched, err := article.Fetch(ctx, client, post.Url)
+		if err != nil {
+			s.logger.Warn("couldn't fetch article, showing the stored description",
+				"post_url", post.Url,
+				"post_id", post.ID,
+				"error", err,
+			)
+		} else if cleaned := sanitize.SanitizeHTML(fetched); cleaned != "" {
+			content = cleaned
+		}
+	}
+
+	text := render.RenderHTML(content, terminalWidth())
+	if text == "" {
+		text = "(no content)"
+	}
+	out, wait, err := pager.StartPager()
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "%s\n%s\n\n%s\n", post.Title, post.Url, text)
+	return wait()
+}
+
+// handlerSearch allows users to perform fuzzy searches on posts
+func handlerSearch(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	limit := fs.Int("limit", 20, "maximum number of results (0 for all)")
+	offset := fs.Int("offset", 0, "number of results to skip")
+	all := fs.Bool("all", false, "return every result, e.g. for piping to other tools")
+	sortBy := fs.String("sort", "date", "result order: date (newest first), title or relevance")
+	mode := fs.String("mode", "fulltext", "search mode: fulltext (ranked) or like (substring match)")
+	useRegex := fs.Bool("regex", false, "treat the query as a Go regular expression")
+	field := fs.String("field", "title", "field matched by --regex: title, desc or url")
+	groupFilter := fs.String("group", "", "only search posts from feeds in this group")
+	tagFilter := fs.String("tag", "", "only search posts from feeds with this tag or given it by an autotag rule")
+	feedFlag := fs.String("feed", "", "only search posts from this feed, by ID or part of its name")
+	wholeWord := fs.Bool("whole-word", false, "only match the query as whole words in titles and descriptions")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: search <query> [--limit N] [--offset M | --all] [--sort date|title|relevance] [--mode fulltext|like] [--whole-word] [--regex [--field title|desc|url]] [--group G] [--tag T] [--feed F]")
+	}
+	if *wholeWord && *useRegex {
+		return fmt.Errorf("--whole-word and --regex can't be combined")
+	}
+	if *mode != "fulltext" && *mode != "like" {
+		return fmt.Errorf("invalid search mode %q: use fulltext or like", *mode)
+	}
+	if *sortBy != "date" && *sortBy != "title" && *sortBy != "relevance" {
+		return fmt.Errorf("invalid sort %q: use date, title or relevance", *sortBy)
+	}
+	if *limit < 0 || *offset < 0 {
+		return fmt.Errorf("--limit and --offset can't be negative")
+	}
+	if *all {
+		if *offset > 0 {
+			return fmt.Errorf("--all and --offset can't be combined")
+		}
+		*limit = 0
+	}
+
+	query := strings.Join(args, " ")
+	params := database.SearchPostsParams{
+		SearchMode: *mode,
+		Query:      query,
+		UserID:     user.ID,
+	}
+	// A post URL finds the post however its link was decorated, since like mode also
+	// matches the normalized URL exactly
+	if parsed, err := neturl.Parse(query); err == nil && !*useRegex && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "" {
+		params.SearchMode = "like"
+		params.Query = normalizedPostURL(query)
+	}
+	if *wholeWord {
+		params.SearchMode = "word"
+		params.WordPattern = wordPattern(query)
+	}
+
+	// PostgreSQL's regex dialect differs from Go's, so regex searches fetch every
+	// post and filter here
+	var pattern *regexp.Regexp
+	if *useRegex {
+		pattern, err = regexp.Compile(query)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression %q: %w", query, err)
+		}
+		if *field != "title" && *field != "desc" && *field != "url" {
+			return fmt.Errorf("invalid field %q: use title, desc or url", *field)
+		}
+		params = database.SearchPostsParams{SearchMode: "like", UserID: user.ID}
+	}
+	params.FeedID, err = resolveFeedFilter(context.Background(), s, *feedFlag)
+	if err != nil {
+		return err
+	}
+	params.SortBy = *sortBy
+	// Regex, group and tag searches page through the matches themselves
+	filterHere := pattern != nil || *groupFilter != "" || *tagFilter != ""
+	if !filterHere {
+		params.Limit = int32(*limit)
+		params.Offset = int32(*offset)
+	}
+
+	// Group membership is checked here too, so grouped searches also fetch every match
+	var inGroup map[uuid.UUID]bool
+	if *groupFilter != "" {
+		group, err := getFeedGroup(context.Background(), s, user, *groupFilter)
+		if err != nil {
+			return err
+		}
+		groupFeeds, err := s.db.GetFeedsInGroup(context.Background(), group.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get feeds in group: %w", err)
+		}
+		inGroup = make(map[uuid.UUID]bool, len(groupFeeds))
+		for _, feed := range groupFeeds {
+			inGroup[feed.ID] = true
+		}
+	}
+
+	// A post has a tag when its feed does or a tag rule gave it the tag
+	var taggedFeeds, taggedPosts map[uuid.UUID]bool
+	if *tagFilter != "" {
+		tag, err := normalizeTag(*tagFilter)
+		if err != nil {
+			return err
+		}
+		feeds, err := s.db.GetFeedsByTag(context.Background(), tag)
+		if err != nil {
+			return fmt.Errorf("couldn't get feeds tagged %q: %w", tag, err)
+		}
+		postIDs, err := s.db.GetPostIDsByTag(context.Background(), tag)
+		if err != nil {
+			return fmt.Errorf("couldn't get posts tagged %q: %w", tag, err)
+		}
+		taggedFeeds = make(map[uuid.UUID]bool, len(feeds))
+		for _, feed := range feeds {
+			taggedFeeds[feed.ID] = true
+		}
+		taggedPosts = make(map[uuid.UUID]bool, len(postIDs))
+		for _, id := range postIDs {
+			taggedPosts[id] = true
+		}
+	}
+
+	results, err := s.db.SearchPosts(context.Background(), params)
+	if err != nil {
+		return fmt.Errorf("error searching posts: %v", err)
+	}
+
+	posts := make([]database.Post, 0, len(results))
+	skipped := 0
+	for _, result := range results {
+		if pattern != nil && !pattern.MatchString(regexField(result, *field)) {
+			continue
+		}
+		if inGroup != nil && !inGroup[result.FeedID] {
+			continue
+		}
+		if taggedFeeds != nil && !taggedFeeds[result.FeedID] && !taggedPosts[result.ID] {
+			continue
+		}
+		if filterHere {
+			if skipped < *offset {
+				skipped++
+				continue
+			}
+			if *limit > 0 && len(posts) == *limit {
+				break
+			}
+		}
+		posts = append(posts, database.Post{
+			ID:          result.ID,
+			CreatedAt:   result.CreatedAt,
+			UpdatedAt:   result.UpdatedAt,
+			Title:       result.Title,
+			Url:         result.Url,
+			Description: result.Description,
+			PublishedAt: result.PublishedAt,
+			FeedID:      result.FeedID,
+		})
+	}
+
+	switch s.outputFormat {
+	case output.JSON:
+		return output.WriteJSON(os.Stdout, output.SearchResult{Query: query, Posts: postsJSON(posts)})
+	case output.CSV:
+		return output.WritePostsCSV(os.Stdout, postsJSON(posts))
+	}
+
+	highlight := func(text string) string { return text }
+	if term.IsTerminal(int(os.Stdout.Fd())) {
+		if pattern == nil {
+			pattern = termsPattern(searchTerms(query))
+		}
+		highlight = func(text string) string { return highlightMatches(text, pattern) }
+	}
+
+	for _, post := range posts {
+		publishedAt := post.CreatedAt
+		if post.PublishedAt.Valid {
+			publishedAt = post.PublishedAt.Time
+		}
+		fmt.Printf("Title: %s\nURL: %s\nPublished At: %s\n\n", highlight(post.Title), highlight(post.Url), publishedAt.Format(time.RFC1123))
+	}
+	// A full page suggests there are more
+	if *limit > 0 && len(posts) == *limit {
+		fmt.Println(searchPageFooter(*offset, len(posts)))
+	}
+
+	return nil
+}
+
+// searchPageFooter points at the next page of results after a full one
+func searchPageFooter(offset, shown int) string {
+	return fmt.Sprintf("Showing results %d-%d, use --offset %d to see more", offset+1, offset+shown, offset+shown)
+}
+
+// wordPattern builds the PostgreSQL regular expression for --whole-word: the query's words
+// in order, taken literally, with no letter, digit or underscore right before or after
+func wordPattern(query string) string {
+	words := strings.Fields(query)
+	for i, word := range words {
+		words[i] = regexp.QuoteMeta(word)
+	}
+	return `(^|\W)` + strings.Join(words, `\s+`) + `(\W|$)`
+}
+
+// searchTerms splits a search query into the words worth highlighting,
+// dropping web-search syntax such as quotes, exclusions and OR
+func searchTerms(query string) []string {
+	var terms []string
+	for _, field := range strings.Fields(query) {
+		if strings.HasPrefix(field, "-") || strings.EqualFold(field, "or") {
+			continue
+		}
+		if field = strings.Trim(field, `"'`); field != "" {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// regexField returns the part of a search result that --field selects for --regex
+func regexField(result database.SearchPostsRow, field string) string {
+	switch field {
+	case "desc":
+		return result.Description.String
+	case "url":
+		return result.Url
+	}
+	return result.Title
+}
+
+// termsPattern matches any of terms case-insensitively, or nothing when there are none
+func termsPattern(terms []string) *regexp.Regexp {
+	if len(terms) == 0 {
+		return nil
+	}
+	quoted := make([]string, len(terms))
+	for i, t := range terms {
+		quoted[i] = regexp.QuoteMeta(t)
+	}
+	return regexp.MustCompile(`(?i)` + strings.Join(quoted, "|"))
+}
+
+// highlightMatches wraps every match of pattern in ANSI bold
+func highlightMatches(text string, pattern *regexp.Regexp) string {
+	if pattern == nil {
+		return text
+	}
+	return pattern.ReplaceAllStringFunc(text, func(match string) string {
+		if match == "" {
+			return match
+		}
+		return "\x1b[1m" + match + "\x1b[0m"
+	})
+}
+
+// countTrue reports how many of the given flags are set
+func countTrue(flags ...bool) int {
+	n := 0
+	for _, set := range flags {
+		if set {
+			n++
+		}
+	}
+	return n
+}
+
+// postsJSON converts posts to their --format json/csv shape
+func postsJSON(posts []database.Post) []output.PostJSON {
+	result := make([]output.PostJSON, 0, len(posts))
+	for _, post := range posts {
+		result = append(result, postJSON(post))
+	}
+	return result
+}
+
+// postJSON converts a single post to its --format json/csv shape
+func postJSON(post database.Post) output.PostJSON {
+	publishedAt := post.CreatedAt
+	if post.PublishedAt.Valid {
+		publishedAt = post.PublishedAt.Time
+	}
+	return output.PostJSON{
+		ID:          post.ID,
+		Title:       post.Title,
+		URL:         post.Url,
+		Description: post.Description.String,
+		Author:      post.Author.String,
+		PublishedAt: publishedAt,
+		FeedID:      post.FeedID,
+	}
+}
+
+// handlerMarkRead marks a post as read for the current user
+func handlerMarkRead(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <post-id>", cmd.name)
+	}
+
+	postID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post ID: %w", err)
+	}
+
+	// Only posts from feeds the user follows can be marked read
+	post, err := s.db.GetPostByID(context.Background(), postID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("no post with ID %s", postID)
+	}
+	if err != nil {
+		return fmt.Errorf("couldn't look up post %s: %w", postID, err)
+	}
+	follows, err := s.db.GetFeedFollowsForUser(context.Background(), user.ID)
+	if err != nil {
+		return fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	following := false
+	for _, follow := range follows {
+		if follow.FeedID == post.FeedID {
+			following = true
+			break
+		}
+	}
+	if !following {
+		return fmt.Errorf("post %s belongs to a feed you don't follow", postID)
+	}
+
+	alreadyRead, err := s.db.IsPostRead(context.Background(), database.IsPostReadParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't check read state: %w", err)
+	}
+	if alreadyRead {
+		fmt.Printf("Post %s is already marked as read\n", postID)
+		return nil
+	}
+
+	_, err = s.db.MarkPostRead(context.Background(), database.MarkPostReadParams{
+		UserID: user.ID,
+		PostID: postID,
+		ReadAt: time.Now().UTC(),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post as read: %w", err)
+	}
+
+	fmt.Printf("Post %s marked as read\n", postID)
+	return nil
+}
+
+// handlerMarkUnread clears the read state of a post for the current user
+func handlerMarkUnread(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <post-id>", cmd.name)
+	}
+
+	postID, err := uuid.Parse(cmd.args[0])
+	if err != nil {
+		return fmt.Errorf("invalid post ID: %w", err)
+	}
+
+	rowsAffected, err := s.db.MarkPostUnread(context.Background(), database.MarkPostUnreadParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't mark post as unread: %w", err)
+	}
+	if rowsAffected == 0 {
+		fmt.Printf("Post %s was not marked as read\n", postID)
+		return nil
+	}
+
+	fmt.Printf("Post %s marked as unread\n", postID)
+	return nil
+}
+
+// handlerPrune deletes posts older than the given age from the feeds the current user follows
+func handlerPrune(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	dryRun := fs.Bool("dry-run", false, "list the posts that would be deleted without deleting them")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) < 1 {
+		return fmt.Errorf("usage: %s <age> [--dry-run] (e.g. 72h, 30d, 6m, 1y)", cmd.name)
+	}
+
+	cutoff, err := parseAge(args[0], time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("invalid age: %w", err)
+	}
+
+	ctx := context.Background()
+	tx, err := s.conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("couldn't start transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	deleted, err := s.db.InTx(tx).DeleteOldPostsForUser(ctx, database.DeleteOldPostsForUserParams{
+		UserID: user.ID,
+		Cutoff: cutoff,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't prune posts: %w", err)
+	}
+
+	if *dryRun {
+		for _, post := range deleted {
+			published := "unknown date"
+			if post.PublishedAt.Valid {
+				published = post.PublishedAt.Time.Format("2006-01-02")
+			}
+			fmt.Printf("* %s (%s) %s\n", post.Title, published, post.Url)
+		}
+		fmt.Printf("Would delete %d posts older than %s\n", len(deleted), cutoff.Format(time.RFC3339))
+		return nil
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("couldn't commit prune: %w", err)
+	}
+	fmt.Printf("Deleted %d posts older than %s\n", len(deleted), cutoff.Format(time.RFC3339))
+	return nil
+}
+
+// handlerBookmark allows users to bookmark a post. A post URL that isn't stored yet can
+// be looked for by refreshing the user's feeds from the same site.
+func handlerBookmark(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	byURL := fs.String("url", "", "the post's URL, even if it looks like a post ID")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args)+countTrue(*byURL != "") != 1 {
+		return fmt.Errorf("usage: bookmark <post-id|post-url> | --url <post-url>")
+	}
+
+	ctx := context.Background()
+	var parsedPostID uuid.UUID
+	postID := *byURL
+	if postID == "" {
+		postID = args[0]
+		parsedPostID, err = resolvePostID(ctx, s, postID)
+	} else {
+		parsedPostID, err = postIDByURL(ctx, s, postID)
+	}
+	if errors.Is(err, errPostNotStored) {
+		parsedPostID, err = fetchMissingPost(ctx, s, user, postID)
+	}
+	if err != nil {
+		return err
+	}
+
+	err = s.db.BookmarkPost(ctx, database.BookmarkPostParams{
+		UserID: user.ID,
+		PostID: parsedPostID,
+	})
+	if err != nil {
+		return fmt.Errorf("error bookmarking post: %v", err)
+	}
+
+	fmt.Printf("Post %s bookmarked successfully!\n", postID)
+	return nil
+}
+
+// errPostNotStored is returned for post URLs that no feed has delivered yet
+var errPostNotStored = errors.New("no post with that ID or URL")
+
+// resolvePostID accepts either a post UUID or the URL of a stored post
+func resolvePostID(ctx context.Context, s *state, arg string) (uuid.UUID, error) {
+	if id, err := uuid.Parse(arg); err == nil {
+		return id, nil
+	}
+	return postIDByURL(ctx, s, arg)
+}
+
+// postIDByURL looks a stored post up by its URL, however the link was decorated
+func postIDByURL(ctx context.Context, s *state, postURL string) (uuid.UUID, error) {
+	post, err := s.db.GetPostByURL(ctx, database.GetPostByURLParams{
+		Url:           postURL,
+		NormalizedUrl: normalizedPostURL(postURL),
+	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return uuid.Nil, fmt.Errorf("%w: %s", errPostNotStored, postURL)
+	}
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("couldn't look up post %s: %w", postURL, err)
+	}
+	return post.ID, nil
+}
+
+// fetchMissingPost offers to refresh the followed feeds on the same site as postURL,
+// which has likely been published since they were last fetched, and returns the post
+// if that turns it up
+func fetchMissingPost(ctx context.Context, s *state, user database.User, postURL string) (uuid.UUID, error) {
+	notStored := fmt.Errorf("%w: %s", errPostNotStored, postURL)
+	parsed, err := neturl.Parse(postURL)
+	if err != nil || parsed.Host == "" || !term.IsTerminal(int(os.Stdin.Fd())) {
+		return uuid.Nil, notStored
+	}
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("couldn't get followed feeds: %w", err)
+	}
+	var sameSite []database.GetFeedFollowsForUserRow
+	for _, follow := range follows {
+		if feedURL, err := neturl.Parse(follow.FeedUrl); err == nil && siteHost(feedURL) == siteHost(parsed) {
+			sameSite = append(sameSite, follow)
+		}
+	}
+	if len(sameSite) == 0 {
+		return uuid.Nil, fmt.Errorf("%w, and you follow no feeds on %s", notStored, parsed.Hostname())
+	}
+
+	answer, err := promptLine(fmt.Sprintf("%s isn't stored yet. Fetch your %d feed(s) on %s to look for it? [y/N] ", postURL, len(sameSite), parsed.Hostname()))
+	if err != nil || !strings.EqualFold(answer, "y") {
+		return uuid.Nil, notStored
+	}
+	for _, follow := range sameSite {
+		feed, err := s.db.GetFeedByID(ctx, follow.FeedID)
+		if err != nil {
+			return uuid.Nil, fmt.Errorf("couldn't get feed: %w", err)
+		}
+		counts, err := refreshFeed(ctx, s, feed)
+		if err != nil {
+			fmt.Printf("Couldn't refresh %s: %v\n", feed.Name, err)
+			continue
+		}
+		fmt.Printf("Refreshed %s: %s\n", feed.Name, refreshSummary(counts))
+	}
+	return postIDByURL(ctx, s, postURL)
+}
+
+// handlerBookmarks lists the current user's bookmarked posts, newest bookmark first
+func handlerBookmarks(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	limit := fs.Int("limit", 20, "maximum number of bookmarks to show")
+	offset := fs.Int("offset", 0, "number of bookmarks to skip")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--limit N] [--offset M]: %w", cmd.name, err)
+	}
+
+	bookmarks, err := s.db.GetBookmarksForUser(context.Background(), database.GetBookmarksForUserParams{
+		UserID: user.ID,
+		Limit:  int32(*limit),
+		Offset: int32(*offset),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get bookmarks: %w", err)
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.BookmarksResult{Bookmarks: make([]output.BookmarkJSON, 0, len(bookmarks))}
+		for i, post := range bookmarkedPosts(bookmarks) {
+			entry := output.BookmarkJSON{PostJSON: postJSON(post), FeedName: bookmarks[i].FeedName}
+			if bookmarks[i].BookmarkedAt.Valid {
+				entry.BookmarkedAt = &bookmarks[i].BookmarkedAt.Time
+			}
+			result.Bookmarks = append(result.Bookmarks, entry)
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(bookmarks) == 0 {
+		fmt.Println("No bookmarks yet.")
+		return nil
+	}
+
+	for _, bookmark := range bookmarks {
+		publishedAt := bookmark.CreatedAt
+		if bookmark.PublishedAt.Valid {
+			publishedAt = bookmark.PublishedAt.Time
+		}
+		fmt.Printf("ID: %s\nTitle: %s\nURL: %s\nFeed: %s\nPublished At: %s\n\n", bookmark.ID, bookmark.Title, bookmark.Url, bookmark.FeedName, publishedAt.Format(time.RFC1123))
+	}
+	return nil
+}
+
+// bookmarkedPosts strips the bookmark details so bookmarks can be shown like any other posts
+func bookmarkedPosts(bookmarks []database.GetBookmarksForUserRow) []database.Post {
+	posts := make([]database.Post, 0, len(bookmarks))
+	for _, bookmark := range bookmarks {
+		posts = append(posts, database.Post{
+			ID:          bookmark.ID,
+			CreatedAt:   bookmark.CreatedAt,
+			UpdatedAt:   bookmark.UpdatedAt,
+			Title:       bookmark.Title,
+			Url:         bookmark.Url,
+			Description: bookmark.Description,
+			PublishedAt: bookmark.PublishedAt,
+			FeedID:      bookmark.FeedID,
+		})
+	}
+	return posts
+}
+
+// handlerUnbookmark removes a post from the current user's bookmarks
+func handlerUnbookmark(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	byURL := fs.String("url", "", "the post's URL, even if it looks like a post ID")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args)+countTrue(*byURL != "") != 1 {
+		return fmt.Errorf("usage: %s <post-id|post-url> | --url <post-url>", cmd.name)
+	}
+
+	arg := *byURL
+	var postID uuid.UUID
+	if arg == "" {
+		arg = args[0]
+		postID, err = resolvePostID(context.Background(), s, arg)
+	} else {
+		postID, err = postIDByURL(context.Background(), s, arg)
+	}
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := s.db.DeleteBookmark(context.Background(), database.DeleteBookmarkParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't remove bookmark: %w", err)
+	}
+	if rowsAffected == 0 {
+		fmt.Printf("Post %s was not bookmarked\n", arg)
+		return nil
+	}
+
+	fmt.Printf("Bookmark for post %s removed\n", arg)
+	return nil
+}
+
+// handlerPin pins a post so it stays at hand, optionally with a note on why
+func handlerPin(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	note := fs.String("note", "", "why the post is pinned")
+	args, err := parseCommandFlags(fs, cmd.args)
+	if err != nil || len(args) != 1 {
+		return fmt.Errorf("usage: %s <post-id|post-url> [--note TEXT]", cmd.name)
+	}
+
+	postID, err := resolvePostID(context.Background(), s, args[0])
+	if err != nil {
+		return err
+	}
+
+	err = s.db.PinPost(context.Background(), database.PinPostParams{
+		UserID: user.ID,
+		PostID: postID,
+		Note:   sql.NullString{String: strings.TrimSpace(*note), Valid: strings.TrimSpace(*note) != ""},
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't pin post: %w", err)
+	}
+
+	fmt.Printf("Post %s pinned\n", args[0])
+	return nil
+}
+
+// handlerUnpin removes a post from the current user's pins
+func handlerUnpin(s *state, cmd command, user database.User) error {
+	if len(cmd.args) < 1 {
+		return fmt.Errorf("usage: %s <post-id|post-url>", cmd.name)
+	}
+
+	postID, err := resolvePostID(context.Background(), s, cmd.args[0])
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := s.db.UnpinPost(context.Background(), database.UnpinPostParams{
+		UserID: user.ID,
+		PostID: postID,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't unpin post: %w", err)
+	}
+	if rowsAffected == 0 {
+		fmt.Printf("Post %s was not pinned\n", cmd.args[0])
+		return nil
+	}
+
+	fmt.Printf("Post %s unpinned\n", cmd.args[0])
+	return nil
+}
+
+// handlerPinned lists the current user's pinned posts with their notes, most recently
+// pinned first
+func handlerPinned(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	limit := fs.Int("limit", 20, "maximum number of pinned posts to show")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--limit N]: %w", cmd.name, err)
+	}
+
+	pins, err := s.db.GetPinnedPostsForUser(context.Background(), database.GetPinnedPostsForUserParams{
+		UserID: user.ID,
+		Limit:  int32(*limit),
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get pinned posts: %w", err)
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.PinnedResult{Pinned: make([]output.PinnedJSON, 0, len(pins))}
+		for _, pin := range pins {
+			result.Pinned = append(result.Pinned, output.PinnedJSON{
+				PostJSON: postJSON(database.Post{
+					ID:          pin.ID,
+					CreatedAt:   pin.CreatedAt,
+					UpdatedAt:   pin.UpdatedAt,
+					Title:       pin.Title,
+					Url:         pin.Url,
+					Description: pin.Description,
+					PublishedAt: pin.PublishedAt,
+					FeedID:      pin.FeedID,
+				}),
+				FeedName: pin.FeedName,
+				PinnedAt: pin.PinnedAt,
+				Note:     pin.Note.String,
+			})
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if len(pins) == 0 {
+		fmt.Println("No pinned posts yet.")
+		return nil
+	}
+
+	for _, pin := range pins {
+		fmt.Printf("ID: %s\nTitle: %s\nURL: %s\nFeed: %s\nPinned At: %s\n", pin.ID, pin.Title, pin.Url, pin.FeedName, pin.PinnedAt.Format(time.RFC1123))
+		if pin.Note.Valid {
+			fmt.Printf("Note: %s\n", pin.Note.String)
+		}
+		fmt.Println()
+	}
+	return nil
+}
+
+// Read queue statuses; a post is pending until queue next hands it out
+const (
+	queuePending = "pending"
+	queueReading = "reading"
+	queueDone    = "done"
+)
+
+// handlerQueue manages the read-later queue: posts to read in a chosen order, each
+// pending, being read or done
+func handlerQueue(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s add <post-id> | list | next | done <post-id> | remove <post-id> | reorder <post-id> <position>", cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "add":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		postID, err := resolvePostID(ctx, s, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		added, err := s.db.AddToQueue(ctx, database.AddToQueueParams{UserID: user.ID, PostID: postID})
+		if err != nil {
+			return fmt.Errorf("couldn't queue post: %w", err)
+		}
+		if added == 0 {
+			fmt.Printf("Post %s is already queued\n", cmd.args[1])
+			return nil
+		}
+		fmt.Printf("Queued post %s\n", cmd.args[1])
+		return nil
+
+	case "list":
+		items, err := s.db.GetQueueForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get queue: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.QueueResult{Items: make([]output.QueueItemJSON, 0, len(items))}
+			for _, item := range items {
+				result.Items = append(result.Items, output.QueueItemJSON{
+					Position:    item.Position,
+					Status:      item.Status,
+					PostID:      item.ID,
+					Title:       item.Title,
+					URL:         item.Url,
+					FeedName:    item.FeedName,
+					AddedAt:     item.AddedAt,
+					ReadingMins: readingMinutes(s, item.Description),
+				})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(items) == 0 {
+			fmt.Println("The queue is empty.")
+			return nil
+		}
+		remaining := 0
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "#	STATUS	TITLE	FEED	READ")
+		for _, item := range items {
+			mins := readingMinutes(s, item.Description)
+			if item.Status != queueDone {
+				remaining += mins
+			}
+			fmt.Fprintf(w, "%d	%s	%s	%s	%s\n", item.Position, queueStatusMark(item.Status), item.Title, item.FeedName, formatReadingMinutes(mins))
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		fmt.Printf("\nEst. read for the rest of the queue: %d min\n", remaining)
+		return nil
+
+	case "next":
+		item, err := s.db.GetNextQueueItem(ctx, user.ID)
+		if errors.Is(err, sql.ErrNoRows) {
+			fmt.Println("Nothing pending in the queue.")
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't get the next queued post: %w", err)
+		}
+		if _, err := s.db.UpdateQueueStatus(ctx, database.UpdateQueueStatusParams{UserID: user.ID, PostID: item.ID, Status: queueReading}); err != nil {
+			return fmt.Errorf("couldn't mark post as reading: %w", err)
+		}
+		fmt.Printf("ID: %s\nTitle: %s\nURL: %s\nFeed: %s\n", item.ID, item.Title, item.Url, item.FeedName)
+		if mins := readingMinutes(s, item.Description); mins > 0 {
+			fmt.Printf("Est. read: %d min\n", mins)
+		}
+		return nil
+
+	case "done":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		postID, err := resolvePostID(ctx, s, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		updated, err := s.db.UpdateQueueStatus(ctx, database.UpdateQueueStatusParams{UserID: user.ID, PostID: postID, Status: queueDone})
+		if err != nil {
+			return fmt.Errorf("couldn't mark post as done: %w", err)
+		}
+		if updated == 0 {
+			return fmt.Errorf("post %s isn't queued", cmd.args[1])
+		}
+		fmt.Printf("Marked post %s done\n", cmd.args[1])
+		return nil
+
+	case "remove":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		postID, err := resolvePostID(ctx, s, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		// Remove the post and close the gap it leaves together, so positions stay 1..n
+		tx, err := s.conn.BeginTx(ctx, nil)
+		if err != nil {
+			return fmt.Errorf("couldn't start transaction: %w", err)
+		}
+		defer tx.Rollback()
+		qtx := s.db.InTx(tx)
+
+		position, err := qtx.DeleteQueueItem(ctx, database.DeleteQueueItemParams{UserID: user.ID, PostID: postID})
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("post %s isn't queued", cmd.args[1])
+		}
+		if err != nil {
+			return fmt.Errorf("couldn't remove post from the queue: %w", err)
+		}
+		if err := qtx.CloseQueueGap(ctx, database.CloseQueueGapParams{UserID: user.ID, Position: position}); err != nil {
+			return fmt.Errorf("couldn't renumber the queue: %w", err)
+		}
+		if err := tx.Commit(); err != nil {
+			return fmt.Errorf("couldn't commit queue removal: %w", err)
+		}
+		fmt.Printf("Removed post %s from the queue\n", cmd.args[1])
+		return nil
+
+	case "reorder":
+		if len(cmd.args) != 3 {
+			return usage
+		}
+		postID, err := resolvePostID(ctx, s, cmd.args[1])
+		if err != nil {
+			return err
+		}
+		position, err := strconv.Atoi(cmd.args[2])
+		if err != nil || position < 1 {
+			return fmt.Errorf("position must be a positive number: %s", cmd.args[2])
+		}
+		count, err := s.db.CountQueueForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't count queued posts: %w", err)
+		}
+		position = min(position, int(count))
+
+		moved, err := s.db.ReorderQueueItem(ctx, database.ReorderQueueItemParams{
+			UserID:      user.ID,
+			PostID:      postID,
+			NewPosition: int32(position),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't reorder queue: %w", err)
+		}
+		if moved == 0 {
+			return fmt.Errorf("post %s isn't queued", cmd.args[1])
+		}
+		fmt.Printf("Moved post %s to position %d\n", cmd.args[1], position)
+		return nil
+	}
+	return usage
+}
+
+// queueStatusMark is the indicator queue list shows for a status
+func queueStatusMark(status string) string {
+	switch status {
+	case queueReading:
+		return "[>] reading"
+	case queueDone:
+		return "[x] done"
+	default:
+		return "[ ] pending"
+	}
+}
+
+// formatReadingMinutes renders an estimate for a table cell, "-" when there's none
+func formatReadingMinutes(mins int) string {
+	if mins == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%d min", mins)
+}
+
+// handlerStats prints how many feeds, posts, reads and bookmarks the user has, plus
+// the outcome of the last aggregator run
+func handlerStats(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	period := fs.String("period", "", "only count posts published within this age, e.g. 7d or 1m")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--period <age>]: %w", cmd.name, err)
+	}
+
+	since := time.Time{}
+	if *period != "" {
+		var err error
+		since, err = parseAge(*period, time.Now().UTC())
+		if err != nil {
+			return err
+		}
+	}
+
+	ctx := context.Background()
+	stats, err := s.db.GetUserStats(ctx, database.GetUserStatsParams{UserID: user.ID, Since: since})
+	if err != nil {
+		return fmt.Errorf("couldn't get stats: %w", err)
+	}
+	run, err := s.db.GetLastAggRun(ctx)
+	ranAgg := err == nil
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("couldn't get the last aggregator run: %w", err)
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.StatsResult{
+			User:                user.Name,
+			FeedsFollowed:       stats.FeedsFollowed,
+			Posts:               stats.TotalPosts,
+			Read:                stats.ReadPosts,
+			Bookmarked:          stats.BookmarkedPosts,
+			OldestPost:          statsTimeJSON(stats.OldestPost),
+			NewestPost:          statsTimeJSON(stats.NewestPost),
+			MostActiveFeed:      stats.MostActiveFeed,
+			MostActiveFeedPosts: stats.MostActiveFeedPosts,
+		}
+		if *period != "" {
+			result.Since = &since
+		}
+		if ranAgg {
+			result.LastAggregation = &output.AggRunJSON{
+				FinishedAt:   run.FinishedAt,
+				FeedsFetched: run.FeedsFetched,
+				FeedsFailed:  run.FeedsFailed,
+				PostsSaved:   run.PostsSaved,
+			}
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if *period != "" {
+		fmt.Printf("Stats for %s, posts since %s:\n\n", user.Name, since.Format("2006-01-02"))
+	} else {
+		fmt.Printf("Stats for %s:\n\n", user.Name)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "Feeds followed\t%d\n", stats.FeedsFollowed)
+	fmt.Fprintf(w, "Posts\t%d\n", stats.TotalPosts)
+	fmt.Fprintf(w, "Read\t%d\n", stats.ReadPosts)
+	fmt.Fprintf(w, "Bookmarked\t%d\n", stats.BookmarkedPosts)
+	fmt.Fprintf(w, "Oldest post\t%s\n", statsTime(stats.OldestPost))
+	fmt.Fprintf(w, "Newest post\t%s\n", statsTime(stats.NewestPost))
+	if stats.MostActiveFeed != "" {
+		fmt.Fprintf(w, "Most active feed\t%s (%d posts)\n", stats.MostActiveFeed, stats.MostActiveFeedPosts)
+	}
+	if ranAgg {
+		fmt.Fprintf(w, "Last aggregation\t%s (%d feeds, %d failed, %d new posts)\n",
+			run.FinishedAt.Format("2006-01-02 15:04:05"), run.FeedsFetched, run.FeedsFailed, run.PostsSaved)
+	} else {
+		fmt.Fprintf(w, "Last aggregation\tnever\n")
+	}
+	return w.Flush()
+}
+
+// statsTime formats a timestamp from the stats query, which is NULL when there are no posts
+func statsTime(value interface{}) string {
+	t, ok := value.(time.Time)
+	if !ok {
+		return "-"
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+// statsTimeJSON is statsTime for JSON output: nil when there are no posts
+func statsTimeJSON(value interface{}) *time.Time {
+	t, ok := value.(time.Time)
+	if !ok {
+		return nil
+	}
+	return &t
+}
+
+// readingPeriods maps readstats' --period values to days; 0 means all time
+var readingPeriods = map[string]int{"7d": 7, "30d": 30, "all": 0}
+
+// readingHistoryDays is how many days the reads-per-day chart covers for --period all
+const readingHistoryDays = 30
+
+// handlerReadStats shows the user's reading habits over a recent period, optionally
+// next to the period before it
+func handlerReadStats(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	period := fs.String("period", "7d", "how far back to look: 7d, 30d or all")
+	compare := fs.Bool("compare", false, "show the period before alongside, with the change")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--period 7d|30d|all] [--compare]: %w", cmd.name, err)
+	}
+	days, ok := readingPeriods[*period]
+	if !ok {
+		return fmt.Errorf("invalid period %q: use 7d, 30d or all", *period)
+	}
+	if *compare && days == 0 {
+		return fmt.Errorf("--compare needs --period 7d or 30d")
+	}
+
+	ctx := context.Background()
+	now := time.Now().UTC()
+	params := database.GetReadingStatsParams{UserID: user.ID, Until: now, Days: readingHistoryDays}
+	if days > 0 {
+		params.Since = now.AddDate(0, 0, -days)
+		params.Days = int32(days)
+	}
+	current, err := s.db.GetReadingStats(ctx, params)
+	if err != nil {
+		return fmt.Errorf("couldn't get reading stats: %w", err)
+	}
+	var previous database.GetReadingStatsRow
+	if *compare {
+		params.Since, params.Until = params.Since.AddDate(0, 0, -days), params.Since
+		previous, err = s.db.GetReadingStats(ctx, params)
+		if err != nil {
+			return fmt.Errorf("couldn't get reading stats for the previous period: %w", err)
+		}
+	}
+
+	if s.outputFormat == output.JSON {
+		result := output.ReadStatsResult{User: user.Name, Days: days, Current: readingStatsJSON(s, current)}
+		if *compare {
+			prev := readingStatsJSON(s, previous)
+			result.Previous = &prev
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if days > 0 {
+		fmt.Printf("Reading stats for %s, last %d days:\n\n", user.Name, days)
+	} else {
+		fmt.Printf("Reading stats for %s:\n\n", user.Name)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	if *compare {
+		fmt.Fprintf(w, "\tLast %d days\tPrevious %d days\tChange\n", days, days)
+	}
+	count := func(label string, cur, prev int64) {
+		if *compare {
+			fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", label, cur, prev, percentChange(cur, prev))
+			return
+		}
+		fmt.Fprintf(w, "%s\t%d\n", label, cur)
+	}
+	count("Posts read", current.PostsRead, previous.PostsRead)
+	if *compare {
+		fmt.Fprintf(w, "Reads per day\t%s\t%s\n", spark.Line(current.ReadsPerDay), spark.Line(previous.ReadsPerDay))
+	} else {
+		fmt.Fprintf(w, "Reads per day\t%s\n", spark.Line(current.ReadsPerDay))
+	}
+	if current.MostReadFeed != "" {
+		fmt.Fprintf(w, "Most-read feed\t%s (%d posts)\n", current.MostReadFeed, current.MostReadFeedPosts)
+	}
+	if minutes := avgReadMinutes(s, current.AvgWords); minutes > 0 {
+		fmt.Fprintf(w, "Average read time\t%d min\n", minutes)
+	}
+	count("Bookmarked", current.PostsBookmarked, previous.PostsBookmarked)
+	count("Pinned", current.PostsPinned, previous.PostsPinned)
+	return w.Flush()
+}
+
+// avgReadMinutes turns the average words of the posts read into minutes, 0 when none
+// had a description
+func avgReadMinutes(s *state, avgWords float64) int {
+	if avgWords <= 0 {
+		return 0
+	}
+	return readtime.Minutes(readtime.ForWords(int(math.Round(avgWords)), s.cfg.ReadingWPM))
+}
+
+// readingStatsJSON is one period of readstats for JSON output
+func readingStatsJSON(s *state, stats database.GetReadingStatsRow) output.ReadingStatsJSON {
+	return output.ReadingStatsJSON{
+		PostsRead:         stats.PostsRead,
+		ReadsPerDay:       stats.ReadsPerDay,
+		MostReadFeed:      stats.MostReadFeed,
+		MostReadFeedPosts: stats.MostReadFeedPosts,
+		AvgReadMinutes:    avgReadMinutes(s, stats.AvgWords),
+		Bookmarked:        stats.PostsBookmarked,
+		Pinned:            stats.PostsPinned,
+	}
+}
+
+// percentChange describes how cur compares with prev, e.g. "▲ 12%"
+func percentChange(cur, prev int64) string {
+	switch {
+	case prev == 0 && cur == 0:
+		return "-"
+	case prev == 0:
+		return "new"
+	case cur == prev:
+		return "= 0%"
+	}
+	pct := math.Round(math.Abs(float64(cur-prev)) * 100 / float64(prev))
+	if cur > prev {
+		return fmt.Sprintf("▲ %.0f%%", pct)
+	}
+	return fmt.Sprintf("▼ %.0f%%", pct)
+}
+
+// handlerFrequency ranks the user's followed feeds by how often they published over a
+// recent period, with the weekday and hour they publish most
+func handlerFrequency(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	top := fs.Int("top", 0, "only show this many feeds (0 for all)")
+	period := fs.String("period", "30d", "how far back to count posts, e.g. 7d or 3m")
+	sortBy := fs.String("sort", "frequency", "order: frequency, name or newest (most recent post first)")
+	quiet := fs.Bool("quiet", false, "only print feed names, one per line")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--top N] [--period 30d] [--sort frequency|name|newest] [--quiet]: %w", cmd.name, err)
+	}
+	if *sortBy != "frequency" && *sortBy != "name" && *sortBy != "newest" {
+		return fmt.Errorf("invalid sort %q: use frequency, name or newest", *sortBy)
+	}
+	if *top < 0 {
+		return fmt.Errorf("--top can't be negative")
+	}
+	now := time.Now().UTC()
+	since, err := parseAge(*period, now)
+	if err != nil {
+		return fmt.Errorf("invalid --period: %w", err)
+	}
+	days := now.Sub(since).Hours() / 24
+	if days <= 0 {
+		return fmt.Errorf("--period must be longer than 0")
+	}
+
+	rows, err := s.db.GetFeedFrequencyStats(context.Background(), database.GetFeedFrequencyStatsParams{
+		UserID: user.ID,
+		Since:  since,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't get feed frequency: %w", err)
+	}
+	entries := rankFeedFrequency(rows, *sortBy, *top)
+
+	if s.outputFormat == output.JSON {
+		result := output.FrequencyResult{Since: since, Feeds: make([]output.FrequencyJSON, 0, len(entries))}
+		for _, entry := range entries {
+			feed := output.FrequencyJSON{
+				Rank:         entry.rank,
+				FeedID:       entry.ID,
+				Name:         entry.Name,
+				URL:          entry.Url,
+				Posts:        entry.PostCount,
+				PostsPerWeek: math.Round(float64(entry.PostCount)*7/days*10) / 10,
+				NewestPost:   statsTimeJSON(entry.NewestPost),
+			}
+			if entry.PeakDay.Valid {
+				feed.PeakDay = time.Weekday(entry.PeakDay.Int32).String()
+			}
+			if entry.PeakHour.Valid {
+				feed.PeakHour = &entry.PeakHour.Int32
+			}
+			result.Feeds = append(result.Feeds, feed)
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+
+	if *quiet {
+		for _, entry := range entries {
+			fmt.Println(entry.Name)
+		}
+		return nil
+	}
+	if len(entries) == 0 {
+		fmt.Println("You don't follow any feeds yet.")
+		return nil
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "RANK\tFEED\tPOSTS/WEEK\tPEAK DAY\tPEAK HOUR (UTC)")
+	for _, entry := range entries {
+		peakDay, peakHour := "-", "-"
+		if entry.PeakDay.Valid {
+			peakDay = time.Weekday(entry.PeakDay.Int32).String()[:3]
+		}
+		if entry.PeakHour.Valid {
+			peakHour = fmt.Sprintf("%02d:00", entry.PeakHour.Int32)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%.1f\t%s\t%s\n", entry.rank, entry.Name, float64(entry.PostCount)*7/days, peakDay, peakHour)
+	}
+	return w.Flush()
+}
+
+// frequencyEntry is a feed's row in the frequency table; rank is its place by post
+// count whatever order the table is in
+type frequencyEntry struct {
+	database.GetFeedFrequencyStatsRow
+	rank int
+}
+
+// rankFeedFrequency numbers rows, which come busiest first, then sorts them by sortBy
+// and keeps the first top (all when top is 0)
+func rankFeedFrequency(rows []database.GetFeedFrequencyStatsRow, sortBy string, top int) []frequencyEntry {
+	entries := make([]frequencyEntry, len(rows))
+	for i, row := range rows {
+		entries[i] = frequencyEntry{GetFeedFrequencyStatsRow: row, rank: i + 1}
+	}
+	switch sortBy {
+	case "name":
+		sort.SliceStable(entries, func(i, j int) bool {
+			return strings.ToLower(entries[i].Name) < strings.ToLower(entries[j].Name)
+		})
+	case "newest":
+		// Feeds without posts in the period go last
+		sort.SliceStable(entries, func(i, j int) bool {
+			left, leftOK := entries[i].NewestPost.(time.Time)
+			right, rightOK := entries[j].NewestPost.(time.Time)
+			if leftOK != rightOK {
+				return leftOK
+			}
+			return left.After(right)
+		})
+	}
+	if top > 0 && len(entries) > top {
+		entries = entries[:top]
+	}
+	return entries
+}
+
+// handlerTUI launches the terminal user interface for viewing posts
+func handlerTUI(s *state, cmd command, user database.User) error {
+	fs := newFlagSet(cmd.name)
+	themeName := fs.String("theme", tui.DefaultTheme, "color theme")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--theme %s]: %w", cmd.name, strings.Join(tui.ThemeNames(), "|"), err)
+	}
+	theme, ok := tui.Themes[*themeName]
+	if !ok {
+		return fmt.Errorf("unknown theme %q; available themes: %s", *themeName, strings.Join(tui.ThemeNames(), ", "))
+	}
+
+	ctx := context.Background()
+	follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching followed feeds: %v", err)
+	}
+	healthRows, err := s.db.GetFeedsWithHealth(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching feed health: %v", err)
+	}
+	now := time.Now()
+	health := make(map[uuid.UUID]tui.Health, len(healthRows))
+	for _, row := range healthRows {
+		health[row.ID] = feedHealth(row, now)
+	}
+
+	feeds := make([]tui.Feed, len(follows))
+	feedNames := make(map[uuid.UUID]string, len(follows))
+	for i, follow := range follows {
+		tags, err := s.db.GetTagsForFeed(ctx, follow.FeedID)
+		if err != nil {
+			return fmt.Errorf("error fetching feed tags: %v", err)
+		}
+		feeds[i] = tui.Feed{
+			ID:     follow.FeedID,
+			Name:   follow.FeedName,
+			URL:    follow.FeedUrl,
+			Tags:   tags,
+			Health: health[follow.FeedID],
+		}
+		feedNames[follow.FeedID] = follow.FeedName
+	}
+
+	members, err := s.db.GetFeedGroupMembersForUser(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("error fetching feed groups: %v", err)
+	}
+	followed := make(map[uuid.UUID]tui.Feed, len(feeds))
+	for _, feed := range feeds {
+		followed[feed.ID] = feed
+	}
+	var groups []tui.Group
+	for _, member := range members {
+		feed, ok := followed[member.FeedID]
+		if !ok {
+			continue
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != member.GroupName {
+			groups = append(groups, tui.Group{Name: member.GroupName})
+		}
+		groups[len(groups)-1].Feeds = append(groups[len(groups)-1].Feeds, feed)
+	}
+
+	// loadPage fetches a page of posts for a feed, or across all followed feeds for
+	// uuid.Nil
+	loadPage := func(feedID uuid.UUID, offset, limit int) ([]tui.Post, error) {
+		var posts []database.GetPostsForUserRow
+		var err error
+		if feedID == uuid.Nil {
+			posts, err = s.db.GetPostsForUser(ctx, database.GetPostsForUserParams{
+				UserID: user.ID,
+				Limit:  int32(limit),
+				Offset: int32(offset),
+			})
+		} else {
+			var feedPosts []database.GetPostsForUserFeedRow
+			feedPosts, err = s.db.GetPostsForUserFeed(ctx, database.GetPostsForUserFeedParams{
+				UserID: user.ID,
+				FeedID: feedID,
+				Limit:  int32(limit),
+				Offset: int32(offset),
+			})
+			for _, post := range feedPosts {
+				posts = append(posts, database.GetPostsForUserRow(post))
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		formattedPosts := make([]tui.Post, len(posts))
+		for i, post := range posts {
+			publishedAt := post.CreatedAt
+			if post.PublishedAt.Valid {
+				publishedAt = post.PublishedAt.Time
+			}
+			formattedPosts[i] = tui.Post{
+				ID:              post.ID,
+				Title:           post.Title,
+				URL:             post.Url,
+				Read:            post.IsRead,
+				Bookmarked:      post.IsBookmarked,
+				Pinned:          post.IsPinned,
+				FeedID:          post.FeedID,
+				FeedName:        feedNames[post.FeedID],
+				ReadingTimeMins: readingMinutes(s, post.Description),
+				Description:     sanitize.HTMLToText(post.Description.String),
+				PublishedAt:     publishedAt,
+			}
+		}
+		return formattedPosts, nil
+	}
+	loadPosts := func(feedID uuid.UUID, offset int) ([]tui.Post, error) {
+		return loadPage(feedID, offset, tui.PageSize)
+	}
+
+	initialPosts, err := loadPage(uuid.Nil, 0, tui.InitialPosts)
+	if err != nil {
+		return fmt.Errorf("error fetching posts: %v", err)
+	}
+
+	markRead := func(postID uuid.UUID) error {
+		_, err := s.db.MarkPostRead(ctx, database.MarkPostReadParams{
+			UserID: user.ID,
+			PostID: postID,
+			ReadAt: time.Now().UTC(),
+		})
+		return err
+	}
+
+	setBookmarked := func(postID uuid.UUID, bookmarked bool) error {
+		if bookmarked {
+			return s.db.BookmarkPost(ctx, database.BookmarkPostParams{UserID: user.ID, PostID: postID})
+		}
+		_, err := s.db.DeleteBookmark(ctx, database.DeleteBookmarkParams{UserID: user.ID, PostID: postID})
+		return err
+	}
+
+	markAllRead := func(feedID uuid.UUID) (int, error) {
+		var marked int64
+		var err error
+		if feedID == uuid.Nil {
+			marked, err = s.db.MarkAllPostsReadForUser(ctx, database.MarkAllPostsReadForUserParams{
+				UserID: user.ID,
+				ReadAt: time.Now().UTC(),
+			})
+		} else {
+			marked, err = s.db.MarkAllPostsReadForFeed(ctx, database.MarkAllPostsReadForFeedParams{
+				UserID: user.ID,
+				FeedID: feedID,
+				ReadAt: time.Now().UTC(),
+			})
+		}
+		return int(marked), err
+	}
+
+	tui.StartSplitTUI(feeds, groups, initialPosts, tui.Callbacks{
+		LoadPosts:     loadPosts,
+		MarkRead:      markRead,
+		SetBookmarked: setBookmarked,
+		MarkAllRead:   markAllRead,
+	}, theme)
+	return nil
+}
+
+// assumedAggInterval stands in for the interval of feeds polled on every agg tick when
+// judging their health, since the TUI can't know how often agg runs
+const assumedAggInterval = time.Hour
+
+// feedHealth judges a feed for the TUI: failing when its last fetch failed, fine when it
+// was fetched within twice its interval, stale otherwise
+func feedHealth(row database.GetFeedsWithHealthRow, now time.Time) tui.Health {
+	if row.ConsecutiveErrors > 0 {
+		return tui.HealthFailing
+	}
+	if !row.LastFetchedAt.Valid {
+		return tui.HealthStale
+	}
+	interval := assumedAggInterval
+	switch {
+	case row.IntervalSecs.Valid:
+		interval = time.Duration(row.IntervalSecs.Int32) * time.Second
+	case row.AdaptiveIntervalSecs.Valid:
+		interval = time.Duration(row.AdaptiveIntervalSecs.Int32) * time.Second
+	}
+	if now.Sub(row.LastFetchedAt.Time) > 2*interval {
+		return tui.HealthStale
+	}
+	return tui.HealthOK
+}
+
+// handlerAPI starts the HTTP API server; requests authenticate with keys from apikey create
+func handlerAPI(s *state, cmd command) error {
+	fs := newFlagSet(cmd.name)
+	addr := fs.String("addr", s.cfg.APIListenAddr(), "address to listen on, e.g. 127.0.0.1:8080")
+	aggInterval := fs.Duration("agg", 0, "also aggregate feeds at this interval, so /ws/posts sees new posts as they arrive")
+	genSpec := fs.Bool("gen-spec", false, "write the OpenAPI spec to openapi.yaml and exit")
+	if _, err := parseCommandFlags(fs, cmd.args); err != nil {
+		return fmt.Errorf("usage: %s [--addr host:port] [--agg <duration>] [--gen-spec]: %w", cmd.name, err)
+	}
+	if *aggInterval < 0 {
+		return fmt.Errorf("--agg must not be negative")
+	}
+
+	if *genSpec {
+		spec, err := api.SpecYAML()
+		if err != nil {
+			return fmt.Errorf("couldn't generate OpenAPI spec: %w", err)
+		}
+		if err := os.WriteFile("openapi.yaml", spec, 0644); err != nil {
+			return fmt.Errorf("couldn't write openapi.yaml: %w", err)
+		}
+		fmt.Println("Wrote openapi.yaml")
+		return nil
+	}
+
+	certFile, keyFile := s.cfg.APITLSCert, s.cfg.APITLSKey
+	if (certFile == "") != (keyFile == "") {
+		return fmt.Errorf("api_tls_cert and api_tls_key must be set together")
+	}
+	scheme := "http"
+	if certFile != "" {
+		scheme = "https"
+	}
+
+	// Drain in-flight requests on Ctrl+C or SIGTERM so the database closes cleanly
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	// Posts saved by refreshes and --agg reach WebSocket clients through the bus
+	s.bus = events.NewBus()
+	aggDone := make(chan struct{})
+	if *aggInterval > 0 {
+		go func() {
+			defer close(aggDone)
+			ticker := time.NewTicker(*aggInterval)
+			defer ticker.Stop()
+			for {
+				scrapeAllDueFeeds(ctx, s, 5, false)
+				select {
+				case <-ctx.Done():
+					return
+				case <-ticker.C:
+				}
+			}
+		}()
+		fmt.Printf("Collecting feeds every %s\n", *aggInterval)
+	} else {
+		close(aggDone)
+	}
+
+	fmt.Printf("Starting HTTP API server on %s://%s...\n", scheme, *addr)
+	err := api.Start(ctx, *addr, s.db, api.Options{
+		TLSCert:        certFile,
+		TLSKey:         keyFile,
+		Bus:            s.bus,
+		AllowedOrigins: s.cfg.AllowedOrigins,
+		Refresh: func(ctx context.Context, feedID uuid.UUID) (int64, error) {
+			feed, err := s.db.GetFeedByID(ctx, feedID)
+			if err != nil {
+				return 0, err
+			}
+			client := &http.Client{Timeout: fetchTimeout, Transport: httpTransport}
+			return scrapeFeed(ctx, s, client, feed)
+		},
+	})
+	stop()
+	<-aggDone // let in-flight feed writes finish
+	if err != nil {
+		return fmt.Errorf("API server failed: %w", err)
+	}
+	fmt.Println("API server stopped")
+	return nil
+}
+
+// handlerAPIKey manages the logged-in user's API keys: create, list and revoke
+func handlerAPIKey(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s create | %s list | %s revoke <key-prefix>", cmd.name, cmd.name, cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "create":
+		key, prefix, err := api.GenerateKey()
+		if err != nil {
+			return fmt.Errorf("couldn't generate API key: %w", err)
+		}
+		_, err = s.db.CreateAPIKey(ctx, database.CreateAPIKeyParams{
+			ID:        uuid.New(),
+			UserID:    user.ID,
+			KeyHash:   api.HashKey(key),
+			KeyPrefix: prefix,
+			CreatedAt: time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't save API key: %w", err)
+		}
+		fmt.Printf("Created API key for %s:\n\n  %s\n\n", user.Name, key)
+		fmt.Println("Only a hash is stored, so copy the key now; it can't be shown again.")
+		return nil
+
+	case "list":
+		keys, err := s.db.GetAPIKeysForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get API keys: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.APIKeysResult{Keys: make([]output.APIKeyJSON, 0, len(keys))}
+			for _, key := range keys {
+				entry := output.APIKeyJSON{Prefix: key.KeyPrefix, CreatedAt: key.CreatedAt}
+				if key.LastUsedAt.Valid {
+					entry.LastUsedAt = &key.LastUsedAt.Time
+				}
+				result.Keys = append(result.Keys, entry)
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(keys) == 0 {
+			fmt.Println("No API keys.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "PREFIX\tCREATED\tLAST USED")
+		for _, key := range keys {
+			lastUsed := "never"
+			if key.LastUsedAt.Valid {
+				lastUsed = key.LastUsedAt.Time.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\n", key.KeyPrefix, key.CreatedAt.Format("2006-01-02 15:04:05"), lastUsed)
+		}
+		return w.Flush()
+
+	case "revoke":
+		if len(cmd.args) < 2 {
+			return usage
+		}
+		// Accept the whole key too; only its stored prefix is compared
+		prefix := cmd.args[1]
+		if len(prefix) > api.KeyPrefixLen {
+			prefix = prefix[:api.KeyPrefixLen]
+		}
+		revoked, err := s.db.DeleteAPIKeyByPrefix(ctx, database.DeleteAPIKeyByPrefixParams{UserID: user.ID, KeyPrefix: prefix})
+		if err != nil {
+			return fmt.Errorf("couldn't revoke API key: %w", err)
+		}
+		if revoked == 0 {
+			return fmt.Errorf("no API key with prefix %s", prefix)
+		}
+		fmt.Printf("Revoked API key %s\n", prefix)
+		return nil
+	}
+	return usage
+}
+
+// handlerNotify turns desktop notifications for a followed feed's new posts on or off
+func handlerNotify(s *state, cmd command, user database.User) error {
+	if len(cmd.args) != 2 || (cmd.args[0] != "enable" && cmd.args[0] != "disable") {
+		return fmt.Errorf("usage: %s enable <feed-url> | %s disable <feed-url>", cmd.name, cmd.name)
+	}
+	enable, feedURL := cmd.args[0] == "enable", cmd.args[1]
+
+	ctx := context.Background()
+	feed, err := s.db.GetFeedByURL(ctx, feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", feedURL, err)
+	}
+	if enable {
+		follows, err := s.db.GetFeedFollowsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get feed follows: %w", err)
+		}
+		following := false
+		for _, follow := range follows {
+			if follow.FeedID == feed.ID {
+				following = true
+				break
+			}
+		}
+		if !following {
+			return fmt.Errorf("you don't follow %s; follow it first", feedURL)
+		}
+	}
+
+	err = s.db.SetNotificationsEnabled(ctx, database.SetNotificationsEnabledParams{
+		FeedID:  feed.ID,
+		UserID:  user.ID,
+		Enabled: enable,
+	})
+	if err != nil {
+		return fmt.Errorf("couldn't save notification setting: %w", err)
+	}
+	if enable {
+		fmt.Printf("Notifications enabled for %s; they appear while agg runs on this machine\n", feed.Name)
+	} else {
+		fmt.Printf("Notifications disabled for %s\n", feed.Name)
+	}
+	return nil
+}
+
+// podcastEpisodeLimit is how many episodes podcast list shows for a feed
+const podcastEpisodeLimit = 20
+
+// handlerPodcast lists followed feeds with media enclosures and their episodes, and
+// downloads episodes
+func handlerPodcast(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s list [feed-url] | %s download <post-id> [dir]", cmd.name, cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "list":
+		if len(cmd.args) > 2 {
+			return usage
+		}
+		if len(cmd.args) == 2 {
+			return listEpisodes(ctx, s, cmd.args[1])
+		}
+
+		feeds, err := s.db.GetPodcastFeedsForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get podcast feeds: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.PodcastsResult{Podcasts: make([]output.PodcastJSON, 0, len(feeds))}
+			for _, feed := range feeds {
+				result.Podcasts = append(result.Podcasts, output.PodcastJSON{
+					ID:       feed.ID,
+					Name:     feed.Name,
+					URL:      feed.Url,
+					Episodes: feed.Episodes,
+					Latest:   feed.Latest,
+				})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(feeds) == 0 {
+			fmt.Println("None of your feeds have episodes with media files.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tEPISODES\tLATEST\tURL")
+		for _, feed := range feeds {
+			fmt.Fprintf(w, "%s\t%d\t%s\t%s\n", feed.Name, feed.Episodes, feed.Latest.Format("2006-01-02"), feed.Url)
+		}
+		return w.Flush()
+
+	case "download":
+		if len(cmd.args) < 2 || len(cmd.args) > 3 {
+			return usage
+		}
+		postID, err := uuid.Parse(cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("invalid post ID %s: %w", cmd.args[1], err)
+		}
+		dir := "."
+		if len(cmd.args) == 3 {
+			dir = cmd.args[2]
+		}
+
+		episode, err := s.db.GetEpisode(ctx, postID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("post %s has no media file", postID)
+		} else if err != nil {
+			return fmt.Errorf("couldn't look up episode %s: %w", postID, err)
+		}
+
+		// Ctrl+C stops the download and removes the partial file
+		ctx, stop := signal.NotifyContext(ctx, os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		dest := podcast.EpisodePath(dir, episode.FeedName, episode.Title, episode.Url, episode.MediaType)
+		fmt.Printf("Downloading %s\n", episode.Title)
+		client := &http.Client{Transport: httpTransport}
+		written, err := podcast.Download(ctx, client, episode.Url, dest, os.Stderr)
+		if err != nil {
+			return fmt.Errorf("couldn't download %s: %w", episode.Url, err)
+		}
+		fmt.Printf("Saved %d bytes to %s\n", written, dest)
+		return nil
+	}
+	return usage
+}
+
+// listEpisodes prints the newest episodes of a feed with the post IDs podcast download takes
+func listEpisodes(ctx context.Context, s *state, feedURL string) error {
+	feed, err := s.db.GetFeedByURL(ctx, feedURL)
+	if err != nil {
+		return fmt.Errorf("couldn't find feed with URL %s: %w", feedURL, err)
+	}
+	episodes, err := s.db.GetEpisodesForFeed(ctx, database.GetEpisodesForFeedParams{FeedID: feed.ID, Limit: podcastEpisodeLimit})
+	if err != nil {
+		return fmt.Errorf("couldn't get episodes: %w", err)
+	}
+	if s.outputFormat == output.JSON {
+		result := output.EpisodesResult{Feed: feed.Name, Episodes: make([]output.EpisodeJSON, 0, len(episodes))}
+		for _, episode := range episodes {
+			entry := output.EpisodeJSON{
+				PostID:    episode.ID,
+				Title:     episode.Title,
+				MediaURL:  episode.Url,
+				MediaType: episode.MediaType,
+				Length:    episode.Length.Int64,
+			}
+			if episode.PublishedAt.Valid {
+				entry.PublishedAt = &episode.PublishedAt.Time
+			}
+			result.Episodes = append(result.Episodes, entry)
+		}
+		return output.WriteJSON(os.Stdout, result)
+	}
+	if len(episodes) == 0 {
+		fmt.Printf("%s has no episodes with media files.\n", feed.Name)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "POST ID\tPUBLISHED\tTYPE\tTITLE")
+	for _, episode := range episodes {
+		published := "-"
+		if episode.PublishedAt.Valid {
+			published = episode.PublishedAt.Time.Format("2006-01-02")
+		}
+		mediaType := episode.MediaType
+		if mediaType == "" {
+			mediaType = "-"
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", episode.ID, published, mediaType, episode.Title)
+	}
+	return w.Flush()
+}
+
+// handlerLanguage shows, sets or clears the language browse keeps posts to. Posts whose
+// language couldn't be detected are always shown.
+func handlerLanguage(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s [set <lang> | clear]", cmd.name)
+	ctx := context.Background()
+	if len(cmd.args) == 0 {
+		if !user.PreferredLanguage.Valid {
+			fmt.Println("No preferred language; browse shows posts in every language.")
+			return nil
+		}
+		fmt.Printf("Preferred language: %s\n", user.PreferredLanguage.String)
+		return nil
+	}
+
+	switch cmd.args[0] {
+	case "set":
+		if len(cmd.args) != 2 {
+			return usage
+		}
+		lang := strings.ToLower(strings.TrimSpace(cmd.args[1]))
+		if !slices.Contains(langdetect.Languages(), lang) {
+			return fmt.Errorf("unknown language %q; detected languages are %s", cmd.args[1], strings.Join(langdetect.Languages(), ", "))
+		}
+		err := s.db.SetUserPreferredLanguage(ctx, database.SetUserPreferredLanguageParams{
+			ID:                user.ID,
+			PreferredLanguage: sql.NullString{String: lang, Valid: true},
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't set preferred language: %w", err)
+		}
+		fmt.Printf("Browse now shows posts in %s and posts whose language is unknown\n", lang)
+		return nil
+
+	case "clear":
+		if len(cmd.args) != 1 {
+			return usage
+		}
+		err := s.db.SetUserPreferredLanguage(ctx, database.SetUserPreferredLanguageParams{ID: user.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't clear preferred language: %w", err)
+		}
+		fmt.Println("Cleared preferred language")
+		return nil
+	}
+	return usage
+}
+
+// handlerWebhook manages the logged-in user's webhooks, which are POSTed each new post
+// from their feeds: add, list and delete
+func handlerWebhook(s *state, cmd command, user database.User) error {
+	usage := fmt.Errorf("usage: %s add <url> [--feed <feed-url>] [--keyword <keyword>] | %s list | %s delete <id>", cmd.name, cmd.name, cmd.name)
+	if len(cmd.args) < 1 {
+		return usage
+	}
+
+	ctx := context.Background()
+	switch cmd.args[0] {
+	case "add":
+		fs := newFlagSet(cmd.name + " add")
+		feedURL := fs.String("feed", "", "only call the webhook for posts from this feed")
+		keyword := fs.String("keyword", "", "only call the webhook for posts mentioning this keyword")
+		args, err := parseCommandFlags(fs, cmd.args[1:])
+		if err != nil {
+			return fmt.Errorf("%w: %v", usage, err)
+		}
+		if len(args) != 1 {
+			return usage
+		}
+		if parsed, err := neturl.Parse(args[0]); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			return fmt.Errorf("webhook URL must be an absolute http or https URL: %s", args[0])
+		}
+
+		var feedID uuid.NullUUID
+		if *feedURL != "" {
+			feed, err := s.db.GetFeedByURL(ctx, *feedURL)
+			if err != nil {
+				return fmt.Errorf("couldn't find feed with URL %s: %w", *feedURL, err)
+			}
+			feedID = uuid.NullUUID{UUID: feed.ID, Valid: true}
+		}
+		kw := strings.TrimSpace(*keyword)
+
+		secret, err := webhook.GenerateSecret()
+		if err != nil {
+			return fmt.Errorf("couldn't generate webhook secret: %w", err)
+		}
+		hook, err := s.db.CreateWebhook(ctx, database.CreateWebhookParams{
+			ID:            uuid.New(),
+			UserID:        user.ID,
+			Url:           args[0],
+			Secret:        secret,
+			FeedFilterID:  feedID,
+			KeywordFilter: sql.NullString{String: kw, Valid: kw != ""},
+			CreatedAt:     time.Now().UTC(),
+		})
+		if err != nil {
+			return fmt.Errorf("couldn't save webhook: %w", err)
+		}
+		fmt.Printf("Added webhook %s for %s\n", hook.ID, hook.Url)
+		fmt.Printf("Deliveries are signed with this secret in the %s header:\n\n  %s\n", webhook.SignatureHeader, secret)
+		return nil
+
+	case "list":
+		hooks, err := s.db.GetWebhooksForUser(ctx, user.ID)
+		if err != nil {
+			return fmt.Errorf("couldn't get webhooks: %w", err)
+		}
+		if s.outputFormat == output.JSON {
+			result := output.WebhooksResult{Webhooks: make([]output.WebhookJSON, 0, len(hooks))}
+			for _, hook := range hooks {
+				result.Webhooks = append(result.Webhooks, output.WebhookJSON{
+					ID:        hook.ID,
+					URL:       hook.Url,
+					FeedURL:   hook.FeedUrl.String,
+					Keyword:   hook.KeywordFilter.String,
+					CreatedAt: hook.CreatedAt,
+				})
+			}
+			return output.WriteJSON(os.Stdout, result)
+		}
+		if len(hooks) == 0 {
+			fmt.Println("No webhooks.")
+			return nil
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tURL\tFEED\tKEYWORD")
+		for _, hook := range hooks {
+			feed, keyword := "any", "any"
+			if hook.FeedUrl.Valid {
+				feed = hook.FeedUrl.String
+			}
+			if hook.KeywordFilter.Valid {
+				keyword = hook.KeywordFilter.String
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", hook.ID, hook.Url, feed, keyword)
+		}
+		return w.Flush()
+
+	case "delete":
+		if len(cmd.args) < 2 {
+			return usage
+		}
+		id, err := uuid.Parse(cmd.args[1])
+		if err != nil {
+			return fmt.Errorf("invalid webhook ID %s: %w", cmd.args[1], err)
+		}
+		deleted, err := s.db.DeleteWebhook(ctx, database.DeleteWebhookParams{ID: id, UserID: user.ID})
+		if err != nil {
+			return fmt.Errorf("couldn't delete webhook: %w", err)
+		}
+		if deleted == 0 {
+			return fmt.Errorf("no webhook with ID %s", id)
+		}
+		fmt.Printf("Deleted webhook %s\n", id)
+		return nil
+	}
+	return usage
+}
+
+// scrapeRun tallies one pass over the due feeds
+type scrapeRun struct {
+	feeds  int
+	failed int
+	saved  int64
+}
+
+// scrapeAllDueFeeds fetches every feed whose next scheduled fetch has passed,
+// spreading the work across a pool of workers. With adaptive set, feeds without their
+// own interval are due according to how often they publish, relearned after each fetch.
+func scrapeAllDueFeeds(ctx context.Context, s *state, workers int, adaptive bool) (scrapeRun, error) {
+	start := time.Now()
+	feeds, err := s.db.GetFeedsDueForFetch(ctx, adaptive)
+	if err != nil {
+		s.logger.Error("couldn't get due feeds", "error", err)
+		return scrapeRun{}, fmt.Errorf("couldn't get due feeds: %w", err)
+	}
+
+	var saved atomic.Int64
+	failed := 0
+	if len(feeds) == 0 {
+		fmt.Println("No feeds are due for fetching")
+	} else {
+		errs := scrapeFeedsConcurrently(ctx, feeds, workers, fetchTimeout, func(ctx context.Context, client *http.Client, feed database.Feed) error {
+			s.metrics.FetchStarted()
+			fetchStart := time.Now()
+			n, err := scrapeFeed(ctx, s, client, feed)
+			s.metrics.FetchFinished(err == nil, time.Since(fetchStart), n)
+			saved.Add(n)
+			if err != nil {
+				s.logger.Error("feed scrape failed",
+					"feed_url", feed.Url,
+					"feed_id", feed.ID,
+					"error", err,
+				)
+				return err
+			}
+			if adaptive {
+				if err := s.db.UpdateAdaptiveInterval(ctx, feed.ID); err != nil {
+					s.logger.Warn("couldn't update adaptive interval",
+						"feed_url", feed.Url,
+						"feed_id", feed.ID,
+						"error", err,
+					)
+				}
+			}
+			return nil
+		})
+		failed = len(errs)
+		s.logger.Info("scrape finished",
+			"feeds", len(feeds),
+			"failed", failed,
+			"new_posts_saved", saved.Load(),
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+	}
+
+	recordAggRun(context.WithoutCancel(ctx), s, start, len(feeds), failed, saved.Load())
+
+	if s.metrics != nil {
+		total, err := s.db.CountFeeds(context.WithoutCancel(ctx))
+		if err != nil {
+			s.logger.Warn("couldn't count feeds for metrics", "error", err)
+		}
+		s.metrics.ScrapeFinished(time.Now(), total)
+	}
+	return scrapeRun{feeds: len(feeds), failed: failed, saved: saved.Load()}, nil
+}
+
+// aggRunRetention is how long agg_runs rows are kept for stats
+const aggRunRetention = 30 * 24 * time.Hour
+
+// recordAggRun logs one aggregator tick for stats and drops runs past the retention period
+func recordAggRun(ctx context.Context, s *state, start time.Time, feeds, failed int, saved int64) {
+	err := s.db.RecordAggRun(ctx, database.RecordAggRunParams{
+		ID:           uuid.New(),
+		StartedAt:    start.UTC(),
+		FinishedAt:   time.Now().UTC(),
+		FeedsFetched: int32(feeds),
+		FeedsFailed:  int32(failed),
+		PostsSaved:   int32(saved),
+	})
+	if err != nil {
+		s.logger.Warn("couldn't record aggregator run", "error", err)
+		return
+	}
+	if err := s.db.DeleteAggRunsBefore(ctx, start.UTC().Add(-aggRunRetention)); err != nil {
+		s.logger.Warn("couldn't prune aggregator runs", "error", err)
+	}
+}
+
+// scrapeFeedsConcurrently runs scrape for every feed on a fixed number of workers.
+// Each worker owns an HTTP client with the given timeout so a hung server only
+// ties up one worker. No new feeds are started once ctx is cancelled. All errors
+// are collected and returned once every started feed is done.
+func scrapeFeedsConcurrently(ctx context.Context, feeds []database.Feed, workers int, timeout time.Duration, scrape func(context.Context, *http.Client, database.Feed) error) []error {
+	jobs := make(chan database.Feed)
+	errCh := make(chan error, len(feeds))
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			client := &http.Client{Timeout: timeout, Transport: httpTransport}
+			for feed := range jobs {
+				if err := scrape(ctx, client, feed); err != nil {
+					errCh <- fmt.Errorf("%s: %w", feed.Url, err)
+				}
+			}
+		}()
+	}
+
+dispatch:
+	for _, feed := range feeds {
+		select {
+		case jobs <- feed:
+		case <-ctx.Done():
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	close(errCh)
+
+	var errs []error
+	for err := range errCh {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// followPermanentRedirect stores the URL a feed has permanently moved to, so later
+// fetches skip the redirect. Failing to, e.g. because another feed already has that
+// URL, only costs the redirect, so it's logged rather than returned.
+func followPermanentRedirect(ctx context.Context, s *state, feed database.Feed, result FetchResult) {
+	if !result.WasRedirected || result.FinalURL == feed.Url {
+		return
+	}
+	if err := s.db.UpdateFeedURL(ctx, database.UpdateFeedURLParams{ID: feed.ID, Url: result.FinalURL}); err != nil {
+		s.logger.Warn("couldn't follow permanent redirect",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"new_url", result.FinalURL,
+			"error", err,
+		)
+		return
+	}
+	fmt.Printf("Feed moved: %s → %s\n", feed.Url, result.FinalURL)
+}
+
+// feedClaimLease is how long a claim on a feed keeps other agg runs from fetching it:
+// long enough for every attempt fetchFeedWithRetry makes with the given timeout, after
+// which a claim left by a run that died is given up
+func feedClaimLease(timeout time.Duration) time.Duration {
+	lease := timeout * time.Duration(len(fetchBackoff)+1)
+	for _, wait := range fetchBackoff {
+		lease += wait
+	}
+	return lease
+}
+
+// feedClient returns client with the feed's own fetch timeout, when it has one
+func feedClient(client *http.Client, feed database.Feed) *http.Client {
+	if !feed.FetchTimeoutSecs.Valid {
+		return client
+	}
+	withTimeout := *client
+	withTimeout.Timeout = time.Duration(feed.FetchTimeoutSecs.Int32) * time.Second
+	return &withTimeout
+}
+
+// claimFeed claims a feed before its request is sent, so overlapping runs don't both
+// fetch it. It returns false when another run holds the claim.
+func claimFeed(ctx context.Context, s *state, feed database.Feed, client *http.Client) (bool, error) {
+	claimed, err := s.db.ClaimFeedForFetch(ctx, database.ClaimFeedForFetchParams{
+		ID:            feed.ID,
+		ClaimedBefore: time.Now().UTC().Add(-feedClaimLease(client.Timeout)),
+	})
+	if err != nil {
+		return false, fmt.Errorf("couldn't claim feed: %w", err)
+	}
+	return claimed > 0, nil
+}
+
+// scrapeFeed claims a feed, fetches it, saves its posts, records the outcome on the
+// feed and returns how many new posts were saved. A feed another agg run has claimed
+// is skipped. Transient failures leave the feed due so a later tick tries it again
+// once the claim lapses; permanent ones mark it fetched until its next interval.
+func scrapeFeed(ctx context.Context, s *state, client *http.Client, feed database.Feed) (int64, error) {
+	client = feedClient(client, feed)
+	claimed, err := claimFeed(ctx, s, feed, client)
+	if err != nil {
+		return 0, err
+	}
+	if !claimed {
+		s.logger.Debug("feed already being fetched",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+		)
+		return 0, nil
+	}
+
+	fmt.Printf("Fetching feed: %s (%s)\n", feed.Name, feed.Url)
+	start := time.Now()
+	rssFeed, result, err := fetchFeedWithRetry(ctx, s.logger, client, feed.Url, feedCache{
+		ETag:         feed.LastEtag.String,
+		LastModified: feed.LastModified.String,
+	}, feed.UserAgent.String)
+	if errors.Is(err, ErrNotModified) {
+		fmt.Printf("Feed not modified: %s\n", feed.Name)
+		if err := s.db.MarkFeedFetchedOK(ctx, feed.ID); err != nil {
+			return 0, fmt.Errorf("couldn't mark feed as fetched: %w", err)
+		}
+		followPermanentRedirect(ctx, s, feed, result)
+		s.logger.Debug("feed not modified",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
+		return 0, nil
+	}
+	if err != nil {
+		// Shutting down isn't the feed's fault, so don't count it against it
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		var recordErr error
+		if isTransientFetchError(err) {
+			recordErr = s.db.RecordFeedFetchError(ctx, database.RecordFeedFetchErrorParams{ID: feed.ID, LastError: err.Error()})
+		} else {
+			recordErr = s.db.MarkFeedFetchedError(ctx, database.MarkFeedFetchedErrorParams{ID: feed.ID, LastError: err.Error()})
+		}
+		if recordErr != nil {
+			s.logger.Error("couldn't record fetch error",
+				"feed_url", feed.Url,
+				"feed_id", feed.ID,
+				"error", recordErr,
+			)
+		}
+		return 0, fmt.Errorf("couldn't fetch feed: %w", err)
+	}
+
+	if err := s.db.MarkFeedFetchedOK(ctx, feed.ID); err != nil {
+		return 0, fmt.Errorf("couldn't mark feed as fetched: %w", err)
+	}
+	followPermanentRedirect(ctx, s, feed, result)
+	followChannelTitle(ctx, s, feed, rssFeed.Channel.Title)
+
+	counts, err := processFeedItems(ctx, s, feed, rssFeed)
+	if err != nil {
+		return counts.saved, err
+	}
+
+	// Remember the validators only once the items are stored
+	saveFeedCacheHeaders(ctx, s, feed, result.Cache)
+
+	s.logger.Info("feed fetched",
+		"feed_url", feed.Url,
+		"feed_id", feed.ID,
+		"duration_ms", time.Since(start).Milliseconds(),
+		"items_fetched", len(rssFeed.Channel.Item),
+		"new_posts_saved", counts.saved,
+		"posts_filtered", counts.filtered,
+	)
+	return counts.saved, nil
+}
+
+// followChannelTitle renames a feed after its channel title when auto_refresh_feed_meta
+// is set. Like following a redirect, failing only costs the update, so it's logged.
+func followChannelTitle(ctx context.Context, s *state, feed database.Feed, title string) {
+	if !s.cfg.AutoRefreshFeedMeta {
+		return
+	}
+	name, ok := renamedFeedTitle(feed.Name, title)
+	if !ok {
+		return
+	}
+	if err := s.db.UpdateFeedName(ctx, database.UpdateFeedNameParams{ID: feed.ID, Name: name}); err != nil {
+		s.logger.Warn("couldn't rename feed after its channel title",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+		return
+	}
+	s.logger.Info("feed renamed after its channel title",
+		"feed_url", feed.Url,
+		"feed_id", feed.ID,
+		"old_name", feed.Name,
+		"new_name", name,
+	)
+}
+
+// renamedFeedTitle returns the trimmed channel title and whether a feed now called name
+// should take it; an empty title never replaces a name
+func renamedFeedTitle(name, title string) (string, bool) {
+	title = strings.TrimSpace(title)
+	return title, title != "" && title != name
+}
+
+// saveFeedCacheHeaders stores the validators to send on the feed's next fetch
+func saveFeedCacheHeaders(ctx context.Context, s *state, feed database.Feed, cache feedCache) {
+	err := s.db.SetFeedCacheHeaders(ctx, database.SetFeedCacheHeadersParams{
+		ID:           feed.ID,
+		LastEtag:     sql.NullString{String: cache.ETag, Valid: cache.ETag != ""},
+		LastModified: sql.NullString{String: cache.LastModified, Valid: cache.LastModified != ""},
+	})
+	if err != nil {
+		s.logger.Warn("couldn't save cache headers",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+	}
+}
+
+// postInsertTimeout bounds how long an insert started before shutdown may take to finish
+const postInsertTimeout = 5 * time.Second
+
+// feedItemCounts tallies what happened to a fetched feed's items
+type feedItemCounts struct {
+	saved      int64
+	duplicates int64 // already stored under the same normalized URL
+	filtered   int64 // skipped by every follower's keyword filters
+}
+
+// processFeedItems saves a fetched feed's items as posts and fans each new one out to
+// subscribers, webhooks and notifications. It stops early if ctx is cancelled.
+func processFeedItems(ctx context.Context, s *state, feed database.Feed, rssFeed *RSSFeed) (feedItemCounts, error) {
+	var counts feedItemCounts
+	followers, filters, err := feedKeywordFilters(ctx, s, feed.ID)
+	if err != nil {
+		return counts, err
+	}
+	savePodcastDetails(ctx, s, feed, rssFeed.Channel.ITunesChannel)
+	// Tag rules are best effort too; the feed's owner decides how its posts are tagged
+	tagRules, err := s.db.GetTagRulesForUser(ctx, feed.UserID)
+	if err != nil {
+		s.logger.Warn("couldn't get tag rules",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+	}
+	// Webhooks are best effort, so failing to load them doesn't stop the scrape
+	hooks, err := s.db.GetWebhooksForFeed(ctx, feed.ID)
+	if err != nil {
+		s.logger.Warn("couldn't get webhooks",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+	}
+
+	var resolved map[string]string
+	if s.resolvePostURLs {
+		resolved = resolveNewPostURLs(ctx, s, feed, rssFeed.Channel.Item)
 	}
 
-	fmt.Printf("Successfully unfollowed feed: %s\n", feed.Name)
-	return nil
-}
+	var lastTitle string
+	for _, item := range rssFeed.Channel.Item {
+		// Don't start new inserts during shutdown
+		if ctx.Err() != nil {
+			return counts, ctx.Err()
+		}
 
-// handlerBrowse supports pagination, sorting, and optional feed filtering
-func handlerBrowse(s *state, cmd command, user database.User) error {
-	limit := 2
-	offset := 0
-	sortBy := "published_at"
-	order := "desc"
-	feedFilter := ""
+		// Descriptions are stored sanitized so nothing downstream renders feed scripts
+		cleaned := sanitize.SanitizeHTML(item.content())
+		description := sql.NullString{String: cleaned, Valid: cleaned != ""}
+		pubTime, ok := parsePublished(item.PubDate, item.DCDate)
+		publishedAt := sql.NullTime{}
+		if ok {
+			publishedAt = sql.NullTime{Time: pubTime, Valid: true}
+		}
 
-	if len(cmd.args) > 0 {
-		parsedLimit, err := strconv.Atoi(cmd.args[0])
-		if err != nil {
-			return fmt.Errorf("invalid limit: %v", err)
+		if !wantedByFollowers(followers, filters, item.Title, item.content()) {
+			counts.filtered++
+			continue
 		}
-		limit = parsedLimit
-	}
-	if len(cmd.args) > 1 {
-		parsedOffset, err := strconv.Atoi(cmd.args[1])
+		language := langdetect.Detect(item.Title + " " + sanitize.HTMLToText(cleaned))
+		link := strings.TrimSpace(item.Link)
+		var redirectFrom sql.NullString
+		if final, ok := resolved[link]; ok {
+			redirectFrom = sql.NullString{String: link, Valid: true}
+			link = final
+		}
+
+		postParams := database.CreatePostParams{
+			ID:            uuid.New(),
+			CreatedAt:     time.Now().UTC(),
+			UpdatedAt:     time.Now().UTC(),
+			Title:         strings.TrimSpace(item.Title),
+			Url:           link,
+			Description:   description,
+			PublishedAt:   publishedAt,
+			FeedID:        feed.ID,
+			NormalizedUrl: normalizedPostURL(link),
+			Author:        sql.NullString{String: item.author(), Valid: item.author() != ""},
+			Language:      sql.NullString{String: language, Valid: language != ""},
+			RedirectFrom:  redirectFrom,
+		}
+
+		// An insert that has started finishes, with its enclosure and tags, even if
+		// ctx is cancelled meanwhile; the timeout keeps shutdown from hanging on it
+		insertCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), postInsertTimeout)
+
+		// Duplicate URLs are skipped by ON CONFLICT on the normalized URL, which also
+		// holds across workers
+		inserted, err := s.db.CreatePost(insertCtx, postParams)
 		if err != nil {
-			return fmt.Errorf("invalid offset: %v", err)
+			cancel()
+			s.logger.Error("couldn't save post",
+				"feed_url", feed.Url,
+				"feed_id", feed.ID,
+				"post_url", postParams.Url,
+				"error", err,
+			)
+			continue
 		}
-		offset = parsedOffset
+		if inserted == 0 {
+			cancel()
+			counts.duplicates++
+			continue
+		}
+		counts.saved++
+		s.bus.Publish(events.PostEvent{
+			PostID:     postParams.ID,
+			Title:      postParams.Title,
+			FeedName:   feed.Name,
+			URL:        postParams.Url,
+			Recipients: followers,
+		})
+		saveEnclosure(insertCtx, s, item, postParams)
+		if _, err := tagPost(insertCtx, s, tagRules, postParams.ID, postParams.Title, cleaned); err != nil {
+			s.logger.Warn("couldn't apply tag rules",
+				"feed_url", feed.Url,
+				"feed_id", feed.ID,
+				"post_url", postParams.Url,
+				"error", err,
+			)
+		}
+		cancel()
+		deliverWebhooks(s, hooks, feed, postParams)
+		lastTitle = postParams.Title
 	}
-	if len(cmd.args) > 2 {
-		sortBy = strings.ToLower(cmd.args[2])
+	if counts.saved > 0 {
+		notifyNewPosts(ctx, s, feed, counts.saved, lastTitle)
 	}
-	if len(cmd.args) > 3 {
-		order = strings.ToLower(cmd.args[3])
-		if order != "asc" && order != "desc" {
-			return fmt.Errorf("invalid order: must be asc or desc")
+	return counts, nil
+}
+
+const (
+	// postURLResolveTimeout bounds each HEAD request made to resolve a post link
+	postURLResolveTimeout = 5 * time.Second
+	// postURLResolveWorkers is how many links of one feed are resolved at once
+	postURLResolveWorkers = 4
+)
+
+// resolveNewPostURLs maps the links of items that aren't stored yet to where they
+// redirect. Links already resolved on an earlier fetch map to the URL stored then, so
+// each link costs one HEAD request at most; links that don't redirect, or whose
+// request fails, are left out.
+func resolveNewPostURLs(ctx context.Context, s *state, feed database.Feed, items []RSSItem) map[string]string {
+	links := make([]string, 0, len(items))
+	normalized := make([]string, 0, len(items))
+	for _, item := range items {
+		if link := strings.TrimSpace(item.Link); link != "" {
+			links = append(links, link)
+			normalized = append(normalized, normalizedPostURL(link))
 		}
 	}
-	if len(cmd.args) > 4 {
-		feedFilter = cmd.args[4]
-	}
 
-	posts, err := s.db.GetPostsForUserPaginated(context.Background(), database.GetPostsForUserPaginatedParams{
-		UserID: user.ID,
-		Limit:  int32(limit),
-		Offset: int32(offset),
-	})
+	resolved := make(map[string]string, len(links))
+	redirects, err := s.db.GetPostRedirects(ctx, links)
 	if err != nil {
-		return fmt.Errorf("error fetching posts: %v", err)
+		s.logger.Warn("couldn't look up resolved post URLs",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+		return nil
+	}
+	for _, redirect := range redirects {
+		resolved[redirect.RedirectFrom.String] = redirect.Url
+	}
+	stored, err := s.db.GetStoredNormalizedURLs(ctx, normalized)
+	if err != nil {
+		s.logger.Warn("couldn't look up stored post URLs",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+		return resolved
+	}
+	known := make(map[string]bool, len(stored))
+	for _, url := range stored {
+		known[url] = true
 	}
 
-	if feedFilter != "" {
-		filtered := make([]database.Post, 0, len(posts))
-		for _, post := range posts {
-			if post.FeedID.String() == feedFilter {
-				filtered = append(filtered, post)
-			}
+	var pending []string
+	for i, link := range links {
+		if _, ok := resolved[link]; !ok && !known[normalized[i]] {
+			pending = append(pending, link)
 		}
-		posts = filtered
 	}
+	client := &http.Client{Transport: httpTransport, Timeout: postURLResolveTimeout}
+	for link, final := range resolvePostURLs(ctx, client, pending, feed.UserAgent.String) {
+		resolved[link] = final
+	}
+	return resolved
+}
 
-	switch sortBy {
-	case "title":
-		sort.SliceStable(posts, func(i, j int) bool {
-			return strings.ToLower(posts[i].Title) < strings.ToLower(posts[j].Title)
-		})
-	case "published_at", "published":
-		sort.SliceStable(posts, func(i, j int) bool {
-			left := posts[i].PublishedAt.Time
-			if !posts[i].PublishedAt.Valid {
-				left = posts[i].CreatedAt
-			}
-			right := posts[j].PublishedAt.Time
-			if !posts[j].PublishedAt.Valid {
-				right = posts[j].CreatedAt
+// resolvePostURLs follows the redirects of each link with a HEAD request, a few at a
+// time, and returns the links that ended up somewhere else
+func resolvePostURLs(ctx context.Context, client *http.Client, links []string, userAgent string) map[string]string {
+	jobs := make(chan string)
+	resolved := make(map[string]string)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	for range min(postURLResolveWorkers, len(links)) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for link := range jobs {
+				final, err := resolvePostURL(ctx, client, link, userAgent)
+				if err != nil || final == link {
+					continue
+				}
+				mu.Lock()
+				resolved[link] = final
+				mu.Unlock()
 			}
-			return left.Before(right)
-		})
-	default:
-		return fmt.Errorf("unsupported sort column: %s", sortBy)
+		}()
 	}
 
-	if order == "desc" {
-		for i, j := 0, len(posts)-1; i < j; i, j = i+1, j-1 {
-			posts[i], posts[j] = posts[j], posts[i]
+dispatch:
+	for _, link := range links {
+		select {
+		case jobs <- link:
+		case <-ctx.Done():
+			break dispatch
 		}
 	}
+	close(jobs)
+	wg.Wait()
+	return resolved
+}
 
-	for _, post := range posts {
-		publishedAt := post.CreatedAt
-		if post.PublishedAt.Valid {
-			publishedAt = post.PublishedAt.Time
-		}
-		description := ""
-		if post.Description.Valid {
-			description = post.Description.String
-		}
-		fmt.Printf("Title: %s\nURL: %s\nPublished At: %s\nDescription: %s\nFeed ID: %s\n\n",
-			post.Title,
-			post.Url,
-			publishedAt.Format(time.RFC1123),
-			description,
-			post.FeedID,
-		)
+// resolvePostURL sends a HEAD request for link and returns the URL it was finally
+// answered from, after any redirects
+func resolvePostURL(ctx context.Context, client *http.Client, link, userAgent string) (string, error) {
+	req, err := newFeedRequest(ctx, http.MethodHead, link, userAgent)
+	if err != nil {
+		return "", err
 	}
-
-	return nil
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return "", fmt.Errorf("HEAD %s: %s", link, resp.Status)
+	}
+	return resp.Request.URL.String(), nil
 }
 
-// handlerSearch allows users to perform fuzzy searches on posts
-func handlerSearch(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("usage: search <query>")
+// deliverWebhooks POSTs a new post to each webhook whose keyword it matches. Deliveries
+// run in the background so a slow receiver can't hold up scraping; failures are logged.
+func deliverWebhooks(s *state, hooks []database.Webhook, feed database.Feed, post database.CreatePostParams) {
+	payload := webhook.Payload{
+		Event: webhook.EventNewPost,
+		Post: webhook.Post{
+			ID:          post.ID,
+			Title:       post.Title,
+			URL:         post.Url,
+			Description: post.Description.String,
+			FeedID:      feed.ID,
+			FeedName:    feed.Name,
+			FeedURL:     feed.Url,
+		},
 	}
-
-	query := cmd.args[0]
-	posts, err := s.db.SearchPosts(context.Background(), database.SearchPostsParams{
-		UserID: user.ID,
-		Title:  fmt.Sprintf("%%%s%%", query),
-	})
-	if err != nil {
-		return fmt.Errorf("error searching posts: %v", err)
+	if post.PublishedAt.Valid {
+		payload.Post.PublishedAt = &post.PublishedAt.Time
 	}
 
-	for _, post := range posts {
-		publishedAt := post.CreatedAt
-		if post.PublishedAt.Valid {
-			publishedAt = post.PublishedAt.Time
+	client := &http.Client{Transport: httpTransport}
+	for _, hook := range hooks {
+		if hook.KeywordFilter.Valid && !webhookKeywordMatches(hook.KeywordFilter.String, post.Title, post.Description.String) {
+			continue
 		}
-		fmt.Printf("Title: %s\nURL: %s\nPublished At: %s\n\n", post.Title, post.Url, publishedAt.Format(time.RFC1123))
+		s.webhooks.Add(1)
+		go func() {
+			defer s.webhooks.Done()
+			if err := webhook.Deliver(context.Background(), client, hook.Url, hook.Secret, payload); err != nil {
+				s.logger.Warn("webhook delivery failed",
+					"webhook_id", hook.ID,
+					"webhook_url", hook.Url,
+					"post_url", post.Url,
+					"error", err,
+				)
+			}
+		}()
 	}
-
-	return nil
 }
 
-// handlerBookmark allows users to bookmark a post
-func handlerBookmark(s *state, cmd command, user database.User) error {
-	if len(cmd.args) < 1 {
-		return fmt.Errorf("usage: bookmark <post-id>")
+// saveEnclosure records the media file attached to a new post, if there is one
+func saveEnclosure(ctx context.Context, s *state, item RSSItem, post database.CreatePostParams) {
+	url, mediaType, length, ok := item.enclosure()
+	if !ok {
+		return
 	}
-
-	postID := cmd.args[0]
-	parsedPostID, err := uuid.Parse(postID)
-	if err != nil {
-		return fmt.Errorf("invalid post ID: %w", err)
+	params := database.CreatePostEnclosureParams{
+		PostID:        post.ID,
+		Url:           url,
+		MediaType:     mediaType,
+		Length:        length,
+		EpisodeNumber: nullInt32(item.Episode),
+		SeasonNumber:  nullInt32(item.Season),
 	}
-
-	err = s.db.BookmarkPost(context.Background(), database.BookmarkPostParams{
-		UserID: user.ID,
-		PostID: parsedPostID,
-	})
+	if duration, ok := feedxml.ParseDuration(item.Duration); ok {
+		params.DurationSecs = sql.NullInt32{Int32: int32(duration.Seconds()), Valid: true}
+	}
+	err := s.db.CreatePostEnclosure(ctx, params)
 	if err != nil {
-		return fmt.Errorf("error bookmarking post: %v", err)
+		s.logger.Error("couldn't save enclosure",
+			"post_url", post.Url,
+			"enclosure_url", url,
+			"error", err,
+		)
 	}
+}
 
-	fmt.Printf("Post %s bookmarked successfully!\n", postID)
-	return nil
+// nullInt32 parses a whole number such as an itunes:episode, NULL when it isn't one
+func nullInt32(raw string) sql.NullInt32 {
+	n, err := strconv.ParseInt(strings.TrimSpace(raw), 10, 32)
+	return sql.NullInt32{Int32: int32(n), Valid: err == nil}
 }
 
-// handlerTUI launches the terminal user interface for viewing posts
-func handlerTUI(s *state, cmd command, user database.User) error {
-	posts, err := s.db.GetPostsForUser(context.Background(), database.GetPostsForUserParams{
-		UserID: user.ID,
-		Limit:  100, // Fetch up to 100 posts for the TUI
+// savePodcastDetails stores a channel's iTunes show details, if it has any
+func savePodcastDetails(ctx context.Context, s *state, feed database.Feed, channel feedxml.ITunesChannel) {
+	if channel == (feedxml.ITunesChannel{}) {
+		return
+	}
+	explicit := strings.ToLower(strings.TrimSpace(channel.Explicit))
+	err := s.db.UpsertPodcastFeed(ctx, database.UpsertPodcastFeedParams{
+		FeedID:    feed.ID,
+		Author:    strings.TrimSpace(channel.Author),
+		ImageUrl:  strings.TrimSpace(channel.Image.Href),
+		Category:  strings.TrimSpace(channel.Category.Text),
+		Explicit:  explicit == "yes" || explicit == "true" || explicit == "explicit",
+		UpdatedAt: time.Now().UTC(),
 	})
 	if err != nil {
-		return fmt.Errorf("error fetching posts: %v", err)
-	}
-
-	formattedPosts := make([]tui.Post, len(posts))
-	for i, post := range posts {
-		formattedPosts[i] = tui.Post{
-			Title: post.Title,
-			URL:   post.Url,
-		}
+		s.logger.Warn("couldn't save podcast details",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
 	}
-
-	tui.StartTUI(formattedPosts)
-	return nil
-}
-
-// handlerAPI starts the HTTP API server
-func handlerAPI(s *state, cmd command) error {
-	fmt.Println("Starting HTTP API server on port 8080...")
-	api.StartAPI()
-	return nil
 }
 
-// scrapeFeeds fetches the next feed, marks it as fetched, and prints post titles
-func scrapeFeeds(s *state) {
-	feed, err := s.db.GetNextFeedToFetch(context.Background())
+// notifyNewPosts shows a desktop notification for a feed's new posts if a follower
+// enabled them. One notification covers the whole fetch, so a feed's first fetch
+// doesn't bring up dozens.
+func notifyNewPosts(ctx context.Context, s *state, feed database.Feed, saved int64, title string) {
+	enabled, err := s.db.NotificationsEnabledForFeed(ctx, feed.ID)
 	if err != nil {
-		log.Printf("error fetching next feed: %v", err)
+		s.logger.Warn("couldn't check notification settings",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
 		return
 	}
-
-	if err := s.db.MarkFeedFetched(context.Background(), feed.ID); err != nil {
-		log.Printf("error marking feed as fetched: %v", err)
+	if !enabled {
 		return
 	}
 
-	fmt.Printf("Fetching feed: %s (%s)\n", feed.Name, feed.Url)
-	rssFeed, err := fetchFeed(context.Background(), feed.Url)
-	if err != nil {
-		log.Printf("error fetching feed URL %s: %v", feed.Url, err)
-		return
+	body := title
+	if saved > 1 {
+		body = fmt.Sprintf("%d new posts, including %s", saved, title)
 	}
+	if err := notify.SendNotification(feed.Name, body); err != nil {
+		s.logger.Warn("couldn't send notification",
+			"feed_url", feed.Url,
+			"feed_id", feed.ID,
+			"error", err,
+		)
+	}
+}
 
-	for _, item := range rssFeed.Channel.Item {
-		description := sql.NullString{String: strings.TrimSpace(item.Description), Valid: strings.TrimSpace(item.Description) != ""}
-		pubTime, ok := parsePublished(item.PubDate)
-		publishedAt := sql.NullTime{}
-		if ok {
-			publishedAt = sql.NullTime{Time: pubTime, Valid: true}
-		}
+// webhookKeywordMatches reports whether keyword appears, case-insensitively, in the title or description
+func webhookKeywordMatches(keyword, title, description string) bool {
+	keyword = strings.ToLower(keyword)
+	return strings.Contains(strings.ToLower(title), keyword) || strings.Contains(strings.ToLower(description), keyword)
+}
 
-		postParams := database.CreatePostParams{
-			ID:          uuid.New(),
-			CreatedAt:   time.Now().UTC(),
-			UpdatedAt:   time.Now().UTC(),
-			Title:       strings.TrimSpace(item.Title),
-			Url:         strings.TrimSpace(item.Link),
-			Description: description,
-			PublishedAt: publishedAt,
-			FeedID:      feed.ID,
-		}
+// feedKeywordFilters loads the feed's followers and their keyword filters, grouped by user
+func feedKeywordFilters(ctx context.Context, s *state, feedID uuid.UUID) ([]uuid.UUID, map[uuid.UUID][]database.KeywordFilter, error) {
+	followerRows, err := s.db.GetFeedFollowersForFeed(ctx, feedID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't get feed followers: %w", err)
+	}
+	rows, err := s.db.GetKeywordFiltersForFeed(ctx, feedID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("couldn't get keyword filters: %w", err)
+	}
 
-		if err := s.db.CreatePost(context.Background(), postParams); err != nil {
-			log.Printf("error saving post %s: %v", item.Link, err)
-		}
+	followers := make([]uuid.UUID, 0, len(followerRows))
+	for _, follower := range followerRows {
+		followers = append(followers, follower.ID)
 	}
+	filters := make(map[uuid.UUID][]database.KeywordFilter)
+	for _, filter := range rows {
+		filters[filter.UserID] = append(filters[filter.UserID], filter)
+	}
+	return followers, filters, nil
 }
 
 var publishedLayouts = []string{
@@ -636,25 +7781,187 @@ var publishedLayouts = []string{
 	time.RFC3339,
 	time.RubyDate,
 	"Mon, 02 Jan 2006 15:04:05 -0700",
+	// W3C-DTF forms seen in dc:date
+	"2006-01-02T15:04Z07:00",
+	time.DateOnly,
+	// Near misses of RFC 822 and friends that turn up in the wild
+	"2 Jan 2006 15:04:05 MST",
+	"2 Jan 2006 15:04 -0700",
+	time.UnixDate,
+	"January 2, 2006",
+	"2 Jan 2006",
+}
+
+// zoneOffsets maps the zone abbreviations feeds commonly use to their UTC offsets.
+// time.Parse only knows the offsets of UTC and the local zone and reads any other
+// abbreviation as UTC+0. CST, IST and the like are ambiguous; RFC 822's US meaning wins.
+var zoneOffsets = map[string]int{
+	"EST":  -5 * 3600,
+	"EDT":  -4 * 3600,
+	"CST":  -6 * 3600,
+	"CDT":  -5 * 3600,
+	"MST":  -7 * 3600,
+	"MDT":  -6 * 3600,
+	"PST":  -8 * 3600,
+	"PDT":  -7 * 3600,
+	"AKST": -9 * 3600,
+	"AKDT": -8 * 3600,
+	"HST":  -10 * 3600,
+	"WEST": 1 * 3600,
+	"BST":  1 * 3600,
+	"CET":  1 * 3600,
+	"CEST": 2 * 3600,
+	"EET":  2 * 3600,
+	"EEST": 3 * 3600,
+	"MSK":  3 * 3600,
+	"AWST": 8 * 3600,
+	"JST":  9 * 3600,
+	"KST":  9 * 3600,
+	"ACST": 9*3600 + 1800,
+	"ACDT": 10*3600 + 1800,
+	"AEST": 10 * 3600,
+	"AEDT": 11 * 3600,
+	"NZST": 12 * 3600,
+	"NZDT": 13 * 3600,
 }
 
-func parsePublished(raw string) (time.Time, bool) {
+// parsePublished parses a post's date. Fallbacks, such as dc:date, are tried in
+// order when raw is absent.
+func parsePublished(raw string, fallbacks ...string) (time.Time, bool) {
 	trimmed := strings.TrimSpace(raw)
+	for _, fallback := range fallbacks {
+		if trimmed != "" {
+			break
+		}
+		trimmed = strings.TrimSpace(fallback)
+	}
 	if trimmed == "" {
 		return time.Time{}, false
 	}
+	if parsed, ok := parseLayouts(trimmed); ok {
+		return parsed, true
+	}
+	return parseDateFuzzy(trimmed)
+}
+
+// parseLayouts tries each of publishedLayouts in turn
+func parseLayouts(raw string) (time.Time, bool) {
 	for _, layout := range publishedLayouts {
-		if parsed, err := time.Parse(layout, trimmed); err == nil {
+		parsed, err := time.Parse(layout, raw)
+		if err != nil {
+			continue
+		}
+		// An abbreviation time.Parse didn't know came back as UTC+0; parse again in a
+		// location that gives it its real offset
+		if zone, offset := parsed.Zone(); offset == 0 {
+			if known, ok := zoneOffsets[zone]; ok && known != 0 {
+				if located, err := time.ParseInLocation(layout, raw, time.FixedZone(zone, known)); err == nil {
+					return located, true
+				}
+			}
+		}
+		return parsed, true
+	}
+	return time.Time{}, false
+}
+
+var (
+	// clockWithoutSeconds matches an hh:mm time that isn't followed by seconds
+	clockWithoutSeconds = regexp.MustCompile(`(?:^|[^:\d])(\d{1,2}:\d{2})(?:[^:\d]|$)`)
+	// monthName matches the spellings of months that publishedLayouts don't expect
+	monthName = regexp.MustCompile(`(?i)\b(jan|feb|mar|apr|may|jun|jul|aug|sep|sept|oct|nov|dec)[a-z]*\.?`)
+	// hyphenatedDate matches dates such as 15-Feb-2024
+	hyphenatedDate = regexp.MustCompile(`\b(\d{1,2})-([A-Za-z]+)-(\d{4})\b`)
+)
+
+// parseDateFuzzy is the last resort for dates no layout accepts. It repairs common
+// slips one after another, retrying the layouts after each: missing seconds, month
+// names in odd case or spelled out, and hyphens between day, month and year.
+func parseDateFuzzy(raw string) (time.Time, bool) {
+	repairs := []func(string) string{
+		func(s string) string {
+			// Only the first match: a later hh:mm is the zone offset
+			loc := clockWithoutSeconds.FindStringSubmatchIndex(s)
+			if loc == nil {
+				return s
+			}
+			return s[:loc[3]] + ":00" + s[loc[3]:]
+		},
+		func(s string) string {
+			return monthName.ReplaceAllStringFunc(s, func(month string) string {
+				return strings.ToUpper(month[:1]) + strings.ToLower(month[1:3])
+			})
+		},
+		func(s string) string {
+			return hyphenatedDate.ReplaceAllString(s, "$1 $2 $3")
+		},
+	}
+	repaired := raw
+	for _, repair := range repairs {
+		next := repair(repaired)
+		if next == repaired {
+			continue
+		}
+		repaired = next
+		if parsed, ok := parseLayouts(repaired); ok {
 			return parsed, true
 		}
 	}
 	return time.Time{}, false
 }
 
+// parseAge returns the time that lies the given age before now. On top of
+// time.ParseDuration it accepts whole days (d), months (m) and years (y);
+// note that this makes "m" mean months rather than minutes.
+func parseAge(raw string, now time.Time) (time.Time, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) > 1 {
+		if n, err := strconv.Atoi(raw[:len(raw)-1]); err == nil {
+			if n < 0 {
+				return time.Time{}, fmt.Errorf("age must not be negative: %s", raw)
+			}
+			switch raw[len(raw)-1] {
+			case 'd':
+				return now.AddDate(0, 0, -n), nil
+			case 'm':
+				return now.AddDate(0, -n, 0), nil
+			case 'y':
+				return now.AddDate(-n, 0, 0), nil
+			}
+		}
+	}
+
+	age, err := time.ParseDuration(raw)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if age < 0 {
+		return time.Time{}, fmt.Errorf("age must not be negative: %s", raw)
+	}
+	return now.Add(-age), nil
+}
+
+// parseTimeBound reads one end of a time window: an age such as 7d or 36h counts back
+// from now, and a YYYY-MM-DD date means the start of that local day, or with endOfDay
+// the start of the next one so the whole day is included
+func parseTimeBound(raw string, now time.Time, endOfDay bool) (time.Time, error) {
+	if t, err := parseAge(raw, now); err == nil {
+		return t, nil
+	}
+	day, err := time.ParseInLocation("2006-01-02", strings.TrimSpace(raw), now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("%q is neither an age like 7d nor a date like 2024-01-31", raw)
+	}
+	if endOfDay {
+		return day.AddDate(0, 0, 1), nil
+	}
+	return day, nil
+}
+
 // handlerAggService keeps the agg command running and restarts it on failure
 func handlerAggService(s *state, cmd command) error {
 	if len(cmd.args) < 1 {
-		return fmt.Errorf("usage: aggservice <time_between_reqs>")
+		return fmt.Errorf("usage: aggservice <time_between_reqs> [agg flags]")
 	}
 
 	timeArg := cmd.args[0]
@@ -668,9 +7975,10 @@ func handlerAggService(s *state, cmd command) error {
 	signal.Notify(sigs, os.Interrupt, syscall.SIGTERM)
 	defer signal.Stop(sigs)
 
-	log.Printf("Starting agg service manager with interval %s", timeArg)
+	s.logger.Info("starting agg service manager", "interval", timeArg)
 
-	remainingArgs := []string{"agg", timeArg}
+	// Flags such as --workers and --log-json are passed through to agg
+	remainingArgs := append([]string{"agg"}, cmd.args...)
 
 	for {
 		cmdCtx, cancel := context.WithCancel(context.Background())
@@ -686,54 +7994,135 @@ func handlerAggService(s *state, cmd command) error {
 
 		select {
 		case sig := <-sigs:
-			log.Printf("Received signal %s, shutting down agg service", sig)
-			cancel()
+			s.logger.Info("shutting down agg service", "signal", sig.String())
 			if aggCmd.Process != nil {
 				_ = aggCmd.Process.Signal(sig)
 			}
+			// agg shuts down gracefully on the signal; only kill it if it hangs
+			select {
+			case <-errCh:
+			case <-time.After(30 * time.Second):
+				s.logger.Warn("agg command did not exit in time, killing it")
+			}
+			cancel()
 			return nil
 		case runErr := <-errCh:
 			cancel()
 			if runErr != nil {
-				log.Printf("agg command exited with error: %v", runErr)
+				s.logger.Error("agg command exited with error", "error", runErr)
 			} else {
-				log.Printf("agg command exited cleanly")
+				s.logger.Info("agg command exited cleanly")
 			}
 		}
 
 		select {
 		case sig := <-sigs:
-			log.Printf("Received signal %s during restart window, exiting", sig)
+			s.logger.Info("signal received during restart window, exiting", "signal", sig.String())
 			return nil
 		case <-time.After(restartDelay):
-			log.Printf("Restarting agg command after %s", restartDelay)
+			s.logger.Info("restarting agg command", "delay_ms", restartDelay.Milliseconds())
 		}
 	}
 }
 
+// formatCommands lists the commands that can print each non-text output format
+var formatCommands = map[string]map[string]bool{
+	output.JSON: {
+		"version": true, "users": true, "validate": true, "feeds": true, "findfeed": true,
+		"feederrors": true, "deadfeeds": true, "check": true, "tagged": true, "group": true,
+		"filter": true, "autotag": true, "webhook": true, "podcast": true, "following": true,
+		"browse": true, "search": true, "bookmarks": true, "pinned": true, "queue": true,
+		"stats": true, "readstats": true, "frequency": true, "apikey": true, "export": true,
+	},
+	output.CSV: {"browse": true, "search": true, "export": true},
+}
+
+// supportsFormat reports whether cmdName can print in the given output format
+func supportsFormat(format, cmdName string) bool {
+	if format == output.Text {
+		return true
+	}
+	return formatCommands[format][cmdName]
+}
+
 func main() {
-	// Read the config file
-	cfg, err := config.Read()
+	// Global flags come before the command name
+	globalFlags := flag.NewFlagSet(os.Args[0], flag.ContinueOnError)
+	format := globalFlags.String("format", output.Text, "output format: text, json or csv (not every command supports json or csv)")
+	if err := globalFlags.Parse(os.Args[1:]); err != nil {
+		os.Exit(1)
+	}
+	outputFormat, err := output.ParseFormat(*format)
 	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Get command-line arguments
+	args := globalFlags.Args()
+	if len(args) < 1 {
+		fmt.Fprintf(os.Stderr, "Usage: %s [--format text|json|csv] <command> [args...]\n", os.Args[0])
+		os.Exit(1)
+	}
+
+	// Parse command name and arguments
+	cmdName := args[0]
+	cmdArgs := args[1:]
+
+	if !supportsFormat(outputFormat, cmdName) {
+		fmt.Fprintf(os.Stderr, "Error: --format %s is not supported by %s\n", outputFormat, cmdName)
+		os.Exit(1)
+	}
+
+	// Read the config file; validate reports a broken one itself
+	cfg, err := config.Read()
+	if err != nil && cmdName != "validate" {
 		fmt.Fprintf(os.Stderr, "Error reading config: %v\n", err)
 		os.Exit(1)
 	}
 
-	// Open database connection
-	db, err := sql.Open("postgres", cfg.DbURL)
+	transport, err := proxy.NewTransport(cfg.ProxyURL, cfg.ProxyType, cfg.NoProxy)
+	if err != nil && cmdName != "validate" {
+		fmt.Fprintf(os.Stderr, "Error configuring proxy: %v\n", err)
+		os.Exit(1)
+	}
+	if transport != nil {
+		httpTransport = transport
+		discover.Client.Transport = transport
+	}
+	fetchTimeout = cfg.FetchTimeout()
+	defaultUserAgent = cfg.UserAgent()
+
+	// Open the database the config names; validate reports an unusable driver itself
+	dbBackend, err := lookupBackend(cfg.Driver())
+	if err != nil && cmdName == "validate" {
+		dbBackend, err = backends[config.DriverPostgres], nil
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	db, err := dbBackend.open(cfg.DbURL)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error opening database: %v\n", err)
 		os.Exit(1)
 	}
 	defer db.Close()
-
-	// Create database queries instance
-	dbQueries := database.New(db)
+	pool := cfg.DBPool()
+	db.SetMaxOpenConns(pool.MaxOpenConns)
+	db.SetMaxIdleConns(pool.MaxIdleConns)
+	db.SetConnMaxLifetime(pool.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(pool.ConnMaxIdleTime)
 
 	// Create state with config and database
 	programState := &state{
-		db:  dbQueries,
-		cfg: &cfg,
+		db:      dbBackend.queries(db),
+		conn:    db,
+		backend: dbBackend,
+		cfg:     &cfg,
+		logger:  slog.New(slog.NewTextHandler(os.Stderr, nil)),
+
+		outputFormat: outputFormat,
 	}
 
 	// Create commands struct with initialized map
@@ -742,37 +8131,80 @@ func main() {
 	}
 
 	// Register command handlers
+	cmds.register("version", handlerVersion)
 	cmds.register("register", handlerRegister)
 	cmds.register("login", handlerLogin)
 	cmds.register("reset", handlerReset)
+	cmds.register("migrate", handlerMigrate)
 	cmds.register("users", handlerUsers)
+	cmds.register("whoami", handlerWhoami)
+	cmds.register("logout", handlerLogout)
+	cmds.register("setproxy", handlerSetProxy)
+	cmds.register("configshow", handlerConfigShow)
+	cmds.register("configedit", handlerConfigEdit)
+	cmds.register("dbconnect", handlerDBConnect)
+	cmds.register("validate", handlerValidate)
+	cmds.register("dbstats", handlerDBStats)
+	cmds.register("dbswitch", handlerDBSwitch)
+	cmds.register("profile", handlerProfile)
+	cmds.register("deleteuser", middlewareLoggedIn(handlerDeleteUser))
 	cmds.register("agg", handlerAgg)
+	cmds.register("aggonce", handlerAggOnce)
+	cmds.register("refresh", handlerRefresh)
+	cmds.register("refreshmeta", middlewareLoggedIn(handlerRefreshMeta))
 	cmds.register("addfeed", middlewareLoggedIn(handlerAddfeed))
+	cmds.register("importopml", middlewareLoggedIn(handlerImportOPML))
+	cmds.register("importurls", middlewareLoggedIn(handlerImportURLs))
+	cmds.register("exportopml", middlewareLoggedIn(handlerExportOPML))
+	cmds.register("export", middlewareLoggedIn(handlerExport))
 	cmds.register("feeds", handlerFeeds)
+	cmds.register("findfeed", middlewareLoggedIn(handlerFindFeed))
+	cmds.register("feederrors", middlewareLoggedIn(handlerFeedErrors))
+	cmds.register("upgradefeeds", handlerUpgradeFeeds)
+	cmds.register("deadfeeds", middlewareLoggedIn(handlerDeadFeeds))
+	cmds.register("setinterval", middlewareLoggedIn(handlerSetInterval))
+	cmds.register("settimeout", middlewareLoggedIn(handlerSetTimeout))
+	cmds.register("setuseragent", middlewareLoggedIn(handlerSetUserAgent))
+	cmds.register("check", middlewareLoggedIn(handlerCheck))
+	cmds.register("tag", middlewareLoggedIn(handlerTag))
+	cmds.register("untag", middlewareLoggedIn(handlerUntag))
+	cmds.register("tagged", handlerTagged)
+	cmds.register("group", middlewareLoggedIn(handlerGroup))
+	cmds.register("filter", middlewareLoggedIn(handlerFilter))
+	cmds.register("autotag", middlewareLoggedIn(handlerAutoTag))
+	cmds.register("webhook", middlewareLoggedIn(handlerWebhook))
+	cmds.register("notify", middlewareLoggedIn(handlerNotify))
+	cmds.register("podcast", middlewareLoggedIn(handlerPodcast))
 	cmds.register("follow", middlewareLoggedIn(handlerFollow))
+	cmds.register("followall", middlewareLoggedIn(handlerFollowAll))
 	cmds.register("following", middlewareLoggedIn(handlerFollowing))
 	cmds.register("unfollow", middlewareLoggedIn(handlerUnfollow))
+	cmds.register("unfollowall", middlewareLoggedIn(handlerUnfollowAll))
+	cmds.register("updatefeed", middlewareLoggedIn(handlerUpdateFeed))
+	cmds.register("deletefeed", middlewareLoggedIn(handlerDeleteFeed))
 	cmds.register("browse", middlewareLoggedIn(handlerBrowse))
 	cmds.register("search", middlewareLoggedIn(handlerSearch))
+	cmds.register("open", middlewareLoggedIn(handlerOpen))
+	cmds.register("read", middlewareLoggedIn(handlerRead))
+	cmds.register("markread", middlewareLoggedIn(handlerMarkRead))
+	cmds.register("markunread", middlewareLoggedIn(handlerMarkUnread))
+	cmds.register("prune", middlewareLoggedIn(handlerPrune))
 	cmds.register("bookmark", middlewareLoggedIn(handlerBookmark))
+	cmds.register("bookmarks", middlewareLoggedIn(handlerBookmarks))
+	cmds.register("unbookmark", middlewareLoggedIn(handlerUnbookmark))
+	cmds.register("pin", middlewareLoggedIn(handlerPin))
+	cmds.register("unpin", middlewareLoggedIn(handlerUnpin))
+	cmds.register("pinned", middlewareLoggedIn(handlerPinned))
+	cmds.register("queue", middlewareLoggedIn(handlerQueue))
+	cmds.register("stats", middlewareLoggedIn(handlerStats))
+	cmds.register("readstats", middlewareLoggedIn(handlerReadStats))
+	cmds.register("frequency", middlewareLoggedIn(handlerFrequency))
+	cmds.register("language", middlewareLoggedIn(handlerLanguage))
 	cmds.register("tui", middlewareLoggedIn(handlerTUI))
 	cmds.register("api", handlerAPI)
+	cmds.register("apikey", middlewareLoggedIn(handlerAPIKey))
 	cmds.register("aggservice", handlerAggService)
 
-	// Get command-line arguments
-	args := os.Args
-	if len(args) < 2 {
-		fmt.Fprintf(os.Stderr, "Usage: %s <command> [args...]\n", args[0])
-		os.Exit(1)
-	}
-
-	// Parse command name and arguments
-	cmdName := args[1]
-	cmdArgs := []string{}
-	if len(args) > 2 {
-		cmdArgs = args[2:]
-	}
-
 	// Create command instance
 	cmd := command{
 		name: cmdName,
@@ -781,6 +8213,12 @@ func main() {
 
 	// Run the command
 	err = cmds.run(programState, cmd)
+	// Let webhook deliveries the command started finish; each is capped at webhook.Timeout
+	programState.webhooks.Wait()
+	var status exitStatus
+	if errors.As(err, &status) {
+		os.Exit(int(status))
+	}
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 		os.Exit(1)
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gator/internal/database"
+
+	"github.com/google/uuid"
+)
+
+func TestLastBrowsedPostID(t *testing.T) {
+	saved := lastBrowseFile
+	lastBrowseFile = filepath.Join(t.TempDir(), "last_browse.json")
+	defer func() { lastBrowseFile = saved }()
+
+	if _, err := lastBrowsedPostID("alice", 1); err == nil {
+		t.Fatal("expected an error before any browse")
+	}
+
+	posts := []database.Post{{ID: uuid.New()}, {ID: uuid.New()}}
+	if err := saveLastBrowse("alice", posts); err != nil {
+		t.Fatalf("unexpected error saving: %v", err)
+	}
+
+	id, err := lastBrowsedPostID("alice", 2)
+	if err != nil || id != posts[1].ID {
+		t.Fatalf("expected second post %s, got %s (%v)", posts[1].ID, id, err)
+	}
+	if _, err := lastBrowsedPostID("alice", 3); err == nil {
+		t.Fatal("expected an out of range error")
+	}
+	if _, err := lastBrowsedPostID("bob", 1); err == nil {
+		t.Fatal("expected another user's browse results to be ignored")
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseAge(t *testing.T) {
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, time.UTC)
+	cases := map[string]time.Time{
+		"30d": time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC),
+		"1m":  time.Date(2024, 3, 2, 12, 0, 0, 0, time.UTC), // Feb 31 normalizes like time.AddDate
+		"6m":  time.Date(2023, 10, 1, 12, 0, 0, 0, time.UTC),
+		"1y":  time.Date(2023, 3, 31, 12, 0, 0, 0, time.UTC),
+		"36h": time.Date(2024, 3, 30, 0, 0, 0, 0, time.UTC),
+		"90s": time.Date(2024, 3, 31, 11, 58, 30, 0, time.UTC),
+	}
+	for raw, want := range cases {
+		got, err := parseAge(raw, now)
+		if err != nil {
+			t.Fatalf("parseAge(%q) returned error: %v", raw, err)
+		}
+		if !got.Equal(want) {
+			t.Fatalf("parseAge(%q) = %v, want %v", raw, got, want)
+		}
+	}
+
+	for _, raw := range []string{"", "d", "-3d", "-1h", "soon"} {
+		if _, err := parseAge(raw, now); err == nil {
+			t.Fatalf("expected parseAge(%q) to fail", raw)
+		}
+	}
+}
+
+func TestParseTimeBound(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	now := time.Date(2024, 3, 31, 12, 0, 0, 0, loc)
+	cases := []struct {
+		raw      string
+		endOfDay bool
+		want     time.Time
+	}{
+		{"7d", false, time.Date(2024, 3, 24, 12, 0, 0, 0, loc)},
+		{"24h", true, time.Date(2024, 3, 30, 12, 0, 0, 0, loc)},
+		{"2024-01-01", false, time.Date(2024, 1, 1, 0, 0, 0, 0, loc)},
+		{"2024-01-31", true, time.Date(2024, 2, 1, 0, 0, 0, 0, loc)},
+	}
+	for _, c := range cases {
+		got, err := parseTimeBound(c.raw, now, c.endOfDay)
+		if err != nil {
+			t.Fatalf("parseTimeBound(%q) returned error: %v", c.raw, err)
+		}
+		if !got.Equal(c.want) {
+			t.Fatalf("parseTimeBound(%q, %v) = %v, want %v", c.raw, c.endOfDay, got, c.want)
+		}
+	}
+
+	for _, raw := range []string{"", "yesterday", "2024-13-01", "-7d"} {
+		if _, err := parseTimeBound(raw, now, false); err == nil {
+			t.Fatalf("expected parseTimeBound(%q) to fail", raw)
+		}
+	}
+}
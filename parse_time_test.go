@@ -26,3 +26,74 @@ func TestParsePublished(t *testing.T) {
         t.Fatalf("expected raw to parse")
     }
 }
+
+func TestParsePublishedFormats(t *testing.T) {
+    cases := []struct {
+        raw  string
+        want time.Time
+    }{
+        {"02 Jan 2006 15:04:05 GMT", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+        {"2 Jan 2006 15:04 -0700", time.Date(2006, 1, 2, 22, 4, 0, 0, time.UTC)},
+        {"January 2, 2006", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+        {"2006-01-02", time.Date(2006, 1, 2, 0, 0, 0, 0, time.UTC)},
+        {"Mon Jan 2 15:04:05 UTC 2006", time.Date(2006, 1, 2, 15, 4, 5, 0, time.UTC)},
+        // Zone abbreviations time.Parse doesn't know on its own
+        {"Mon, 02 Jan 2006 15:04:05 EDT", time.Date(2006, 1, 2, 19, 4, 5, 0, time.UTC)},
+        {"Mon Jan 2 15:04:05 PST 2006", time.Date(2006, 1, 2, 23, 4, 5, 0, time.UTC)},
+        {"2 Jan 2006 15:04:05 CEST", time.Date(2006, 1, 2, 13, 4, 5, 0, time.UTC)},
+    }
+    for _, c := range cases {
+        got, ok := parsePublished(c.raw)
+        if !ok {
+            t.Errorf("parsePublished(%q) failed", c.raw)
+            continue
+        }
+        if !got.Equal(c.want) {
+            t.Errorf("parsePublished(%q) = %v, want %v", c.raw, got.UTC(), c.want)
+        }
+    }
+}
+
+func TestParseDateFuzzy(t *testing.T) {
+    cases := []struct {
+        raw  string
+        want time.Time
+    }{
+        {"Thu, 15 Feb 2024 14:30 +0000", time.Date(2024, 2, 15, 14, 30, 0, 0, time.UTC)},
+        {"2024-02-15T14:30Z", time.Date(2024, 2, 15, 14, 30, 0, 0, time.UTC)},
+        {"2024-02-15T14:30+05:30", time.Date(2024, 2, 15, 9, 0, 0, 0, time.UTC)},
+        {"15-Feb-2024", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+        {"15-february-2024", time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)},
+        {"Thu, 15 Sept 2024 14:30:00 GMT", time.Date(2024, 9, 15, 14, 30, 0, 0, time.UTC)},
+        {"15 February 2024 14:30 EST", time.Date(2024, 2, 15, 19, 30, 0, 0, time.UTC)},
+    }
+    for _, c := range cases {
+        got, ok := parsePublished(c.raw)
+        if !ok {
+            t.Errorf("parsePublished(%q) failed", c.raw)
+            continue
+        }
+        if !got.Equal(c.want) {
+            t.Errorf("parsePublished(%q) = %v, want %v", c.raw, got.UTC(), c.want)
+        }
+    }
+    if _, ok := parseDateFuzzy("14:30 sometime in February"); ok {
+        t.Errorf("expected a date without a day and year to fail")
+    }
+}
+
+func FuzzParsePublished(f *testing.F) {
+    for _, seed := range []string{
+        "Mon, 02 Jan 2006 15:04:05 -0700",
+        "Thu, 15 Feb 2024 14:30 +0000",
+        "2024-02-15T14:30Z",
+        "15-Feb-2024",
+        "1:2",
+        "",
+    } {
+        f.Add(seed)
+    }
+    f.Fuzz(func(t *testing.T, raw string) {
+        parsePublished(raw)
+    })
+}
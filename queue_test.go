@@ -0,0 +1,25 @@
+package main
+
+import "testing"
+
+func TestQueueStatusMark(t *testing.T) {
+	cases := map[string]string{
+		queuePending: "[ ] pending",
+		queueReading: "[>] reading",
+		queueDone:    "[x] done",
+	}
+	for status, want := range cases {
+		if got := queueStatusMark(status); got != want {
+			t.Errorf("queueStatusMark(%q) = %q, want %q", status, got, want)
+		}
+	}
+}
+
+func TestFormatReadingMinutes(t *testing.T) {
+	if got := formatReadingMinutes(0); got != "-" {
+		t.Errorf("formatReadingMinutes(0) = %q, want -", got)
+	}
+	if got := formatReadingMinutes(7); got != "7 min" {
+		t.Errorf("formatReadingMinutes(7) = %q, want 7 min", got)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseFeedRDF(t *testing.T) {
+	body := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/" xmlns:dc="http://purl.org/dc/elements/1.1/">
+  <channel rdf:about="https://example.org/">
+    <title>Old &amp;amp; Reliable</title>
+    <link>https://example.org/</link>
+  </channel>
+  <item rdf:about="https://example.org/1">
+    <title>First</title>
+    <link>https://example.org/1</link>
+    <dc:date>2024-02-15T14:30+00:00</dc:date>
+  </item>
+</rdf:RDF>`)
+
+	// Plenty of RDF feeds are served as text/xml, so the root element decides
+	feed, err := parseFeed("text/xml", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if feed.Channel.Title != "Old & Reliable" {
+		t.Fatalf("unexpected channel title %q", feed.Channel.Title)
+	}
+	if len(feed.Channel.Item) != 1 {
+		t.Fatalf("expected 1 item, got %d", len(feed.Channel.Item))
+	}
+
+	item := feed.Channel.Item[0]
+	if item.Title != "First" || item.Link != "https://example.org/1" || item.PubDate != "" {
+		t.Fatalf("unexpected item %+v", item)
+	}
+	published, ok := parsePublished(item.PubDate, item.DCDate)
+	if !ok || !published.Equal(time.Date(2024, 2, 15, 14, 30, 0, 0, time.UTC)) {
+		t.Fatalf("expected dc:date to be used without pubDate, got %v (ok=%v)", published, ok)
+	}
+}
+
+func TestParsePublishedPrefersPubDate(t *testing.T) {
+	published, ok := parsePublished("Mon, 02 Jan 2006 15:04:05 GMT", "2024-02-15")
+	if !ok || published.Year() != 2006 {
+		t.Fatalf("expected pubDate to win over dc:date, got %v (ok=%v)", published, ok)
+	}
+	published, ok = parsePublished("  ", "2024-02-15")
+	if !ok || !published.Equal(time.Date(2024, 2, 15, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("expected a date-only dc:date to parse, got %v (ok=%v)", published, ok)
+	}
+}
@@ -0,0 +1,22 @@
+package main
+
+import "testing"
+
+func TestPercentChange(t *testing.T) {
+	cases := []struct {
+		cur, prev int64
+		want      string
+	}{
+		{0, 0, "-"},
+		{5, 0, "new"},
+		{4, 4, "= 0%"},
+		{28, 25, "▲ 12%"},
+		{19, 20, "▼ 5%"},
+		{0, 3, "▼ 100%"},
+	}
+	for _, c := range cases {
+		if got := percentChange(c.cur, c.prev); got != c.want {
+			t.Errorf("percentChange(%d, %d) = %q, want %q", c.cur, c.prev, got, c.want)
+		}
+	}
+}
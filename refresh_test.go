@@ -0,0 +1,19 @@
+package main
+
+import "testing"
+
+func TestRefreshSummary(t *testing.T) {
+	tests := []struct {
+		counts feedItemCounts
+		want   string
+	}{
+		{feedItemCounts{}, "0 new post(s), 0 duplicate(s)"},
+		{feedItemCounts{saved: 3, duplicates: 7}, "3 new post(s), 7 duplicate(s)"},
+		{feedItemCounts{saved: 1, filtered: 2}, "1 new post(s), 0 duplicate(s), 2 filtered out"},
+	}
+	for _, tt := range tests {
+		if got := refreshSummary(tt.counts); got != tt.want {
+			t.Errorf("refreshSummary(%+v) = %q, want %q", tt.counts, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,23 @@
+package main
+
+import "testing"
+
+func TestRenamedFeedTitle(t *testing.T) {
+	tests := []struct {
+		name, title string
+		want        string
+		wantOK      bool
+	}{
+		{"Old Blog", "New Blog", "New Blog", true},
+		{"Old Blog", "  New Blog\n", "New Blog", true},
+		{"Same", "Same", "Same", false},
+		{"Same", " Same ", "Same", false},
+		{"Kept", "   ", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := renamedFeedTitle(tt.name, tt.title)
+		if got != tt.want || ok != tt.wantOK {
+			t.Errorf("renamedFeedTitle(%q, %q) = %q, %v, want %q, %v", tt.name, tt.title, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
@@ -0,0 +1,20 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"golang.org/x/term"
+)
+
+func TestResetRefusesWithoutTerminal(t *testing.T) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		t.Skip("stdin is a terminal")
+	}
+	// The database is never reached, so a nil one is fine
+	err := handlerReset(&state{}, command{name: "reset"})
+	if err == nil || !strings.Contains(err.Error(), "non-interactive") {
+		t.Fatalf("expected reset to refuse without --confirm, got %v", err)
+	}
+}
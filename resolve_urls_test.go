@@ -0,0 +1,34 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestResolvePostURLs(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/short", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodHead {
+			t.Errorf("expected a HEAD request, got %s", r.Method)
+		}
+		http.Redirect(w, r, "/hop", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/hop", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/article", http.StatusFound)
+	})
+	mux.HandleFunc("/article", func(w http.ResponseWriter, r *http.Request) {})
+	mux.HandleFunc("/gone", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/missing", http.StatusMovedPermanently)
+	})
+	mux.HandleFunc("/missing", http.NotFound)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	links := []string{srv.URL + "/short", srv.URL + "/article", srv.URL + "/gone"}
+	resolved := resolvePostURLs(context.Background(), srv.Client(), links, "")
+	if len(resolved) != 1 || resolved[srv.URL+"/short"] != srv.URL+"/article" {
+		t.Fatalf("expected only the redirect to resolve, got %v", resolved)
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import "testing"
+
+func TestParseFeedItemBodies(t *testing.T) {
+	body := []byte(`<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:content="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Bodies</title>
+    <item>
+      <title>CDATA</title>
+      <description><![CDATA[<p>Use &lt;b&gt; for bold</p>]]></description>
+    </item>
+    <item>
+      <title>Escaped twice</title>
+      <description>&amp;lt;p&amp;gt;Fish &amp;amp;amp; chips&amp;lt;/p&amp;gt;</description>
+    </item>
+    <item>
+      <title>Mixed</title>
+      <description>Intro <![CDATA[<b>bold</b>]]> &amp;amp; outro</description>
+    </item>
+    <item>
+      <title>Full text</title>
+      <description>A short summary</description>
+      <content:encoded><![CDATA[<p>The whole article</p>]]></content:encoded>
+    </item>
+  </channel>
+</rss>`)
+
+	feed, err := parseFeed("application/rss+xml", body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	want := map[string]string{
+		"CDATA":         "<p>Use &lt;b&gt; for bold</p>",
+		"Escaped twice": "<p>Fish &amp; chips</p>",
+		"Mixed":         "Intro <b>bold</b> & outro",
+		"Full text":     "<p>The whole article</p>",
+	}
+	if len(feed.Channel.Item) != len(want) {
+		t.Fatalf("expected %d items, got %d", len(want), len(feed.Channel.Item))
+	}
+	for _, item := range feed.Channel.Item {
+		if got := item.content(); got != want[item.Title] {
+			t.Errorf("%s: content() = %q, want %q", item.Title, got, want[item.Title])
+		}
+	}
+	if summary := feed.Channel.Item[3].Description; summary != "A short summary" {
+		t.Errorf("expected the description to be kept next to content:encoded, got %q", summary)
+	}
+}
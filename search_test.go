@@ -0,0 +1,59 @@
+package main
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestSearchTerms(t *testing.T) {
+	got := searchTerms(`"go generics" or -java rust`)
+	want := []string{"go", "generics", "rust"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("searchTerms = %v, want %v", got, want)
+	}
+}
+
+func TestHighlightMatches(t *testing.T) {
+	got := highlightMatches("Go 1.22 released: go.dev/blog", termsPattern([]string{"go", "1.22"}))
+	want := "\x1b[1mGo\x1b[0m \x1b[1m1.22\x1b[0m released: \x1b[1mgo\x1b[0m.dev/blog"
+	if got != want {
+		t.Fatalf("highlightMatches = %q, want %q", got, want)
+	}
+	if highlightMatches("plain", termsPattern(nil)) != "plain" {
+		t.Fatal("expected text without terms to be unchanged")
+	}
+
+	got = highlightMatches("Go 1.22 is out", regexp.MustCompile(`Go \d+\.\d+`))
+	if got != "\x1b[1mGo 1.22\x1b[0m is out" {
+		t.Fatalf("unexpected regex highlight %q", got)
+	}
+}
+
+func TestSearchPageFooter(t *testing.T) {
+	if got := searchPageFooter(0, 20); got != "Showing results 1-20, use --offset 20 to see more" {
+		t.Errorf("unexpected first page footer %q", got)
+	}
+	if got := searchPageFooter(40, 20); got != "Showing results 41-60, use --offset 60 to see more" {
+		t.Errorf("unexpected third page footer %q", got)
+	}
+}
+
+func TestWordPattern(t *testing.T) {
+	// PostgreSQL's regular expressions agree with Go's on everything wordPattern emits
+	pattern := regexp.MustCompile("(?i)" + wordPattern("go  1.22"))
+	for text, want := range map[string]bool{
+		"Go 1.22 is out":         true,
+		"What's new in go\t1.22": true,
+		"Go 1x22":                false,
+		"Django 1.22":            false,
+		"go 1.225":               false,
+	} {
+		if got := pattern.MatchString(text); got != want {
+			t.Errorf("match %q = %v, want %v", text, got, want)
+		}
+	}
+	if got := wordPattern("C++"); got != `(^|\W)C\+\+(\W|$)` {
+		t.Errorf("unexpected pattern %q", got)
+	}
+}
@@ -0,0 +1,36 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestFeedIntervalSecs(t *testing.T) {
+	cleared, err := feedIntervalSecs(0)
+	if err != nil || cleared.Valid {
+		t.Fatalf("expected 0 to clear the override, got %+v, %v", cleared, err)
+	}
+
+	valid := map[time.Duration]int32{
+		time.Second:                 1,
+		90 * time.Minute:            5400,
+		1500 * time.Millisecond:     1,
+		math.MaxInt32 * time.Second: math.MaxInt32,
+	}
+	for interval, want := range valid {
+		got, err := feedIntervalSecs(interval)
+		if err != nil {
+			t.Fatalf("feedIntervalSecs(%s) returned error: %v", interval, err)
+		}
+		if !got.Valid || got.Int32 != want {
+			t.Fatalf("feedIntervalSecs(%s) = %+v, want %d", interval, got, want)
+		}
+	}
+
+	for _, interval := range []time.Duration{-time.Second, -time.Nanosecond, 500 * time.Millisecond, 1000000 * time.Hour} {
+		if _, err := feedIntervalSecs(interval); err == nil {
+			t.Fatalf("expected feedIntervalSecs(%s) to fail", interval)
+		}
+	}
+}
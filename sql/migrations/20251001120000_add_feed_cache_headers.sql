@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN last_etag TEXT NULL,
+ADD COLUMN last_modified TEXT NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_modified,
+DROP COLUMN last_etag;
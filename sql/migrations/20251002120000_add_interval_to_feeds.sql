@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN interval_secs INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN interval_secs;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN consecutive_errors INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT NULL,
+ADD COLUMN last_attempted_at TIMESTAMP NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN last_attempted_at,
+DROP COLUMN last_error,
+DROP COLUMN consecutive_errors;
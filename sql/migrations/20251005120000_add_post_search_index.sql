@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE INDEX posts_search_idx ON posts USING GIN ((
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('simple', url), 'C')
+));
+
+-- +goose Down
+DROP INDEX posts_search_idx;
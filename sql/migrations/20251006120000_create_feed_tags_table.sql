@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE feed_tags (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (feed_id, tag)
+);
+
+CREATE INDEX feed_tags_tag_idx ON feed_tags (tag);
+
+-- +goose Down
+DROP TABLE IF EXISTS feed_tags;
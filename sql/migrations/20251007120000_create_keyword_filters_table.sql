@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE keyword_filters (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    filter_type TEXT NOT NULL CHECK (filter_type IN ('include', 'exclude')),
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any'))
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS keyword_filters;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN fetch_timeout_secs INTEGER NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN fetch_timeout_secs;
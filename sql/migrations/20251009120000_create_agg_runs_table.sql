@@ -0,0 +1,12 @@
+-- +goose Up
+CREATE TABLE agg_runs (
+    id UUID PRIMARY KEY,
+    started_at TIMESTAMP NOT NULL,
+    finished_at TIMESTAMP NOT NULL,
+    feeds_fetched INTEGER NOT NULL,
+    feeds_failed INTEGER NOT NULL,
+    posts_saved INTEGER NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS agg_runs;
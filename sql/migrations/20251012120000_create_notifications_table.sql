@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE notifications (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (feed_id, user_id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS notifications;
@@ -0,0 +1,10 @@
+-- +goose Up
+CREATE TABLE post_enclosures (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    media_type TEXT NOT NULL DEFAULT '',
+    length BIGINT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS post_enclosures;
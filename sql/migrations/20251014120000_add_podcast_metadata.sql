@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE post_enclosures
+    ADD COLUMN episode_number INT NULL,
+    ADD COLUMN season_number INT NULL,
+    ADD COLUMN duration_secs INT NULL;
+
+CREATE TABLE podcast_feeds (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    author TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    explicit BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS podcast_feeds;
+ALTER TABLE post_enclosures
+    DROP COLUMN IF EXISTS episode_number,
+    DROP COLUMN IF EXISTS season_number,
+    DROP COLUMN IF EXISTS duration_secs;
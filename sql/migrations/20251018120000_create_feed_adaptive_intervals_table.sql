@@ -0,0 +1,9 @@
+-- +goose Up
+CREATE TABLE feed_adaptive_intervals (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    interval_secs INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS feed_adaptive_intervals;
@@ -0,0 +1,13 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN normalized_url TEXT;
+UPDATE posts SET normalized_url = url WHERE normalized_url IS NULL;
+ALTER TABLE posts ALTER COLUMN normalized_url SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_url_key;
+CREATE UNIQUE INDEX posts_normalized_url_key ON posts (normalized_url);
+CREATE INDEX posts_url_idx ON posts (url);
+
+-- +goose Down
+DROP INDEX IF EXISTS posts_url_idx;
+DROP INDEX IF EXISTS posts_normalized_url_key;
+ALTER TABLE posts DROP COLUMN normalized_url;
+ALTER TABLE posts ADD CONSTRAINT posts_url_key UNIQUE (url);
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN language TEXT;
+ALTER TABLE users ADD COLUMN preferred_language TEXT;
+
+-- +goose Down
+ALTER TABLE users DROP COLUMN preferred_language;
+ALTER TABLE posts DROP COLUMN language;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE feeds
+ADD COLUMN user_agent TEXT NULL;
+
+-- +goose Down
+ALTER TABLE feeds
+DROP COLUMN user_agent;
@@ -0,0 +1,7 @@
+-- +goose Up
+ALTER TABLE posts ADD COLUMN redirect_from TEXT;
+CREATE INDEX posts_redirect_from_idx ON posts (redirect_from) WHERE redirect_from IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS posts_redirect_from_idx;
+ALTER TABLE posts DROP COLUMN redirect_from;
@@ -0,0 +1,20 @@
+-- name: CreateAPIKey :one
+INSERT INTO api_keys (id, user_id, key_hash, key_prefix, created_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAPIKeysForUser :many
+SELECT * FROM api_keys
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: DeleteAPIKeyByPrefix :execrows
+DELETE FROM api_keys
+WHERE user_id = $1 AND key_prefix = $2;
+
+-- name: UseAPIKey :one
+UPDATE api_keys
+SET last_used_at = NOW()
+FROM users
+WHERE api_keys.key_hash = $1 AND users.id = api_keys.user_id
+RETURNING users.id, users.created_at, users.updated_at, users.name, users.preferred_language;
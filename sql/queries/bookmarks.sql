@@ -1,4 +1,22 @@
 -- name: BookmarkPost :exec
 INSERT INTO bookmarks (user_id, post_id)
 VALUES ($1, $2)
-ON CONFLICT (user_id, post_id) DO NOTHING;
\ No newline at end of file
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: DeleteBookmarksForUser :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1;
+
+-- name: GetBookmarksForUser :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+       f.name AS feed_name, b.created_at AS bookmarked_at
+FROM bookmarks b
+JOIN posts p ON b.post_id = p.id
+JOIN feeds f ON p.feed_id = f.id
+WHERE b.user_id = $1
+ORDER BY b.created_at DESC
+LIMIT $2 OFFSET $3;
+
+-- name: DeleteBookmark :execrows
+DELETE FROM bookmarks
+WHERE user_id = $1 AND post_id = $2;
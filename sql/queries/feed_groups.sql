@@ -0,0 +1,45 @@
+-- name: CreateFeedGroup :one
+INSERT INTO feed_groups (id, user_id, name, created_at)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetFeedGroupByName :one
+SELECT * FROM feed_groups
+WHERE user_id = $1 AND name = $2;
+
+-- name: DeleteFeedGroup :execrows
+DELETE FROM feed_groups
+WHERE user_id = $1 AND name = $2;
+
+-- name: GetFeedGroupsForUser :many
+SELECT feed_groups.id, feed_groups.name, COUNT(feed_group_members.feed_id) AS feeds
+FROM feed_groups
+LEFT JOIN feed_group_members ON feed_group_members.group_id = feed_groups.id
+WHERE feed_groups.user_id = $1
+GROUP BY feed_groups.id, feed_groups.name
+ORDER BY feed_groups.name;
+
+-- name: AddFeedToGroup :execrows
+INSERT INTO feed_group_members (group_id, feed_id)
+VALUES ($1, $2)
+ON CONFLICT (group_id, feed_id) DO NOTHING;
+
+-- name: RemoveFeedFromGroup :execrows
+DELETE FROM feed_group_members
+WHERE group_id = $1 AND feed_id = $2;
+
+-- name: GetFeedsInGroup :many
+SELECT feeds.id, feeds.name, feeds.url
+FROM feed_group_members
+JOIN feeds ON feed_group_members.feed_id = feeds.id
+WHERE feed_group_members.group_id = $1
+ORDER BY feeds.name;
+
+-- name: GetFeedGroupMembersForUser :many
+-- Every group a user has with the feeds in it, for showing feeds sorted into folders
+SELECT feed_groups.name AS group_name, feeds.id AS feed_id, feeds.name AS feed_name, feeds.url AS feed_url
+FROM feed_groups
+JOIN feed_group_members ON feed_group_members.group_id = feed_groups.id
+JOIN feeds ON feed_group_members.feed_id = feeds.id
+WHERE feed_groups.user_id = $1
+ORDER BY feed_groups.name, feeds.name;
@@ -0,0 +1,19 @@
+-- name: UpdateAdaptiveInterval :exec
+-- Sets a feed's adaptive interval to half the average gap between its last 20 posts,
+-- kept between 5 minutes and a day. Feeds with fewer than two posts are left alone.
+INSERT INTO feed_adaptive_intervals (feed_id, interval_secs, updated_at)
+SELECT sqlc.arg(feed_id)::uuid,
+       LEAST(86400, GREATEST(300,
+           EXTRACT(EPOCH FROM MAX(recent.published) - MIN(recent.published)) / (COUNT(*) - 1) / 2
+       ))::int,
+       NOW()
+FROM (
+    SELECT COALESCE(published_at, created_at) AS published
+    FROM posts
+    WHERE feed_id = sqlc.arg(feed_id)::uuid
+    ORDER BY COALESCE(published_at, created_at) DESC
+    LIMIT 20
+) recent
+HAVING COUNT(*) >= 2
+ON CONFLICT (feed_id) DO UPDATE
+SET interval_secs = EXCLUDED.interval_secs, updated_at = EXCLUDED.updated_at;
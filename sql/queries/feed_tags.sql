@@ -0,0 +1,20 @@
+-- name: AddTagToFeed :execrows
+INSERT INTO feed_tags (feed_id, tag, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (feed_id, tag) DO NOTHING;
+
+-- name: RemoveTagFromFeed :execrows
+DELETE FROM feed_tags
+WHERE feed_id = $1 AND tag = $2;
+
+-- name: GetTagsForFeed :many
+SELECT tag FROM feed_tags
+WHERE feed_id = $1
+ORDER BY tag;
+
+-- name: GetFeedsByTag :many
+SELECT feeds.id, feeds.name, feeds.url
+FROM feed_tags
+JOIN feeds ON feed_tags.feed_id = feeds.id
+WHERE feed_tags.tag = $1
+ORDER BY feeds.name;
@@ -11,19 +11,52 @@ VALUES (
 RETURNING id, created_at, updated_at, name, url, user_id;
 
 -- name: GetFeeds :many
+-- Feeds whose names match the LIKE pattern filter, ignoring case; a limit of 0 returns
+-- every match
 SELECT 
     feeds.id AS feed_id,
     feeds.name AS feed_name,
     feeds.url AS feed_url,
     users.name AS user_name
 FROM feeds
-JOIN users ON feeds.user_id = users.id;
+JOIN users ON feeds.user_id = users.id
+WHERE lower(feeds.name) LIKE lower(sqlc.arg(filter)::text)
+ORDER BY feeds.name
+LIMIT NULLIF(sqlc.arg('limit')::int, 0) OFFSET sqlc.arg('offset')::int;
+
+-- name: GetFeedsVerbose :many
+-- GetFeeds with each feed's fetch health, how many posts are stored and its polling
+-- intervals
+SELECT
+    feeds.id AS feed_id,
+    feeds.name AS feed_name,
+    feeds.url AS feed_url,
+    users.name AS user_name,
+    feeds.last_fetched_at,
+    feeds.consecutive_errors,
+    feeds.last_error,
+    feeds.interval_secs,
+    feed_adaptive_intervals.interval_secs AS adaptive_interval_secs,
+    COUNT(posts.id) AS post_count
+FROM feeds
+JOIN users ON feeds.user_id = users.id
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+LEFT JOIN posts ON posts.feed_id = feeds.id
+WHERE lower(feeds.name) LIKE lower(sqlc.arg(filter)::text)
+GROUP BY feeds.id, users.name, feed_adaptive_intervals.interval_secs
+ORDER BY feeds.name
+LIMIT NULLIF(sqlc.arg('limit')::int, 0) OFFSET sqlc.arg('offset')::int;
 
 -- name: GetFeedByURL :one
 SELECT id, created_at, updated_at, name, url, user_id
 FROM feeds
 WHERE url = $1;
 
+-- name: GetFeedByID :one
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, last_etag, last_modified, interval_secs, consecutive_errors, last_error, last_attempted_at, fetch_timeout_secs, user_agent
+FROM feeds
+WHERE id = $1;
+
 -- name: CreateFeedFollow :one
 WITH inserted_feed_follow AS (
     INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
@@ -61,13 +94,227 @@ WHERE feed_follows.user_id = $1;
 DELETE FROM feed_follows
 WHERE user_id = $1 AND feed_id = $2;
 
--- name: MarkFeedFetched :exec
+-- name: GetDeadFeedsForUser :many
+-- Followed feeds that keep failing, or that haven't been fetched since stale_before and
+-- have had no new posts since then either
+SELECT feeds.id, feeds.name, feeds.url, feeds.last_fetched_at, feeds.consecutive_errors, feeds.last_error
+FROM feeds
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (
+    feeds.consecutive_errors >= sqlc.arg(threshold)::int
+    OR (
+        COALESCE(feeds.last_fetched_at, feeds.created_at) < sqlc.arg(stale_before)::timestamp
+        AND NOT EXISTS (
+            SELECT 1 FROM posts
+            WHERE posts.feed_id = feeds.id AND posts.created_at >= sqlc.arg(stale_before)::timestamp
+        )
+    )
+  )
+ORDER BY feeds.name;
+
+-- name: MarkFeedFetchedOK :exec
 UPDATE feeds
-SET last_fetched_at = NOW(), updated_at = NOW()
+SET last_fetched_at = NOW(), last_attempted_at = NOW(), updated_at = NOW(),
+    consecutive_errors = 0, last_error = NULL
+WHERE id = $1;
+
+-- name: MarkFeedFetchedError :exec
+UPDATE feeds
+SET last_fetched_at = NOW(), last_attempted_at = NOW(), updated_at = NOW(),
+    consecutive_errors = consecutive_errors + 1, last_error = $2::text
+WHERE id = $1;
+
+-- name: ClaimFeedForFetch :execrows
+-- Stamps last_attempted_at before a fetch. A stamp newer than the last fetch is a
+-- claim by another agg run, which holds until claimed_before passes it.
+UPDATE feeds
+SET last_attempted_at = NOW(), updated_at = NOW()
+WHERE id = sqlc.arg(id)
+  AND (last_attempted_at IS NULL
+    OR last_attempted_at <= last_fetched_at
+    OR last_attempted_at < sqlc.arg(claimed_before)::timestamp);
+
+-- name: RecordFeedFetchError :exec
+UPDATE feeds
+SET last_attempted_at = NOW(), updated_at = NOW(),
+    consecutive_errors = consecutive_errors + 1, last_error = $2::text
 WHERE id = $1;
 
 -- name: GetNextFeedToFetch :one
-SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at
+-- A feed's own interval wins; with adaptive set, the learned one applies next
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.last_etag, feeds.last_modified, feeds.interval_secs, feeds.consecutive_errors, feeds.last_error, feeds.last_attempted_at, feeds.fetch_timeout_secs, feeds.user_agent
 FROM feeds
-ORDER BY last_fetched_at NULLS FIRST
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feeds.last_fetched_at IS NULL
+   OR feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN sqlc.arg(adaptive)::bool THEN feed_adaptive_intervals.interval_secs END, 0)) <= NOW()
+ORDER BY feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN sqlc.arg(adaptive)::bool THEN feed_adaptive_intervals.interval_secs END, 0)) NULLS FIRST
 LIMIT 1;
+
+-- name: SetFeedCacheHeaders :exec
+UPDATE feeds
+SET last_etag = $2, last_modified = $3
+WHERE id = $1;
+
+-- name: SetFeedInterval :exec
+UPDATE feeds
+SET interval_secs = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedFetchTimeout :exec
+UPDATE feeds
+SET fetch_timeout_secs = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: SetFeedUserAgent :exec
+UPDATE feeds
+SET user_agent = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: GetFeedsDueForFetch :many
+-- A feed's own interval wins; with adaptive set, the learned one applies next
+SELECT feeds.id, feeds.created_at, feeds.updated_at, feeds.name, feeds.url, feeds.user_id, feeds.last_fetched_at, feeds.last_etag, feeds.last_modified, feeds.interval_secs, feeds.consecutive_errors, feeds.last_error, feeds.last_attempted_at, feeds.fetch_timeout_secs, feeds.user_agent
+FROM feeds
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feeds.last_fetched_at IS NULL
+   OR feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN sqlc.arg(adaptive)::bool THEN feed_adaptive_intervals.interval_secs END, 0)) <= NOW()
+ORDER BY feeds.last_fetched_at + make_interval(secs => COALESCE(feeds.interval_secs, CASE WHEN sqlc.arg(adaptive)::bool THEN feed_adaptive_intervals.interval_secs END, 0)) NULLS FIRST;
+
+-- name: GetFeedFollowersForFeed :many
+SELECT users.id, users.name
+FROM feed_follows
+INNER JOIN users ON feed_follows.user_id = users.id
+WHERE feed_follows.feed_id = $1
+ORDER BY feed_follows.created_at;
+
+-- name: DeleteFeedFollowsForFeed :execrows
+DELETE FROM feed_follows
+WHERE feed_id = $1;
+
+-- name: DeleteFeed :execrows
+DELETE FROM feeds
+WHERE id = $1;
+
+-- name: GetFeedsWithErrors :many
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, last_etag, last_modified, interval_secs, consecutive_errors, last_error, last_attempted_at, fetch_timeout_secs, user_agent
+FROM feeds
+WHERE consecutive_errors > 0 AND consecutive_errors >= sqlc.arg(threshold)::int
+ORDER BY consecutive_errors DESC, name;
+
+-- name: GetHTTPFeeds :many
+SELECT id, name, url
+FROM feeds
+WHERE url ILIKE 'http://%'
+ORDER BY name;
+
+-- name: SearchFeedsByName :many
+-- Feeds whose name or URL contains pattern, ignoring case. Exact name matches come
+-- first, then names starting with pattern, then the rest.
+SELECT id, created_at, updated_at, name, url, user_id, last_fetched_at, last_etag, last_modified, interval_secs, consecutive_errors, last_error, last_attempted_at, fetch_timeout_secs, user_agent
+FROM feeds
+WHERE name ILIKE '%' || sqlc.arg(pattern)::text || '%'
+   OR url ILIKE '%' || sqlc.arg(pattern)::text || '%'
+ORDER BY CASE
+    WHEN lower(name) = lower(sqlc.arg(pattern)::text) THEN 0
+    WHEN name ILIKE sqlc.arg(pattern)::text || '%' THEN 1
+    ELSE 2
+END, name;
+
+-- name: ResetFeedErrors :execrows
+UPDATE feeds
+SET consecutive_errors = 0, last_error = NULL, updated_at = NOW()
+WHERE url = $1;
+
+-- name: UpdateFeedName :exec
+UPDATE feeds
+SET name = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: UpdateFeedURL :exec
+UPDATE feeds
+SET url = $2, last_etag = NULL, last_modified = NULL, updated_at = NOW()
+WHERE id = $1;
+
+-- name: MoveFeedFollows :execrows
+UPDATE feed_follows
+SET feed_id = sqlc.arg(to_feed_id), updated_at = NOW()
+WHERE feed_id = sqlc.arg(from_feed_id)
+  AND user_id NOT IN (
+    SELECT user_id FROM feed_follows WHERE feed_id = sqlc.arg(to_feed_id)
+  );
+
+-- name: GetFeedsCreatedByUser :many
+SELECT id, name, url
+FROM feeds
+WHERE user_id = $1
+ORDER BY name;
+
+-- name: ReassignFeed :exec
+UPDATE feeds
+SET user_id = $2, updated_at = NOW()
+WHERE id = $1;
+
+-- name: DeleteFeedFollowsForUser :execrows
+DELETE FROM feed_follows
+WHERE user_id = $1;
+
+-- name: CountFeeds :one
+SELECT COUNT(*) FROM feeds;
+
+-- name: CountFeedsMatching :one
+SELECT COUNT(*) FROM feeds
+WHERE lower(name) LIKE lower(sqlc.arg(filter)::text);
+
+-- name: GetFollowedFeedsMatching :many
+-- The feeds a user follows, narrowed to a tag and/or one of their groups when given
+SELECT feeds.id, feeds.name, feeds.url
+FROM feed_follows
+JOIN feeds ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(tag)::text IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_tags WHERE tag = sqlc.narg(tag)::text))
+  AND (sqlc.narg(group_id)::uuid IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_group_members WHERE group_id = sqlc.narg(group_id)::uuid))
+ORDER BY feeds.name;
+
+-- name: DeleteFeedFollowsMatching :execrows
+-- Unfollows what GetFollowedFeedsMatching lists, in one statement
+DELETE FROM feed_follows
+WHERE user_id = sqlc.arg(user_id)
+  AND (sqlc.narg(tag)::text IS NULL
+    OR feed_id IN (SELECT feed_id FROM feed_tags WHERE tag = sqlc.narg(tag)::text))
+  AND (sqlc.narg(group_id)::uuid IS NULL
+    OR feed_id IN (SELECT feed_id FROM feed_group_members WHERE group_id = sqlc.narg(group_id)::uuid));
+
+-- name: GetUnfollowedFeeds :many
+-- Every feed the user doesn't follow yet, or only those with a tag when given
+SELECT feeds.id, feeds.name, feeds.url
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = sqlc.arg(user_id) AND feed_follows.feed_id = feeds.id
+  )
+  AND (sqlc.narg(tag)::text IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_tags WHERE tag = sqlc.narg(tag)::text))
+ORDER BY feeds.name;
+
+-- name: FollowAllFeeds :execrows
+-- Follows what GetUnfollowedFeeds lists, in one statement
+INSERT INTO feed_follows (id, created_at, updated_at, user_id, feed_id)
+SELECT gen_random_uuid(), sqlc.arg(now)::timestamp, sqlc.arg(now)::timestamp, sqlc.arg(user_id)::uuid, feeds.id
+FROM feeds
+WHERE NOT EXISTS (
+    SELECT 1 FROM feed_follows
+    WHERE feed_follows.user_id = sqlc.arg(user_id)::uuid AND feed_follows.feed_id = feeds.id
+  )
+  AND (sqlc.narg(tag)::text IS NULL
+    OR feeds.id IN (SELECT feed_id FROM feed_tags WHERE tag = sqlc.narg(tag)::text));
+
+-- name: GetFeedsWithHealth :many
+-- How recently and how well each feed the user follows was fetched, for the TUI's feed pane
+SELECT feeds.id, feeds.last_fetched_at, feeds.consecutive_errors, feeds.interval_secs,
+    feed_adaptive_intervals.interval_secs AS adaptive_interval_secs
+FROM feed_follows
+JOIN feeds ON feeds.id = feed_follows.feed_id
+LEFT JOIN feed_adaptive_intervals ON feed_adaptive_intervals.feed_id = feeds.id
+WHERE feed_follows.user_id = $1;
@@ -0,0 +1,19 @@
+-- name: CreateKeywordFilter :one
+INSERT INTO keyword_filters (id, created_at, user_id, keyword, filter_type, field)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: DeleteKeywordFilter :execrows
+DELETE FROM keyword_filters
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetKeywordFiltersForUser :many
+SELECT * FROM keyword_filters
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: GetKeywordFiltersForFeed :many
+SELECT kf.id, kf.created_at, kf.user_id, kf.keyword, kf.filter_type, kf.field
+FROM keyword_filters kf
+JOIN feed_follows ff ON kf.user_id = ff.user_id
+WHERE ff.feed_id = $1;
@@ -0,0 +1,12 @@
+-- name: SetNotificationsEnabled :exec
+INSERT INTO notifications (feed_id, user_id, enabled)
+VALUES ($1, $2, $3)
+ON CONFLICT (feed_id, user_id) DO UPDATE SET enabled = EXCLUDED.enabled;
+
+-- name: NotificationsEnabledForFeed :one
+-- Only followers count, so unfollowing a feed also silences it
+SELECT EXISTS (
+    SELECT 1 FROM notifications
+    JOIN feed_follows ON feed_follows.user_id = notifications.user_id AND feed_follows.feed_id = notifications.feed_id
+    WHERE notifications.feed_id = $1 AND notifications.enabled
+);
@@ -0,0 +1,23 @@
+-- name: PinPost :exec
+-- Pinning again replaces the note but keeps the original pin time
+INSERT INTO pinned_posts (user_id, post_id, note)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO UPDATE SET note = EXCLUDED.note;
+
+-- name: UnpinPost :execrows
+DELETE FROM pinned_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: GetPinnedPostsForUser :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+       f.name AS feed_name, pp.pinned_at, pp.note
+FROM pinned_posts pp
+JOIN posts p ON pp.post_id = p.id
+JOIN feeds f ON p.feed_id = f.id
+WHERE pp.user_id = $1
+ORDER BY pp.pinned_at DESC
+LIMIT $2;
+
+-- name: GetPinnedPostIDs :many
+SELECT post_id FROM pinned_posts
+WHERE user_id = $1 AND post_id = ANY(sqlc.arg(post_ids)::uuid[]);
@@ -0,0 +1,51 @@
+-- name: CreatePostEnclosure :exec
+INSERT INTO post_enclosures (post_id, url, media_type, length, episode_number, season_number, duration_secs)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+ON CONFLICT (post_id) DO NOTHING;
+
+-- name: GetPodcastFeedsForUser :many
+SELECT feeds.id, feeds.name, feeds.url, COUNT(*) AS episodes, MAX(COALESCE(posts.published_at, posts.created_at))::timestamp AS latest
+FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+JOIN feed_follows ON feed_follows.feed_id = feeds.id
+WHERE feed_follows.user_id = $1
+GROUP BY feeds.id, feeds.name, feeds.url
+ORDER BY feeds.name;
+
+-- name: GetEpisodesForFeed :many
+SELECT posts.id, posts.title, posts.published_at, post_enclosures.url, post_enclosures.media_type, post_enclosures.length
+FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+WHERE posts.feed_id = $1
+ORDER BY COALESCE(posts.published_at, posts.created_at) DESC
+LIMIT $2;
+
+-- name: GetEpisode :one
+SELECT posts.id, posts.title, posts.feed_id, feeds.name AS feed_name, post_enclosures.url, post_enclosures.media_type, post_enclosures.length
+FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id
+JOIN feeds ON feeds.id = posts.feed_id
+WHERE post_enclosures.post_id = $1;
+
+-- name: GetEpisodeInfoForPosts :many
+SELECT post_id, episode_number, season_number, duration_secs
+FROM post_enclosures
+WHERE post_id = ANY(sqlc.arg(post_ids)::uuid[]);
+
+-- name: UpsertPodcastFeed :exec
+INSERT INTO podcast_feeds (feed_id, author, image_url, category, explicit, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (feed_id) DO UPDATE
+SET author = EXCLUDED.author,
+    image_url = EXCLUDED.image_url,
+    category = EXCLUDED.category,
+    explicit = EXCLUDED.explicit,
+    updated_at = EXCLUDED.updated_at;
+
+-- name: GetPodcastFeedIDs :many
+-- Feeds that look like podcasts: they carry iTunes show details or posts with media files
+SELECT feed_id FROM podcast_feeds
+UNION
+SELECT DISTINCT posts.feed_id FROM post_enclosures
+JOIN posts ON posts.id = post_enclosures.post_id;
@@ -1,27 +1,207 @@
--- name: CreatePost :exec
-INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-ON CONFLICT (url) DO NOTHING;
+-- name: CreatePost :execrows
+INSERT INTO posts (id, created_at, updated_at, title, url, description, published_at, feed_id, normalized_url, author, language, redirect_from)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+ON CONFLICT (normalized_url) DO NOTHING;
 
 -- name: GetPostsForUser :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+    EXISTS (
+        SELECT 1 FROM read_posts rp
+        WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.user_id = ff.user_id AND b.post_id = p.id
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM pinned_posts pp
+        WHERE pp.user_id = ff.user_id AND pp.post_id = p.id
+    ) AS is_pinned
 FROM posts p
 JOIN feed_follows ff ON p.feed_id = ff.feed_id
 WHERE ff.user_id = $1
 ORDER BY COALESCE(p.published_at, p.created_at) DESC
-LIMIT $2;
+LIMIT $2 OFFSET $3;
 
 -- name: GetPostsForUserPaginated :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
 FROM posts p
 JOIN feed_follows ff ON p.feed_id = ff.feed_id
-WHERE ff.user_id = $1
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (NOT sqlc.arg(unread_only)::bool OR NOT EXISTS (
+    SELECT 1 FROM read_posts rp
+    WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+  ))
+  AND (sqlc.narg(published_after)::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) >= sqlc.narg(published_after)::timestamp)
+  AND (sqlc.narg(published_before)::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) < sqlc.narg(published_before)::timestamp)
+  AND (sqlc.narg(feed_id)::uuid IS NULL OR p.feed_id = sqlc.narg(feed_id)::uuid)
+  AND (sqlc.narg(language)::text IS NULL OR p.language IS NULL OR p.language = sqlc.narg(language)::text)
 ORDER BY COALESCE(p.published_at, p.created_at) DESC
-LIMIT $2 OFFSET $3;
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetUnreadPostsForUser :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND NOT EXISTS (
+    SELECT 1 FROM read_posts rp
+    WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+  )
+  AND (sqlc.narg(published_after)::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) >= sqlc.narg(published_after)::timestamp)
+  AND (sqlc.narg(published_before)::timestamp IS NULL
+    OR COALESCE(p.published_at, p.created_at) < sqlc.narg(published_before)::timestamp)
+  AND (sqlc.narg(feed_id)::uuid IS NULL OR p.feed_id = sqlc.narg(feed_id)::uuid)
+  AND (sqlc.narg(language)::text IS NULL OR p.language IS NULL OR p.language = sqlc.narg(language)::text)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
 
 -- name: SearchPosts :many
-SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id
+-- The tsvector expression must match posts_search_idx exactly for the index to be used.
+-- A limit of 0 returns every match; like mode with an empty query matches every post.
+-- Word mode matches word_pattern, a case-insensitive regular expression, against titles
+-- and descriptions. Author names match as substrings in every mode. Matches come newest
+-- first unless sort_by is title or relevance.
+WITH matches AS (
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+       (CASE
+           WHEN sqlc.arg(search_mode)::text = 'fulltext' THEN ts_rank(
+               setweight(to_tsvector('english', p.title), 'A') ||
+               setweight(to_tsvector('english', COALESCE(p.description, '')), 'B') ||
+               setweight(to_tsvector('simple', p.url), 'C'),
+               websearch_to_tsquery('english', sqlc.arg(query)::text))
+           WHEN p.title ILIKE '%' || sqlc.arg(query)::text || '%' THEN 1
+           ELSE 0.5
+       END)::real AS rank
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (
+    (sqlc.arg(search_mode)::text = 'fulltext' AND (
+        setweight(to_tsvector('english', p.title), 'A') ||
+        setweight(to_tsvector('english', COALESCE(p.description, '')), 'B') ||
+        setweight(to_tsvector('simple', p.url), 'C')
+    ) @@ websearch_to_tsquery('english', sqlc.arg(query)::text))
+    OR p.author ILIKE '%' || sqlc.arg(query)::text || '%'
+    OR
+    (sqlc.arg(search_mode)::text = 'like' AND (
+        p.title ILIKE '%' || sqlc.arg(query)::text || '%'
+        OR p.description ILIKE '%' || sqlc.arg(query)::text || '%'
+        OR p.url ILIKE '%' || sqlc.arg(query)::text || '%'
+        OR p.normalized_url = sqlc.arg(query)::text
+    ))
+    OR
+    (sqlc.arg(search_mode)::text = 'word' AND (
+        p.title ~* sqlc.arg(word_pattern)::text
+        OR p.description ~* sqlc.arg(word_pattern)::text
+    ))
+  )
+  AND (sqlc.narg(feed_id)::uuid IS NULL OR p.feed_id = sqlc.narg(feed_id)::uuid)
+)
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, rank
+FROM matches
+ORDER BY
+    CASE WHEN sqlc.arg(sort_by)::text = 'title' THEN lower(title) END,
+    CASE WHEN sqlc.arg(sort_by)::text = 'relevance' THEN rank END DESC,
+    COALESCE(published_at, created_at) DESC
+LIMIT NULLIF(sqlc.arg('limit')::int, 0) OFFSET sqlc.arg('offset');
+
+-- name: CountPostsForFeed :one
+SELECT COUNT(*) FROM posts
+WHERE feed_id = $1;
+
+-- name: DeletePostsForFeed :execrows
+DELETE FROM posts
+WHERE feed_id = $1;
+
+-- name: DeleteOldPostsForUser :many
+DELETE FROM posts p
+USING feed_follows ff
+WHERE p.feed_id = ff.feed_id
+  AND ff.user_id = sqlc.arg(user_id)
+  AND COALESCE(p.published_at, p.created_at) < sqlc.arg(cutoff)::timestamp
+RETURNING p.id, p.title, p.url, p.published_at;
+
+-- name: MovePostsToFeed :execrows
+UPDATE posts
+SET feed_id = sqlc.arg(to_feed_id), updated_at = NOW()
+WHERE feed_id = sqlc.arg(from_feed_id);
+
+-- name: GetPostByURL :one
+-- Matches the URL as published or any variant that normalizes to the same post
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, normalized_url, author, language, redirect_from
+FROM posts
+WHERE url = $1 OR normalized_url = $2
+LIMIT 1;
+
+-- name: GetPostsForUserByTag :many
+-- Posts match when their feed has the tag or a tag rule gave it to the post itself
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
 FROM posts p
 JOIN feed_follows ff ON p.feed_id = ff.feed_id
-WHERE ff.user_id = $1 AND (p.title ILIKE $2 OR p.description ILIKE $2)
-ORDER BY COALESCE(p.published_at, p.created_at) DESC;
\ No newline at end of file
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (EXISTS (
+    SELECT 1 FROM feed_tags ft WHERE ft.feed_id = p.feed_id AND ft.tag = sqlc.arg(tag)
+  ) OR EXISTS (
+    SELECT 1 FROM post_tags pt WHERE pt.post_id = p.id AND pt.tag = sqlc.arg(tag)
+  ))
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserByGroup :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+JOIN feed_group_members gm ON p.feed_id = gm.feed_id
+WHERE ff.user_id = sqlc.arg(user_id) AND gm.group_id = sqlc.arg(group_id)
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserByAuthor :many
+-- Matches any part of the author's name, ignoring case
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id, p.normalized_url, p.author, p.language, p.redirect_from
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id) AND p.author ILIKE '%' || sqlc.arg(author)::text || '%'
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT sqlc.arg('limit') OFFSET sqlc.arg('offset');
+
+-- name: GetPostsForUserFeed :many
+SELECT p.id, p.created_at, p.updated_at, p.title, p.url, p.description, p.published_at, p.feed_id,
+    EXISTS (
+        SELECT 1 FROM read_posts rp
+        WHERE rp.user_id = ff.user_id AND rp.post_id = p.id
+    ) AS is_read,
+    EXISTS (
+        SELECT 1 FROM bookmarks b
+        WHERE b.user_id = ff.user_id AND b.post_id = p.id
+    ) AS is_bookmarked,
+    EXISTS (
+        SELECT 1 FROM pinned_posts pp
+        WHERE pp.user_id = ff.user_id AND pp.post_id = p.id
+    ) AS is_pinned
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = $1 AND p.feed_id = $2
+ORDER BY COALESCE(p.published_at, p.created_at) DESC
+LIMIT $3 OFFSET $4;
+
+-- name: GetPostByID :one
+SELECT id, created_at, updated_at, title, url, description, published_at, feed_id, normalized_url, author, language, redirect_from
+FROM posts
+WHERE id = $1;
+
+-- name: GetStoredNormalizedURLs :many
+-- Which of the given normalized URLs already belong to a post
+SELECT normalized_url
+FROM posts
+WHERE normalized_url = ANY(sqlc.arg(normalized_urls)::text[]);
+
+-- name: GetPostRedirects :many
+-- The posts stored under a resolved URL whose original link is one of the given ones
+SELECT redirect_from, url
+FROM posts
+WHERE redirect_from = ANY(sqlc.arg(urls)::text[]);
@@ -0,0 +1,35 @@
+-- name: MarkPostRead :execrows
+INSERT INTO read_posts (user_id, post_id, read_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkPostUnread :execrows
+DELETE FROM read_posts
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: IsPostRead :one
+SELECT EXISTS (
+    SELECT 1 FROM read_posts
+    WHERE user_id = $1 AND post_id = $2
+);
+
+-- name: DeleteReadPostsForUser :execrows
+DELETE FROM read_posts
+WHERE user_id = $1;
+
+-- name: MarkAllPostsReadForFeed :execrows
+-- Marks every post of one feed read at once
+INSERT INTO read_posts (user_id, post_id, read_at)
+SELECT sqlc.arg(user_id)::uuid, posts.id, sqlc.arg(read_at)::timestamp
+FROM posts
+WHERE posts.feed_id = sqlc.arg(feed_id)
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: MarkAllPostsReadForUser :execrows
+-- Marks every post of every feed the user follows read at once
+INSERT INTO read_posts (user_id, post_id, read_at)
+SELECT feed_follows.user_id, posts.id, sqlc.arg(read_at)::timestamp
+FROM posts
+JOIN feed_follows ON feed_follows.feed_id = posts.feed_id
+WHERE feed_follows.user_id = sqlc.arg(user_id)
+ON CONFLICT (user_id, post_id) DO NOTHING;
@@ -0,0 +1,60 @@
+-- name: AddToQueue :execrows
+-- New posts join the back of the queue; queuing a post twice does nothing
+INSERT INTO read_queue (user_id, post_id, position)
+SELECT sqlc.arg(user_id)::uuid, sqlc.arg(post_id)::uuid, COALESCE(MAX(position), 0) + 1
+FROM read_queue
+WHERE user_id = sqlc.arg(user_id)::uuid
+ON CONFLICT (user_id, post_id) DO NOTHING;
+
+-- name: GetQueueForUser :many
+SELECT q.position, q.status, q.added_at, p.id, p.title, p.url, p.description, f.name AS feed_name
+FROM read_queue q
+JOIN posts p ON q.post_id = p.id
+JOIN feeds f ON p.feed_id = f.id
+WHERE q.user_id = $1
+ORDER BY q.position;
+
+-- name: CountQueueForUser :one
+SELECT COUNT(*) FROM read_queue
+WHERE user_id = $1;
+
+-- name: GetNextQueueItem :one
+SELECT q.position, q.status, q.added_at, p.id, p.title, p.url, p.description, f.name AS feed_name
+FROM read_queue q
+JOIN posts p ON q.post_id = p.id
+JOIN feeds f ON p.feed_id = f.id
+WHERE q.user_id = $1 AND q.status = 'pending'
+ORDER BY q.position
+LIMIT 1;
+
+-- name: UpdateQueueStatus :execrows
+UPDATE read_queue SET status = $3
+WHERE user_id = $1 AND post_id = $2;
+
+-- name: DeleteQueueItem :one
+DELETE FROM read_queue
+WHERE user_id = $1 AND post_id = $2
+RETURNING position;
+
+-- name: CloseQueueGap :exec
+-- Moves everything behind a removed post up one place
+UPDATE read_queue SET position = position - 1
+WHERE user_id = $1 AND position > $2;
+
+-- name: ReorderQueueItem :execrows
+-- Moves a post to new_position and shifts the posts between its old and new places
+-- by one to make room
+WITH moved AS (
+    SELECT position AS old_position FROM read_queue
+    WHERE user_id = sqlc.arg(user_id) AND post_id = sqlc.arg(post_id)
+)
+UPDATE read_queue q
+SET position = CASE
+    WHEN q.post_id = sqlc.arg(post_id) THEN sqlc.arg(new_position)::int
+    WHEN moved.old_position < sqlc.arg(new_position)::int THEN q.position - 1
+    ELSE q.position + 1
+END
+FROM moved
+WHERE q.user_id = sqlc.arg(user_id)
+  AND q.position BETWEEN LEAST(moved.old_position, sqlc.arg(new_position)::int)
+                     AND GREATEST(moved.old_position, sqlc.arg(new_position)::int);
@@ -0,0 +1,127 @@
+-- name: RecordAggRun :exec
+INSERT INTO agg_runs (id, started_at, finished_at, feeds_fetched, feeds_failed, posts_saved)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: DeleteAggRunsBefore :exec
+DELETE FROM agg_runs
+WHERE started_at < $1;
+
+-- name: GetLastAggRun :one
+SELECT id, started_at, finished_at, feeds_fetched, feeds_failed, posts_saved
+FROM agg_runs
+ORDER BY started_at DESC
+LIMIT 1;
+
+-- name: GetUserStats :one
+WITH followed AS (
+    SELECT feed_id FROM feed_follows WHERE user_id = sqlc.arg(user_id)
+),
+user_posts AS (
+    SELECT p.id, p.feed_id, COALESCE(p.published_at, p.created_at) AS posted_at
+    FROM posts p
+    JOIN followed f ON p.feed_id = f.feed_id
+    WHERE COALESCE(p.published_at, p.created_at) >= sqlc.arg(since)::timestamp
+),
+most_active AS (
+    SELECT feeds.name, COUNT(*) AS post_count
+    FROM user_posts
+    JOIN feeds ON feeds.id = user_posts.feed_id
+    GROUP BY feeds.id, feeds.name
+    ORDER BY post_count DESC, feeds.name
+    LIMIT 1
+)
+SELECT
+    (SELECT COUNT(*) FROM followed) AS feeds_followed,
+    (SELECT COUNT(*) FROM user_posts) AS total_posts,
+    (SELECT COUNT(*) FROM read_posts rp JOIN user_posts up ON rp.post_id = up.id
+        WHERE rp.user_id = sqlc.arg(user_id)) AS read_posts,
+    (SELECT COUNT(*) FROM bookmarks b JOIN user_posts up ON b.post_id = up.id
+        WHERE b.user_id = sqlc.arg(user_id)) AS bookmarked_posts,
+    (SELECT MIN(posted_at) FROM user_posts) AS oldest_post,
+    (SELECT MAX(posted_at) FROM user_posts) AS newest_post,
+    COALESCE((SELECT name FROM most_active), '')::text AS most_active_feed,
+    COALESCE((SELECT post_count FROM most_active), 0)::bigint AS most_active_feed_posts;
+
+-- name: GetReadingStats :one
+-- What the user read between since and until. reads_per_day counts reads on each of
+-- the last days days, ending with until's, oldest first. avg_words is the mean length of
+-- the read posts' descriptions with tags stripped, 0 when none had one.
+WITH reads AS (
+    SELECT rp.post_id, rp.read_at, p.feed_id, p.description
+    FROM read_posts rp
+    JOIN posts p ON p.id = rp.post_id
+    WHERE rp.user_id = sqlc.arg(user_id)
+      AND rp.read_at >= sqlc.arg(since)::timestamp
+      AND rp.read_at < sqlc.arg(until)::timestamp
+),
+feed_reads AS (
+    SELECT feeds.name, COUNT(*) AS read_count,
+           ROW_NUMBER() OVER (ORDER BY COUNT(*) DESC, feeds.name) AS place
+    FROM reads
+    JOIN feeds ON feeds.id = reads.feed_id
+    GROUP BY feeds.id, feeds.name
+),
+days AS (
+    SELECT generate_series(
+        (sqlc.arg(until)::timestamp - make_interval(days => sqlc.arg(days)::int - 1))::date,
+        sqlc.arg(until)::timestamp::date,
+        interval '1 day'
+    )::date AS day
+)
+SELECT
+    (SELECT COUNT(*) FROM reads) AS posts_read,
+    ARRAY(
+        SELECT COUNT(rp.post_id)
+        FROM days
+        LEFT JOIN read_posts rp ON rp.user_id = sqlc.arg(user_id) AND rp.read_at::date = days.day
+        GROUP BY days.day
+        ORDER BY days.day
+    )::bigint[] AS reads_per_day,
+    COALESCE((SELECT name FROM feed_reads WHERE place = 1), '')::text AS most_read_feed,
+    COALESCE((SELECT read_count FROM feed_reads WHERE place = 1), 0)::bigint AS most_read_feed_posts,
+    COALESCE((SELECT AVG(array_length(regexp_split_to_array(
+        NULLIF(btrim(regexp_replace(description, '<[^>]*>', ' ', 'g')), ''), '\s+'), 1))
+        FROM reads), 0)::float8 AS avg_words,
+    (SELECT COUNT(*) FROM bookmarks b
+        WHERE b.user_id = sqlc.arg(user_id)
+          AND b.created_at >= sqlc.arg(since)::timestamp AT TIME ZONE 'UTC'
+          AND b.created_at < sqlc.arg(until)::timestamp AT TIME ZONE 'UTC') AS posts_bookmarked,
+    (SELECT COUNT(*) FROM pinned_posts pp
+        WHERE pp.user_id = sqlc.arg(user_id)
+          AND pp.pinned_at >= sqlc.arg(since)::timestamp AT TIME ZONE 'UTC'
+          AND pp.pinned_at < sqlc.arg(until)::timestamp AT TIME ZONE 'UTC') AS posts_pinned;
+
+-- name: GetFeedFrequencyStats :many
+-- How often each followed feed published since the given time. peak_day (0 is Sunday)
+-- and peak_hour are the UTC weekday and hour with the most posts, ties going to the
+-- earlier one, and are NULL for feeds without posts in the period.
+WITH recent AS (
+    SELECT p.feed_id, COALESCE(p.published_at, p.created_at) AS posted_at
+    FROM posts p
+    JOIN feed_follows ff ON ff.feed_id = p.feed_id
+    WHERE ff.user_id = sqlc.arg(user_id)
+      AND COALESCE(p.published_at, p.created_at) >= sqlc.arg(since)::timestamp
+),
+by_day AS (
+    SELECT feed_id, EXTRACT(DOW FROM posted_at)::int AS day,
+           ROW_NUMBER() OVER (PARTITION BY feed_id ORDER BY COUNT(*) DESC, EXTRACT(DOW FROM posted_at)) AS place
+    FROM recent
+    GROUP BY feed_id, EXTRACT(DOW FROM posted_at)
+),
+by_hour AS (
+    SELECT feed_id, EXTRACT(HOUR FROM posted_at)::int AS hour,
+           ROW_NUMBER() OVER (PARTITION BY feed_id ORDER BY COUNT(*) DESC, EXTRACT(HOUR FROM posted_at)) AS place
+    FROM recent
+    GROUP BY feed_id, EXTRACT(HOUR FROM posted_at)
+)
+SELECT f.id, f.name, f.url,
+       (SELECT COUNT(*) FROM recent r WHERE r.feed_id = f.id) AS post_count,
+       (SELECT MAX(r.posted_at) FROM recent r WHERE r.feed_id = f.id) AS newest_post,
+       d.day AS peak_day,
+       h.hour AS peak_hour
+FROM feed_follows ff
+JOIN feeds f ON f.id = ff.feed_id
+LEFT JOIN by_day d ON d.feed_id = f.id AND d.place = 1
+LEFT JOIN by_hour h ON h.feed_id = f.id AND h.place = 1
+WHERE ff.user_id = sqlc.arg(user_id)
+ORDER BY post_count DESC, f.name;
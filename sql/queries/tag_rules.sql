@@ -0,0 +1,34 @@
+-- name: CreateTagRule :one
+INSERT INTO tag_rules (id, created_at, user_id, keyword, tag, field, case_sensitive)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: DeleteTagRule :execrows
+DELETE FROM tag_rules
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetTagRulesForUser :many
+SELECT * FROM tag_rules
+WHERE user_id = $1
+ORDER BY created_at;
+
+-- name: AddTagToPost :execrows
+INSERT INTO post_tags (post_id, tag, created_at)
+VALUES ($1, $2, $3)
+ON CONFLICT (post_id, tag) DO NOTHING;
+
+-- name: GetPostIDsByTag :many
+SELECT post_id FROM post_tags
+WHERE tag = $1;
+
+-- name: GetPostsToTag :many
+-- Posts from the user's feeds for autotag apply; without reprocess only posts that
+-- have no tags yet
+SELECT p.id, p.title, p.description
+FROM posts p
+JOIN feed_follows ff ON p.feed_id = ff.feed_id
+WHERE ff.user_id = sqlc.arg(user_id)
+  AND (sqlc.arg(reprocess)::bool OR NOT EXISTS (
+    SELECT 1 FROM post_tags pt WHERE pt.post_id = p.id
+  ))
+ORDER BY p.created_at;
@@ -15,4 +15,13 @@ SELECT * FROM users WHERE name = $1;
 SELECT * FROM users;
 
 -- name: ResetUsers :exec
-DELETE FROM users;
\ No newline at end of file
+DELETE FROM users;
+
+-- name: DeleteUser :execrows
+DELETE FROM users
+WHERE id = $1;
+
+-- name: SetUserPreferredLanguage :exec
+UPDATE users
+SET preferred_language = $2, updated_at = NOW()
+WHERE id = $1;
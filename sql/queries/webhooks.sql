@@ -0,0 +1,21 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, user_id, url, secret, feed_filter_id, keyword_filter, created_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetWebhooksForUser :many
+SELECT webhooks.*, feeds.url AS feed_url
+FROM webhooks
+LEFT JOIN feeds ON feeds.id = webhooks.feed_filter_id
+WHERE webhooks.user_id = $1
+ORDER BY webhooks.created_at;
+
+-- name: DeleteWebhook :execrows
+DELETE FROM webhooks
+WHERE id = $1 AND user_id = $2;
+
+-- name: GetWebhooksForFeed :many
+-- Webhooks of the feed's followers that cover it: those for this feed and those for every feed
+SELECT webhooks.* FROM webhooks
+JOIN feed_follows ON feed_follows.user_id = webhooks.user_id AND feed_follows.feed_id = sqlc.arg(feed_id)
+WHERE webhooks.feed_filter_id IS NULL OR webhooks.feed_filter_id = sqlc.arg(feed_id);
@@ -0,0 +1,4 @@
+-- Add HTTP cache validators to feeds table
+ALTER TABLE feeds
+ADD COLUMN last_etag TEXT NULL,
+ADD COLUMN last_modified TEXT NULL;
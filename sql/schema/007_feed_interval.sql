@@ -0,0 +1,3 @@
+-- Add per-feed polling interval (seconds) to feeds table
+ALTER TABLE feeds
+ADD COLUMN interval_secs INTEGER NULL;
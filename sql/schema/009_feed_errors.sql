@@ -0,0 +1,5 @@
+-- Track fetch failures per feed so broken feeds can be reported
+ALTER TABLE feeds
+ADD COLUMN consecutive_errors INTEGER NOT NULL DEFAULT 0,
+ADD COLUMN last_error TEXT NULL,
+ADD COLUMN last_attempted_at TIMESTAMP NULL;
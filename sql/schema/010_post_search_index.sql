@@ -0,0 +1,8 @@
+-- Full-text search index over post title, description and URL.
+-- An expression index keeps the posts table (and its sqlc model) unchanged;
+-- SearchPosts must repeat this exact expression to use it.
+CREATE INDEX posts_search_idx ON posts USING GIN ((
+    setweight(to_tsvector('english', title), 'A') ||
+    setweight(to_tsvector('english', COALESCE(description, '')), 'B') ||
+    setweight(to_tsvector('simple', url), 'C')
+));
@@ -0,0 +1,3 @@
+-- Per-feed override (seconds) of the global fetch timeout
+ALTER TABLE feeds
+ADD COLUMN fetch_timeout_secs INTEGER NULL;
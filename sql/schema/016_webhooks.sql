@@ -0,0 +1,11 @@
+-- Webhooks are called for a user's new posts, optionally only for one feed or keyword.
+-- The secret signs each delivery so receivers can check it came from gator.
+CREATE TABLE webhooks (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    feed_filter_id UUID NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    keyword_filter TEXT NULL,
+    created_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,7 @@
+-- Desktop notifications for new posts, switched on per user and feed
+CREATE TABLE notifications (
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    PRIMARY KEY (feed_id, user_id)
+);
@@ -0,0 +1,7 @@
+-- Media attached to a post, e.g. a podcast episode's audio file
+CREATE TABLE post_enclosures (
+    post_id UUID PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    media_type TEXT NOT NULL DEFAULT '',
+    length BIGINT NULL
+);
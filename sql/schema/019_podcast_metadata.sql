@@ -0,0 +1,15 @@
+-- iTunes podcast metadata: episode numbering and length per episode, and the
+-- show's details per feed
+ALTER TABLE post_enclosures
+    ADD COLUMN episode_number INT NULL,
+    ADD COLUMN season_number INT NULL,
+    ADD COLUMN duration_secs INT NULL;
+
+CREATE TABLE podcast_feeds (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    author TEXT NOT NULL DEFAULT '',
+    image_url TEXT NOT NULL DEFAULT '',
+    category TEXT NOT NULL DEFAULT '',
+    explicit BOOLEAN NOT NULL DEFAULT FALSE,
+    updated_at TIMESTAMP NOT NULL
+);
@@ -0,0 +1,8 @@
+-- Posts a user keeps at hand, each with an optional note on why
+CREATE TABLE pinned_posts (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    pinned_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    note TEXT,
+    PRIMARY KEY (user_id, post_id)
+);
@@ -0,0 +1,9 @@
+-- Posts a user means to read, in the order they mean to read them
+CREATE TABLE read_queue (
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    added_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    status TEXT NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'reading', 'done')),
+    PRIMARY KEY (user_id, post_id)
+);
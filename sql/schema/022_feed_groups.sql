@@ -0,0 +1,14 @@
+-- Named folders a user sorts their feeds into; a feed can sit in several
+CREATE TABLE feed_groups (
+    id UUID PRIMARY KEY,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    UNIQUE (user_id, name)
+);
+
+CREATE TABLE feed_group_members (
+    group_id UUID NOT NULL REFERENCES feed_groups(id) ON DELETE CASCADE,
+    feed_id UUID NOT NULL REFERENCES feeds(id) ON DELETE CASCADE,
+    PRIMARY KEY (group_id, feed_id)
+);
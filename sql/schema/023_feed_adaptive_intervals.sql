@@ -0,0 +1,7 @@
+-- Polling intervals learned from how often each feed publishes, used by agg --adaptive.
+-- A feed's own interval_secs, set with setinterval, still takes precedence.
+CREATE TABLE feed_adaptive_intervals (
+    feed_id UUID PRIMARY KEY REFERENCES feeds(id) ON DELETE CASCADE,
+    interval_secs INTEGER NOT NULL,
+    updated_at TIMESTAMP NOT NULL
+);
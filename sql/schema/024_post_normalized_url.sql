@@ -0,0 +1,8 @@
+-- Posts are deduplicated by their normalized URL (see internal/urlnorm) rather than the
+-- URL as published, which stays in url for display. Existing posts keep their URL as is.
+ALTER TABLE posts ADD COLUMN normalized_url TEXT;
+UPDATE posts SET normalized_url = url WHERE normalized_url IS NULL;
+ALTER TABLE posts ALTER COLUMN normalized_url SET NOT NULL;
+ALTER TABLE posts DROP CONSTRAINT IF EXISTS posts_url_key;
+CREATE UNIQUE INDEX posts_normalized_url_key ON posts (normalized_url);
+CREATE INDEX posts_url_idx ON posts (url);
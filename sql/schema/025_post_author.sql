@@ -0,0 +1,2 @@
+-- The item's author, from dc:creator, itunes:author or author in that order
+ALTER TABLE posts ADD COLUMN author TEXT;
@@ -0,0 +1,3 @@
+-- The language detected in each post, and the one a user wants to read in
+ALTER TABLE posts ADD COLUMN language TEXT;
+ALTER TABLE users ADD COLUMN preferred_language TEXT;
@@ -0,0 +1,19 @@
+-- SQL schema for tag_rules and post_tags tables
+CREATE TABLE tag_rules (
+    id UUID PRIMARY KEY,
+    created_at TIMESTAMP NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    keyword TEXT NOT NULL,
+    tag TEXT NOT NULL,
+    field TEXT NOT NULL CHECK (field IN ('title', 'description', 'any')),
+    case_sensitive BOOLEAN NOT NULL DEFAULT FALSE
+);
+
+CREATE TABLE post_tags (
+    post_id UUID NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    tag TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (post_id, tag)
+);
+
+CREATE INDEX post_tags_tag_idx ON post_tags (tag);
@@ -0,0 +1,3 @@
+-- Per-feed override of the User-Agent feed requests send
+ALTER TABLE feeds
+ADD COLUMN user_agent TEXT NULL;
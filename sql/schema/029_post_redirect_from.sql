@@ -0,0 +1,3 @@
+-- The link a post was published with, when agg resolved it to where it redirects
+ALTER TABLE posts ADD COLUMN redirect_from TEXT;
+CREATE INDEX posts_redirect_from_idx ON posts (redirect_from) WHERE redirect_from IS NOT NULL;
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestClassifyUpgrade(t *testing.T) {
+	cases := []struct {
+		stored, final string
+		want          feedUpgrade
+	}{
+		{"http://example.com/feed", "http://example.com/feed", upgradeNone},
+		{"http://example.com/feed", "http://example.com/rss", upgradeNone},
+		{"http://example.com/feed", "https://example.com/feed", upgradeHTTPS},
+		{"http://example.com/feed", "https://www.example.com/feed/", upgradeHTTPS},
+		{"http://Example.com/feed", "https://example.com/feed", upgradeHTTPS},
+		{"http://example.com/feed", "https://feeds.other.net/example", upgradeDomainChanged},
+	}
+	for _, tc := range cases {
+		if got := classifyUpgrade(tc.stored, tc.final); got != tc.want {
+			t.Errorf("classifyUpgrade(%q, %q) = %d, want %d", tc.stored, tc.final, got, tc.want)
+		}
+	}
+}
+
+func TestRedirectTarget(t *testing.T) {
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodHead && r.URL.Path == "/nohead" {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		w.Write([]byte("<rss></rss>"))
+	}))
+	defer secure.Close()
+	plain := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, secure.URL+r.URL.Path, http.StatusMovedPermanently)
+	}))
+	defer plain.Close()
+
+	client := secure.Client()
+	for _, path := range []string{"/feed", "/nohead"} {
+		got, err := redirectTarget(context.Background(), client, plain.URL+path)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", path, err)
+		}
+		if got != secure.URL+path {
+			t.Errorf("%s: redirect ended at %s, want %s", path, got, secure.URL+path)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gator/internal/output"
+)
+
+func TestValidateChecksStopAtBrokenConfig(t *testing.T) {
+	t.Setenv("GATOR_CONFIG_PATH", filepath.Join(t.TempDir(), "missing.json"))
+
+	checks := validateChecks(&state{})
+	want := []string{checkFail, checkWarn, checkWarn, checkWarn, checkWarn}
+	if len(checks) != len(want) {
+		t.Fatalf("expected %d checks, got %+v", len(want), checks)
+	}
+	for i, check := range checks {
+		if check.Status != want[i] {
+			t.Errorf("check %s: expected %s, got %s (%s)", check.Name, want[i], check.Status, check.Detail)
+		}
+	}
+	if got := worstStatus(checks); got != checkFail {
+		t.Errorf("expected a failed config to fail validation, got %s", got)
+	}
+}
+
+func TestWorstStatus(t *testing.T) {
+	pass := output.ValidateCheck{Status: checkPass}
+	warn := output.ValidateCheck{Status: checkWarn}
+	fail := output.ValidateCheck{Status: checkFail}
+	cases := []struct {
+		checks []output.ValidateCheck
+		want   string
+	}{
+		{nil, checkPass},
+		{[]output.ValidateCheck{pass, pass}, checkPass},
+		{[]output.ValidateCheck{pass, warn}, checkWarn},
+		{[]output.ValidateCheck{fail, warn}, checkFail},
+		{[]output.ValidateCheck{warn, fail}, checkFail},
+	}
+	for _, c := range cases {
+		if got := worstStatus(c.checks); got != c.want {
+			t.Errorf("worstStatus(%v) = %s, want %s", c.checks, got, c.want)
+		}
+	}
+}
+
+func TestIsHTTPURL(t *testing.T) {
+	for raw, want := range map[string]bool{
+		"https://example.com/feed.xml": true,
+		"http://example.com/rss":       true,
+		"ftp://example.com/feed":       false,
+		"example.com/feed":             false,
+		"https:///feed":                false,
+		"":                             false,
+	} {
+		if got := isHTTPURL(raw); got != want {
+			t.Errorf("isHTTPURL(%q) = %v, want %v", raw, got, want)
+		}
+	}
+}
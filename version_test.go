@@ -0,0 +1,43 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gator/internal/output"
+)
+
+func TestVersionCommand(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds the gator binary")
+	}
+
+	dir := t.TempDir()
+	binary := filepath.Join(dir, "gator")
+	if out, err := exec.Command("go", "build", "-o", binary, ".").CombinedOutput(); err != nil {
+		t.Fatalf("build failed: %v\n%s", err, out)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	if err := os.WriteFile(configPath, []byte(`{"db_url": "postgres://localhost/gator", "current_user_name": ""}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := exec.Command(binary, "--format", "json", "version")
+	cmd.Env = append(os.Environ(), "GATOR_CONFIG_PATH="+configPath)
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("version failed: %v", err)
+	}
+
+	var result output.VersionResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("version output isn't JSON: %v\n%s", err, out)
+	}
+	if result.Module != "gator" || result.Version == "" || !strings.HasPrefix(result.GoVersion, "go") {
+		t.Fatalf("unexpected version output: %+v", result)
+	}
+}
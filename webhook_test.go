@@ -0,0 +1,59 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"gator/internal/database"
+	"gator/internal/webhook"
+
+	"github.com/google/uuid"
+)
+
+func TestDeliverWebhooksFiltersByKeyword(t *testing.T) {
+	var mu sync.Mutex
+	received := map[string]webhook.Payload{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload webhook.Payload
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		received[r.URL.Path] = payload
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	s := &state{logger: slog.New(slog.NewTextHandler(io.Discard, nil))}
+	hooks := []database.Webhook{
+		{ID: uuid.New(), Url: srv.URL + "/all", Secret: "a"},
+		{ID: uuid.New(), Url: srv.URL + "/golang", Secret: "b", KeywordFilter: sql.NullString{String: "GoLang", Valid: true}},
+		{ID: uuid.New(), Url: srv.URL + "/rust", Secret: "c", KeywordFilter: sql.NullString{String: "rust", Valid: true}},
+	}
+	feed := database.Feed{ID: uuid.New(), Name: "Example", Url: "https://example.com/rss"}
+	post := database.CreatePostParams{
+		ID:          uuid.New(),
+		Title:       "What's new in golang",
+		Url:         "https://example.com/go",
+		Description: sql.NullString{String: "Release notes", Valid: true},
+		FeedID:      feed.ID,
+	}
+
+	deliverWebhooks(s, hooks, feed, post)
+	s.webhooks.Wait()
+
+	if len(received) != 2 {
+		t.Fatalf("expected deliveries to /all and /golang, got %v", received)
+	}
+	if _, ok := received["/rust"]; ok {
+		t.Error("webhook with a non-matching keyword was called")
+	}
+	got := received["/golang"]
+	if got.Event != webhook.EventNewPost || got.Post.ID != post.ID || got.Post.FeedName != "Example" || got.Post.Description != "Release notes" {
+		t.Errorf("unexpected payload %+v", got)
+	}
+}